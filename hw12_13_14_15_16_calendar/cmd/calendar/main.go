@@ -2,29 +2,41 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/app"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/blobstore"
+	localblob "github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/blobstore/local"
+	s3blob "github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/blobstore/s3"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/config"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/health"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/logger"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/metrics"
 	internalhttp "github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/server/http"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/storage"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/storage/instrumented"
 	memory "github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/storage/memory"
 	sql "github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/storage/sql"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/tracing"
 )
 
 var (
 	configFile  string
+	overrides   config.Overrides
 	showVersion bool
 )
 
 func init() {
 	flag.StringVar(&configFile, "config", "configs/config.yaml", "Path to configuration file")
+	flag.Var(&overrides, "set", "Override a config option, e.g. -set storage.type=sql (repeatable)")
 	flag.BoolVar(&showVersion, "version", false, "Show version information")
 }
 
@@ -36,7 +48,8 @@ func main() {
 		os.Exit(0)
 	}
 
-	cfg, err := config.LoadConfig(configFile)
+	loader := config.Loader{File: configFile, Overrides: overrides}
+	cfg, err := loader.Load()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
@@ -46,7 +59,21 @@ func main() {
 		log.Fatalf("Failed to create logger: %v", err)
 	}
 
-	store, err := initStorage(cfg, logg)
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing, "calendar")
+	if err != nil {
+		logg.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logg.Errorf("Failed to flush traces: %v", err)
+		}
+	}()
+
+	metricsInstance := metrics.NewMetrics(metrics.NewRegistry())
+
+	store, err := initStorage(cfg, logg, metricsInstance)
 	if err != nil {
 		logg.Fatalf("Failed to initialize storage: %v", err)
 	}
@@ -56,19 +83,41 @@ func main() {
 		}
 	}()
 
-	calendarApp := app.New(logg, store)
+	blobs, err := initBlobStore(cfg, logg)
+	if err != nil {
+		logg.Fatalf("Failed to initialize attachment storage: %v", err)
+	}
+
+	calendarApp := app.New(logg, store,
+		app.WithAttachments(blobs, cfg.Attachments),
+		app.WithQuotas(cfg.Quotas),
+	)
 
-	httpServer := internalhttp.NewServer(calendarApp, cfg.Server.Host, cfg.Server.Port)
+	ready := health.NewChecker(cfg.Health.Timeout).Add("storage", store.Ping)
+	httpServer := internalhttp.NewServer(calendarApp, cfg.Server, logg, metricsInstance, ready)
 
 	// Запускаем сервер в горутине для graceful shutdown.
 	go func() {
 		logg.Info("Starting calendar service...")
-		if err := httpServer.Start(); err != nil {
+		// ErrServerClosed — штатная остановка: main должен дойти до отложенных Close
+		if err := httpServer.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			logg.Error("Failed to start server: " + err.Error())
 			os.Exit(1)
 		}
 	}()
 
+	// По SIGHUP применяем уровень логирования; остальные параметры требуют перезапуска
+	reloadCtx, stopReload := context.WithCancel(context.Background())
+	defer stopReload()
+	go config.WatchReload(reloadCtx, loader, func(newCfg *config.Config, err error) {
+		if err != nil {
+			logg.Errorf("Failed to reload config, keeping current settings: %v", err)
+			return
+		}
+		logg.SetLevel(newCfg.Logger.Level)
+		logg.Infof("Config reloaded: logger.level=%s", newCfg.Logger.Level)
+	})
+
 	// Ожидаем сигналы для graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -87,16 +136,64 @@ func main() {
 	logg.Info("Server stopped")
 }
 
-func initStorage(cfg *config.Config, logg *logger.Logger) (storage.Storage, error) {
+// initStorage создает хранилище, записывающее метрики операций.
+func initStorage(cfg *config.Config, logg *logger.Logger, m *metrics.Metrics) (storage.Storage, error) {
 	if cfg.Storage.Type == "sql" {
 		store, err := sql.NewStorage(cfg.Storage.DSN)
 		if err != nil {
 			return nil, err
 		}
 		logg.Info("SQL storage initialized")
-		return store, nil
+		return instrumented.New(store, m, "sql"), nil
+	}
+
+	if dir := cfg.Storage.Memory.Dir; dir != "" {
+		store, err := memory.Open(memory.Options{
+			Dir:              dir,
+			Fsync:            memory.FsyncPolicy(cfg.Storage.Memory.Fsync),
+			FsyncInterval:    cfg.Storage.Memory.FsyncInterval,
+			SnapshotInterval: cfg.Storage.Memory.SnapshotInterval,
+			Logger:           logg,
+		})
+		if err != nil {
+			return nil, err
+		}
+		logg.Infof("In-memory storage initialized, persisted to %s", dir)
+		return instrumented.New(store, m, "memory"), nil
 	}
 
 	logg.Info("In-memory storage initialized")
-	return memory.NewStorage(), nil
+	return instrumented.New(memory.NewStorage(), m, "memory"), nil
+}
+
+func initBlobStore(cfg *config.Config, logg *logger.Logger) (blobstore.Store, error) {
+	switch cfg.Attachments.Backend {
+	case "local":
+		store, err := localblob.NewStore(cfg.Attachments.Local.Dir)
+		if err != nil {
+			return nil, err
+		}
+		logg.Info("Local attachment storage initialized")
+		return store, nil
+	case "s3":
+		s3cfg := cfg.Attachments.S3
+		store, err := s3blob.NewStore(context.Background(), s3blob.Config{
+			Endpoint:  s3cfg.Endpoint,
+			AccessKey: s3cfg.AccessKey,
+			SecretKey: s3cfg.SecretKey,
+			Bucket:    s3cfg.Bucket,
+			Region:    s3cfg.Region,
+			UseSSL:    s3cfg.UseSSL,
+		})
+		if err != nil {
+			return nil, err
+		}
+		logg.Info("S3 attachment storage initialized")
+		return store, nil
+	case "":
+		logg.Info("Attachments are disabled")
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown attachments backend %q", cfg.Attachments.Backend)
+	}
 }
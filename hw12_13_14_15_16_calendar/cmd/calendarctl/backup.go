@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/archive"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/server/http/api"
+	sqlstorage "github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/storage/sql"
+)
+
+// backupData сохраняет данные календаря из GET /api/archive и, если задан storage.dsn,
+// уведомления из БД сервиса сохранения в один архив.
+func backupData(ctx context.Context, c *cli, args []string) error {
+	var file string
+	fs := c.newFlagSet("backup", "[-f FILE]")
+	fs.StringVar(&file, "f", "-", "Archive file in JSON Lines (- for stdout)")
+	if _, err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	resp, err := client.ExportArchiveWithResponse(ctx)
+	if err != nil {
+		return err
+	}
+	if err := checkResponse(resp.HTTPResponse, resp.Body); err != nil {
+		return err
+	}
+	backup, err := archive.Read(bytes.NewReader(resp.Body))
+	if err != nil {
+		return fmt.Errorf("read archive from server: %w", err)
+	}
+
+	if c.config.Storage.DSN == "" {
+		fmt.Fprintln(c.stderr, "Notifications are not included: storage.dsn is not set")
+	} else {
+		store, err := c.notificationStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		listCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+		if backup.Notifications, err = store.ListAllNotifications(listCtx); err != nil {
+			return err
+		}
+	}
+
+	if file == "-" {
+		return archive.Write(c.stdout, backup)
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := archive.Write(f, backup); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	total := 0
+	for _, n := range backup.Counts() {
+		total += n
+	}
+	fmt.Fprintf(c.stderr, "Backed up %d records to %s\n", total, file)
+	return nil
+}
+
+// restoreData загружает архив в пустое хранилище календаря: через POST /api/archive
+// или, с -direct, напрямую в БД из storage.dsn. Уведомления записываются в БД
+// сервиса сохранения; без storage.dsn они пропускаются.
+func restoreData(ctx context.Context, c *cli, args []string) error {
+	var (
+		file   string
+		direct bool
+	)
+	fs := c.newFlagSet("restore", "[-f FILE] [-direct]")
+	fs.StringVar(&file, "f", "-", "Archive file in JSON Lines (- for stdin)")
+	fs.BoolVar(&direct, "direct", false,
+		"Load events into the SQL storage at storage.dsn instead of the calendar API")
+	if _, err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	var in io.Reader = c.stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = bufio.NewReader(f)
+	}
+	backup, err := archive.Read(in)
+	if err != nil {
+		return err
+	}
+
+	var result *api.ArchiveRestoreResult
+	if direct {
+		result, err = c.restoreDirect(ctx, backup)
+	} else {
+		result, err = c.restoreViaAPI(ctx, backup)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(backup.Notifications) > 0 {
+		if c.config.Storage.DSN == "" {
+			fmt.Fprintf(c.stderr, "Skipped %d notifications: storage.dsn is not set\n", len(backup.Notifications))
+		} else {
+			if err := c.restoreNotifications(ctx, backup); err != nil {
+				return err
+			}
+			result.Restored[archive.TypeNotification] = len(backup.Notifications)
+			delete(result.Skipped, archive.TypeNotification)
+		}
+	}
+
+	t := &table{header: []string{"TYPE", "RESTORED", "SKIPPED"}}
+	counts := backup.Counts()
+	for _, typ := range sortedKeys(counts) {
+		t.add(typ, strconv.Itoa(result.Restored[typ]), strconv.Itoa(result.Skipped[typ]))
+	}
+	return c.printer.print(result, t)
+}
+
+// restoreViaAPI отправляет календарную часть архива серверу.
+func (c *cli) restoreViaAPI(ctx context.Context, backup *archive.Archive) (*api.ArchiveRestoreResult, error) {
+	var body bytes.Buffer
+	if err := archive.Write(&body, &archive.Archive{CreatedAt: backup.CreatedAt, Snapshot: backup.Snapshot}); err != nil {
+		return nil, err
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.RestoreArchiveWithBodyWithResponse(ctx, archive.ContentType, &body)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(resp.HTTPResponse, resp.Body); err != nil {
+		return nil, err
+	}
+
+	result := resp.JSON200
+	if result.Skipped == nil {
+		result.Skipped = map[string]int{}
+	}
+	result.Skipped[archive.TypeNotification] = len(backup.Notifications)
+	return result, nil
+}
+
+// restoreDirect загружает архив в SQL-хранилище календаря, например при переходе
+// с хранилища в памяти: календарь при этом может быть не запущен.
+func (c *cli) restoreDirect(ctx context.Context, backup *archive.Archive) (*api.ArchiveRestoreResult, error) {
+	if c.config.Storage.DSN == "" {
+		return nil, errors.New("-direct loads into the database: set storage.dsn with -config, " +
+			"-set storage.dsn=... or CALENDAR_STORAGE_DSN")
+	}
+	store, err := sqlstorage.NewStorage(c.config.Storage.DSN)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+
+	if err := store.LoadSnapshot(ctx, &backup.Snapshot); err != nil {
+		return nil, err
+	}
+
+	restored := backup.Counts()
+	delete(restored, archive.TypeNotification)
+	return &api.ArchiveRestoreResult{
+		Restored: restored,
+		Skipped:  map[string]int{archive.TypeNotification: len(backup.Notifications)},
+	}, nil
+}
+
+// restoreNotifications сохраняет уведомления архива. Сохранение идемпотентно,
+// поэтому уже существующие уведомления не дублируются.
+func (c *cli) restoreNotifications(ctx context.Context, backup *archive.Archive) error {
+	store, err := c.notificationStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return store.SaveNotifications(ctx, backup.Notifications)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/server/http/api"
+)
+
+// client создает клиент API календаря, отправляющий X-User-ID инициатора.
+func (c *cli) client() (*api.ClientWithResponses, error) {
+	return api.NewClientWithResponses(c.server+"/api",
+		api.WithHTTPClient(c.http),
+		api.WithRequestEditorFn(func(_ context.Context, req *http.Request) error {
+			if c.actor != "" {
+				req.Header.Set("X-User-ID", c.actor)
+			}
+			return nil
+		}),
+	)
+}
+
+// checkResponse возвращает ошибку для неуспешного ответа API с сообщением из ErrorResponse.
+func checkResponse(resp *http.Response, body []byte) error {
+	if resp == nil {
+		return errors.New("no response")
+	}
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return nil
+	}
+
+	var apiErr api.ErrorResponse
+	if err := json.Unmarshal(body, &apiErr); err != nil {
+		return errors.New(resp.Status)
+	}
+	if message := firstNonEmpty(deref(apiErr.Message), deref(apiErr.Error)); message != "" {
+		return fmt.Errorf("%s: %s", resp.Status, message)
+	}
+	return errors.New(resp.Status)
+}
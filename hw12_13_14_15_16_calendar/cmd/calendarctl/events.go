@@ -0,0 +1,470 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/server/http/api"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// timeLayout — формат времени в таблицах
+const timeLayout = "2006-01-02 15:04"
+
+func eventsTable(events []api.Event) *table {
+	t := &table{header: []string{"ID", "TITLE", "START", "END", "USER", "PRIORITY", "VERSION"}}
+	for _, e := range events {
+		t.add(e.Id, e.Title, e.StartTime.Local().Format(timeLayout), e.EndTime.Local().Format(timeLayout),
+			e.UserId, deref(e.Priority), strconv.FormatInt(e.Version, 10))
+	}
+	return t
+}
+
+func listEvents(ctx context.Context, c *cli, args []string) error {
+	var (
+		tags     stringList
+		category string
+		priority string
+		from, to string
+	)
+	fs := c.newFlagSet("events list", "[-from TIME] [-to TIME] [-tag T]... [-category C] [-priority P]")
+	fs.StringVar(&from, "from", "", "Events starting from, RFC 3339 (default a month ago)")
+	fs.StringVar(&to, "to", "", "Events starting until, RFC 3339 (default a month ahead)")
+	fs.Var(&tags, "tag", "Only events with this tag (repeatable or comma separated)")
+	fs.StringVar(&category, "category", "", "Only events of this category")
+	fs.StringVar(&priority, "priority", "", "Only events of this priority: low, normal, high or urgent")
+	if _, err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	params := &api.ListEventsParams{}
+	if err := parseRange(from, to, params); err != nil {
+		return err
+	}
+	if len(tags) > 0 {
+		params.Tag = (*[]string)(&tags)
+	}
+	if category != "" {
+		params.Category = &category
+	}
+	if priority != "" {
+		p := api.Priority(priority)
+		params.Priority = &p
+	}
+
+	events, err := c.listEvents(ctx, params)
+	if err != nil {
+		return err
+	}
+	return c.printer.print(events, eventsTable(events))
+}
+
+// parseRange переносит границы интервала в параметры запроса; пустая граница остается
+// значением сервера по умолчанию.
+func parseRange(from, to string, params *api.ListEventsParams) error {
+	for _, bound := range []struct {
+		name  string
+		value string
+		dst   **time.Time
+	}{{"from", from, &params.From}, {"to", to, &params.To}} {
+		if bound.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, bound.value)
+		if err != nil {
+			return fmt.Errorf("-%s: %w", bound.name, err)
+		}
+		*bound.dst = &t
+	}
+	return nil
+}
+
+func (c *cli) listEvents(ctx context.Context, params *api.ListEventsParams) ([]api.Event, error) {
+	client, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.ListEventsWithResponse(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(resp.HTTPResponse, resp.Body); err != nil {
+		return nil, err
+	}
+	if resp.JSON200 == nil {
+		return nil, nil
+	}
+	return *resp.JSON200, nil
+}
+
+func getEvent(ctx context.Context, c *cli, args []string) error {
+	fs := c.newFlagSet("events get", "ID")
+	rest, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	resp, err := client.GetEventWithResponse(ctx, rest[0])
+	if err != nil {
+		return err
+	}
+	if err := checkResponse(resp.HTTPResponse, resp.Body); err != nil {
+		return err
+	}
+	return c.printer.print(resp.JSON200, eventsTable([]api.Event{*resp.JSON200}))
+}
+
+// eventFlags — поля события, задаваемые флагами create и update.
+type eventFlags struct {
+	title, description, start, end, user string
+	category, color, priority            string
+	notifyBefore                         time.Duration
+	tags                                 stringList
+}
+
+func (f *eventFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.title, "title", "", "Event title")
+	fs.StringVar(&f.description, "description", "", "Event description")
+	fs.StringVar(&f.start, "start", "", "Start time, RFC 3339 (2024-05-01T10:00:00+03:00)")
+	fs.StringVar(&f.end, "end", "", "End time, RFC 3339")
+	fs.StringVar(&f.user, "user", "", "Owner user ID")
+	fs.DurationVar(&f.notifyBefore, "notify-before", 0, "Notify this long before the start, e.g. 15m")
+	fs.Var(&f.tags, "tag", "Event tag (repeatable or comma separated)")
+	fs.StringVar(&f.category, "category", "", "Event category")
+	fs.StringVar(&f.color, "color", "", "Event color, #RRGGBB")
+	fs.StringVar(&f.priority, "priority", "", "Priority: low, normal, high or urgent")
+}
+
+// patch строит JSON Merge Patch из заданных флагов. Пустое значение необязательного
+// поля удаляет его из события.
+func (f *eventFlags) patch(set map[string]bool) (map[string]any, error) {
+	patch := make(map[string]any)
+	optional := func(name, value string) {
+		if !set[name] {
+			return
+		}
+		if value == "" {
+			patch[name] = nil
+			return
+		}
+		patch[name] = value
+	}
+
+	if set["title"] {
+		patch["title"] = f.title
+	}
+	if set["user"] {
+		patch["user_id"] = f.user
+	}
+	for name, value := range map[string]string{"start": f.start, "end": f.end} {
+		if !set[name] {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("-%s: %w", name, err)
+		}
+		patch[name+"_time"] = t
+	}
+	optional("description", f.description)
+	optional("category", f.category)
+	optional("color", f.color)
+	optional("priority", f.priority)
+	if set["notify-before"] {
+		if f.notifyBefore == 0 {
+			patch["notify_before"] = nil
+		} else {
+			patch["notify_before"] = int(f.notifyBefore.Seconds())
+		}
+	}
+	if set["tag"] {
+		patch["tags"] = []string(f.tags)
+	}
+	return patch, nil
+}
+
+func createEvent(ctx context.Context, c *cli, args []string) error {
+	var (
+		fields   eventFlags
+		file     string
+		template string
+		vars     variables
+	)
+	fs := c.newFlagSet("events create", createEventArgs)
+	fields.register(fs)
+	fs.StringVar(&file, "f", "", "Read the event from a JSON or YAML file (- for stdin) instead of flags")
+	fs.StringVar(&template, "template", "", "Create the event from this template; unset fields come from the template")
+	fs.Var(&vars, "var", "Title pattern variable of the template, name=value (repeatable)")
+	if _, err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	// Без шаблона тело — CreateEventRequest, с шаблоном — TemplateEventRequest
+	var (
+		body   api.CreateEventJSONRequestBody
+		params api.CreateEventParams
+		req    any = &api.CreateEventRequest{}
+	)
+	if template != "" {
+		params.Template = &template
+		req = &api.TemplateEventRequest{}
+	} else if len(vars) > 0 {
+		return errors.New("-var requires -template")
+	}
+
+	if file != "" {
+		if err := c.readDocument(file, req); err != nil {
+			return err
+		}
+	} else {
+		// Событие из флагов собирается тем же путем, что и из файла
+		patch, err := fields.patch(visited(fs))
+		if err != nil {
+			return err
+		}
+		if err := convert(patch, req); err != nil {
+			return err
+		}
+	}
+
+	switch req := req.(type) {
+	case *api.CreateEventRequest:
+		if err := body.FromCreateEventRequest(*req); err != nil {
+			return err
+		}
+	case *api.TemplateEventRequest:
+		if len(vars) > 0 {
+			req.Variables = (*map[string]string)(&vars)
+		}
+		if err := body.FromTemplateEventRequest(*req); err != nil {
+			return err
+		}
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	resp, err := client.CreateEventWithResponse(ctx, &params, body)
+	if err != nil {
+		return err
+	}
+	if err := checkResponse(resp.HTTPResponse, resp.Body); err != nil {
+		return err
+	}
+	return c.printer.print(resp.JSON201, eventsTable([]api.Event{*resp.JSON201}))
+}
+
+// createEventArgs — аргументы events create для справки
+const createEventArgs = "-title T -start TIME -end TIME -user U [...] | -f FILE | -template ID -start TIME -user U [-var K=V]..."
+
+// variables — повторяемый флаг name=value с подстановками заголовка шаблона.
+type variables map[string]string
+
+func (v *variables) String() string {
+	if v == nil {
+		return ""
+	}
+	pairs := make([]string, 0, len(*v))
+	for _, name := range sortedKeys(*v) {
+		pairs = append(pairs, name+"="+(*v)[name])
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (v *variables) Set(s string) error {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("expected name=value, got %q", s)
+	}
+	if *v == nil {
+		*v = make(variables)
+	}
+	(*v)[name] = value
+	return nil
+}
+
+func quickAddEvent(ctx context.Context, c *cli, args []string) error {
+	var (
+		user     string
+		timeZone string
+		create   bool
+	)
+	fs := c.newFlagSet("events quick", "TEXT... -user U [-tz ZONE] [-create]")
+	fs.StringVar(&user, "user", "", "Owner user ID")
+	fs.StringVar(&timeZone, "tz", "", "Time zone of relative dates, e.g. Europe/Moscow (default the user's working hours zone)")
+	fs.BoolVar(&create, "create", false, "Create the event instead of only showing how the text was understood")
+	rest, err := parseFlags(fs, args, -1)
+	if err != nil {
+		return err
+	}
+	if len(rest) == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	req := api.QuickEventRequest{Text: strings.Join(rest, " "), UserId: user}
+	if timeZone != "" {
+		req.TimeZone = &timeZone
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	resp, err := client.QuickAddEventWithResponse(ctx, &api.QuickAddEventParams{Create: &create}, req)
+	if err != nil {
+		return err
+	}
+	if err := checkResponse(resp.HTTPResponse, resp.Body); err != nil {
+		return err
+	}
+
+	if resp.JSON201 != nil {
+		return c.printer.print(resp.JSON201, eventsTable([]api.Event{*resp.JSON201.Event}))
+	}
+	parsed := resp.JSON200.Request
+	t := &table{header: []string{"TITLE", "START", "END", "NOTIFY BEFORE", "TAGS"}}
+	notify := ""
+	if parsed.NotifyBefore != nil {
+		notify = (time.Duration(*parsed.NotifyBefore) * time.Second).String()
+	}
+	tags := ""
+	if parsed.Tags != nil {
+		tags = strings.Join(*parsed.Tags, ",")
+	}
+	t.add(parsed.Title, parsed.StartTime.Format(timeLayout), parsed.EndTime.Format(timeLayout), notify, tags)
+	return c.printer.print(resp.JSON200, t)
+}
+
+func updateEvent(ctx context.Context, c *cli, args []string) error {
+	var (
+		fields  eventFlags
+		version int64
+	)
+	fs := c.newFlagSet("events update", "ID [-title T] [-start TIME] [...] [-version N]")
+	fields.register(fs)
+	fs.Int64Var(&version, "version", 0, "Fail if the event version differs (optimistic locking)")
+	rest, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	set := visited(fs)
+	patch, err := fields.patch(set)
+	if err != nil {
+		return err
+	}
+	if len(patch) == 0 {
+		return errors.New("nothing to update: set at least one field flag")
+	}
+	body, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	params := &api.PatchEventParams{IfMatch: ifMatch(set, version)}
+	resp, err := client.PatchEventWithBodyWithResponse(ctx, rest[0], params,
+		"application/merge-patch+json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if err := checkResponse(resp.HTTPResponse, resp.Body); err != nil {
+		return err
+	}
+	return c.printer.print(resp.JSON200, eventsTable([]api.Event{*resp.JSON200}))
+}
+
+func deleteEvent(ctx context.Context, c *cli, args []string) error {
+	var version int64
+	fs := c.newFlagSet("events delete", "ID [-version N]")
+	fs.Int64Var(&version, "version", 0, "Fail if the event version differs (optimistic locking)")
+	rest, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	params := &api.DeleteEventParams{IfMatch: ifMatch(visited(fs), version)}
+	resp, err := client.DeleteEventWithResponse(ctx, rest[0], params)
+	if err != nil {
+		return err
+	}
+	if err := checkResponse(resp.HTTPResponse, resp.Body); err != nil {
+		return err
+	}
+
+	t := &table{header: []string{"ID", "RESULT"}}
+	t.add(rest[0], "moved to trash")
+	return c.printer.print(resp.JSON200, t)
+}
+
+// ifMatch превращает ожидаемую версию события в ETag, который возвращает API.
+func ifMatch(set map[string]bool, version int64) *api.IfMatch {
+	if !set["version"] {
+		return nil
+	}
+	etag := strconv.Quote(strconv.FormatInt(version, 10))
+	return &etag
+}
+
+// readDocument читает JSON или YAML из файла или stdin ("-") в v. Неизвестные поля
+// считаются ошибкой, чтобы опечатка в имени поля не терялась молча.
+func (c *cli) readDocument(file string, v any) error {
+	var (
+		data []byte
+		err  error
+	)
+	if file == "-" {
+		data, err = io.ReadAll(c.stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return err
+	}
+
+	// JSON — подмножество YAML, поэтому оба формата разбираются одинаково
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("parse %s: %w", file, err)
+	}
+	if err := convert(doc, v); err != nil {
+		return fmt.Errorf("parse %s: %w", file, err)
+	}
+	return nil
+}
+
+// convert переносит значение в тип API через JSON, чтобы применились теги json.
+func convert(from, to any) error {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(to); err != nil {
+		return errors.New(strings.TrimPrefix(err.Error(), "json: "))
+	}
+	return nil
+}
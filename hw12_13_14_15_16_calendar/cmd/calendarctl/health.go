@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/health"
+)
+
+// probes — проверки, которые отдают календарь и адреса метрик фоновых сервисов
+var probes = []string{"/livez", "/readyz"}
+
+// probeResult — ответ одной проверки сервиса.
+type probeResult struct {
+	Service string        `json:"service"`
+	Probe   string        `json:"probe"`
+	Report  health.Report `json:"report"`
+	Error   string        `json:"error,omitempty"` // сервис недоступен или ответил не отчетом
+}
+
+// checkHealth опрашивает /livez и /readyz календаря или переданных адресов,
+// например http://localhost:9101 планировщика. Ошибка возвращается, если хотя бы
+// одна проверка не прошла, чтобы команду можно было использовать в скриптах.
+func checkHealth(ctx context.Context, c *cli, args []string) error {
+	fs := c.newFlagSet("health", "[URL...]")
+	services, err := parseFlags(fs, args, -1)
+	if err != nil {
+		return err
+	}
+	if len(services) == 0 {
+		services = []string{c.server}
+	}
+
+	var (
+		results []probeResult
+		failed  int
+	)
+	for _, service := range services {
+		service = strings.TrimSuffix(service, "/")
+		for _, probe := range probes {
+			result := c.probe(ctx, service, probe)
+			if result.Report.Status != health.StatusOK {
+				failed++
+			}
+			results = append(results, result)
+		}
+	}
+
+	t := &table{header: []string{"SERVICE", "PROBE", "CHECK", "STATUS", "DURATION", "ERROR"}}
+	for _, r := range results {
+		if r.Error != "" || len(r.Report.Checks) == 0 {
+			t.add(r.Service, r.Probe, "", r.Report.Status, "", r.Error)
+			continue
+		}
+		for _, name := range sortedKeys(r.Report.Checks) {
+			check := r.Report.Checks[name]
+			t.add(r.Service, r.Probe, name, check.Status, strconv.FormatInt(check.DurationMs, 10)+"ms", check.Error)
+		}
+	}
+	if err := c.printer.print(results, t); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d probes failed", failed, len(results))
+	}
+	return nil
+}
+
+func (c *cli) probe(ctx context.Context, service, probe string) probeResult {
+	result := probeResult{Service: service, Probe: probe, Report: health.Report{Status: health.StatusFail}}
+	fail := func(err error) probeResult {
+		result.Error = err.Error()
+		return result
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, service+probe, nil)
+	if err != nil {
+		return fail(err)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fail(err)
+	}
+	defer resp.Body.Close()
+
+	// При 503 тело тоже содержит отчет с причиной
+	if err := json.NewDecoder(resp.Body).Decode(&result.Report); err != nil {
+		return fail(fmt.Errorf("%s: %w", resp.Status, err))
+	}
+	if resp.StatusCode != http.StatusOK {
+		result.Report.Status = health.StatusFail
+	}
+	return result
+}
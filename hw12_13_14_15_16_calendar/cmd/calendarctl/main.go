@@ -0,0 +1,316 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/config"
+)
+
+// Переменные окружения утилиты. Адрес сервера также берется из конфигурации календаря
+// (-config и CALENDAR_SERVER_*), если не задан флагом или CALENDARCTL_SERVER.
+const (
+	serverEnv = "CALENDARCTL_SERVER"
+	actorEnv  = "CALENDARCTL_ACTOR"
+	outputEnv = "CALENDARCTL_OUTPUT"
+)
+
+// errUsage означает неверный вызов; справка уже выведена.
+var errUsage = errors.New("usage error")
+
+// command — подкоманда calendarctl. Команды с вложенными подкомандами задают subcommands.
+type command struct {
+	name        string
+	args        string
+	summary     string
+	run         func(ctx context.Context, c *cli, args []string) error
+	subcommands []*command
+}
+
+var commands = []*command{
+	{
+		name:    "events",
+		summary: "manage events",
+		subcommands: []*command{
+			{name: "list", args: "[-from TIME] [-to TIME] [-tag T]... [-category C] [-priority P]", summary: "list events", run: listEvents},
+			{name: "get", args: "ID", summary: "show an event", run: getEvent},
+			{name: "create", args: createEventArgs, summary: "create an event", run: createEvent},
+			{name: "quick", args: "TEXT... -user U [-tz ZONE] [-create]", summary: "parse a phrase like \"Lunch tomorrow 13:00\" into an event", run: quickAddEvent},
+			{name: "update", args: "ID [-title T] [-start TIME] [...] [-version N]", summary: "change event fields", run: updateEvent},
+			{name: "delete", args: "ID [-version N]", summary: "move an event to the trash", run: deleteEvent},
+		},
+	},
+	{name: "export", args: "[-f FILE] [-from TIME] [-to TIME]", summary: "write all events to an export file", run: exportEvents},
+	{name: "import", args: "[-f FILE] [-atomic]", summary: "create events from an export file", run: importEvents},
+	{name: "backup", args: "[-f FILE]", summary: "write all calendar data and notifications to an archive", run: backupData},
+	{name: "restore", args: "[-f FILE] [-direct]", summary: "load an archive into an empty storage", run: restoreData},
+	{
+		name:    "notifications",
+		summary: "inspect stored notifications",
+		subcommands: []*command{
+			{name: "list", args: "-user U [-from TIME] [-to TIME]", summary: "list notifications of a user", run: listNotifications},
+		},
+	},
+	{name: "health", args: "[URL...]", summary: "check /livez and /readyz of the calendar or the given services", run: checkHealth},
+}
+
+// cli — общие параметры, доступные командам.
+type cli struct {
+	server  string // адрес календаря без /api, например http://localhost:8080
+	actor   string // X-User-ID запросов, попадает в журнал аудита
+	config  *config.Config
+	timeout time.Duration
+	http    *http.Client
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
+	printer *printer
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr, os.LookupEnv)
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+	case errors.Is(err, errUsage):
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "calendarctl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer,
+	lookupEnv func(string) (string, bool),
+) error {
+	var (
+		configFile string
+		overrides  config.Overrides
+		server     string
+		actor      string
+		output     string
+		timeout    time.Duration
+	)
+	fs := flag.NewFlagSet("calendarctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&configFile, "config", "", "Calendar configuration file to take the server address and storage.dsn from")
+	fs.Var(&overrides, "set", "Override a config option, e.g. -set storage.dsn=postgres://... (repeatable)")
+	fs.StringVar(&server, "server", "", "Calendar address, e.g. http://localhost:8080 (env "+serverEnv+")")
+	fs.StringVar(&actor, "actor", "", "User ID sent as X-User-ID and recorded in the audit log (env "+actorEnv+")")
+	fs.StringVar(&output, "output", "", "Output format: table, json or yaml (env "+outputEnv+", default table)")
+	fs.DurationVar(&timeout, "timeout", 30*time.Second, "Timeout of a single request")
+	fs.Usage = func() { printUsage(fs) }
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return errUsage
+	}
+
+	cmd, cmdArgs, err := findCommand(fs.Args())
+	if err != nil {
+		fmt.Fprintf(stderr, "calendarctl: %v\n\n", err)
+		fs.Usage()
+		return errUsage
+	}
+
+	cfg, err := config.Loader{File: configFile, Overrides: overrides, LookupEnv: lookupEnv}.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	if output == "" {
+		output, _ = lookupEnv(outputEnv)
+	}
+	p, err := newPrinter(output, stdout)
+	if err != nil {
+		return err
+	}
+
+	c := &cli{
+		server:  resolveServer(server, cfg, lookupEnv),
+		actor:   firstNonEmpty(actor, lookupEnvValue(lookupEnv, actorEnv)),
+		config:  cfg,
+		timeout: timeout,
+		http:    &http.Client{Timeout: timeout},
+		stdin:   stdin,
+		stdout:  stdout,
+		stderr:  stderr,
+		printer: p,
+	}
+	return cmd.run(ctx, c, cmdArgs)
+}
+
+// findCommand находит команду по первым аргументам и возвращает оставшиеся.
+func findCommand(args []string) (*command, []string, error) {
+	list, path := commands, ""
+	for {
+		if len(args) == 0 {
+			if path == "" {
+				return nil, nil, errors.New("command required")
+			}
+			return nil, nil, fmt.Errorf("%s: subcommand required", path)
+		}
+
+		var found *command
+		for _, cmd := range list {
+			if cmd.name == args[0] {
+				found = cmd
+				break
+			}
+		}
+		if found == nil {
+			return nil, nil, fmt.Errorf("unknown command %q", strings.TrimSpace(path+" "+args[0]))
+		}
+
+		path, args = strings.TrimSpace(path+" "+found.name), args[1:]
+		if found.run != nil {
+			return found, args, nil
+		}
+		list = found.subcommands
+	}
+}
+
+func printUsage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintln(w, "Usage: calendarctl [flags] <command> [command flags] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		printCommand(w, "", cmd)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Flags:")
+	fs.PrintDefaults()
+}
+
+func printCommand(w io.Writer, prefix string, cmd *command) {
+	name := strings.TrimSpace(prefix + " " + cmd.name)
+	if cmd.run != nil {
+		fmt.Fprintf(w, "  %-24s %s\n      %s %s\n", name, cmd.summary, name, cmd.args)
+	}
+	for _, sub := range cmd.subcommands {
+		printCommand(w, name, sub)
+	}
+}
+
+// resolveServer выбирает адрес календаря: флаг -server, CALENDARCTL_SERVER, затем
+// server.host и server.port конфигурации.
+func resolveServer(flagValue string, cfg *config.Config, lookupEnv func(string) (string, bool)) string {
+	if server := firstNonEmpty(flagValue, lookupEnvValue(lookupEnv, serverEnv)); server != "" {
+		if !strings.Contains(server, "://") {
+			server = "http://" + server
+		}
+		return strings.TrimSuffix(server, "/")
+	}
+
+	// Сервис слушает все интерфейсы, подключаемся к локальному
+	host := cfg.Server.Host
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(cfg.Server.Port))
+}
+
+func lookupEnvValue(lookupEnv func(string) (string, bool), name string) string {
+	value, _ := lookupEnv(name)
+	return value
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// newFlagSet создает набор флагов подкоманды, который выводит ошибки в stderr утилиты.
+func (c *cli) newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: calendarctl %s %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags разбирает флаги подкоманды, допуская позиционные аргументы перед ними
+// (calendarctl events delete ID -version N), и проверяет число позиционных аргументов.
+func parseFlags(fs *flag.FlagSet, args []string, positional int) ([]string, error) {
+	var rest []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, errUsage
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		rest = append(rest, args[0])
+		args = args[1:]
+	}
+
+	if positional >= 0 && len(rest) != positional {
+		fmt.Fprintf(fs.Output(), "expected %d argument(s), got %d\n", positional, len(rest))
+		fs.Usage()
+		return nil, errUsage
+	}
+	return rest, nil
+}
+
+// stringList — повторяемый флаг, значения также можно перечислять через запятую.
+type stringList []string
+
+func (l *stringList) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(s string) error {
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+// visited возвращает имена флагов, заданных в командной строке.
+func visited(fs *flag.FlagSet) map[string]bool {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	return set
+}
+
+// sortedKeys нужен для стабильного вывода отчетов.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
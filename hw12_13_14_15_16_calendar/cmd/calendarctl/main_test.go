@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/app"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/config"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/logger"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/metrics"
+	internalhttp "github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/server/http"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/server/http/api"
+	memorystorage "github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return newConfiguredTestServer(t, config.ServerConfig{Archive: config.ArchiveConfig{Enabled: true}})
+}
+
+func newConfiguredTestServer(t *testing.T, cfg config.ServerConfig) *httptest.Server {
+	t.Helper()
+	calendarApp := app.New(logger.Nop(), memorystorage.NewStorage())
+	s := internalhttp.NewServer(calendarApp, cfg, logger.Nop(),
+		metrics.NewMetrics(metrics.NewRegistry()), nil)
+	server := httptest.NewServer(s.Handler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+// calendarctl запускает утилиту против server и возвращает stdout.
+func calendarctl(t *testing.T, server string, args ...string) (string, error) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	env := map[string]string{serverEnv: server}
+	err := run(context.Background(), args, strings.NewReader(""), &stdout, &stderr,
+		func(key string) (string, bool) {
+			v, ok := env[key]
+			return v, ok
+		})
+	return stdout.String(), err
+}
+
+func TestEventsCommands(t *testing.T) {
+	server := newTestServer(t)
+
+	out, err := calendarctl(t, server.URL, "-output", "json", "events", "create",
+		"-title", "Standup", "-user", "alice", "-tag", "team,daily", "-notify-before", "15m",
+		"-start", "2030-05-01T10:00:00Z", "-end", "2030-05-01T10:15:00Z")
+	require.NoError(t, err)
+	var created api.Event
+	require.NoError(t, json.Unmarshal([]byte(out), &created))
+	assert.Equal(t, "Standup", created.Title)
+	assert.Equal(t, []string{"team", "daily"}, *created.Tags)
+	assert.Equal(t, 900, *created.NotifyBefore)
+
+	out, err = calendarctl(t, server.URL, "events", "list", "-tag", "daily", "-from", "2030-01-01T00:00:00Z",
+		"-to", "2031-01-01T00:00:00Z")
+	require.NoError(t, err)
+	assert.Contains(t, out, "ID")
+	assert.Contains(t, out, created.Id)
+	assert.Contains(t, out, "Standup")
+
+	// Флаги после ID и частичное изменение: остальные поля сохраняются
+	out, err = calendarctl(t, server.URL, "-output", "yaml", "events", "update", created.Id,
+		"-title", "Daily standup", "-version", fmt.Sprint(created.Version))
+	require.NoError(t, err)
+	assert.Contains(t, out, "title: Daily standup")
+	assert.Contains(t, out, "user_id: alice")
+
+	_, err = calendarctl(t, server.URL, "events", "delete", created.Id, "-version", fmt.Sprint(created.Version))
+	assert.ErrorContains(t, err, "412")
+
+	_, err = calendarctl(t, server.URL, "events", "delete", created.Id)
+	require.NoError(t, err)
+
+	_, err = calendarctl(t, server.URL, "events", "get", created.Id)
+	assert.ErrorContains(t, err, "404")
+}
+
+func TestCreateFromTemplate(t *testing.T) {
+	server := newTestServer(t)
+
+	client, err := api.NewClientWithResponses(server.URL + "/api")
+	require.NoError(t, err)
+	resp, err := client.CreateTemplateWithResponse(context.Background(), api.EventTemplateRequest{
+		Name:         "Handover",
+		TitlePattern: "On-call handover {user} -> {to}",
+		Duration:     900,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp.JSON201)
+
+	out, err := calendarctl(t, server.URL, "-output", "json", "events", "create", "-template", resp.JSON201.Id,
+		"-user", "alice", "-start", "2030-05-01T10:00:00Z", "-var", "to=bob")
+	require.NoError(t, err)
+	var created api.Event
+	require.NoError(t, json.Unmarshal([]byte(out), &created))
+	assert.Equal(t, "On-call handover alice -> bob", created.Title)
+	assert.Equal(t, "2030-05-01T10:15:00Z", created.EndTime.UTC().Format(time.RFC3339))
+
+	_, err = calendarctl(t, server.URL, "events", "create", "-title", "X", "-var", "to=bob")
+	assert.ErrorContains(t, err, "-var requires -template")
+}
+
+func TestQuickAdd(t *testing.T) {
+	server := newTestServer(t)
+
+	out, err := calendarctl(t, server.URL, "events", "quick", "Lunch", "with", "Anna", "tomorrow", "13:00",
+		"for", "1h", "remind", "15m", "-user", "alice", "-tz", "UTC")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Lunch with Anna")
+	assert.Contains(t, out, "15m0s")
+
+	events, err := calendarctl(t, server.URL, "events", "list")
+	require.NoError(t, err)
+	assert.NotContains(t, events, "Lunch with Anna")
+
+	out, err = calendarctl(t, server.URL, "-output", "json", "events", "quick", "-create", "-user", "alice",
+		"Обед завтра в 13:00 на 30 минут")
+	require.NoError(t, err)
+	var resp api.QuickEventResponse
+	require.NoError(t, json.Unmarshal([]byte(out), &resp))
+	require.NotNil(t, resp.Event)
+	assert.Equal(t, "Обед", resp.Event.Title)
+
+	_, err = calendarctl(t, server.URL, "events", "quick", "-user", "alice", "Lunch tomorrow")
+	assert.ErrorContains(t, err, "400")
+}
+
+func TestExportImport(t *testing.T) {
+	// Предел пакета у target меньше, чем пакеты calendarctl: import уменьшает их сам
+	source := newTestServer(t)
+	target := newConfiguredTestServer(t, config.ServerConfig{MaxBatchOperations: 40})
+
+	const count = maxBatchOperations + 5
+	for i := 0; i < count; i++ {
+		_, err := calendarctl(t, source.URL, "events", "create",
+			"-title", fmt.Sprintf("Event %d", i), "-user", "bob", "-category", "work",
+			"-start", fmt.Sprintf("2030-06-01T%02d:%02d:00Z", i/60, i%60),
+			"-end", fmt.Sprintf("2030-06-01T%02d:%02d:30Z", i/60, i%60))
+		require.NoError(t, err)
+	}
+
+	file := filepath.Join(t.TempDir(), "events.yaml")
+	_, err := calendarctl(t, source.URL, "-output", "yaml", "export", "-f", file)
+	require.NoError(t, err)
+
+	_, err = calendarctl(t, target.URL, "import", "-f", file)
+	require.NoError(t, err)
+
+	out, err := calendarctl(t, target.URL, "-output", "json", "events", "list", "-category", "work",
+		"-from", "2030-06-01T00:00:00Z", "-to", "2030-06-02T00:00:00Z")
+	require.NoError(t, err)
+	var imported []api.Event
+	require.NoError(t, json.Unmarshal([]byte(out), &imported))
+	assert.Len(t, imported, count)
+
+	// Загрузка файла другой версии отклоняется
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(file, bytes.Replace(data, []byte("version: 1"), []byte("version: 2"), 1), 0o600))
+	_, err = calendarctl(t, target.URL, "import", "-f", file)
+	assert.ErrorContains(t, err, "unsupported export file version 2")
+}
+
+func TestBackupRestore(t *testing.T) {
+	source, target := newTestServer(t), newTestServer(t)
+
+	out, err := calendarctl(t, source.URL, "-output", "json", "events", "create",
+		"-title", "Planning", "-user", "carol", "-start", "2030-07-01T10:00:00Z", "-end", "2030-07-01T11:00:00Z")
+	require.NoError(t, err)
+	var kept api.Event
+	require.NoError(t, json.Unmarshal([]byte(out), &kept))
+	_, err = calendarctl(t, source.URL, "events", "update", kept.Id, "-title", "Sprint planning")
+	require.NoError(t, err)
+
+	out, err = calendarctl(t, source.URL, "-output", "json", "events", "create",
+		"-title", "Cancelled", "-user", "carol", "-start", "2030-07-02T10:00:00Z", "-end", "2030-07-02T11:00:00Z")
+	require.NoError(t, err)
+	var trashed api.Event
+	require.NoError(t, json.Unmarshal([]byte(out), &trashed))
+	_, err = calendarctl(t, source.URL, "events", "delete", trashed.Id)
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "calendar.jsonl")
+	_, err = calendarctl(t, source.URL, "backup", "-f", file)
+	require.NoError(t, err)
+
+	out, err = calendarctl(t, target.URL, "restore", "-f", file)
+	require.NoError(t, err)
+	assert.Regexp(t, `event\s+2\s+0`, out)
+
+	// ID и версии сохраняются, в отличие от import
+	out, err = calendarctl(t, target.URL, "-output", "json", "events", "get", kept.Id)
+	require.NoError(t, err)
+	var restored api.Event
+	require.NoError(t, json.Unmarshal([]byte(out), &restored))
+	assert.Equal(t, "Sprint planning", restored.Title)
+	assert.Equal(t, int64(2), restored.Version)
+
+	_, err = calendarctl(t, target.URL, "restore", "-f", file)
+	assert.ErrorContains(t, err, "409")
+	_, err = calendarctl(t, target.URL, "restore", "-direct", "-f", file)
+	assert.ErrorContains(t, err, "storage.dsn")
+
+	// Обрезанный архив не загружается
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(file, data[:len(data)-10], 0o600))
+	_, err = calendarctl(t, newTestServer(t).URL, "restore", "-f", file)
+	assert.ErrorContains(t, err, "invalid archive")
+}
+
+func TestHealthCommand(t *testing.T) {
+	server := newTestServer(t)
+
+	out, err := calendarctl(t, server.URL, "health")
+	require.NoError(t, err)
+	assert.Contains(t, out, "/livez")
+	assert.Contains(t, out, "/readyz")
+
+	_, err = calendarctl(t, server.URL, "health", "http://127.0.0.1:1")
+	assert.ErrorContains(t, err, "2 of 2 probes failed")
+}
+
+func TestResolveServer(t *testing.T) {
+	cfg := config.Default()
+	noEnv := func(string) (string, bool) { return "", false }
+
+	assert.Equal(t, "http://localhost:8080", resolveServer("", &cfg, noEnv))
+	assert.Equal(t, "http://calendar:9000", resolveServer("calendar:9000/", &cfg, noEnv))
+	assert.Equal(t, "https://env.example", resolveServer("", &cfg, func(key string) (string, bool) {
+		return "https://env.example", key == serverEnv
+	}))
+
+	cfg.Server.Host = "10.0.0.5"
+	assert.Equal(t, "http://10.0.0.5:8080", resolveServer("", &cfg, noEnv))
+}
+
+func TestUsage(t *testing.T) {
+	_, err := calendarctl(t, "", "events")
+	assert.ErrorIs(t, err, errUsage)
+	_, err = calendarctl(t, "", "bogus")
+	assert.ErrorIs(t, err, errUsage)
+	_, err = calendarctl(t, "", "events", "get")
+	assert.ErrorIs(t, err, errUsage)
+}
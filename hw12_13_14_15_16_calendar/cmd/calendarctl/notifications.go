@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/storage/notifications"
+)
+
+// notificationsWindow — период по умолчанию до и после текущего момента
+const notificationsWindow = 30 * 24 * time.Hour
+
+// listNotifications читает уведомления из хранилища сервиса сохранения: API календаря
+// их не отдает. Адрес БД берется из storage.dsn конфигурации.
+func listNotifications(ctx context.Context, c *cli, args []string) error {
+	var user, from, to string
+	fs := c.newFlagSet("notifications list", "-user U [-from TIME] [-to TIME]")
+	fs.StringVar(&user, "user", "", "User ID (required)")
+	fs.StringVar(&from, "from", "", "Notify time from, RFC 3339 (default 30 days ago)")
+	fs.StringVar(&to, "to", "", "Notify time to, RFC 3339 (default 30 days ahead)")
+	if _, err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+	if user == "" {
+		fs.Usage()
+		return errUsage
+	}
+
+	now := time.Now()
+	fromTime, err := parseTimeOr(from, now.Add(-notificationsWindow))
+	if err != nil {
+		return fmt.Errorf("-from: %w", err)
+	}
+	toTime, err := parseTimeOr(to, now.Add(notificationsWindow))
+	if err != nil {
+		return fmt.Errorf("-to: %w", err)
+	}
+
+	store, err := c.notificationStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	list, err := store.GetNotifications(ctx, user, fromTime, toTime)
+	if err != nil {
+		return err
+	}
+	if list == nil {
+		list = []*models.Notification{}
+	}
+
+	t := &table{header: []string{"ID", "EVENT", "TITLE", "NOTIFY AT", "MESSAGE"}}
+	for _, n := range list {
+		t.add(n.ID, n.EventID, n.EventTitle, n.NotifyAt.Local().Format(timeLayout), n.Message)
+	}
+	return c.printer.print(list, t)
+}
+
+// errNoDSN — команде нужен адрес БД, а storage.dsn не задан.
+var errNoDSN = errors.New("notifications are stored in the database: set storage.dsn with -config, " +
+	"-set storage.dsn=... or CALENDAR_STORAGE_DSN")
+
+// notificationStore подключается к хранилищу уведомлений по storage.dsn.
+func (c *cli) notificationStore() (*notifications.PostgresNotificationStorage, error) {
+	if c.config.Storage.DSN == "" {
+		return nil, errNoDSN
+	}
+	return notifications.NewPostgresNotificationStorage(c.config.Storage.DSN)
+}
+
+func parseTimeOr(value string, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/server/http/api"
+)
+
+// exportVersion — версия формата файла выгрузки
+const exportVersion = 1
+
+// Интервал выгрузки по умолчанию охватывает все события
+var (
+	exportFrom = time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	exportTo   = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+)
+
+// maxBatchOperations — число операций в одном запросе /events:batch по умолчанию.
+// Если у сервера server.max_batch_operations меньше, пакеты уменьшаются до его предела.
+const maxBatchOperations = 100
+
+// exportFile — файл выгрузки событий. ID и версии сохраняются для справки:
+// при загрузке события создаются заново и получают новые ID.
+type exportFile struct {
+	Version    int         `json:"version"`
+	ExportedAt time.Time   `json:"exported_at"`
+	Server     string      `json:"server"`
+	Events     []api.Event `json:"events"`
+}
+
+func exportEvents(ctx context.Context, c *cli, args []string) error {
+	var file, from, to string
+	fs := c.newFlagSet("export", "[-f FILE] [-from TIME] [-to TIME]")
+	fs.StringVar(&file, "f", "-", "Export file (- for stdout); YAML with -output yaml, otherwise JSON")
+	fs.StringVar(&from, "from", "", "Only events starting from, RFC 3339 (default all)")
+	fs.StringVar(&to, "to", "", "Only events starting until, RFC 3339 (default all)")
+	if _, err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	params := &api.ListEventsParams{From: &exportFrom, To: &exportTo}
+	if err := parseRange(from, to, params); err != nil {
+		return err
+	}
+	events, err := c.listEvents(ctx, params)
+	if err != nil {
+		return err
+	}
+	export := exportFile{
+		Version:    exportVersion,
+		ExportedAt: time.Now().UTC(),
+		Server:     c.server,
+		Events:     events,
+	}
+
+	write := writeJSON
+	if c.printer.format == outputYAML {
+		write = writeYAML
+	}
+	if file == "-" {
+		return write(c.stdout, export)
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := write(f, export); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stderr, "Exported %d events to %s\n", len(events), file)
+	return nil
+}
+
+func importEvents(ctx context.Context, c *cli, args []string) error {
+	var (
+		file   string
+		atomic bool
+	)
+	fs := c.newFlagSet("import", "[-f FILE] [-atomic]")
+	fs.StringVar(&file, "f", "-", "Export file in JSON or YAML (- for stdin)")
+	fs.BoolVar(&atomic, "atomic", false,
+		"Create each batch of up to "+strconv.Itoa(maxBatchOperations)+" events all or nothing")
+	if _, err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	var export exportFile
+	if err := c.readDocument(file, &export); err != nil {
+		return err
+	}
+	if export.Version != exportVersion {
+		return fmt.Errorf("unsupported export file version %d, expected %d", export.Version, exportVersion)
+	}
+
+	results, err := c.createEvents(ctx, export.Events, atomic)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	t := &table{header: []string{"#", "SOURCE ID", "STATUS", "ID", "ERROR"}}
+	for _, result := range results {
+		id := ""
+		if result.Event != nil {
+			id = result.Event.Id
+		}
+		if result.Status >= http.StatusMultipleChoices {
+			failed++
+		}
+		t.add(strconv.Itoa(result.Index), export.Events[result.Index].Id, strconv.Itoa(result.Status), id,
+			deref(result.Error))
+	}
+	if err := c.printer.print(results, t); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d events were not imported", failed, len(results))
+	}
+	return nil
+}
+
+// createEvents создает события пакетами /events:batch и возвращает результат по каждому
+// событию; Index результата — номер события в events.
+func (c *cli) createEvents(ctx context.Context, events []api.Event, atomic bool) ([]api.BatchResult, error) {
+	client, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+
+	mode := api.BatchBestEffort
+	if atomic {
+		mode = api.BatchAtomic
+	}
+
+	results := make([]api.BatchResult, 0, len(events))
+	size := maxBatchOperations
+	for start := 0; start < len(events); start += size {
+		chunk := events[start:min(start+size, len(events))]
+		req := api.BatchRequest{Mode: &mode, Operations: make([]api.BatchOperation, 0, len(chunk))}
+		for _, e := range chunk {
+			event := eventRequest(e)
+			req.Operations = append(req.Operations, api.BatchOperation{Op: api.BatchCreate, Event: &event})
+		}
+
+		resp, err := client.BatchEventsWithResponse(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if limit := batchLimit(resp); limit > 0 && limit < len(chunk) {
+			// Повторяем тот же пакет по пределу сервера
+			size = limit
+			start -= size
+			continue
+		}
+		if err := checkResponse(resp.HTTPResponse, resp.Body); err != nil {
+			return nil, fmt.Errorf("import events %d-%d: %w", start, start+len(chunk)-1, err)
+		}
+		for _, result := range resp.JSON200.Results {
+			result.Index += start
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// batchLimit возвращает предел операций из ответа 413 на слишком большой пакет или 0.
+func batchLimit(resp *api.BatchEventsResponse) int {
+	if resp.JSON413 == nil || resp.JSON413.Limit == nil {
+		return 0
+	}
+	return *resp.JSON413.Limit
+}
+
+// eventRequest возвращает поля события, которые можно задать при создании.
+func eventRequest(e api.Event) api.UpdateEventRequest {
+	return api.UpdateEventRequest{
+		Title:        e.Title,
+		Description:  e.Description,
+		StartTime:    e.StartTime,
+		EndTime:      e.EndTime,
+		UserId:       e.UserId,
+		NotifyBefore: e.NotifyBefore,
+		Tags:         e.Tags,
+		Category:     e.Category,
+		Color:        e.Color,
+		Priority:     e.Priority,
+		ResourceIds:  e.ResourceIds,
+	}
+}
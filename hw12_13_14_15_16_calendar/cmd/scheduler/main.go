@@ -3,32 +3,48 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/app"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/blobstore"
+	localblob "github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/blobstore/local"
+	s3blob "github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/blobstore/s3"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/config"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/health"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/logger"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/metrics"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/mq/kafka"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/storage"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/storage/instrumented"
 	memory "github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/storage/memory"
 	sql "github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/storage/sql"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/tracing"
 )
 
-var configFile string
+// defaultMetricsAddr — адрес метрик и проверок, если metrics.addr не задан
+const defaultMetricsAddr = ":9101"
+
+var (
+	configFile string
+	overrides  config.Overrides
+)
 
 func init() {
 	flag.StringVar(&configFile, "config", "configs/scheduler.yaml", "Path to configuration file")
+	flag.Var(&overrides, "set", "Override a config option, e.g. -set kafka.brokers=kafka:9092 (repeatable)")
 }
 
 func main() {
 	flag.Parse()
 
-	cfg, err := config.LoadConfig(configFile)
+	loader := config.Loader{File: configFile, Overrides: overrides}
+	cfg, err := loader.Load()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
@@ -38,19 +54,37 @@ func main() {
 		log.Fatalf("Failed to create logger: %v", err)
 	}
 
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing, "calendar-scheduler")
+	if err != nil {
+		logg.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logg.Errorf("Failed to flush traces: %v", err)
+		}
+	}()
+
+	metricsInstance := metrics.NewMetrics(metrics.NewRegistry())
+
 	var store storage.Storage
 	if cfg.Storage.Type == "sql" {
-		store, err = sql.NewStorage(cfg.Storage.DSN)
+		sqlStore, err := sql.NewStorage(cfg.Storage.DSN)
 		if err != nil {
 			logg.Fatalf("Failed to create SQL storage: %v", err)
 		}
+		store = instrumented.New(sqlStore, metricsInstance, "sql")
 	} else {
-		store = memory.NewStorage()
+		store = instrumented.New(memory.NewStorage(), metricsInstance, "memory")
 	}
 	defer store.Close()
 
 	// Создаем и подключаем Kafka producer с retry
-	producer := kafka.NewProducer(cfg.Kafka.Brokers, cfg.Kafka.Topic)
+	producer, err := kafka.NewProducer(cfg.Kafka, metricsInstance)
+	if err != nil {
+		logg.Fatalf("Failed to create Kafka producer: %v", err)
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -62,8 +96,13 @@ func main() {
 
 	logg.Info("Successfully connected to Kafka")
 
-	calendarApp := app.New(logg, store)
-	metricsInstance := metrics.NewMetrics()
+	// Хранилище вложений нужно, чтобы удалять содержимое вложений событий, удаляемых из корзины
+	blobs, err := initBlobStore(cfg, logg)
+	if err != nil {
+		logg.Fatalf("Failed to initialize attachment storage: %v", err)
+	}
+
+	calendarApp := app.New(logg, store, app.WithAttachments(blobs, cfg.Attachments))
 	scheduler := app.NewScheduler(calendarApp, producer, logg, cfg.Scheduler, metricsInstance)
 
 	// Graceful shutdown
@@ -78,9 +117,83 @@ func main() {
 		mainCancel()
 	}()
 
+	// Планировщик жив, пока его цикл отмечается; по умолчанию допускаем три пропущенных
+	// прохода. Интервал читается при каждой проверке, так как меняется при перезагрузке
+	liveness := func(ctx context.Context) error {
+		heartbeatTimeout := cfg.Health.HeartbeatTimeout
+		if heartbeatTimeout <= 0 {
+			heartbeatTimeout = 3 * scheduler.Config().Interval
+		}
+		return scheduler.Heartbeat().Check(heartbeatTimeout)(ctx)
+	}
+	probes := map[string]http.Handler{
+		"/livez": health.NewChecker(cfg.Health.Timeout).
+			Add("scheduler", liveness),
+		"/readyz": health.NewChecker(cfg.Health.Timeout).
+			Add("storage", store.Ping).
+			Add("kafka", producer.Ping),
+	}
+
+	// По SIGHUP применяем уровень логирования и параметры планировщика;
+	// остальные параметры требуют перезапуска
+	go config.WatchReload(mainCtx, loader, func(newCfg *config.Config, err error) {
+		if err != nil {
+			logg.Errorf("Failed to reload config, keeping current settings: %v", err)
+			return
+		}
+		logg.SetLevel(newCfg.Logger.Level)
+		scheduler.UpdateConfig(newCfg.Scheduler)
+		logg.Infof("Config reloaded: logger.level=%s, scheduler.interval=%s",
+			newCfg.Logger.Level, newCfg.Scheduler.Interval)
+	})
+
+	// Сервер метрик отдает и проверки /livez, /readyz, поэтому запускается всегда
+	metricsAddr := cfg.Metrics.Addr
+	if metricsAddr == "" {
+		metricsAddr = defaultMetricsAddr
+	}
+	go func() {
+		logg.Infof("Serving metrics on %s", metricsAddr)
+		if err := metrics.ListenAndServe(mainCtx, metricsAddr, metricsInstance, probes); err != nil {
+			logg.Errorf("Metrics server error: %v", err)
+		}
+	}()
+
 	logg.Info("Starting scheduler...")
 	if err := scheduler.Run(mainCtx); err != nil {
 		logg.Errorf("Scheduler error: %v", err)
 		os.Exit(1)
 	}
 }
+
+func initBlobStore(cfg *config.Config, logg *logger.Logger) (blobstore.Store, error) {
+	switch cfg.Attachments.Backend {
+	case "local":
+		store, err := localblob.NewStore(cfg.Attachments.Local.Dir)
+		if err != nil {
+			return nil, err
+		}
+		logg.Info("Local attachment storage initialized")
+		return store, nil
+	case "s3":
+		s3cfg := cfg.Attachments.S3
+		store, err := s3blob.NewStore(context.Background(), s3blob.Config{
+			Endpoint:  s3cfg.Endpoint,
+			AccessKey: s3cfg.AccessKey,
+			SecretKey: s3cfg.SecretKey,
+			Bucket:    s3cfg.Bucket,
+			Region:    s3cfg.Region,
+			UseSSL:    s3cfg.UseSSL,
+		})
+		if err != nil {
+			return nil, err
+		}
+		logg.Info("S3 attachment storage initialized")
+		return store, nil
+	case "":
+		logg.Info("Attachments are disabled")
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown attachments backend %q", cfg.Attachments.Backend)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"flag"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
@@ -11,21 +12,36 @@ import (
 
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/app"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/config"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/health"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/logger"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/metrics"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/mq/kafka"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/storage/notifications"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/tracing"
 )
 
-var configFile string
+var (
+	configFile string
+	overrides  config.Overrides
+)
+
+// defaultHeartbeatTimeout — сколько цикл чтения может не отмечаться, прежде чем
+// сервис сохранения будет считаться зависшим
+const defaultHeartbeatTimeout = time.Minute
+
+// defaultMetricsAddr — адрес метрик и проверок, если metrics.addr не задан
+const defaultMetricsAddr = ":9102"
 
 func init() {
 	flag.StringVar(&configFile, "config", "configs/storer.yaml", "Path to configuration file")
+	flag.Var(&overrides, "set", "Override a config option, e.g. -set kafka.brokers=kafka:9092 (repeatable)")
 }
 
 func main() {
 	flag.Parse()
 
-	cfg, err := config.LoadConfig(configFile)
+	loader := config.Loader{File: configFile, Overrides: overrides}
+	cfg, err := loader.Load()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
@@ -35,6 +51,18 @@ func main() {
 		log.Fatalf("Failed to create logger: %v", err)
 	}
 
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing, "calendar-storer")
+	if err != nil {
+		logg.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logg.Errorf("Failed to flush traces: %v", err)
+		}
+	}()
+
 	// Создаем хранилище для уведомлений
 	notificationStore, err := notifications.NewPostgresNotificationStorage(cfg.Storage.DSN)
 	if err != nil {
@@ -43,7 +71,11 @@ func main() {
 	defer notificationStore.Close()
 
 	// Создаем и подключаем Kafka consumer с retry
-	consumer := kafka.NewConsumer(cfg.Kafka.Brokers, cfg.Kafka.Topic, cfg.Kafka.GroupID)
+	metricsInstance := metrics.NewMetrics(metrics.NewRegistry())
+	consumer, err := kafka.NewConsumer(cfg.Kafka, logg, metricsInstance)
+	if err != nil {
+		logg.Fatalf("Failed to create Kafka consumer: %v", err)
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -55,7 +87,7 @@ func main() {
 
 	logg.Info("Successfully connected to Kafka")
 
-	storer := app.NewStorer(notificationStore, consumer, logg)
+	storer := app.NewStorer(notificationStore, consumer, cfg.Storer, logg, metricsInstance)
 
 	// Graceful shutdown
 	mainCtx, mainCancel := context.WithCancel(context.Background())
@@ -69,6 +101,40 @@ func main() {
 		mainCancel()
 	}()
 
+	heartbeatTimeout := cfg.Health.HeartbeatTimeout
+	if heartbeatTimeout <= 0 {
+		heartbeatTimeout = defaultHeartbeatTimeout
+	}
+	probes := map[string]http.Handler{
+		"/livez": health.NewChecker(cfg.Health.Timeout).
+			Add("storer", storer.Heartbeat().Check(heartbeatTimeout)),
+		"/readyz": health.NewChecker(cfg.Health.Timeout).
+			Add("storage", notificationStore.Ping).
+			Add("kafka", consumer.Ping),
+	}
+
+	// По SIGHUP применяем уровень логирования; остальные параметры требуют перезапуска
+	go config.WatchReload(mainCtx, loader, func(newCfg *config.Config, err error) {
+		if err != nil {
+			logg.Errorf("Failed to reload config, keeping current settings: %v", err)
+			return
+		}
+		logg.SetLevel(newCfg.Logger.Level)
+		logg.Infof("Config reloaded: logger.level=%s", newCfg.Logger.Level)
+	})
+
+	// Сервер метрик отдает и проверки /livez, /readyz, поэтому запускается всегда
+	metricsAddr := cfg.Metrics.Addr
+	if metricsAddr == "" {
+		metricsAddr = defaultMetricsAddr
+	}
+	go func() {
+		logg.Infof("Serving metrics on %s", metricsAddr)
+		if err := metrics.ListenAndServe(mainCtx, metricsAddr, metricsInstance, probes); err != nil {
+			logg.Errorf("Metrics server error: %v", err)
+		}
+	}()
+
 	logg.Info("Starting storer...")
 	if err := storer.Run(mainCtx); err != nil {
 		logg.Errorf("Storer error: %v", err)
@@ -2,41 +2,210 @@ package app
 
 import (
 	"context"
+	"slices"
 	"time"
 
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/blobstore"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/config"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/logger"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/storage"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/tracing"
 )
 
 type App struct {
-	logger  *logger.Logger
-	storage storage.Storage
+	logger      *logger.Logger
+	storage     storage.Storage
+	blobs       blobstore.Store
+	attachments config.AttachmentsConfig
+	quotas      config.QuotaConfig
 }
 
-func New(logger *logger.Logger, storage storage.Storage) *App {
-	return &App{
+// Option настраивает необязательные возможности приложения.
+type Option func(*App)
+
+func New(logger *logger.Logger, storage storage.Storage, opts ...Option) *App {
+	a := &App{
 		logger:  logger,
 		storage: storage,
 	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// log возвращает логгер запроса из контекста, чтобы записи содержали его request_id.
+func (a *App) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, a.logger)
 }
 
 func (a *App) CreateEvent(ctx context.Context, event *models.Event) error {
+	ctx, span := tracing.Start(ctx, "App.CreateEvent")
+	defer span.End()
+
+	if err := a.checkQuota(ctx, event.UserID); err != nil {
+		return err
+	}
+
 	return a.storage.CreateEvent(ctx, event)
 }
 
 func (a *App) UpdateEvent(ctx context.Context, event *models.Event) error {
+	ctx, span := tracing.Start(ctx, "App.UpdateEvent")
+	defer span.End()
+
+	if err := a.checkOwnerQuota(ctx, event.ID, event.UserID); err != nil {
+		return err
+	}
+
 	return a.storage.UpdateEvent(ctx, event)
 }
 
-func (a *App) DeleteEvent(ctx context.Context, id string) error {
-	return a.storage.DeleteEvent(ctx, id)
+func (a *App) PatchEvent(ctx context.Context, event *models.Event, fields []models.EventField) error {
+	ctx, span := tracing.Start(ctx, "App.PatchEvent")
+	defer span.End()
+
+	if slices.Contains(fields, models.EventFieldUserID) {
+		if err := a.checkOwnerQuota(ctx, event.ID, event.UserID); err != nil {
+			return err
+		}
+	}
+
+	return a.storage.PatchEvent(ctx, event, fields)
+}
+
+func (a *App) DeleteEvent(ctx context.Context, id string, version int64) error {
+	ctx, span := tracing.Start(ctx, "App.DeleteEvent")
+	defer span.End()
+
+	return a.storage.DeleteEvent(ctx, id, version)
+}
+
+// ApplyBatch применяет пакет операций. Операции создания сверх квоты отклоняются
+// до обращения к хранилищу: в атомарном пакете это отменяет весь пакет.
+func (a *App) ApplyBatch(ctx context.Context, ops []models.BatchOperation, atomic bool) ([]models.BatchResult, error) {
+	ctx, span := tracing.Start(ctx, "App.ApplyBatch")
+	defer span.End()
+
+	rejected, err := a.checkBatchQuota(ctx, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	accepted, index := ops, []int(nil)
+	if len(rejected) > 0 {
+		if atomic {
+			results := make([]models.BatchResult, len(ops))
+			for i := range results {
+				results[i] = models.BatchResult{Err: models.ErrBatchAborted}
+				if err, ok := rejected[i]; ok {
+					results[i].Err = err
+				}
+			}
+			return results, nil
+		}
+
+		accepted = nil
+		for i, op := range ops {
+			if _, ok := rejected[i]; !ok {
+				accepted = append(accepted, op)
+				index = append(index, i)
+			}
+		}
+	}
+
+	results, err := a.storage.ApplyBatch(ctx, accepted, atomic)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rejected) > 0 {
+		merged := make([]models.BatchResult, len(ops))
+		for i, err := range rejected {
+			merged[i] = models.BatchResult{Err: err}
+		}
+		for j, result := range results {
+			merged[index[j]] = result
+		}
+		results = merged
+	}
+
+	return results, nil
 }
 
 func (a *App) GetEvent(ctx context.Context, id string) (*models.Event, error) {
+	ctx, span := tracing.Start(ctx, "App.GetEvent")
+	defer span.End()
+
 	return a.storage.GetEvent(ctx, id)
 }
 
-func (a *App) ListEvents(ctx context.Context, from, to time.Time) ([]*models.Event, error) {
-	return a.storage.ListEvents(ctx, from, to)
+func (a *App) ListEvents(ctx context.Context, from, to time.Time, filter models.EventFilter) ([]*models.Event, error) {
+	ctx, span := tracing.Start(ctx, "App.ListEvents")
+	defer span.End()
+
+	return a.storage.ListEvents(ctx, from, to, filter)
+}
+
+func (a *App) ListDeletedEvents(ctx context.Context) ([]*models.Event, error) {
+	ctx, span := tracing.Start(ctx, "App.ListDeletedEvents")
+	defer span.End()
+
+	return a.storage.ListDeletedEvents(ctx)
+}
+
+func (a *App) RestoreEvent(ctx context.Context, id string) (*models.Event, error) {
+	ctx, span := tracing.Start(ctx, "App.RestoreEvent")
+	defer span.End()
+
+	if err := a.checkRestoreQuota(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return a.storage.RestoreEvent(ctx, id)
+}
+
+func (a *App) PurgeDeletedEvents(ctx context.Context, deletedBefore time.Time) (int, error) {
+	ctx, span := tracing.Start(ctx, "App.PurgeDeletedEvents")
+	defer span.End()
+
+	trash, err := a.storage.ListDeletedEvents(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var attachments []*models.Attachment
+	for _, event := range trash {
+		if event.DeletedAt.Before(deletedBefore) {
+			eventAttachments, err := a.storage.ListAttachments(ctx, event.ID)
+			if err != nil {
+				return 0, err
+			}
+			attachments = append(attachments, eventAttachments...)
+		}
+	}
+
+	purged, err := a.storage.PurgeDeletedEvents(ctx, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	a.deleteAttachmentBlobs(ctx, attachments)
+
+	return purged, nil
+}
+
+func (a *App) ListAudit(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, error) {
+	ctx, span := tracing.Start(ctx, "App.ListAudit")
+	defer span.End()
+
+	return a.storage.ListAudit(ctx, filter)
+}
+
+func (a *App) PurgeAudit(ctx context.Context, createdBefore time.Time) (int, error) {
+	ctx, span := tracing.Start(ctx, "App.PurgeAudit")
+	defer span.End()
+
+	return a.storage.PurgeAudit(ctx, createdBefore)
 }
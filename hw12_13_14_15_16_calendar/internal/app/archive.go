@@ -0,0 +1,24 @@
+package app
+
+import (
+	"context"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/tracing"
+)
+
+// ExportSnapshot возвращает все данные хранилища для резервной копии.
+func (a *App) ExportSnapshot(ctx context.Context) (*models.Snapshot, error) {
+	ctx, span := tracing.Start(ctx, "App.ExportSnapshot")
+	defer span.End()
+
+	return a.storage.Snapshot(ctx)
+}
+
+// ImportSnapshot восстанавливает резервную копию в пустое хранилище.
+func (a *App) ImportSnapshot(ctx context.Context, snapshot *models.Snapshot) error {
+	ctx, span := tracing.Start(ctx, "App.ImportSnapshot")
+	defer span.End()
+
+	return a.storage.LoadSnapshot(ctx, snapshot)
+}
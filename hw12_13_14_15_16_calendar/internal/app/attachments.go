@@ -0,0 +1,262 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/blobstore"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/config"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/tracing"
+	"github.com/google/uuid"
+)
+
+const (
+	// defaultMaxAttachmentSize используется, если max_size не задан в конфигурации
+	defaultMaxAttachmentSize = 10 << 20
+	// sniffLen — сколько байт нужно http.DetectContentType для определения типа
+	sniffLen = 512
+)
+
+// WithAttachments включает вложения событий, содержимое которых хранится в blobs.
+// При blobs == nil загрузка файлов отключена, ссылки по-прежнему доступны.
+func WithAttachments(blobs blobstore.Store, cfg config.AttachmentsConfig) Option {
+	return func(a *App) {
+		if cfg.MaxSize <= 0 {
+			cfg.MaxSize = defaultMaxAttachmentSize
+		}
+		a.blobs = blobs
+		a.attachments = cfg
+	}
+}
+
+// MaxAttachmentSize возвращает ограничение на размер вложения в байтах.
+func (a *App) MaxAttachmentSize() int64 {
+	return a.attachments.MaxSize
+}
+
+// AddAttachment сохраняет содержимое вложения и его метаданные. Если тип содержимого
+// не указан, он определяется по первым байтам.
+func (a *App) AddAttachment(ctx context.Context, eventID, name, contentType string, body io.Reader) (*models.Attachment, error) {
+	ctx, span := tracing.Start(ctx, "App.AddAttachment")
+	defer span.End()
+
+	if a.blobs == nil {
+		return nil, models.ErrAttachmentsDisabled
+	}
+
+	if _, err := a.storage.GetEvent(ctx, eventID); err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReaderSize(body, sniffLen)
+	contentType, err := a.resolveContentType(contentType, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := &models.Attachment{
+		ID:          uuid.New().String(),
+		EventID:     eventID,
+		Name:        name,
+		ContentType: contentType,
+		CreatedAt:   time.Now(),
+	}
+
+	hash := sha256.New()
+	limited := &limitedReader{r: io.TeeReader(reader, hash), limit: a.attachments.MaxSize}
+	if err := a.blobs.Put(ctx, attachment.BlobKey(), limited, -1, contentType); err != nil {
+		a.deleteBlob(ctx, attachment.BlobKey())
+		if limited.exceeded {
+			return nil, models.ErrAttachmentTooLarge
+		}
+		return nil, err
+	}
+
+	attachment.Size = limited.read
+	attachment.Checksum = hex.EncodeToString(hash.Sum(nil))
+
+	if err := a.storage.AddAttachment(ctx, attachment); err != nil {
+		a.deleteBlob(ctx, attachment.BlobKey())
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// AddAttachmentLink добавляет к событию ссылку, например на документ или запись встречи.
+func (a *App) AddAttachmentLink(ctx context.Context, eventID, name, rawURL string) (*models.Attachment, error) {
+	ctx, span := tracing.Start(ctx, "App.AddAttachmentLink")
+	defer span.End()
+
+	link, err := url.Parse(rawURL)
+	if err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" {
+		return nil, fmt.Errorf("%w: url must be an absolute http(s) URL", models.ErrInvalidAttachment)
+	}
+
+	if name == "" {
+		name = rawURL
+	}
+
+	attachment := &models.Attachment{
+		ID:        uuid.New().String(),
+		EventID:   eventID,
+		Name:      name,
+		URL:       link.String(),
+		CreatedAt: time.Now(),
+	}
+
+	if err := a.storage.AddAttachment(ctx, attachment); err != nil {
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+func (a *App) ListAttachments(ctx context.Context, eventID string) ([]*models.Attachment, error) {
+	ctx, span := tracing.Start(ctx, "App.ListAttachments")
+	defer span.End()
+
+	if _, err := a.storage.GetEvent(ctx, eventID); err != nil {
+		return nil, err
+	}
+	return a.storage.ListAttachments(ctx, eventID)
+}
+
+// OpenAttachment возвращает метаданные вложения и его содержимое. Для ссылок содержимое равно nil.
+func (a *App) OpenAttachment(ctx context.Context, eventID, id string) (*models.Attachment, io.ReadCloser, error) {
+	ctx, span := tracing.Start(ctx, "App.OpenAttachment")
+	defer span.End()
+
+	attachment, err := a.storage.GetAttachment(ctx, eventID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if attachment.IsLink() {
+		return attachment, nil, nil
+	}
+
+	if a.blobs == nil {
+		return nil, nil, models.ErrAttachmentsDisabled
+	}
+
+	content, err := a.blobs.Get(ctx, attachment.BlobKey())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return attachment, content, nil
+}
+
+func (a *App) DeleteAttachment(ctx context.Context, eventID, id string) error {
+	ctx, span := tracing.Start(ctx, "App.DeleteAttachment")
+	defer span.End()
+
+	attachment, err := a.storage.GetAttachment(ctx, eventID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := a.storage.DeleteAttachment(ctx, eventID, id); err != nil {
+		return err
+	}
+
+	if !attachment.IsLink() {
+		a.deleteBlob(ctx, attachment.BlobKey())
+	}
+	return nil
+}
+
+// deleteAttachmentBlobs удаляет содержимое вложений окончательно удаленных событий.
+// Метаданные к этому моменту уже удалены вместе с событиями.
+func (a *App) deleteAttachmentBlobs(ctx context.Context, attachments []*models.Attachment) {
+	for _, attachment := range attachments {
+		if !attachment.IsLink() {
+			a.deleteBlob(ctx, attachment.BlobKey())
+		}
+	}
+}
+
+// deleteBlob удаляет содержимое вложения; ошибка только логируется, так как
+// метаданные вложения уже удалены или не были сохранены.
+func (a *App) deleteBlob(ctx context.Context, key string) {
+	if a.blobs == nil {
+		return
+	}
+	if err := a.blobs.Delete(ctx, key); err != nil {
+		a.log(ctx).Errorf("Failed to delete attachment blob %s: %v", key, err)
+	}
+}
+
+// resolveContentType нормализует заявленный тип содержимого, определяет его по первым
+// байтам, если он не указан, и проверяет по списку допустимых типов.
+func (a *App) resolveContentType(declared string, content *bufio.Reader) (string, error) {
+	contentType := ""
+	if declared != "" {
+		mediaType, _, err := mime.ParseMediaType(declared)
+		if err != nil {
+			return "", fmt.Errorf("%w: %q", models.ErrUnsupportedContentType, declared)
+		}
+		contentType = mediaType
+	}
+
+	if contentType == "" || contentType == "application/octet-stream" {
+		head, err := content.Peek(sniffLen)
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return "", err
+		}
+		contentType, _, _ = mime.ParseMediaType(http.DetectContentType(head))
+	}
+
+	if !a.contentTypeAllowed(contentType) {
+		return "", fmt.Errorf("%w: %q", models.ErrUnsupportedContentType, contentType)
+	}
+
+	return contentType, nil
+}
+
+func (a *App) contentTypeAllowed(contentType string) bool {
+	if len(a.attachments.AllowedTypes) == 0 {
+		return true
+	}
+
+	for _, allowed := range a.attachments.AllowedTypes {
+		allowed = strings.ToLower(allowed)
+		if allowed == contentType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(contentType, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// limitedReader считает прочитанные байты и возвращает ошибку, как только их больше limit.
+type limitedReader struct {
+	r        io.Reader
+	limit    int64
+	read     int64
+	exceeded bool
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if l.read > l.limit {
+		l.exceeded = true
+		return n, models.ErrAttachmentTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,123 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/config"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+)
+
+// WithQuotas ограничивает количество активных событий пользователя.
+func WithQuotas(cfg config.QuotaConfig) Option {
+	return func(a *App) {
+		a.quotas = cfg
+	}
+}
+
+// checkQuota возвращает ErrQuotaExceeded, если пользователь не может добавить еще одно
+// событие. Проверка выполняется до записи, поэтому параллельные запросы могут
+// незначительно превысить квоту.
+func (a *App) checkQuota(ctx context.Context, userID string) error {
+	limit := a.quotas.MaxEventsPerUser
+	if limit <= 0 {
+		return nil
+	}
+
+	count, err := a.storage.CountEvents(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if count >= limit {
+		return quotaError(userID, limit)
+	}
+	return nil
+}
+
+// checkOwnerQuota проверяет квоту нового владельца, если обновление передает событие
+// id пользователю userID: иначе квоту можно обойти, создав событие от другого пользователя.
+func (a *App) checkOwnerQuota(ctx context.Context, id, userID string) error {
+	if a.quotas.MaxEventsPerUser <= 0 {
+		return nil
+	}
+
+	current, err := a.storage.GetEvent(ctx, id)
+	if err != nil {
+		// Ошибку вернет само обновление
+		return nil
+	}
+	if current.UserID == userID {
+		return nil
+	}
+	return a.checkQuota(ctx, userID)
+}
+
+// checkBatchQuota проверяет квоты для операций создания и передачи события другому
+// пользователю в пакете с учетом событий, добавленных предыдущими операциями. Возвращает ошибки по индексам отклоненных операций.
+func (a *App) checkBatchQuota(ctx context.Context, ops []models.BatchOperation) (map[int]error, error) {
+	limit := a.quotas.MaxEventsPerUser
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	counts := make(map[string]int)
+	rejected := make(map[int]error)
+	for i, op := range ops {
+		if op.Event == nil {
+			continue
+		}
+		switch op.Op {
+		case models.BatchOpCreate:
+		case models.BatchOpUpdate:
+			current, err := a.storage.GetEvent(ctx, op.Event.ID)
+			if err != nil || current.UserID == op.Event.UserID {
+				continue
+			}
+		default:
+			continue
+		}
+
+		userID := op.Event.UserID
+		count, known := counts[userID]
+		if !known {
+			var err error
+			if count, err = a.storage.CountEvents(ctx, userID); err != nil {
+				return nil, err
+			}
+		}
+
+		if count >= limit {
+			rejected[i] = quotaError(userID, limit)
+			counts[userID] = count
+			continue
+		}
+		counts[userID] = count + 1
+	}
+
+	return rejected, nil
+}
+
+func quotaError(userID string, limit int) error {
+	return fmt.Errorf("%w: user %s already has %d events", models.ErrQuotaExceeded, userID, limit)
+}
+
+// checkRestoreQuota не дает обойти квоту, удаляя события в корзину и восстанавливая их.
+func (a *App) checkRestoreQuota(ctx context.Context, id string) error {
+	if a.quotas.MaxEventsPerUser <= 0 {
+		return nil
+	}
+
+	trash, err := a.storage.ListDeletedEvents(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range trash {
+		if event.ID == id {
+			return a.checkQuota(ctx, event.UserID)
+		}
+	}
+	// Событие не в корзине — ошибку вернет само восстановление
+	return nil
+}
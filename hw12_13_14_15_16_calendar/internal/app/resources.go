@@ -0,0 +1,64 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/tracing"
+)
+
+func (a *App) CreateResource(ctx context.Context, resource *models.Resource) error {
+	ctx, span := tracing.Start(ctx, "App.CreateResource")
+	defer span.End()
+
+	resource.CreatedAt = time.Now()
+	return a.storage.CreateResource(ctx, resource)
+}
+
+func (a *App) UpdateResource(ctx context.Context, resource *models.Resource) error {
+	ctx, span := tracing.Start(ctx, "App.UpdateResource")
+	defer span.End()
+
+	return a.storage.UpdateResource(ctx, resource)
+}
+
+func (a *App) DeleteResource(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "App.DeleteResource")
+	defer span.End()
+
+	return a.storage.DeleteResource(ctx, id)
+}
+
+func (a *App) GetResource(ctx context.Context, id string) (*models.Resource, error) {
+	ctx, span := tracing.Start(ctx, "App.GetResource")
+	defer span.End()
+
+	return a.storage.GetResource(ctx, id)
+}
+
+func (a *App) ListResources(ctx context.Context, filter models.ResourceFilter) ([]*models.Resource, error) {
+	ctx, span := tracing.Start(ctx, "App.ListResources")
+	defer span.End()
+
+	return a.storage.ListResources(ctx, filter)
+}
+
+// ResourceAvailability возвращает бронирования ресурса в интервале [from, to)
+// и свободные промежутки между ними.
+func (a *App) ResourceAvailability(ctx context.Context, id string, from, to time.Time) ([]*models.Booking, []models.TimeSlot, error) {
+	ctx, span := tracing.Start(ctx, "App.ResourceAvailability")
+	defer span.End()
+
+	bookings, err := a.storage.ListBookings(ctx, id, from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	busy := make([]models.TimeSlot, len(bookings))
+	for i, booking := range bookings {
+		busy[i] = models.TimeSlot{Start: booking.StartTime, End: booking.EndTime}
+	}
+
+	return bookings, models.FreeSlots(from, to, busy), nil
+}
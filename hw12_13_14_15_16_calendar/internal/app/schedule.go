@@ -0,0 +1,150 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/tracing"
+)
+
+func (a *App) GetWorkingHours(ctx context.Context, userID string) (*models.WorkingHours, error) {
+	ctx, span := tracing.Start(ctx, "App.GetWorkingHours")
+	defer span.End()
+
+	return a.storage.GetWorkingHours(ctx, userID)
+}
+
+func (a *App) SetWorkingHours(ctx context.Context, hours *models.WorkingHours) error {
+	ctx, span := tracing.Start(ctx, "App.SetWorkingHours")
+	defer span.End()
+
+	if hours.TimeZone == "" {
+		hours.TimeZone = "UTC"
+	}
+	if err := hours.Validate(); err != nil {
+		return err
+	}
+	return a.storage.SetWorkingHours(ctx, hours)
+}
+
+func (a *App) AddOutOfOffice(ctx context.Context, period *models.OutOfOffice) error {
+	ctx, span := tracing.Start(ctx, "App.AddOutOfOffice")
+	defer span.End()
+
+	if !period.EndTime.After(period.StartTime) {
+		return fmt.Errorf("%w: end_time must be after start_time", models.ErrInvalidSchedule)
+	}
+	period.CreatedAt = time.Now()
+	return a.storage.AddOutOfOffice(ctx, period)
+}
+
+func (a *App) ListOutOfOffice(ctx context.Context, userID string, from, to time.Time) ([]*models.OutOfOffice, error) {
+	ctx, span := tracing.Start(ctx, "App.ListOutOfOffice")
+	defer span.End()
+
+	return a.storage.ListOutOfOffice(ctx, userID, from, to)
+}
+
+func (a *App) DeleteOutOfOffice(ctx context.Context, userID, id string) error {
+	ctx, span := tracing.Start(ctx, "App.DeleteOutOfOffice")
+	defer span.End()
+
+	return a.storage.DeleteOutOfOffice(ctx, userID, id)
+}
+
+func (a *App) ListReminders(ctx context.Context, from, to time.Time) ([]*models.Event, error) {
+	ctx, span := tracing.Start(ctx, "App.ListReminders")
+	defer span.End()
+
+	return a.storage.ListReminders(ctx, from, to)
+}
+
+// FreeBusy возвращает занятые интервалы пользователя в [from, to) — события и периоды
+// отсутствия — и свободные промежутки между ними.
+func (a *App) FreeBusy(ctx context.Context, userID string, from, to time.Time) ([]models.BusySlot, []models.TimeSlot, error) {
+	ctx, span := tracing.Start(ctx, "App.FreeBusy")
+	defer span.End()
+
+	events, err := a.storage.ListEvents(ctx, time.Time{}, to, models.EventFilter{UserID: userID, EndsAfter: from})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	away, err := a.storage.ListOutOfOffice(ctx, userID, from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	busy := make([]models.BusySlot, 0, len(events)+len(away))
+	for _, event := range events {
+		if models.Overlaps(event.StartTime, event.EndTime, from, to) {
+			busy = append(busy, models.BusySlot{
+				Start: event.StartTime,
+				End:   event.EndTime,
+				Kind:  models.BusyKindEvent,
+				ID:    event.ID,
+			})
+		}
+	}
+	for _, period := range away {
+		busy = append(busy, models.BusySlot{
+			Start: period.StartTime,
+			End:   period.EndTime,
+			Kind:  models.BusyKindOutOfOffice,
+			ID:    period.ID,
+		})
+	}
+	models.SortBusySlots(busy)
+
+	slots := make([]models.TimeSlot, len(busy))
+	for i, slot := range busy {
+		slots[i] = models.TimeSlot{Start: slot.Start, End: slot.End}
+	}
+
+	return busy, models.FreeSlots(from, to, slots), nil
+}
+
+// ReminderTime возвращает момент, когда следует доставить напоминание о событии.
+// Срочные напоминания и напоминания пользователей, не включивших перенос, доставляются
+// в срок. Остальные переносятся на ближайшее рабочее время вне периодов отсутствия;
+// ok=false, если такого времени нет до окончания события.
+func (a *App) ReminderTime(ctx context.Context, event *models.Event) (at time.Time, ok bool, err error) {
+	ctx, span := tracing.Start(ctx, "App.ReminderTime")
+	defer span.End()
+
+	reminder := event.ReminderAt()
+	if event.Priority == models.PriorityUrgent {
+		return reminder, true, nil
+	}
+
+	hours, err := a.storage.GetWorkingHours(ctx, event.UserID)
+	if errors.Is(err, models.ErrWorkingHoursNotFound) {
+		return reminder, true, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if !hours.DeferReminders {
+		return reminder, true, nil
+	}
+
+	away, err := a.storage.ListOutOfOffice(ctx, event.UserID, reminder, event.EndTime)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	at, ok = hours.NextWorkingTime(reminder, event.EndTime, away)
+	return at, ok, nil
+}
+
+// DeferReminder переносит напоминание о событии на момент at. Само событие не меняется:
+// перенос хранится отдельно и отменяется, если пользователь изменит напоминание.
+func (a *App) DeferReminder(ctx context.Context, event *models.Event, at time.Time) error {
+	ctx, span := tracing.Start(ctx, "App.DeferReminder")
+	defer span.End()
+
+	return a.storage.DeferReminder(ctx, event.ID, models.ReminderDeferral{From: event.Reminder, To: at})
+}
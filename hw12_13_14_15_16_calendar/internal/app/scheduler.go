@@ -3,137 +3,244 @@ package app
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/config"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/health"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/logger"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/metrics"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/mq"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/requestctx"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/tracing"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// defaultTrashRetention используется, если trash_retention не задан в конфигурации
+	defaultTrashRetention = 30 * 24 * time.Hour
+	// defaultAuditRetention используется, если audit_retention не задан в конфигурации
+	defaultAuditRetention = 365 * 24 * time.Hour
+
+	// schedulerActor — инициатор изменений планировщика в журнале аудита
+	schedulerActor = "scheduler"
 )
 
 type Scheduler struct {
 	app      *App
 	producer mq.Producer
 	logger   *logger.Logger
-	config   config.SchedulerConfig
 	metrics  *metrics.Metrics
+	// heartbeat отмечается после каждого прохода планировщика
+	heartbeat *health.Heartbeat
+
+	// config меняется при перезагрузке конфигурации, reload будит Run, чтобы
+	// сразу применить новый интервал
+	mu     sync.RWMutex
+	config config.SchedulerConfig
+	reload chan struct{}
 }
 
 func NewScheduler(app *App, producer mq.Producer, logger *logger.Logger, config config.SchedulerConfig, metrics *metrics.Metrics) *Scheduler {
 	return &Scheduler{
-		app:      app,
-		producer: producer,
-		logger:   logger,
-		config:   config,
-		metrics:  metrics,
+		app:       app,
+		producer:  producer,
+		logger:    logger,
+		config:    config,
+		metrics:   metrics,
+		heartbeat: health.NewHeartbeat(),
+		reload:    make(chan struct{}, 1),
 	}
 }
 
+// Config возвращает действующие настройки планировщика.
+func (s *Scheduler) Config() config.SchedulerConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config
+}
+
+// UpdateConfig применяет новые настройки без перезапуска. Новый интервал
+// отсчитывается с момента вызова.
+func (s *Scheduler) UpdateConfig(cfg config.SchedulerConfig) {
+	s.mu.Lock()
+	s.config = cfg
+	s.mu.Unlock()
+
+	select {
+	case s.reload <- struct{}{}:
+	default:
+	}
+}
+
+// Heartbeat возвращает сигнал работы цикла планировщика для проверки живости.
+func (s *Scheduler) Heartbeat() *health.Heartbeat {
+	return s.heartbeat
+}
+
 func (s *Scheduler) Run(ctx context.Context) error {
-	ticker := time.NewTicker(s.config.Interval)
-	defer ticker.Stop()
+	ctx = requestctx.WithActor(ctx, schedulerActor)
+	ctx = logger.WithContext(ctx, s.logger)
 
-	// Увеличиваем счетчик запусков планировщика
-	s.metrics.IncSchedulerRun()
+	interval := s.Config().Interval
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
 	// Выполняем сразу при запуске
-	if err := s.processNotifications(ctx); err != nil {
-		s.logger.Errorf("Failed to process notifications: %v", err)
-	}
-	if err := s.cleanupOldEvents(ctx); err != nil {
-		s.logger.Errorf("Failed to cleanup old events: %v", err)
-	}
+	s.runOnce(ctx)
 
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
 		case <-ticker.C:
-			// Увеличиваем счетчик запусков планировщика
-			s.metrics.IncSchedulerRun()
-
-			if err := s.processNotifications(ctx); err != nil {
-				s.logger.Errorf("Failed to process notifications: %v", err)
-			}
-			if err := s.cleanupOldEvents(ctx); err != nil {
-				s.logger.Errorf("Failed to cleanup old events: %v", err)
+			s.runOnce(ctx)
+		case <-s.reload:
+			if cfg := s.Config(); cfg.Interval != interval {
+				interval = cfg.Interval
+				ticker.Reset(interval)
+				s.logger.Infof("Scheduler interval changed to %s", interval)
 			}
 		}
 	}
 }
 
+// runOnce выполняет один проход планировщика и отмечает heartbeat.
+func (s *Scheduler) runOnce(ctx context.Context) {
+	// Увеличиваем счетчик запусков планировщика
+	s.metrics.IncSchedulerRun()
+
+	if err := s.processNotifications(ctx); err != nil {
+		s.logger.Errorf("Failed to process notifications: %v", err)
+	}
+	if err := s.cleanupOldEvents(ctx); err != nil {
+		s.logger.Errorf("Failed to cleanup old events: %v", err)
+	}
+	if err := s.purgeTrash(ctx); err != nil {
+		s.logger.Errorf("Failed to purge trash: %v", err)
+	}
+	if err := s.purgeAudit(ctx); err != nil {
+		s.logger.Errorf("Failed to purge audit log: %v", err)
+	}
+
+	s.heartbeat.Beat()
+}
+
 func (s *Scheduler) processNotifications(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "Scheduler.processNotifications")
+	defer span.End()
+
 	now := time.Now()
-	// Ищем события, о которых нужно уведомить в ближайший интервал
+	// Ищем события, напоминания о которых приходятся на ближайший интервал
 	from := now
-	to := now.Add(s.config.Interval)
+	to := now.Add(s.Config().Interval)
 
-	events, err := s.app.ListEvents(ctx, from, to)
+	events, err := s.app.ListReminders(ctx, from, to)
 	if err != nil {
-		return fmt.Errorf("list events: %w", err)
+		return fmt.Errorf("list reminders: %w", err)
 	}
 
 	sentCount := 0
 	for _, event := range events {
 		// Проверяем, нужно ли отправить уведомление для этого события
-		if s.shouldNotify(event, now) {
-			notification := &models.Notification{
-				ID:         uuid.New().String(),
-				EventID:    event.ID,
-				EventTitle: event.Title,
-				UserID:     event.UserID,
-				Message:    fmt.Sprintf("Напоминание: %s начинается в %s", event.Title, event.StartTime.Format("15:04")),
-				NotifyAt:   time.Now(),
-				CreatedAt:  time.Now(),
-			}
+		if !s.shouldNotify(event, now) || s.deferNotification(ctx, event, to) {
+			continue
+		}
 
-			if err := s.producer.SendNotification(ctx, notification); err != nil {
-				s.logger.Errorf("Failed to send notification for event %s: %v", event.ID, err)
-				// Увеличиваем счетчик неудачных отправок уведомлений
-				s.metrics.IncNotificationFailed()
-				continue
-			}
+		notification := &models.Notification{
+			ID:         uuid.New().String(),
+			EventID:    event.ID,
+			EventTitle: event.Title,
+			UserID:     event.UserID,
+			Message:    fmt.Sprintf("Напоминание: %s начинается в %s", event.Title, event.StartTime.Format("15:04")),
+			NotifyAt:   time.Now(),
+			CreatedAt:  time.Now(),
+		}
 
-			// Увеличиваем счетчик отправленных уведомлений
-			s.metrics.IncNotificationSent()
-			sentCount++
-			s.logger.Infof("Notification sent for event: %s (user: %s)", event.Title, event.UserID)
+		sendCtx, span := tracing.Start(ctx, "Scheduler.sendReminder",
+			trace.WithAttributes(attribute.String("event.id", event.ID)))
+		err := s.producer.SendNotification(sendCtx, notification)
+		tracing.End(span, err)
+		if err != nil {
+			s.logger.Errorf("Failed to send notification for event %s: %v", event.ID, err)
+			// Увеличиваем счетчик неудачных отправок уведомлений
+			s.metrics.IncNotificationFailed()
+			continue
 		}
+
+		// Увеличиваем счетчик отправленных уведомлений
+		s.metrics.IncNotificationSent()
+		sentCount++
+		s.logger.Infof("Notification sent for event: %s (user: %s)", event.Title, event.UserID)
 	}
 
 	s.logger.Infof("Processed %d events, sent %d notifications", len(events), sentCount)
 	return nil
 }
 
+// deferNotification переносит напоминание, выпавшее на нерабочее время пользователя,
+// и сообщает, что отправлять его сейчас не нужно. Напоминания, которые некуда перенести
+// до окончания события, пропускаются. При ошибке напоминание отправляется в срок.
+func (s *Scheduler) deferNotification(ctx context.Context, event *models.Event, windowEnd time.Time) bool {
+	at, ok, err := s.app.ReminderTime(ctx, event)
+	if err != nil {
+		s.logger.Errorf("Failed to check working hours for event %s: %v", event.ID, err)
+		return false
+	}
+
+	if !ok {
+		s.logger.Infof("Reminder for event %s skipped: user is unavailable until the event ends", event.ID)
+		return true
+	}
+
+	if at.Before(windowEnd) {
+		return false
+	}
+
+	if err := s.app.DeferReminder(ctx, event, at); err != nil {
+		s.logger.Errorf("Failed to defer reminder for event %s: %v", event.ID, err)
+		return false
+	}
+
+	s.metrics.IncNotificationDeferred()
+	s.logger.Infof("Reminder for event %s deferred to %s", event.ID, at.Format(time.RFC3339))
+	return true
+}
+
 func (s *Scheduler) shouldNotify(event *models.Event, now time.Time) bool {
 	// Проверяем, установлено ли время напоминания и попадает ли оно в текущий интервал
-	if event.Reminder.IsZero() {
+	at := event.ReminderAt()
+	if at.IsZero() {
 		return false
 	}
 
 	// Напоминание должно быть в будущем, но не дальше чем текущий интервал
-	return event.Reminder.After(now) && event.Reminder.Before(now.Add(s.config.Interval))
+	return at.After(now) && at.Before(now.Add(s.Config().Interval))
 }
 
 func (s *Scheduler) cleanupOldEvents(ctx context.Context) error {
-	cutoffTime := time.Now().Add(-s.config.CleanupOlderThan)
+	ctx, span := tracing.Start(ctx, "Scheduler.cleanupOldEvents")
+	defer span.End()
+
+	cutoffTime := time.Now().Add(-s.Config().CleanupOlderThan)
 
 	// Получаем старые события
-	oldEvents, err := s.app.ListEvents(ctx, time.Time{}, cutoffTime)
+	oldEvents, err := s.app.ListEvents(ctx, time.Time{}, cutoffTime, models.EventFilter{})
 	if err != nil {
 		return fmt.Errorf("list old events: %w", err)
 	}
 
 	deletedCount := 0
 	for _, event := range oldEvents {
-		if err := s.app.DeleteEvent(ctx, event.ID); err != nil {
+		if err := s.app.DeleteEvent(ctx, event.ID, event.Version); err != nil {
 			s.logger.Errorf("Failed to delete old event %s: %v", event.ID, err)
 			continue
 		}
 		deletedCount++
-		s.logger.Infof("Deleted old event: %s (created: %s)", event.Title, event.StartTime.Format("2006-01-02"))
+		s.logger.Infof("Moved old event to trash: %s (created: %s)", event.Title, event.StartTime.Format("2006-01-02"))
 	}
 
 	if deletedCount > 0 {
@@ -142,3 +249,47 @@ func (s *Scheduler) cleanupOldEvents(ctx context.Context) error {
 
 	return nil
 }
+
+// purgeTrash окончательно удаляет события, пролежавшие в корзине дольше trash_retention
+func (s *Scheduler) purgeTrash(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "Scheduler.purgeTrash")
+	defer span.End()
+
+	retention := s.Config().TrashRetention
+	if retention <= 0 {
+		retention = defaultTrashRetention
+	}
+
+	purged, err := s.app.PurgeDeletedEvents(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return fmt.Errorf("purge deleted events: %w", err)
+	}
+
+	if purged > 0 {
+		s.logger.Infof("Purged %d events from trash", purged)
+	}
+
+	return nil
+}
+
+// purgeAudit удаляет записи журнала аудита старше audit_retention
+func (s *Scheduler) purgeAudit(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "Scheduler.purgeAudit")
+	defer span.End()
+
+	retention := s.Config().AuditRetention
+	if retention <= 0 {
+		retention = defaultAuditRetention
+	}
+
+	purged, err := s.app.PurgeAudit(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return fmt.Errorf("purge audit log: %w", err)
+	}
+
+	if purged > 0 {
+		s.logger.Infof("Purged %d audit entries", purged)
+	}
+
+	return nil
+}
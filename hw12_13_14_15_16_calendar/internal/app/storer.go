@@ -2,55 +2,243 @@ package app
 
 import (
 	"context"
+	"hash/fnv"
+	"sync"
+	"time"
 
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/config"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/health"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/logger"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/metrics"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/mq"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/storage/notifications"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// storerHeartbeatInterval — как часто цикл сервиса сохранения отмечает heartbeat,
+// пока он не занят обработкой уведомления
+const storerHeartbeatInterval = 10 * time.Second
+
+// Задержка между попытками записи пакета, пока хранилище недоступно
+const (
+	storerRetryBackoff    = time.Second
+	storerMaxRetryBackoff = 30 * time.Second
 )
 
 type Storer struct {
 	storage  notifications.NotificationStorage
 	consumer mq.Consumer
+	config   config.StorerConfig
 	logger   *logger.Logger
+	metrics  *metrics.Metrics
+	// heartbeat отмечается циклом чтения; зависшая обработка уведомления его останавливает
+	heartbeat *health.Heartbeat
 }
 
-func NewStorer(storage notifications.NotificationStorage, consumer mq.Consumer, logger *logger.Logger) *Storer {
+func NewStorer(storage notifications.NotificationStorage, consumer mq.Consumer, cfg config.StorerConfig,
+	logger *logger.Logger, metrics *metrics.Metrics,
+) *Storer {
 	return &Storer{
-		storage:  storage,
-		consumer: consumer,
-		logger:   logger,
+		storage:   storage,
+		consumer:  consumer,
+		config:    cfg,
+		logger:    logger,
+		metrics:   metrics,
+		heartbeat: health.NewHeartbeat(),
 	}
 }
 
+// Heartbeat возвращает сигнал работы цикла сервиса сохранения для проверки живости.
+func (s *Storer) Heartbeat() *health.Heartbeat {
+	return s.heartbeat
+}
+
+// Run накапливает уведомления в пакеты и сохраняет их, когда пакет заполнен или
+// истек FlushInterval. Получение уведомлений подтверждается после сохранения пакета,
+// поэтому при остановке несохраненный пакет будет получен повторно.
 func (s *Storer) Run(ctx context.Context) error {
-	notifications, err := s.consumer.Consume(ctx)
+	deliveries, err := s.consumer.Consume(ctx)
 	if err != nil {
 		return err
 	}
 
-	s.logger.Info("Started consuming notifications")
+	s.logger.Infof("Started consuming notifications: workers=%d, batch_size=%d",
+		s.config.Workers, s.config.BatchSize)
+
+	ticker := time.NewTicker(storerHeartbeatInterval)
+	defer ticker.Stop()
+
+	flushTimer := time.NewTimer(s.config.FlushInterval)
+	flushTimer.Stop()
+	defer flushTimer.Stop()
+
+	batch := make([]mq.Delivery, 0, s.config.BatchSize)
+	flush := func() {
+		flushTimer.Stop()
+		if len(batch) > 0 {
+			s.flush(ctx, batch)
+			batch = make([]mq.Delivery, 0, s.config.BatchSize)
+		}
+	}
 
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
-		case notification, ok := <-notifications:
+		case <-ticker.C:
+			s.heartbeat.Beat()
+		case <-flushTimer.C:
+			flush()
+		case delivery, ok := <-deliveries:
 			if !ok {
+				flush()
 				s.logger.Info("Notifications channel closed")
 				return nil
 			}
-			if err := s.processNotification(ctx, notification); err != nil {
-				s.logger.Errorf("Failed to process notification: %v", err)
+			batch = append(batch, delivery)
+			if len(batch) == 1 {
+				flushTimer.Reset(s.config.FlushInterval)
+			}
+			if len(batch) >= s.config.BatchSize {
+				flush()
+			}
+		}
+	}
+}
+
+// flush сохраняет пакет, распределив уведомления между обработчиками по пользователю,
+// и подтверждает получение всего пакета.
+func (s *Storer) flush(ctx context.Context, batch []mq.Delivery) {
+	start := time.Now()
+	s.metrics.ObserveNotificationBatchSize(len(batch))
+
+	var wg sync.WaitGroup
+	for _, group := range partitionByUser(batch, s.config.Workers) {
+		if len(group) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.store(ctx, group)
+		}()
+	}
+	wg.Wait()
+	s.metrics.ObserveNotificationStoreDuration(time.Since(start).Seconds())
+
+	// Сервис останавливается, и часть пакета могла остаться несохраненной:
+	// без подтверждения весь пакет будет получен повторно
+	if ctx.Err() != nil {
+		return
+	}
+	if err := s.consumer.Commit(ctx, batch...); err != nil {
+		s.logger.Errorf("Failed to commit %d notifications, they will be redelivered: %v", len(batch), err)
+	}
+	s.heartbeat.Beat()
+}
+
+// partitionByUser делит пакет на n групп так, что уведомления одного пользователя
+// попадают в одну группу в порядке получения.
+func partitionByUser(batch []mq.Delivery, n int) [][]mq.Delivery {
+	groups := make([][]mq.Delivery, max(n, 1))
+	for _, delivery := range batch {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(delivery.Notification.UserID))
+		i := h.Sum32() % uint32(len(groups))
+		groups[i] = append(groups[i], delivery)
+	}
+	return groups
+}
+
+// store сохраняет группу уведомлений одного обработчика. Пока хранилище недоступно,
+// запись повторяется; если хранилище доступно, но отклоняет пакет, уведомления
+// сохраняются по одному, чтобы одно некорректное не задерживало остальные.
+func (s *Storer) store(ctx context.Context, group []mq.Delivery) {
+	backoff := storerRetryBackoff
+	for {
+		err := s.saveBatch(ctx, group)
+		if err == nil {
+			for _, delivery := range group {
+				s.metrics.IncNotificationStored(metrics.StatusSuccess)
+				s.logger.Debugf("Stored notification for event: %s, user: %s",
+					delivery.Notification.EventTitle, delivery.Notification.UserID)
 			}
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		if pingErr := s.storage.Ping(ctx); pingErr == nil {
+			s.logger.Warnf("Failed to store batch of %d notifications, storing one by one: %v", len(group), err)
+			s.saveEach(ctx, group)
+			return
+		}
+
+		s.logger.Warnf("Notification storage unavailable, retrying in %s: %v", backoff, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		// Ожидание хранилища — не зависание цикла
+		s.heartbeat.Beat()
+		backoff = min(2*backoff, storerMaxRetryBackoff)
+	}
+}
+
+func (s *Storer) saveBatch(ctx context.Context, group []mq.Delivery) error {
+	// Пакет объединяет уведомления разных трасс, поэтому спан связан с каждой из них
+	links := make([]trace.Link, 0, len(group))
+	batch := make([]*models.Notification, 0, len(group))
+	for _, delivery := range group {
+		if delivery.Ctx != nil {
+			links = append(links, trace.LinkFromContext(delivery.Ctx))
+		}
+		batch = append(batch, delivery.Notification)
+	}
+
+	ctx, span := tracing.Start(ctx, "Storer.saveBatch",
+		trace.WithLinks(links...),
+		trace.WithAttributes(attribute.Int("notifications.count", len(batch))))
+	err := s.storage.SaveNotifications(ctx, batch)
+	tracing.End(span, err)
+
+	return err
+}
+
+// saveEach сохраняет уведомления по одному и прекращает работу при остановке сервиса.
+// Спан каждого уведомления продолжает его трассу, но отмена берется из ctx обработчика.
+func (s *Storer) saveEach(ctx context.Context, group []mq.Delivery) {
+	for _, delivery := range group {
+		if ctx.Err() != nil {
+			return
+		}
+		itemCtx := ctx
+		if delivery.Ctx != nil {
+			itemCtx = trace.ContextWithSpanContext(ctx, trace.SpanContextFromContext(delivery.Ctx))
+		}
+		if err := s.processNotification(itemCtx, delivery.Notification); err != nil {
+			s.logger.Errorf("Failed to store notification %s: %v", delivery.Notification.ID, err)
 		}
 	}
 }
 
 func (s *Storer) processNotification(ctx context.Context, notification *models.Notification) error {
-	if err := s.storage.SaveNotification(ctx, notification); err != nil {
+	ctx, span := tracing.Start(ctx, "Storer.processNotification",
+		trace.WithAttributes(attribute.String("event.id", notification.EventID)))
+
+	err := s.storage.SaveNotification(ctx, notification)
+	tracing.End(span, err)
+
+	if err != nil {
+		s.metrics.IncNotificationStored(metrics.StatusError)
 		return err
 	}
+	s.metrics.IncNotificationStored(metrics.StatusSuccess)
 
 	s.logger.Infof("Stored notification for event: %s, user: %s",
 		notification.EventTitle, notification.UserID)
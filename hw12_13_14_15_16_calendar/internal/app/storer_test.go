@@ -0,0 +1,217 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/config"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/logger"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/metrics"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/mq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeNotificationStorage struct {
+	mu        sync.Mutex
+	saved     []*models.Notification
+	batches   int
+	batchErr  error
+	rejectID  string
+	pingErr   error
+	pingCalls int
+}
+
+func (f *fakeNotificationStorage) SaveNotification(_ context.Context, n *models.Notification) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if n.ID == f.rejectID {
+		return errors.New("rejected")
+	}
+	f.saved = append(f.saved, n)
+	return nil
+}
+
+func (f *fakeNotificationStorage) SaveNotifications(_ context.Context, batch []*models.Notification) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.batches++
+	if f.batchErr != nil {
+		return f.batchErr
+	}
+	f.saved = append(f.saved, batch...)
+	return nil
+}
+
+func (f *fakeNotificationStorage) GetNotifications(context.Context, string, time.Time, time.Time) ([]*models.Notification, error) {
+	return nil, nil
+}
+
+func (f *fakeNotificationStorage) ListAllNotifications(context.Context) ([]*models.Notification, error) {
+	return nil, nil
+}
+
+func (f *fakeNotificationStorage) Ping(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pingCalls++
+	return f.pingErr
+}
+
+func (f *fakeNotificationStorage) Close() error { return nil }
+
+func (f *fakeNotificationStorage) savedIDs() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ids := make([]string, 0, len(f.saved))
+	for _, n := range f.saved {
+		ids = append(ids, n.ID)
+	}
+	return ids
+}
+
+type fakeConsumer struct {
+	deliveries chan mq.Delivery
+	mu         sync.Mutex
+	committed  [][]string
+}
+
+func (f *fakeConsumer) Consume(context.Context) (<-chan mq.Delivery, error) {
+	return f.deliveries, nil
+}
+
+func (f *fakeConsumer) Commit(_ context.Context, deliveries ...mq.Delivery) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ids := make([]string, 0, len(deliveries))
+	for _, d := range deliveries {
+		ids = append(ids, d.Notification.ID)
+	}
+	f.committed = append(f.committed, ids)
+	return nil
+}
+
+func (f *fakeConsumer) Close() error { return nil }
+
+func newTestStorer(storage *fakeNotificationStorage, consumer *fakeConsumer, cfg config.StorerConfig) *Storer {
+	return NewStorer(storage, consumer, cfg, &logger.Logger{SugaredLogger: zap.NewNop().Sugar()},
+		metrics.NewMetrics(metrics.NewRegistry()))
+}
+
+func delivery(id, userID string) mq.Delivery {
+	return mq.Delivery{
+		Ctx:          context.Background(),
+		Notification: &models.Notification{ID: id, UserID: userID},
+	}
+}
+
+func TestStorerBatchesAndCommits(t *testing.T) {
+	storage := &fakeNotificationStorage{}
+	consumer := &fakeConsumer{deliveries: make(chan mq.Delivery)}
+	s := newTestStorer(storage, consumer, config.StorerConfig{Workers: 3, BatchSize: 4, FlushInterval: time.Hour})
+
+	done := make(chan error, 1)
+	go func() { done <- s.Run(context.Background()) }()
+
+	users := []string{"alice", "bob", "carol"}
+	for i := 0; i < 10; i++ {
+		consumer.deliveries <- delivery(fmt.Sprintf("n%d", i), users[i%len(users)])
+	}
+	// Закрытие канала сохраняет неполный последний пакет
+	close(consumer.deliveries)
+	require.NoError(t, <-done)
+
+	assert.Equal(t, [][]string{
+		{"n0", "n1", "n2", "n3"},
+		{"n4", "n5", "n6", "n7"},
+		{"n8", "n9"},
+	}, consumer.committed)
+
+	// Порядок сохраняется в пределах пользователя
+	byUser := make(map[string][]string)
+	for _, n := range storage.saved {
+		byUser[n.UserID] = append(byUser[n.UserID], n.ID)
+	}
+	assert.Equal(t, []string{"n0", "n3", "n6", "n9"}, byUser["alice"])
+	assert.Equal(t, []string{"n1", "n4", "n7"}, byUser["bob"])
+	assert.Equal(t, []string{"n2", "n5", "n8"}, byUser["carol"])
+}
+
+func TestStorerFlushInterval(t *testing.T) {
+	storage := &fakeNotificationStorage{}
+	consumer := &fakeConsumer{deliveries: make(chan mq.Delivery)}
+	s := newTestStorer(storage, consumer, config.StorerConfig{Workers: 1, BatchSize: 100, FlushInterval: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = s.Run(ctx) }()
+
+	consumer.deliveries <- delivery("n1", "alice")
+	require.Eventually(t, func() bool {
+		consumer.mu.Lock()
+		defer consumer.mu.Unlock()
+		return len(consumer.committed) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"n1"}, storage.savedIDs())
+}
+
+func TestStorerFallsBackToSingleInserts(t *testing.T) {
+	storage := &fakeNotificationStorage{batchErr: errors.New("bad row"), rejectID: "n2"}
+	consumer := &fakeConsumer{}
+	s := newTestStorer(storage, consumer, config.StorerConfig{Workers: 1, BatchSize: 10, FlushInterval: time.Hour})
+
+	s.flush(context.Background(), []mq.Delivery{
+		delivery("n1", "alice"), delivery("n2", "alice"), delivery("n3", "alice"),
+	})
+
+	assert.Equal(t, []string{"n1", "n3"}, storage.savedIDs())
+	assert.Equal(t, 1, storage.pingCalls)
+	// Отклоненное уведомление не задерживает подтверждение пакета
+	assert.Equal(t, [][]string{{"n1", "n2", "n3"}}, consumer.committed)
+}
+
+func TestStorerDoesNotCommitUnsavedBatchOnShutdown(t *testing.T) {
+	storage := &fakeNotificationStorage{batchErr: errors.New("connection refused"), pingErr: errors.New("down")}
+	consumer := &fakeConsumer{}
+	s := newTestStorer(storage, consumer, config.StorerConfig{Workers: 2, BatchSize: 10, FlushInterval: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	s.flush(ctx, []mq.Delivery{delivery("n1", "alice"), delivery("n2", "bob")})
+
+	assert.Empty(t, storage.savedIDs())
+	assert.Empty(t, consumer.committed)
+}
+
+func TestStorerSingleInsertsWithoutDeliveryContext(t *testing.T) {
+	storage := &fakeNotificationStorage{batchErr: errors.New("bad row"), rejectID: "n2"}
+	consumer := &fakeConsumer{}
+	s := newTestStorer(storage, consumer, config.StorerConfig{Workers: 1, BatchSize: 10, FlushInterval: time.Hour})
+
+	group := []mq.Delivery{delivery("n1", "alice"), delivery("n2", "alice"), delivery("n3", "alice")}
+	for i := range group {
+		group[i].Ctx = nil
+	}
+	s.flush(context.Background(), group)
+
+	assert.Equal(t, []string{"n1", "n3"}, storage.savedIDs())
+	assert.Equal(t, [][]string{{"n1", "n2", "n3"}}, consumer.committed)
+}
+
+func TestStorerSingleInsertsStopOnShutdown(t *testing.T) {
+	storage := &fakeNotificationStorage{}
+	consumer := &fakeConsumer{}
+	s := newTestStorer(storage, consumer, config.StorerConfig{Workers: 1, BatchSize: 10, FlushInterval: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.saveEach(ctx, []mq.Delivery{delivery("n1", "alice"), delivery("n2", "alice")})
+
+	assert.Empty(t, storage.savedIDs())
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/tracing"
+)
+
+func (a *App) CreateTemplate(ctx context.Context, template *models.EventTemplate) error {
+	ctx, span := tracing.Start(ctx, "App.CreateTemplate")
+	defer span.End()
+
+	template.CreatedAt = time.Now()
+	template.UpdatedAt = template.CreatedAt
+	return a.storage.CreateTemplate(ctx, template)
+}
+
+func (a *App) UpdateTemplate(ctx context.Context, template *models.EventTemplate) error {
+	ctx, span := tracing.Start(ctx, "App.UpdateTemplate")
+	defer span.End()
+
+	template.UpdatedAt = time.Now()
+	return a.storage.UpdateTemplate(ctx, template)
+}
+
+func (a *App) DeleteTemplate(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "App.DeleteTemplate")
+	defer span.End()
+
+	return a.storage.DeleteTemplate(ctx, id)
+}
+
+func (a *App) GetTemplate(ctx context.Context, id string) (*models.EventTemplate, error) {
+	ctx, span := tracing.Start(ctx, "App.GetTemplate")
+	defer span.End()
+
+	return a.storage.GetTemplate(ctx, id)
+}
+
+func (a *App) ListTemplates(ctx context.Context) ([]*models.EventTemplate, error) {
+	ctx, span := tracing.Start(ctx, "App.ListTemplates")
+	defer span.End()
+
+	return a.storage.ListTemplates(ctx)
+}
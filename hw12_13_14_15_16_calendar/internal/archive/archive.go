@@ -0,0 +1,248 @@
+// Package archive записывает и читает резервную копию данных календаря в формате
+// JSON Lines: по одному JSON-объекту на строку. Первая строка — заголовок с версией
+// формата, последняя — итоговая запись с числом записей, по которой обнаруживается
+// обрезанный архив. Между ними записи вида {"type": "event", "data": {...}}
+// в порядке, допускающем последовательное восстановление: ресурсы и шаблоны,
+// расписания, события, вложения, журнал аудита и уведомления.
+package archive
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+)
+
+// ContentType — тип содержимого архива в HTTP.
+const ContentType = "application/x-ndjson"
+
+// Version — текущая версия формата архива. Версия повышается только при
+// несовместимых изменениях записей.
+const Version = 1
+
+// Типы записей архива
+const (
+	TypeHeader       = "header"
+	TypeFooter       = "footer"
+	TypeResource     = "resource"
+	TypeTemplate     = "template"
+	TypeWorkingHours = "working_hours"
+	TypeOutOfOffice  = "out_of_office"
+	TypeEvent        = "event"
+	TypeAttachment   = "attachment"
+	TypeAudit        = "audit"
+	TypeNotification = "notification"
+)
+
+// maxLineSize ограничивает длину строки архива: записи аудита содержат
+// копии событий и могут быть заметно длиннее самих событий.
+const maxLineSize = 16 << 20
+
+var (
+	// ErrUnsupportedVersion — архив записан более новой версией формата.
+	ErrUnsupportedVersion = errors.New("unsupported archive version")
+	// ErrInvalidArchive — архив поврежден или обрезан.
+	ErrInvalidArchive = errors.New("invalid archive")
+)
+
+// Archive — содержимое резервной копии: данные хранилища календаря
+// и уведомления, сохраненные сервисом рассылки.
+type Archive struct {
+	Version       int
+	CreatedAt     time.Time
+	Snapshot      models.Snapshot
+	Notifications []*models.Notification
+}
+
+type record struct {
+	Type      string          `json:"type"`
+	Version   int             `json:"version,omitempty"`
+	CreatedAt *time.Time      `json:"created_at,omitempty"`
+	Records   *int            `json:"records,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+// Counts возвращает число записей архива каждого типа.
+func (a *Archive) Counts() map[string]int {
+	return map[string]int{
+		TypeResource:     len(a.Snapshot.Resources),
+		TypeTemplate:     len(a.Snapshot.Templates),
+		TypeWorkingHours: len(a.Snapshot.WorkingHours),
+		TypeOutOfOffice:  len(a.Snapshot.OutOfOffice),
+		TypeEvent:        len(a.Snapshot.Events),
+		TypeAttachment:   len(a.Snapshot.Attachments),
+		TypeAudit:        len(a.Snapshot.Audit),
+		TypeNotification: len(a.Notifications),
+	}
+}
+
+// Write записывает архив текущей версии формата. Нулевое CreatedAt заменяется текущим временем.
+func Write(w io.Writer, a *Archive) error {
+	buf := bufio.NewWriter(w)
+	enc := json.NewEncoder(buf)
+
+	createdAt := a.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now().UTC()
+	}
+	if err := enc.Encode(record{Type: TypeHeader, Version: Version, CreatedAt: &createdAt}); err != nil {
+		return err
+	}
+
+	count := 0
+	write := func(typ string, v any) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("encode %s: %w", typ, err)
+		}
+		count++
+		return enc.Encode(record{Type: typ, Data: data})
+	}
+
+	if err := writeAll(write, TypeResource, a.Snapshot.Resources); err != nil {
+		return err
+	}
+	if err := writeAll(write, TypeTemplate, a.Snapshot.Templates); err != nil {
+		return err
+	}
+	if err := writeAll(write, TypeWorkingHours, a.Snapshot.WorkingHours); err != nil {
+		return err
+	}
+	if err := writeAll(write, TypeOutOfOffice, a.Snapshot.OutOfOffice); err != nil {
+		return err
+	}
+	if err := writeAll(write, TypeEvent, a.Snapshot.Events); err != nil {
+		return err
+	}
+	if err := writeAll(write, TypeAttachment, a.Snapshot.Attachments); err != nil {
+		return err
+	}
+	if err := writeAll(write, TypeAudit, a.Snapshot.Audit); err != nil {
+		return err
+	}
+	if err := writeAll(write, TypeNotification, a.Notifications); err != nil {
+		return err
+	}
+
+	if err := enc.Encode(record{Type: TypeFooter, Records: &count}); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+func writeAll[T any](write func(string, any) error, typ string, items []*T) error {
+	for _, item := range items {
+		if err := write(typ, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Read читает архив любой поддерживаемой версии формата. Архив без итоговой записи
+// или с неизвестными типами записей считается поврежденным.
+func Read(r io.Reader) (*Archive, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	a := &Archive{}
+	line := 0
+	count := 0
+	footer := false
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		if footer {
+			return nil, fmt.Errorf("%w: line %d: data after footer", ErrInvalidArchive, line)
+		}
+
+		var rec record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidArchive, line, err)
+		}
+
+		if a.Version == 0 {
+			if rec.Type != TypeHeader {
+				return nil, fmt.Errorf("%w: line %d: expected header, got %q", ErrInvalidArchive, line, rec.Type)
+			}
+			if rec.Version < 1 || rec.Version > Version {
+				return nil, fmt.Errorf("%w %d, supported up to %d", ErrUnsupportedVersion, rec.Version, Version)
+			}
+			a.Version = rec.Version
+			if rec.CreatedAt != nil {
+				a.CreatedAt = *rec.CreatedAt
+			}
+			continue
+		}
+
+		if rec.Type == TypeFooter {
+			if rec.Records == nil || *rec.Records != count {
+				return nil, fmt.Errorf("%w: footer does not match %d records read", ErrInvalidArchive, count)
+			}
+			footer = true
+			continue
+		}
+
+		if err := a.decode(rec); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidArchive, line, err)
+		}
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidArchive, line+1, err)
+	}
+
+	if a.Version == 0 {
+		return nil, fmt.Errorf("%w: missing header", ErrInvalidArchive)
+	}
+	if !footer {
+		return nil, fmt.Errorf("%w: missing footer, archive is truncated", ErrInvalidArchive)
+	}
+	return a, nil
+}
+
+// decode добавляет запись в архив по ее типу.
+func (a *Archive) decode(rec record) error {
+	var err error
+	switch rec.Type {
+	case TypeResource:
+		a.Snapshot.Resources, err = appendDecoded(a.Snapshot.Resources, rec.Data)
+	case TypeTemplate:
+		a.Snapshot.Templates, err = appendDecoded(a.Snapshot.Templates, rec.Data)
+	case TypeWorkingHours:
+		a.Snapshot.WorkingHours, err = appendDecoded(a.Snapshot.WorkingHours, rec.Data)
+	case TypeOutOfOffice:
+		a.Snapshot.OutOfOffice, err = appendDecoded(a.Snapshot.OutOfOffice, rec.Data)
+	case TypeEvent:
+		a.Snapshot.Events, err = appendDecoded(a.Snapshot.Events, rec.Data)
+	case TypeAttachment:
+		a.Snapshot.Attachments, err = appendDecoded(a.Snapshot.Attachments, rec.Data)
+	case TypeAudit:
+		a.Snapshot.Audit, err = appendDecoded(a.Snapshot.Audit, rec.Data)
+	case TypeNotification:
+		a.Notifications, err = appendDecoded(a.Notifications, rec.Data)
+	default:
+		return fmt.Errorf("unknown record type %q", rec.Type)
+	}
+	if err != nil {
+		return fmt.Errorf("decode %s: %w", rec.Type, err)
+	}
+	return nil
+}
+
+func appendDecoded[T any](items []*T, data json.RawMessage) ([]*T, error) {
+	if len(data) == 0 {
+		return nil, errors.New("missing data")
+	}
+	var item T
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, err
+	}
+	return append(items, &item), nil
+}
@@ -0,0 +1,130 @@
+package archive
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testArchive() *Archive {
+	start := time.Date(2025, 3, 14, 10, 0, 0, 0, time.UTC)
+	return &Archive{
+		CreatedAt: time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC),
+		Snapshot: models.Snapshot{
+			Resources: []*models.Resource{{ID: "r1", Name: "Room", Kind: models.ResourceKindRoom, Capacity: 6}},
+			Templates: []*models.EventTemplate{{ID: "t1", Name: "1:1", TitlePattern: "1:1 {with}", Duration: 30 * time.Minute}},
+			WorkingHours: []*models.WorkingHours{{
+				UserID: "alice", TimeZone: "Europe/Moscow",
+				Windows: []models.WorkingWindow{{Weekday: time.Monday, Start: 9 * time.Hour, End: 18 * time.Hour}},
+			}},
+			OutOfOffice: []*models.OutOfOffice{{ID: "o1", UserID: "alice", StartTime: start, EndTime: start.Add(time.Hour)}},
+			Events: []*models.Event{
+				{ID: "e1", Title: "Планерка", StartTime: start, EndTime: start.Add(time.Hour), UserID: "alice",
+					ResourceIDs: []string{"r1"}, Version: 3},
+				{ID: "e2", Title: "Deleted", StartTime: start, EndTime: start.Add(time.Hour), UserID: "bob",
+					Version: 2, DeletedAt: start},
+			},
+			Attachments: []*models.Attachment{{ID: "a1", EventID: "e1", Name: "agenda.txt", Size: 5}},
+			Audit: []*models.AuditEntry{{
+				ID: "l1", EventID: "e1", Actor: "alice", Action: models.AuditActionCreate,
+				After: []byte(`{"id":"e1"}`), CreatedAt: start,
+			}},
+		},
+		Notifications: []*models.Notification{{ID: "n1", EventID: "e1", UserID: "alice", NotifyAt: start}},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	want := testArchive()
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, want))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 11)
+	assert.JSONEq(t, `{"type":"header","version":1,"created_at":"2025-03-15T00:00:00Z"}`, lines[0])
+	assert.JSONEq(t, `{"type":"footer","records":9}`, lines[len(lines)-1])
+
+	got, err := Read(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, Version, got.Version)
+	want.Version = Version
+	assert.Equal(t, want, got)
+	assert.Equal(t, 2, got.Counts()[TypeEvent])
+	assert.Equal(t, 1, got.Counts()[TypeNotification])
+}
+
+func TestReadEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, &Archive{}))
+
+	got, err := Read(&buf)
+	require.NoError(t, err)
+	assert.False(t, got.CreatedAt.IsZero())
+	assert.Empty(t, got.Snapshot.Events)
+}
+
+func TestReadInvalid(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, testArchive()))
+	valid := buf.String()
+	lines := strings.SplitAfter(valid, "\n")
+
+	tests := []struct {
+		name    string
+		archive string
+		err     error
+		message string
+	}{
+		{name: "empty", archive: "", err: ErrInvalidArchive, message: "missing header"},
+		{name: "no header", archive: strings.Join(lines[1:], ""), err: ErrInvalidArchive, message: "expected header"},
+		{
+			name:    "newer version",
+			archive: `{"type":"header","version":2}` + "\n",
+			err:     ErrUnsupportedVersion,
+		},
+		{
+			name:    "truncated at record boundary",
+			archive: strings.Join(lines[:len(lines)-3], ""),
+			err:     ErrInvalidArchive,
+			message: "truncated",
+		},
+		{
+			name:    "truncated mid-record",
+			archive: valid[:len(valid)-len(lines[len(lines)-2])-10],
+			err:     ErrInvalidArchive,
+			message: "line 10",
+		},
+		{
+			name:    "unknown record",
+			archive: lines[0] + `{"type":"calendar","data":{}}` + "\n",
+			err:     ErrInvalidArchive,
+			message: `unknown record type "calendar"`,
+		},
+		{
+			name:    "footer count mismatch",
+			archive: strings.Join(lines[:5], "") + lines[len(lines)-2],
+			err:     ErrInvalidArchive,
+			message: "footer",
+		},
+		{
+			name:    "data after footer",
+			archive: valid + lines[1],
+			err:     ErrInvalidArchive,
+			message: "after footer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(tt.archive))
+			require.ErrorIs(t, err, tt.err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
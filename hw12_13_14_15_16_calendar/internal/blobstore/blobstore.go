@@ -0,0 +1,19 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound возвращается, если объекта с указанным ключом нет в хранилище.
+var ErrNotFound = errors.New("blob not found")
+
+// Store — хранилище содержимого вложений. Ключ — путь вида "<event_id>/<attachment_id>".
+type Store interface {
+	// Put сохраняет содержимое r под ключом key. size может быть -1, если размер неизвестен.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete удаляет объект; отсутствие объекта не считается ошибкой.
+	Delete(ctx context.Context, key string) error
+}
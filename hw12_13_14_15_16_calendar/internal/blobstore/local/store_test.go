@@ -0,0 +1,45 @@
+package localblob
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/blobstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewStore(t.TempDir())
+	require.NoError(t, err)
+
+	t.Run("should put and get blob", func(t *testing.T) {
+		require.NoError(t, store.Put(ctx, "event1/a1", strings.NewReader("agenda"), -1, "text/plain"))
+
+		content, err := store.Get(ctx, "event1/a1")
+		require.NoError(t, err)
+		defer content.Close()
+
+		data, err := io.ReadAll(content)
+		require.NoError(t, err)
+		assert.Equal(t, "agenda", string(data))
+	})
+
+	t.Run("should delete blob", func(t *testing.T) {
+		require.NoError(t, store.Delete(ctx, "event1/a1"))
+
+		_, err := store.Get(ctx, "event1/a1")
+		assert.ErrorIs(t, err, blobstore.ErrNotFound)
+
+		assert.NoError(t, store.Delete(ctx, "event1/a1"))
+	})
+
+	t.Run("should reject keys outside store", func(t *testing.T) {
+		for _, key := range []string{"", "../secret", "/etc/passwd", "event1/../../secret"} {
+			assert.Error(t, store.Put(ctx, key, strings.NewReader("x"), -1, "text/plain"), key)
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package s3blob
+
+import (
+	"context"
+	"io"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/blobstore"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+type Config struct {
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+	Bucket    string
+	Region    string
+	UseSSL    bool
+}
+
+// partSize — размер части при загрузке объекта неизвестного размера. Без него minio
+// выбирает часть по максимальному размеру объекта (около 537 МБ) и выделяет под нее буфер
+// при каждой загрузке.
+const partSize = 16 << 20
+
+// objectClient — методы minio.Client, которые использует Store.
+type objectClient interface {
+	PutObject(ctx context.Context, bucket, key string, r io.Reader, size int64,
+		opts minio.PutObjectOptions) (minio.UploadInfo, error)
+	GetObject(ctx context.Context, bucket, key string, opts minio.GetObjectOptions) (*minio.Object, error)
+	RemoveObject(ctx context.Context, bucket, key string, opts minio.RemoveObjectOptions) error
+}
+
+// Store хранит объекты в бакете S3-совместимого хранилища (AWS S3, MinIO).
+type Store struct {
+	client objectClient
+	bucket string
+}
+
+// NewStore подключается к хранилищу и создает бакет, если его еще нет.
+func NewStore(ctx context.Context, cfg Config) (*Store, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Store{client: client, bucket: cfg.Bucket}, nil
+}
+
+func (s *Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	opts := minio.PutObjectOptions{ContentType: contentType}
+	if size < 0 {
+		opts.PartSize = partSize
+	}
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, opts)
+	return err
+}
+
+// Get проверяет наличие объекта заранее: GetObject ленивый и сообщил бы
+// об отсутствии объекта только при первом чтении.
+func (s *Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, blobstore.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return object, nil
+}
+
+func (s *Store) Delete(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
@@ -0,0 +1,58 @@
+package s3blob
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type putCall struct {
+	size int64
+	opts minio.PutObjectOptions
+}
+
+// fakeClient запоминает параметры загрузок.
+type fakeClient struct {
+	puts []putCall
+}
+
+func (c *fakeClient) PutObject(_ context.Context, _, _ string, r io.Reader, size int64,
+	opts minio.PutObjectOptions,
+) (minio.UploadInfo, error) {
+	c.puts = append(c.puts, putCall{size: size, opts: opts})
+	_, err := io.Copy(io.Discard, r)
+	return minio.UploadInfo{}, err
+}
+
+func (c *fakeClient) GetObject(context.Context, string, string, minio.GetObjectOptions) (*minio.Object, error) {
+	return nil, nil
+}
+
+func (c *fakeClient) RemoveObject(context.Context, string, string, minio.RemoveObjectOptions) error {
+	return nil
+}
+
+func TestStore_Put(t *testing.T) {
+	ctx := context.Background()
+
+	for _, size := range []int64{-1, 6} {
+		client := &fakeClient{}
+		store := &Store{client: client, bucket: "attachments"}
+
+		require.NoError(t, store.Put(ctx, "event1/a1", strings.NewReader("agenda"), size, "text/plain"))
+
+		require.Len(t, client.puts, 1)
+		put := client.puts[0]
+		assert.Equal(t, size, put.size)
+		assert.Equal(t, "text/plain", put.opts.ContentType)
+		if put.size < 0 {
+			assert.NotZero(t, put.opts.PartSize, "upload of unknown size must set part size")
+			assert.LessOrEqual(t, put.opts.PartSize, uint64(64<<20))
+		}
+	}
+}
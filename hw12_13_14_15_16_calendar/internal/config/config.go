@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"time"
 
@@ -13,11 +14,83 @@ type Config struct {
 	Storage   StorageConfig   `yaml:"storage"`
 	Kafka     KafkaConfig     `yaml:"kafka"`
 	Scheduler SchedulerConfig `yaml:"scheduler"`
+	Storer    StorerConfig    `yaml:"storer"`
+	// Attachments — хранилище вложений; пустой backend отключает загрузку файлов
+	Attachments AttachmentsConfig `yaml:"attachments"`
+	Quotas      QuotaConfig       `yaml:"quotas"`
+	Tracing     TracingConfig     `yaml:"tracing"`
+	Metrics     MetricsConfig     `yaml:"metrics"`
+	Health      HealthConfig      `yaml:"health"`
 }
 
 type ServerConfig struct {
-	Host string `yaml:"host"`
-	Port int    `yaml:"port"`
+	Host      string          `yaml:"host"`
+	Port      int             `yaml:"port"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Archive   ArchiveConfig   `yaml:"archive"`
+	// MaxBatchOperations — максимальное число операций в одном запросе /api/events:batch
+	MaxBatchOperations int `yaml:"max_batch_operations"`
+}
+
+// ArchiveConfig — выгрузка и восстановление резервной копии через /api/archive.
+// Архив содержит данные всех пользователей, поэтому маршруты по умолчанию отключены.
+type ArchiveConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// MaxSize — максимальный размер загружаемого архива в байтах; 0 — без ограничения
+	MaxSize int64 `yaml:"max_size"`
+}
+
+// RateLimitConfig — ограничение частоты запросов к HTTP API по IP клиента.
+type RateLimitConfig struct {
+	// Default применяется к маршрутам без отдельного правила
+	Default RateLimitRule    `yaml:"default"`
+	Routes  []RouteRateLimit `yaml:"routes"`
+	// TrustForwardedFor — брать IP клиента из X-Forwarded-For, если сервис работает за прокси
+	TrustForwardedFor bool `yaml:"trust_forwarded_for"`
+}
+
+// RateLimitRule — параметры корзины токенов. Нулевой Rate отключает ограничение.
+type RateLimitRule struct {
+	Rate  float64 `yaml:"rate"`  // запросов в секунду
+	Burst int     `yaml:"burst"` // максимальный всплеск, по умолчанию не меньше 1
+}
+
+type RouteRateLimit struct {
+	Method        string `yaml:"method"` // пустое значение подходит для любого метода
+	Path          string `yaml:"path"`   // шаблон маршрута, например /api/events/{id}
+	RateLimitRule `yaml:",inline"`
+}
+
+type QuotaConfig struct {
+	// MaxEventsPerUser — максимум активных событий пользователя; 0 — без ограничения
+	MaxEventsPerUser int `yaml:"max_events_per_user"`
+}
+
+// TracingConfig — экспорт трассировок OpenTelemetry.
+type TracingConfig struct {
+	Exporter string `yaml:"exporter"` // "otlp" или "stdout"; пустое значение отключает экспорт
+	// Endpoint — адрес коллектора OTLP/HTTP, например localhost:4318
+	Endpoint string `yaml:"endpoint"`
+	Insecure bool   `yaml:"insecure"` // отправлять в коллектор по HTTP без TLS
+	// SampleRatio — доля записываемых трасс от 0 до 1; 0 — все трассы
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// MetricsConfig — HTTP-сервер метрик фоновых сервисов (scheduler, storer).
+// Календарь отдает /metrics на адресе своего API.
+type MetricsConfig struct {
+	// Addr — адрес /metrics, /livez и /readyz; пустое значение — адрес сервиса по
+	// умолчанию (":9101" у планировщика, ":9102" у сервиса сохранения)
+	Addr string `yaml:"addr"`
+}
+
+// HealthConfig — проверки /livez и /readyz. Фоновые сервисы отдают их на адресе метрик.
+type HealthConfig struct {
+	// Timeout — предельное время одной проверки зависимости; 0 — 2 секунды
+	Timeout time.Duration `yaml:"timeout"`
+	// HeartbeatTimeout — сколько фоновый цикл может не отмечаться, прежде чем /livez
+	// начнет отвечать 503; 0 — значение по умолчанию сервиса
+	HeartbeatTimeout time.Duration `yaml:"heartbeat_timeout"`
 }
 
 type LoggerConfig struct {
@@ -25,34 +98,211 @@ type LoggerConfig struct {
 }
 
 type StorageConfig struct {
-	Type string `yaml:"type"`
-	DSN  string `yaml:"dsn"`
+	Type   string              `yaml:"type"`
+	DSN    string              `yaml:"dsn"`
+	Memory MemoryStorageConfig `yaml:"memory"`
+}
+
+// MemoryStorageConfig — сохранение хранилища в памяти на диск: журнал изменений (WAL)
+// и периодические снимки, по которым данные восстанавливаются при запуске.
+type MemoryStorageConfig struct {
+	Dir string `yaml:"dir"` // каталог журнала и снимков; пустое значение — данные не сохраняются
+	// Fsync — когда журнал сбрасывается на диск: "always" после каждого изменения,
+	// "interval" раз в FsyncInterval, "never" — на усмотрение ОС
+	Fsync         string        `yaml:"fsync"`
+	FsyncInterval time.Duration `yaml:"fsync_interval"`
+	// SnapshotInterval — как часто журнал сворачивается в снимок; 0 — только при остановке
+	SnapshotInterval time.Duration `yaml:"snapshot_interval"`
 }
 
 type KafkaConfig struct {
-	Brokers      []string      `yaml:"brokers"`
-	Topic        string        `yaml:"topic"`
-	GroupID      string        `yaml:"group_id"`
-	ClientID     string        `yaml:"client_id"`
-	MaxAttempts  int           `yaml:"max_attempts"`
-	RetryBackoff time.Duration `yaml:"retry_backoff"`
+	Brokers     []string `yaml:"brokers"`
+	Topic       string   `yaml:"topic"`
+	GroupID     string   `yaml:"group_id"`
+	ClientID    string   `yaml:"client_id"`
+	MaxAttempts int      `yaml:"max_attempts"`
+	// RetryBackoff — начальная задержка между попытками подключения и отправки;
+	// растет экспоненциально до MaxRetryBackoff
+	RetryBackoff    time.Duration `yaml:"retry_backoff"`
+	MaxRetryBackoff time.Duration `yaml:"max_retry_backoff"`
+	// AutoCreateTopic — создать топик при подключении, если его нет
+	AutoCreateTopic   bool `yaml:"auto_create_topic"`
+	Partitions        int  `yaml:"partitions"`         // число разделов создаваемого топика
+	ReplicationFactor int  `yaml:"replication_factor"` // фактор репликации создаваемого топика
+
+	TLS  KafkaTLSConfig  `yaml:"tls"`
+	SASL KafkaSASLConfig `yaml:"sasl"`
+
+	// Compression — сжатие отправляемых сообщений: "gzip", "snappy", "lz4", "zstd";
+	// пустое значение — без сжатия
+	Compression  string        `yaml:"compression"`
+	BatchSize    int           `yaml:"batch_size"`    // максимум сообщений в пакете producer
+	BatchTimeout time.Duration `yaml:"batch_timeout"` // сколько producer ждет заполнения пакета
+	// Acks — подтверждение записи: "none", "one" (лидер раздела) или "all" (все реплики)
+	Acks string `yaml:"acks"`
+	// StartOffset — откуда читать топик группе без сохраненной позиции: "earliest" или "latest"
+	StartOffset string `yaml:"start_offset"`
+	// Encoding — формат отправляемых уведомлений: "json" или "protobuf". Сервис сохранения
+	// читает оба формата, поэтому переходить на protobuf можно после его обновления
+	Encoding string `yaml:"encoding"`
+}
+
+// KafkaTLSConfig — TLS-соединение с брокерами. Клиентский сертификат нужен только
+// для взаимной аутентификации (mTLS).
+type KafkaTLSConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	CAFile     string `yaml:"ca_file"` // пустое значение — системные корневые сертификаты
+	CertFile   string `yaml:"cert_file"`
+	KeyFile    string `yaml:"key_file"`
+	ServerName string `yaml:"server_name"` // имя в сертификате брокера, если отличается от адреса
+	// InsecureSkipVerify отключает проверку сертификата брокера; только для отладки
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify"`
+}
+
+// KafkaSASLConfig — аутентификация SASL. Пароль лучше передавать через
+// CALENDAR_KAFKA_SASL_PASSWORD_FILE.
+type KafkaSASLConfig struct {
+	Mechanism string `yaml:"mechanism"` // "plain", "scram-sha-256", "scram-sha-512"; пустое значение отключает SASL
+	Username  string `yaml:"username"`
+	Password  string `yaml:"password"`
 }
 
 type SchedulerConfig struct {
 	Interval         time.Duration `yaml:"interval"`
 	CleanupOlderThan time.Duration `yaml:"cleanup_older_than"`
+	TrashRetention   time.Duration `yaml:"trash_retention"`
+	AuditRetention   time.Duration `yaml:"audit_retention"`
+}
+
+// StorerConfig — пакетная обработка уведомлений сервисом сохранения.
+type StorerConfig struct {
+	// Workers — число параллельных обработчиков; уведомления одного пользователя
+	// всегда попадают к одному обработчику и сохраняются по порядку
+	Workers   int `yaml:"workers"`
+	BatchSize int `yaml:"batch_size"` // максимум уведомлений в пакете
+	// FlushInterval — сколько неполный пакет ждет новых уведомлений перед сохранением
+	FlushInterval time.Duration `yaml:"flush_interval"`
+}
+
+type AttachmentsConfig struct {
+	Backend string `yaml:"backend"` // "local" или "s3"
+	// MaxSize — максимальный размер вложения в байтах
+	MaxSize int64 `yaml:"max_size"`
+	// AllowedTypes — допустимые MIME-типы, поддерживаются шаблоны вида image/*; пустой список разрешает все
+	AllowedTypes []string        `yaml:"allowed_types"`
+	Local        LocalBlobConfig `yaml:"local"`
+	S3           S3BlobConfig    `yaml:"s3"`
+}
+
+type LocalBlobConfig struct {
+	Dir string `yaml:"dir"`
+}
+
+type S3BlobConfig struct {
+	Endpoint  string `yaml:"endpoint"`
+	AccessKey string `yaml:"access_key"`
+	SecretKey string `yaml:"secret_key"`
+	Bucket    string `yaml:"bucket"`
+	Region    string `yaml:"region"`
+	UseSSL    bool   `yaml:"use_ssl"`
+}
+
+// Default возвращает конфигурацию по умолчанию: файл, переменные окружения и флаги
+// переопределяют только заданные в них параметры.
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Host:               "0.0.0.0",
+			Port:               8080,
+			Archive:            ArchiveConfig{MaxSize: 256 << 20},
+			MaxBatchOperations: 100,
+		},
+		Logger: LoggerConfig{Level: "info"},
+		Storage: StorageConfig{
+			Type: "memory",
+			Memory: MemoryStorageConfig{
+				Fsync:            "always",
+				FsyncInterval:    time.Second,
+				SnapshotInterval: 10 * time.Minute,
+			},
+		},
+		Kafka: KafkaConfig{
+			Brokers:           []string{"localhost:9092"},
+			Topic:             "calendar-notifications",
+			GroupID:           "calendar-storer",
+			MaxAttempts:       5,
+			RetryBackoff:      5 * time.Second,
+			MaxRetryBackoff:   time.Minute,
+			AutoCreateTopic:   true,
+			Partitions:        1,
+			ReplicationFactor: 1,
+			BatchSize:         100,
+			BatchTimeout:      10 * time.Millisecond,
+			Acks:              "one",
+			StartOffset:       "earliest",
+			Encoding:          "json",
+		},
+		Scheduler: SchedulerConfig{
+			Interval:         time.Minute,
+			CleanupOlderThan: 365 * 24 * time.Hour,
+			TrashRetention:   30 * 24 * time.Hour,
+			AuditRetention:   365 * 24 * time.Hour,
+		},
+		Storer: StorerConfig{
+			Workers:       4,
+			BatchSize:     100,
+			FlushInterval: time.Second,
+		},
+		Attachments: AttachmentsConfig{
+			MaxSize: 10 << 20,
+			Local:   LocalBlobConfig{Dir: "data/attachments"},
+		},
+		Tracing: TracingConfig{SampleRatio: 1},
+		Health:  HealthConfig{Timeout: 2 * time.Second},
+	}
 }
 
+// LoadConfig загружает конфигурацию из файла поверх значений по умолчанию,
+// применяет переменные окружения CALENDAR_* и проверяет результат.
 func LoadConfig(filename string) (*Config, error) {
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, err
+	return Loader{File: filename}.Load()
+}
+
+// Loader собирает конфигурацию по слоям: значения по умолчанию, YAML-файл, переменные
+// окружения и флаги -set. Один и тот же Loader используется при запуске и при перезагрузке.
+type Loader struct {
+	File      string    // пустое значение — без файла
+	Overrides Overrides // значения флагов -set, применяются последними
+	// LookupEnv читает переменные окружения; nil — os.LookupEnv
+	LookupEnv func(key string) (string, bool)
+}
+
+func (l Loader) Load() (*Config, error) {
+	cfg := Default()
+
+	if l.File != "" {
+		data, err := os.ReadFile(l.File)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", l.File, err)
+		}
 	}
 
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	lookupEnv := l.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+	if err := applyEnv(&cfg, lookupEnv); err != nil {
+		return nil, err
+	}
+	if err := l.Overrides.apply(&cfg); err != nil {
 		return nil, err
 	}
 
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 	return &cfg, nil
 }
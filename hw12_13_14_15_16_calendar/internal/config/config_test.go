@@ -0,0 +1,158 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := vars[key]
+		return value, ok
+	}
+}
+
+func TestLoaderLayers(t *testing.T) {
+	file := writeFile(t, `
+logger:
+  level: debug
+storage:
+  type: sql
+  dsn: postgres://file
+kafka:
+  topic: from-file
+`)
+
+	t.Run("defaults and file", func(t *testing.T) {
+		cfg, err := Loader{File: file, LookupEnv: env(nil)}.Load()
+		require.NoError(t, err)
+
+		assert.Equal(t, "debug", cfg.Logger.Level)
+		assert.Equal(t, "postgres://file", cfg.Storage.DSN)
+		assert.Equal(t, "from-file", cfg.Kafka.Topic)
+		// Не заданные в файле параметры берутся из значений по умолчанию
+		assert.Equal(t, 8080, cfg.Server.Port)
+		assert.Equal(t, []string{"localhost:9092"}, cfg.Kafka.Brokers)
+		assert.Equal(t, time.Minute, cfg.Scheduler.Interval)
+	})
+
+	t.Run("env overrides file", func(t *testing.T) {
+		cfg, err := Loader{File: file, LookupEnv: env(map[string]string{
+			"CALENDAR_STORAGE_DSN":                     "postgres://env",
+			"CALENDAR_KAFKA_BROKERS":                   "kafka-1:9092, kafka-2:9092",
+			"CALENDAR_SCHEDULER_INTERVAL":              "15s",
+			"CALENDAR_TRACING_INSECURE":                "true",
+			"CALENDAR_TRACING_SAMPLE_RATIO":            "0.5",
+			"CALENDAR_SERVER_RATE_LIMIT_DEFAULT_BURST": "7",
+			"CALENDAR_STORAGE_MEMORY_DIR":              "/var/lib/calendar",
+		})}.Load()
+		require.NoError(t, err)
+
+		assert.Equal(t, "postgres://env", cfg.Storage.DSN)
+		assert.Equal(t, []string{"kafka-1:9092", "kafka-2:9092"}, cfg.Kafka.Brokers)
+		assert.Equal(t, 15*time.Second, cfg.Scheduler.Interval)
+		assert.True(t, cfg.Tracing.Insecure)
+		assert.InDelta(t, 0.5, cfg.Tracing.SampleRatio, 1e-9)
+		assert.Equal(t, 7, cfg.Server.RateLimit.Default.Burst)
+		assert.Equal(t, "/var/lib/calendar", cfg.Storage.Memory.Dir)
+	})
+
+	t.Run("flags override env", func(t *testing.T) {
+		cfg, err := Loader{
+			File:      file,
+			Overrides: Overrides{"storage.dsn=postgres://flag", "logger.level=warn"},
+			LookupEnv: env(map[string]string{"CALENDAR_STORAGE_DSN": "postgres://env"}),
+		}.Load()
+		require.NoError(t, err)
+
+		assert.Equal(t, "postgres://flag", cfg.Storage.DSN)
+		assert.Equal(t, "warn", cfg.Logger.Level)
+	})
+
+	t.Run("invalid env value", func(t *testing.T) {
+		_, err := Loader{File: file, LookupEnv: env(map[string]string{
+			"CALENDAR_SCHEDULER_INTERVAL": "often",
+		})}.Load()
+		assert.ErrorContains(t, err, "CALENDAR_SCHEDULER_INTERVAL")
+	})
+
+	t.Run("unknown flag option", func(t *testing.T) {
+		_, err := Loader{File: file, Overrides: Overrides{"storage.password=x"}, LookupEnv: env(nil)}.Load()
+		assert.ErrorContains(t, err, "storage.password: unknown config option")
+	})
+}
+
+func TestLoaderSecretFiles(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "dsn")
+	require.NoError(t, os.WriteFile(secret, []byte("postgres://secret\n"), 0o600))
+
+	cfg, err := Loader{LookupEnv: env(map[string]string{
+		"CALENDAR_STORAGE_TYPE":     "sql",
+		"CALENDAR_STORAGE_DSN_FILE": secret,
+	})}.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://secret", cfg.Storage.DSN)
+
+	_, err = Loader{LookupEnv: env(map[string]string{
+		"CALENDAR_STORAGE_DSN":      "postgres://env",
+		"CALENDAR_STORAGE_DSN_FILE": secret,
+	})}.Load()
+	assert.ErrorContains(t, err, "both CALENDAR_STORAGE_DSN and CALENDAR_STORAGE_DSN_FILE are set")
+
+	_, err = Loader{LookupEnv: env(map[string]string{
+		"CALENDAR_STORAGE_DSN_FILE": filepath.Join(t.TempDir(), "missing"),
+	})}.Load()
+	assert.ErrorContains(t, err, "CALENDAR_STORAGE_DSN_FILE")
+}
+
+func TestValidate(t *testing.T) {
+	cfg := Default()
+	require.NoError(t, cfg.Validate())
+
+	cfg.Logger.Level = "verbose"
+	cfg.Storage.Type = "sql"
+	cfg.Storage.Memory.Fsync = "interval"
+	cfg.Storage.Memory.FsyncInterval = 0
+	cfg.Scheduler.Interval = 0
+	cfg.Tracing.SampleRatio = 2
+	cfg.Server.RateLimit.Routes = []RouteRateLimit{{Method: "GET"}}
+	cfg.Server.MaxBatchOperations = 0
+
+	err := cfg.Validate()
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, []string{
+		`server.rate_limit.routes[0].path: required`,
+		`server.max_batch_operations: must be positive, got 0`,
+		`logger.level: must be one of "debug", "info", "warn", "error", got "verbose"`,
+		`storage.dsn: required when storage.type is "sql"`,
+		`storage.memory.fsync_interval: must be positive when storage.memory.fsync is "interval", got 0s`,
+		`scheduler.interval: must be positive, got 0s`,
+		`tracing.sample_ratio: must be between 0 and 1, got 2`,
+	}, verr.Problems)
+}
+
+func TestShippedConfigsAreValid(t *testing.T) {
+	files, err := filepath.Glob("../../configs/*.yaml")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			_, err := Loader{File: file, LookupEnv: env(nil)}.Load()
+			assert.NoError(t, err)
+		})
+	}
+}
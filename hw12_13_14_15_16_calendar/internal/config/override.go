@@ -0,0 +1,186 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix — префикс переменных окружения. Имя переменной строится из пути параметра
+// в YAML: storage.dsn -> CALENDAR_STORAGE_DSN, attachments.s3.secret_key ->
+// CALENDAR_ATTACHMENTS_S3_SECRET_KEY. Переменная с суффиксом _FILE указывает на файл
+// со значением, например секрет, смонтированный Docker или Kubernetes.
+const EnvPrefix = "CALENDAR_"
+
+// fileEnvSuffix — суффикс переменной, значение которой читается из файла
+const fileEnvSuffix = "_FILE"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// option — параметр конфигурации, который можно задать строкой.
+type option struct {
+	path  string // путь в YAML через точку, например storage.dsn
+	value reflect.Value
+}
+
+func (o option) envName() string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(o.path, ".", "_"))
+}
+
+// options перечисляет параметры cfg со скалярными значениями и списками строк.
+// Списки структур (например, server.rate_limit.routes) задаются только в файле.
+func options(cfg *Config) []option {
+	var opts []option
+	collectOptions(reflect.ValueOf(cfg).Elem(), "", &opts)
+	return opts
+}
+
+func collectOptions(v reflect.Value, prefix string, opts *[]option) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("yaml")
+		name, flags, _ := strings.Cut(tag, ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+
+		fv := v.Field(i)
+		if flags == "inline" {
+			collectOptions(fv, prefix, opts)
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		path := prefix + name
+
+		switch {
+		case fv.Kind() == reflect.Struct:
+			collectOptions(fv, path+".", opts)
+		case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.String:
+		default:
+			*opts = append(*opts, option{path: path, value: fv})
+		}
+	}
+}
+
+// setValue разбирает s по типу параметра. Списки задаются через запятую.
+func setValue(v reflect.Value, s string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// applyEnv переопределяет параметры переменными окружения CALENDAR_* и CALENDAR_*_FILE.
+func applyEnv(cfg *Config, lookupEnv func(string) (string, bool)) error {
+	for _, opt := range options(cfg) {
+		name := opt.envName()
+		value, ok := lookupEnv(name)
+
+		if file, fromFile := lookupEnv(name + fileEnvSuffix); fromFile {
+			if ok {
+				return fmt.Errorf("both %s and %s are set", name, name+fileEnvSuffix)
+			}
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name+fileEnvSuffix, err)
+			}
+			// Редакторы и echo добавляют перевод строки в конец файла секрета
+			value, ok = strings.TrimRight(string(data), "\r\n"), true
+		}
+		if !ok {
+			continue
+		}
+
+		if err := setValue(opt.value, value); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Overrides — значения флагов -set путь=значение, например -set storage.type=sql.
+// Реализует flag.Value, флаг можно указывать несколько раз.
+type Overrides []string
+
+func (o *Overrides) String() string {
+	if o == nil {
+		return ""
+	}
+	return strings.Join(*o, " ")
+}
+
+func (o *Overrides) Set(s string) error {
+	if path, _, ok := strings.Cut(s, "="); !ok || path == "" {
+		return errors.New("expected path=value, for example storage.type=sql")
+	}
+	*o = append(*o, s)
+	return nil
+}
+
+func (o Overrides) apply(cfg *Config) error {
+	if len(o) == 0 {
+		return nil
+	}
+
+	byPath := make(map[string]reflect.Value)
+	for _, opt := range options(cfg) {
+		byPath[opt.path] = opt.value
+	}
+
+	for _, override := range o {
+		path, value, _ := strings.Cut(override, "=")
+		v, ok := byPath[path]
+		if !ok {
+			return fmt.Errorf("-set %s: unknown config option", path)
+		}
+		if err := setValue(v, value); err != nil {
+			return fmt.Errorf("-set %s: %w", path, err)
+		}
+	}
+	return nil
+}
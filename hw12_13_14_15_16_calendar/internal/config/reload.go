@@ -0,0 +1,26 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// WatchReload перечитывает конфигурацию по SIGHUP до отмены ctx и передает результат
+// в apply. Если новая конфигурация не загрузилась или не прошла проверку, apply получает
+// ошибку, а сервис продолжает работать с прежними настройками.
+func WatchReload(ctx context.Context, loader Loader, apply func(*Config, error)) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+	defer signal.Stop(sig)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sig:
+			apply(loader.Load())
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ValidationError перечисляет все ошибки конфигурации сразу, чтобы их можно было
+// исправить за один заход.
+type ValidationError struct {
+	Problems []string // в виде "путь: описание"
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid config:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+type validator struct {
+	problems []string
+}
+
+func (v *validator) check(ok bool, path, format string, args ...any) {
+	if !ok {
+		v.problems = append(v.problems, path+": "+fmt.Sprintf(format, args...))
+	}
+}
+
+func (v *validator) oneOf(value, path string, allowed ...string) {
+	v.check(slices.Contains(allowed, value), path, "must be one of %s, got %q", quoteAll(allowed), value)
+}
+
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = fmt.Sprintf("%q", value)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// Validate проверяет согласованность параметров. Пустые значения, допустимые
+// для части сервисов (например, kafka у календаря), не считаются ошибкой.
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	v.check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port", "must be between 1 and 65535, got %d", c.Server.Port)
+	validateRateLimit(v, c.Server.RateLimit)
+	v.check(c.Server.Archive.MaxSize >= 0, "server.archive.max_size", "must not be negative")
+	v.check(c.Server.MaxBatchOperations > 0, "server.max_batch_operations", "must be positive, got %d",
+		c.Server.MaxBatchOperations)
+
+	v.oneOf(c.Logger.Level, "logger.level", "debug", "info", "warn", "error")
+
+	v.oneOf(c.Storage.Type, "storage.type", "memory", "sql")
+	v.check(c.Storage.Type != "sql" || c.Storage.DSN != "", "storage.dsn", "required when storage.type is \"sql\"")
+	v.oneOf(c.Storage.Memory.Fsync, "storage.memory.fsync", "always", "interval", "never")
+	if c.Storage.Memory.Fsync == "interval" {
+		v.check(c.Storage.Memory.FsyncInterval > 0, "storage.memory.fsync_interval",
+			"must be positive when storage.memory.fsync is \"interval\", got %s", c.Storage.Memory.FsyncInterval)
+	}
+	v.check(c.Storage.Memory.SnapshotInterval >= 0, "storage.memory.snapshot_interval", "must not be negative")
+
+	for i, broker := range c.Kafka.Brokers {
+		v.check(broker != "", fmt.Sprintf("kafka.brokers[%d]", i), "must not be empty")
+	}
+	v.check(c.Kafka.MaxAttempts >= 0, "kafka.max_attempts", "must not be negative")
+	v.check(c.Kafka.RetryBackoff >= 0, "kafka.retry_backoff", "must not be negative")
+	v.check(c.Kafka.MaxRetryBackoff >= c.Kafka.RetryBackoff, "kafka.max_retry_backoff",
+		"must not be less than kafka.retry_backoff (%s)", c.Kafka.RetryBackoff)
+	if c.Kafka.AutoCreateTopic {
+		v.check(c.Kafka.Partitions > 0, "kafka.partitions", "must be positive when kafka.auto_create_topic is set")
+		v.check(c.Kafka.ReplicationFactor > 0, "kafka.replication_factor",
+			"must be positive when kafka.auto_create_topic is set")
+	}
+
+	v.oneOf(c.Kafka.Compression, "kafka.compression", "", "gzip", "snappy", "lz4", "zstd")
+	v.check(c.Kafka.BatchSize >= 0, "kafka.batch_size", "must not be negative")
+	v.check(c.Kafka.BatchTimeout >= 0, "kafka.batch_timeout", "must not be negative")
+	v.oneOf(c.Kafka.Acks, "kafka.acks", "none", "one", "all")
+	v.oneOf(c.Kafka.StartOffset, "kafka.start_offset", "earliest", "latest")
+	v.oneOf(c.Kafka.Encoding, "kafka.encoding", "json", "protobuf")
+	v.check((c.Kafka.TLS.CertFile == "") == (c.Kafka.TLS.KeyFile == ""), "kafka.tls",
+		"cert_file and key_file must be set together")
+	v.oneOf(c.Kafka.SASL.Mechanism, "kafka.sasl.mechanism", "", "plain", "scram-sha-256", "scram-sha-512")
+	if c.Kafka.SASL.Mechanism != "" {
+		v.check(c.Kafka.SASL.Username != "", "kafka.sasl.username", "required when kafka.sasl.mechanism is set")
+	}
+
+	v.check(c.Scheduler.Interval > 0, "scheduler.interval", "must be positive, got %s", c.Scheduler.Interval)
+	v.check(c.Scheduler.CleanupOlderThan >= 0, "scheduler.cleanup_older_than", "must not be negative")
+	v.check(c.Scheduler.TrashRetention >= 0, "scheduler.trash_retention", "must not be negative")
+	v.check(c.Scheduler.AuditRetention >= 0, "scheduler.audit_retention", "must not be negative")
+
+	v.check(c.Storer.Workers > 0, "storer.workers", "must be positive, got %d", c.Storer.Workers)
+	v.check(c.Storer.BatchSize > 0, "storer.batch_size", "must be positive, got %d", c.Storer.BatchSize)
+	v.check(c.Storer.FlushInterval > 0, "storer.flush_interval", "must be positive, got %s", c.Storer.FlushInterval)
+
+	v.oneOf(c.Attachments.Backend, "attachments.backend", "", "local", "s3")
+	v.check(c.Attachments.MaxSize >= 0, "attachments.max_size", "must not be negative")
+	if c.Attachments.Backend == "local" {
+		v.check(c.Attachments.Local.Dir != "", "attachments.local.dir", "required when attachments.backend is \"local\"")
+	}
+	if c.Attachments.Backend == "s3" {
+		v.check(c.Attachments.S3.Endpoint != "", "attachments.s3.endpoint", "required when attachments.backend is \"s3\"")
+		v.check(c.Attachments.S3.Bucket != "", "attachments.s3.bucket", "required when attachments.backend is \"s3\"")
+	}
+
+	v.check(c.Quotas.MaxEventsPerUser >= 0, "quotas.max_events_per_user", "must not be negative")
+
+	v.oneOf(c.Tracing.Exporter, "tracing.exporter", "", "otlp", "stdout")
+	v.check(c.Tracing.Exporter != "otlp" || c.Tracing.Endpoint != "", "tracing.endpoint", "required when tracing.exporter is \"otlp\"")
+	v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio", "must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+
+	v.check(c.Health.Timeout >= 0, "health.timeout", "must not be negative")
+	v.check(c.Health.HeartbeatTimeout >= 0, "health.heartbeat_timeout", "must not be negative")
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+func validateRateLimit(v *validator, cfg RateLimitConfig) {
+	validateRule := func(path string, rule RateLimitRule) {
+		v.check(rule.Rate >= 0, path+".rate", "must not be negative")
+		v.check(rule.Burst >= 0, path+".burst", "must not be negative")
+	}
+
+	validateRule("server.rate_limit.default", cfg.Default)
+	for i, route := range cfg.Routes {
+		path := fmt.Sprintf("server.rate_limit.routes[%d]", i)
+		v.check(route.Path != "", path+".path", "required")
+		validateRule(path, route.RateLimitRule)
+	}
+}
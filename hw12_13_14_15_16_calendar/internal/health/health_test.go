@@ -0,0 +1,102 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecker(t *testing.T) {
+	t.Run("all ok", func(t *testing.T) {
+		report := NewChecker(time.Second).
+			Add("a", func(context.Context) error { return nil }).
+			Add("b", func(context.Context) error { return nil }).
+			Run(context.Background())
+
+		assert.Equal(t, StatusOK, report.Status)
+		assert.Len(t, report.Checks, 2)
+		assert.Equal(t, StatusOK, report.Checks["a"].Status)
+	})
+
+	t.Run("one failed", func(t *testing.T) {
+		report := NewChecker(time.Second).
+			Add("ok", func(context.Context) error { return nil }).
+			Add("broken", func(context.Context) error { return errors.New("connection refused") }).
+			Run(context.Background())
+
+		assert.Equal(t, StatusFail, report.Status)
+		assert.Equal(t, StatusOK, report.Checks["ok"].Status)
+		assert.Equal(t, StatusFail, report.Checks["broken"].Status)
+		assert.Equal(t, "connection refused", report.Checks["broken"].Error)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		block := make(chan struct{})
+		defer close(block)
+
+		start := time.Now()
+		report := NewChecker(50*time.Millisecond).
+			// Проверка игнорирует контекст и все равно не задерживает отчет
+			Add("hung", func(context.Context) error { <-block; return nil }).
+			Run(context.Background())
+
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Equal(t, StatusFail, report.Status)
+		assert.Contains(t, report.Checks["hung"].Error, "timed out")
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		assert.Equal(t, StatusOK, NewChecker(0).Run(context.Background()).Status)
+	})
+}
+
+func TestCheckerServeHTTP(t *testing.T) {
+	failing := true
+	checker := NewChecker(time.Second).Add("storage", func(context.Context) error {
+		if failing {
+			return errors.New("down")
+		}
+		return nil
+	})
+
+	do := func() (*httptest.ResponseRecorder, Report) {
+		w := httptest.NewRecorder()
+		checker.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+
+		var report Report
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+		return w, report
+	}
+
+	w, report := do()
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, "down", report.Checks["storage"].Error)
+
+	failing = false
+	w, report = do()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, StatusOK, report.Status)
+}
+
+func TestHeartbeat(t *testing.T) {
+	h := NewHeartbeat()
+	check := h.Check(time.Minute)
+	assert.NoError(t, check(context.Background()))
+
+	// Цикл давно не отмечался
+	h.last.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+	assert.Error(t, check(context.Background()))
+
+	h.Beat()
+	assert.NoError(t, check(context.Background()))
+}
@@ -1,29 +1,21 @@
 package logger
 
 import (
+	"context"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
 type Logger struct {
 	*zap.SugaredLogger
+	// level общий для логгера и всех производных от него, чтобы SetLevel действовал на все
+	level *zap.AtomicLevel
 }
 
 func NewLogger(level string) (*Logger, error) {
 	cfg := zap.NewProductionConfig()
-
-	switch level {
-	case "debug":
-		cfg.Level = zap.NewAtomicLevelAt(zap.DebugLevel)
-	case "info":
-		cfg.Level = zap.NewAtomicLevelAt(zap.InfoLevel)
-	case "warn":
-		cfg.Level = zap.NewAtomicLevelAt(zap.WarnLevel)
-	case "error":
-		cfg.Level = zap.NewAtomicLevelAt(zap.ErrorLevel)
-	default:
-		cfg.Level = zap.NewAtomicLevelAt(zap.InfoLevel)
-	}
+	cfg.Level = zap.NewAtomicLevelAt(parseLevel(level))
 
 	cfg.EncoderConfig.TimeKey = "timestamp"
 	cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
@@ -33,9 +25,55 @@ func NewLogger(level string) (*Logger, error) {
 		return nil, err
 	}
 
-	return &Logger{SugaredLogger: logger.Sugar()}, nil
+	return &Logger{SugaredLogger: logger.Sugar(), level: &cfg.Level}, nil
+}
+
+func parseLevel(level string) zapcore.Level {
+	switch level {
+	case "debug":
+		return zap.DebugLevel
+	case "warn":
+		return zap.WarnLevel
+	case "error":
+		return zap.ErrorLevel
+	default:
+		return zap.InfoLevel
+	}
+}
+
+// SetLevel меняет уровень логирования на лету, например при перезагрузке конфигурации.
+// Для логгеров, созданных не через NewLogger, ничего не делает.
+func (l *Logger) SetLevel(level string) {
+	if l.level != nil {
+		l.level.SetLevel(parseLevel(level))
+	}
 }
 
 func (l *Logger) WithField(key string, value interface{}) *Logger {
-	return &Logger{SugaredLogger: l.SugaredLogger.With(key, value)}
+	return &Logger{SugaredLogger: l.SugaredLogger.With(key, value), level: l.level}
+}
+
+// Nop возвращает логгер, отбрасывающий все записи.
+func Nop() *Logger {
+	return &Logger{SugaredLogger: zap.NewNop().Sugar()}
+}
+
+type ctxKey struct{}
+
+// WithContext сохраняет в контексте логгер запроса, например с полем request_id,
+// чтобы записи приложения и хранилища можно было связать с запросом.
+func WithContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext возвращает логгер из контекста, а если его нет — fallback.
+// При пустом fallback возвращается Nop.
+func FromContext(ctx context.Context, fallback *Logger) *Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*Logger); ok {
+		return l
+	}
+	if fallback == nil {
+		return Nop()
+	}
+	return fallback
 }
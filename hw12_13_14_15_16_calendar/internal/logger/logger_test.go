@@ -1,7 +1,35 @@
 package logger
 
-import "testing"
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
 
 func TestLogger(t *testing.T) {
-	// TODO
+	l, err := NewLogger("debug")
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	assert.Same(t, l, FromContext(ctx, l))
+	assert.NotNil(t, FromContext(ctx, nil))
+
+	reqLogger := l.WithField("request_id", "42")
+	assert.Same(t, reqLogger, FromContext(WithContext(ctx, reqLogger), l))
+}
+
+func TestSetLevel(t *testing.T) {
+	l, err := NewLogger("info")
+	assert.NoError(t, err)
+	reqLogger := l.WithField("request_id", "42")
+	assert.False(t, reqLogger.Desugar().Core().Enabled(zapcore.DebugLevel))
+
+	// Уровень общий для логгера и производных от него
+	l.SetLevel("debug")
+	assert.True(t, reqLogger.Desugar().Core().Enabled(zapcore.DebugLevel))
+
+	// Логгер без уровня, например Nop, изменение игнорирует
+	Nop().SetLevel("debug")
 }
@@ -1,12 +1,18 @@
 package metrics
 
 import (
+	"net/http"
+
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // Metrics содержит все метрики сервиса
 type Metrics struct {
+	gatherer prometheus.Gatherer
+
 	// HTTP метрики
 	httpRequestsTotal   *prometheus.CounterVec
 	httpRequestDuration *prometheus.HistogramVec
@@ -18,19 +24,54 @@ type Metrics struct {
 	eventsQueriedTotal prometheus.Counter
 
 	// Метрики фоновых задач
-	schedulerRunsTotal       prometheus.Counter
-	notificationsSentTotal   prometheus.Counter
-	notificationsFailedTotal prometheus.Counter
+	schedulerRunsTotal         prometheus.Counter
+	notificationsSentTotal     prometheus.Counter
+	notificationsFailedTotal   prometheus.Counter
+	notificationsDeferredTotal prometheus.Counter
 
 	// Метрики хранилища
 	storageOperationsTotal   *prometheus.CounterVec
 	storageOperationDuration *prometheus.HistogramVec
+	storageErrorsTotal       *prometheus.CounterVec
+
+	// Метрики Kafka
+	kafkaMessagesProducedTotal *prometheus.CounterVec
+	kafkaProduceDuration       *prometheus.HistogramVec
+	kafkaMessagesConsumedTotal *prometheus.CounterVec
+	kafkaConsumerLag           *prometheus.GaugeVec
+	kafkaMessageLatency        *prometheus.HistogramVec
+	kafkaReconnectsTotal       *prometheus.CounterVec
+
+	// Метрики сохранения уведомлений
+	notificationsStoredTotal  *prometheus.CounterVec
+	notificationStoreDuration prometheus.Histogram
+	notificationBatchSize     prometheus.Histogram
+}
+
+// NewRegistry создает реестр метрик процесса с метриками среды выполнения Go и процесса.
+func NewRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
 }
 
-// NewMetrics создает новый экземпляр метрик
-func NewMetrics() *Metrics {
+// NewMetrics создает метрики сервиса и регистрирует их в reg. Каждому экземпляру
+// нужен свой реестр: повторная регистрация в том же реестре приводит к панике.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	factory := promauto.With(reg)
+
+	gatherer := prometheus.DefaultGatherer
+	if g, ok := reg.(prometheus.Gatherer); ok {
+		gatherer = g
+	}
+
 	return &Metrics{
-		httpRequestsTotal: promauto.NewCounterVec(
+		gatherer: gatherer,
+
+		httpRequestsTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "calendar_http_requests_total",
 				Help: "Общее количество HTTP запросов",
@@ -38,7 +79,7 @@ func NewMetrics() *Metrics {
 			[]string{"method", "endpoint", "status_code"},
 		),
 
-		httpRequestDuration: promauto.NewHistogramVec(
+		httpRequestDuration: factory.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Name:    "calendar_http_request_duration_seconds",
 				Help:    "Длительность HTTP запросов в секундах",
@@ -47,56 +88,63 @@ func NewMetrics() *Metrics {
 			[]string{"method", "endpoint"},
 		),
 
-		eventsCreatedTotal: promauto.NewCounter(
+		eventsCreatedTotal: factory.NewCounter(
 			prometheus.CounterOpts{
 				Name: "calendar_events_created_total",
 				Help: "Общее количество созданных событий",
 			},
 		),
 
-		eventsUpdatedTotal: promauto.NewCounter(
+		eventsUpdatedTotal: factory.NewCounter(
 			prometheus.CounterOpts{
 				Name: "calendar_events_updated_total",
 				Help: "Общее количество обновленных событий",
 			},
 		),
 
-		eventsDeletedTotal: promauto.NewCounter(
+		eventsDeletedTotal: factory.NewCounter(
 			prometheus.CounterOpts{
 				Name: "calendar_events_deleted_total",
 				Help: "Общее количество удаленных событий",
 			},
 		),
 
-		eventsQueriedTotal: promauto.NewCounter(
+		eventsQueriedTotal: factory.NewCounter(
 			prometheus.CounterOpts{
 				Name: "calendar_events_queried_total",
 				Help: "Общее количество запросов списка событий",
 			},
 		),
 
-		schedulerRunsTotal: promauto.NewCounter(
+		schedulerRunsTotal: factory.NewCounter(
 			prometheus.CounterOpts{
 				Name: "calendar_scheduler_runs_total",
 				Help: "Общее количество запусков планировщика",
 			},
 		),
 
-		notificationsSentTotal: promauto.NewCounter(
+		notificationsSentTotal: factory.NewCounter(
 			prometheus.CounterOpts{
 				Name: "calendar_notifications_sent_total",
 				Help: "Общее количество отправленных уведомлений",
 			},
 		),
 
-		notificationsFailedTotal: promauto.NewCounter(
+		notificationsFailedTotal: factory.NewCounter(
 			prometheus.CounterOpts{
 				Name: "calendar_notifications_failed_total",
 				Help: "Общее количество неудачных отправок уведомлений",
 			},
 		),
 
-		storageOperationsTotal: promauto.NewCounterVec(
+		notificationsDeferredTotal: factory.NewCounter(
+			prometheus.CounterOpts{
+				Name: "calendar_notifications_deferred_total",
+				Help: "Общее количество напоминаний, перенесенных на рабочее время",
+			},
+		),
+
+		storageOperationsTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "calendar_storage_operations_total",
 				Help: "Общее количество операций с хранилищем",
@@ -104,7 +152,7 @@ func NewMetrics() *Metrics {
 			[]string{"operation", "storage_type"},
 		),
 
-		storageOperationDuration: promauto.NewHistogramVec(
+		storageOperationDuration: factory.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Name:    "calendar_storage_operation_duration_seconds",
 				Help:    "Длительность операций с хранилищем в секундах",
@@ -112,9 +160,96 @@ func NewMetrics() *Metrics {
 			},
 			[]string{"operation", "storage_type"},
 		),
+
+		storageErrorsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "calendar_storage_errors_total",
+				Help: "Общее количество операций с хранилищем, завершившихся ошибкой",
+			},
+			[]string{"operation", "storage_type"},
+		),
+
+		kafkaMessagesProducedTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "calendar_kafka_messages_produced_total",
+				Help: "Общее количество сообщений, отправленных в Kafka",
+			},
+			[]string{"topic", "status"},
+		),
+
+		kafkaProduceDuration: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "calendar_kafka_produce_duration_seconds",
+				Help:    "Длительность отправки сообщения в Kafka в секундах",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"topic"},
+		),
+
+		kafkaMessagesConsumedTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "calendar_kafka_messages_consumed_total",
+				Help: "Общее количество сообщений, прочитанных из Kafka",
+			},
+			[]string{"topic", "status"},
+		),
+
+		kafkaConsumerLag: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "calendar_kafka_consumer_lag",
+				Help: "Количество сообщений в топике, еще не прочитанных потребителем",
+			},
+			[]string{"topic"},
+		),
+
+		kafkaMessageLatency: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "calendar_kafka_message_latency_seconds",
+				Help:    "Время от отправки сообщения в Kafka до его получения в секундах",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"topic"},
+		),
+
+		kafkaReconnectsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "calendar_kafka_reconnects_total",
+				Help: "Общее количество попыток переподключения к Kafka после потери соединения",
+			},
+			[]string{"topic"},
+		),
+
+		notificationsStoredTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "calendar_notifications_stored_total",
+				Help: "Общее количество уведомлений, обработанных сервисом сохранения",
+			},
+			[]string{"status"},
+		),
+
+		notificationStoreDuration: factory.NewHistogram(
+			prometheus.HistogramOpts{
+				Name:    "calendar_notification_store_duration_seconds",
+				Help:    "Длительность сохранения пакета уведомлений в секундах",
+				Buckets: prometheus.DefBuckets,
+			},
+		),
+
+		notificationBatchSize: factory.NewHistogram(
+			prometheus.HistogramOpts{
+				Name:    "calendar_notification_batch_size",
+				Help:    "Количество уведомлений в пакете, сохраняемом сервисом сохранения",
+				Buckets: []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000},
+			},
+		),
 	}
 }
 
+// Handler отдает метрики реестра, переданного в NewMetrics, в формате Prometheus.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.gatherer, promhttp.HandlerOpts{})
+}
+
 // IncHTTPRequest увеличивает счетчик HTTP запросов
 func (m *Metrics) IncHTTPRequest(method, endpoint, statusCode string) {
 	m.httpRequestsTotal.WithLabelValues(method, endpoint, statusCode).Inc()
@@ -160,6 +295,11 @@ func (m *Metrics) IncNotificationFailed() {
 	m.notificationsFailedTotal.Inc()
 }
 
+// IncNotificationDeferred увеличивает счетчик напоминаний, перенесенных на рабочее время
+func (m *Metrics) IncNotificationDeferred() {
+	m.notificationsDeferredTotal.Inc()
+}
+
 // IncStorageOperation увеличивает счетчик операций с хранилищем
 func (m *Metrics) IncStorageOperation(operation, storageType string) {
 	m.storageOperationsTotal.WithLabelValues(operation, storageType).Inc()
@@ -169,3 +309,59 @@ func (m *Metrics) IncStorageOperation(operation, storageType string) {
 func (m *Metrics) ObserveStorageOperationDuration(operation, storageType string, duration float64) {
 	m.storageOperationDuration.WithLabelValues(operation, storageType).Observe(duration)
 }
+
+// IncStorageError увеличивает счетчик операций с хранилищем, завершившихся ошибкой
+func (m *Metrics) IncStorageError(operation, storageType string) {
+	m.storageErrorsTotal.WithLabelValues(operation, storageType).Inc()
+}
+
+// IncKafkaMessageProduced увеличивает счетчик отправленных в Kafka сообщений
+func (m *Metrics) IncKafkaMessageProduced(topic, status string) {
+	m.kafkaMessagesProducedTotal.WithLabelValues(topic, status).Inc()
+}
+
+// ObserveKafkaProduceDuration записывает длительность отправки сообщения в Kafka
+func (m *Metrics) ObserveKafkaProduceDuration(topic string, duration float64) {
+	m.kafkaProduceDuration.WithLabelValues(topic).Observe(duration)
+}
+
+// IncKafkaMessageConsumed увеличивает счетчик прочитанных из Kafka сообщений
+func (m *Metrics) IncKafkaMessageConsumed(topic, status string) {
+	m.kafkaMessagesConsumedTotal.WithLabelValues(topic, status).Inc()
+}
+
+// SetKafkaConsumerLag устанавливает отставание потребителя от конца топика
+func (m *Metrics) SetKafkaConsumerLag(topic string, lag int64) {
+	m.kafkaConsumerLag.WithLabelValues(topic).Set(float64(lag))
+}
+
+// ObserveKafkaMessageLatency записывает время от отправки сообщения до его получения
+func (m *Metrics) ObserveKafkaMessageLatency(topic string, latency float64) {
+	m.kafkaMessageLatency.WithLabelValues(topic).Observe(latency)
+}
+
+// IncKafkaReconnect увеличивает счетчик попыток переподключения к Kafka
+func (m *Metrics) IncKafkaReconnect(topic string) {
+	m.kafkaReconnectsTotal.WithLabelValues(topic).Inc()
+}
+
+// IncNotificationStored увеличивает счетчик уведомлений, обработанных сервисом сохранения
+func (m *Metrics) IncNotificationStored(status string) {
+	m.notificationsStoredTotal.WithLabelValues(status).Inc()
+}
+
+// ObserveNotificationStoreDuration записывает длительность сохранения пакета уведомлений
+func (m *Metrics) ObserveNotificationStoreDuration(duration float64) {
+	m.notificationStoreDuration.Observe(duration)
+}
+
+// ObserveNotificationBatchSize записывает размер сохраняемого пакета уведомлений
+func (m *Metrics) ObserveNotificationBatchSize(size int) {
+	m.notificationBatchSize.Observe(float64(size))
+}
+
+// Значения метки status метрик Kafka и сохранения уведомлений
+const (
+	StatusSuccess = "success"
+	StatusError   = "error"
+)
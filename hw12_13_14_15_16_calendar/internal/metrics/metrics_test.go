@@ -8,7 +8,6 @@ import (
 	"testing"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func TestMetricsEndpoint(t *testing.T) {
@@ -16,7 +15,7 @@ func TestMetricsEndpoint(t *testing.T) {
 	registry := prometheus.NewRegistry()
 
 	// Создаем экземпляр метрик с custom registry
-	m := newMetricsWithRegistry(registry)
+	m := NewMetrics(registry)
 
 	// Увеличиваем некоторые метрики для теста
 	m.IncHTTPRequest("GET", "/api/events", "OK")
@@ -30,9 +29,17 @@ func TestMetricsEndpoint(t *testing.T) {
 	m.IncStorageOperation("create", "memory")
 	m.ObserveHTTPRequestDuration("GET", "/api/events", 0.1)
 	m.ObserveStorageOperationDuration("create", "memory", 0.05)
+	m.IncStorageError("get_event", "memory")
+	m.IncKafkaMessageProduced("notifications", StatusSuccess)
+	m.ObserveKafkaProduceDuration("notifications", 0.01)
+	m.IncKafkaMessageConsumed("notifications", StatusError)
+	m.SetKafkaConsumerLag("notifications", 3)
+	m.ObserveKafkaMessageLatency("notifications", 0.2)
+	m.IncNotificationStored(StatusSuccess)
+	m.ObserveNotificationStoreDuration(0.01)
 
 	// Создаем тестовый сервер с эндпоинтом /metrics
-	handler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+	handler := m.Handler()
 	req := httptest.NewRequest("GET", "/metrics", nil)
 	w := httptest.NewRecorder()
 	handler.ServeHTTP(w, req)
@@ -66,6 +73,14 @@ func TestMetricsEndpoint(t *testing.T) {
 		"calendar_notifications_failed_total",
 		"calendar_storage_operations_total",
 		"calendar_storage_operation_duration_seconds",
+		"calendar_storage_errors_total",
+		"calendar_kafka_messages_produced_total",
+		"calendar_kafka_produce_duration_seconds",
+		"calendar_kafka_messages_consumed_total",
+		"calendar_kafka_consumer_lag",
+		"calendar_kafka_message_latency_seconds",
+		"calendar_notifications_stored_total",
+		"calendar_notification_store_duration_seconds",
 	}
 
 	for _, metric := range expectedMetrics {
@@ -86,12 +101,16 @@ func TestMetricsEndpoint(t *testing.T) {
 	if !strings.Contains(metricsOutput, `calendar_scheduler_runs_total 1`) {
 		t.Error("Scheduler runs metric value not correct")
 	}
+
+	if !strings.Contains(metricsOutput, `calendar_kafka_consumer_lag{topic="notifications"} 3`) {
+		t.Error("Kafka consumer lag metric value not correct")
+	}
 }
 
 func TestMetricIncrement(t *testing.T) {
 	// Создаем новый registry для этого теста
 	registry := prometheus.NewRegistry()
-	m := newMetricsWithRegistry(registry)
+	m := NewMetrics(registry)
 
 	// Тестируем увеличение счетчиков
 	m.IncEventCreated()
@@ -101,7 +120,7 @@ func TestMetricIncrement(t *testing.T) {
 	m.IncEventsQueried()
 
 	// Создаем handler для получения значений
-	handler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+	handler := m.Handler()
 	req := httptest.NewRequest("GET", "/metrics", nil)
 	w := httptest.NewRecorder()
 	handler.ServeHTTP(w, req)
@@ -134,107 +153,31 @@ func TestMetricIncrement(t *testing.T) {
 	}
 }
 
-// Вспомогательная функция для создания метрик с custom registry
-func newMetricsWithRegistry(registry prometheus.Registerer) *Metrics {
-	m := &Metrics{
-		httpRequestsTotal: prometheus.NewCounterVec(
-			prometheus.CounterOpts{
-				Name: "calendar_http_requests_total",
-				Help: "Общее количество HTTP запросов",
-			},
-			[]string{"method", "endpoint", "status_code"},
-		),
-
-		httpRequestDuration: prometheus.NewHistogramVec(
-			prometheus.HistogramOpts{
-				Name:    "calendar_http_request_duration_seconds",
-				Help:    "Длительность HTTP запросов в секундах",
-				Buckets: prometheus.DefBuckets,
-			},
-			[]string{"method", "endpoint"},
-		),
-
-		eventsCreatedTotal: prometheus.NewCounter(
-			prometheus.CounterOpts{
-				Name: "calendar_events_created_total",
-				Help: "Общее количество созданных событий",
-			},
-		),
-
-		eventsUpdatedTotal: prometheus.NewCounter(
-			prometheus.CounterOpts{
-				Name: "calendar_events_updated_total",
-				Help: "Общее количество обновленных событий",
-			},
-		),
-
-		eventsDeletedTotal: prometheus.NewCounter(
-			prometheus.CounterOpts{
-				Name: "calendar_events_deleted_total",
-				Help: "Общее количество удаленных событий",
-			},
-		),
-
-		eventsQueriedTotal: prometheus.NewCounter(
-			prometheus.CounterOpts{
-				Name: "calendar_events_queried_total",
-				Help: "Общее количество запросов списка событий",
-			},
-		),
-
-		schedulerRunsTotal: prometheus.NewCounter(
-			prometheus.CounterOpts{
-				Name: "calendar_scheduler_runs_total",
-				Help: "Общее количество запусков планировщика",
-			},
-		),
-
-		notificationsSentTotal: prometheus.NewCounter(
-			prometheus.CounterOpts{
-				Name: "calendar_notifications_sent_total",
-				Help: "Общее количество отправленных уведомлений",
-			},
-		),
-
-		notificationsFailedTotal: prometheus.NewCounter(
-			prometheus.CounterOpts{
-				Name: "calendar_notifications_failed_total",
-				Help: "Общее количество неудачных отправок уведомлений",
-			},
-		),
-
-		storageOperationsTotal: prometheus.NewCounterVec(
-			prometheus.CounterOpts{
-				Name: "calendar_storage_operations_total",
-				Help: "Общее количество операций с хранилищем",
-			},
-			[]string{"operation", "storage_type"},
-		),
-
-		storageOperationDuration: prometheus.NewHistogramVec(
-			prometheus.HistogramOpts{
-				Name:    "calendar_storage_operation_duration_seconds",
-				Help:    "Длительность операций с хранилищем в секундах",
-				Buckets: prometheus.DefBuckets,
-			},
-			[]string{"operation", "storage_type"},
-		),
-	}
-
-	// Регистрируем все метрики в переданном registry
-	registry.MustRegister(
-		m.httpRequestsTotal,
-		m.httpRequestDuration,
-		m.eventsCreatedTotal,
-		m.eventsUpdatedTotal,
-		m.eventsDeletedTotal,
-		m.eventsQueriedTotal,
-		m.schedulerRunsTotal,
-		m.notificationsSentTotal,
-		m.notificationsFailedTotal,
-		m.storageOperationsTotal,
-		m.storageOperationDuration,
-	)
-
-	return m
+func TestMetricsInstancesAreIndependent(t *testing.T) {
+	// Экземпляры с разными реестрами не конфликтуют при регистрации и не делят значения
+	first := NewMetrics(NewRegistry())
+	second := NewMetrics(NewRegistry())
+
+	first.IncEventCreated()
+
+	scrape := func(m *Metrics) string {
+		w := httptest.NewRecorder()
+		m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+		return w.Body.String()
+	}
+
+	firstOutput, secondOutput := scrape(first), scrape(second)
+	if !strings.Contains(firstOutput, `calendar_events_created_total 1`) {
+		t.Error("Events created should be 1 in the first registry")
+	}
+	if !strings.Contains(secondOutput, `calendar_events_created_total 0`) {
+		t.Error("Events created should be 0 in the second registry")
+	}
+
+	// Реестр процесса содержит метрики среды выполнения Go и процесса
+	for _, metric := range []string{"go_goroutines", "process_cpu_seconds_total"} {
+		if !strings.Contains(firstOutput, metric) {
+			t.Errorf("Expected metric %s not found in output", metric)
+		}
+	}
 }
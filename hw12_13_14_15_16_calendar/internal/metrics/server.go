@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ListenAndServe отдает метрики m по пути /metrics и обработчики handlers по их путям
+// (например, /livez и /readyz) на адресе addr до отмены ctx.
+// Используется фоновыми сервисами, у которых нет собственного HTTP API.
+func ListenAndServe(ctx context.Context, addr string, m *Metrics, handlers map[string]http.Handler) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Handler())
+	for path, handler := range handlers {
+		mux.Handle(path, handler)
+	}
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		// Ошибка остановки означает лишь прерванные запросы сборщика метрик
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
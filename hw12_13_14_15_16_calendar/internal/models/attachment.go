@@ -0,0 +1,37 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrAttachmentNotFound     = errors.New("attachment not found")
+	ErrAttachmentTooLarge     = errors.New("attachment is too large")
+	ErrUnsupportedContentType = errors.New("unsupported attachment content type")
+	ErrAttachmentsDisabled    = errors.New("attachments are disabled")
+	ErrInvalidAttachment      = errors.New("invalid attachment")
+)
+
+// Attachment — вложение события: файл в хранилище вложений либо ссылка, если задан URL.
+type Attachment struct {
+	ID          string `json:"id"`
+	EventID     string `json:"event_id"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	// Checksum — SHA-256 содержимого в hex, пустой для ссылок.
+	Checksum  string    `json:"checksum"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IsLink сообщает, является ли вложение ссылкой без содержимого.
+func (a *Attachment) IsLink() bool {
+	return a.URL != ""
+}
+
+// BlobKey возвращает ключ содержимого вложения в хранилище.
+func (a *Attachment) BlobKey() string {
+	return a.EventID + "/" + a.ID
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	AuditActionCreate  AuditAction = "create"
+	AuditActionUpdate  AuditAction = "update"
+	AuditActionPatch   AuditAction = "patch"
+	AuditActionDelete  AuditAction = "delete"
+	AuditActionRestore AuditAction = "restore"
+	AuditActionPurge   AuditAction = "purge"
+)
+
+// AuditEntry — неизменяемая запись журнала об изменении события.
+// Before и After содержат JSON события до и после изменения,
+// Diff — JSON Merge Patch, переводящий Before в After.
+type AuditEntry struct {
+	ID        string          `json:"id"`
+	EventID   string          `json:"event_id"`
+	Actor     string          `json:"actor"`
+	Action    AuditAction     `json:"action"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	Diff      json.RawMessage `json:"diff,omitempty"`
+	RequestID string          `json:"request_id"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// AuditFilter ограничивает выборку журнала; пустые поля не фильтруют.
+type AuditFilter struct {
+	EventID string
+	Actor   string
+	Limit   int
+}
@@ -0,0 +1,31 @@
+package models
+
+import "errors"
+
+// ErrBatchAborted возвращается для операций атомарного пакета,
+// отмененных из-за ошибки в другой операции.
+var ErrBatchAborted = errors.New("batch aborted")
+
+type BatchOp string
+
+const (
+	BatchOpCreate BatchOp = "create"
+	BatchOpUpdate BatchOp = "update"
+	BatchOpDelete BatchOp = "delete"
+)
+
+// BatchOperation — одна операция пакетного изменения. Create и update используют
+// Event (ненулевая Event.Version проверяется как в UpdateEvent), delete — ID и Version.
+type BatchOperation struct {
+	Op      BatchOp
+	Event   *Event
+	ID      string
+	Version int64
+}
+
+// BatchResult — итог операции пакета: состояние события до и после изменения или ошибка.
+type BatchResult struct {
+	Before *Event
+	After  *Event
+	Err    error
+}
@@ -2,15 +2,39 @@ package models
 
 import (
 	"errors"
+	"fmt"
+	"slices"
 	"time"
 )
 
 var (
-	ErrDateBusy      = errors.New("time slot is already busy")
-	ErrEventNotFound = errors.New("event not found")
-	ErrInvalidEvent  = errors.New("invalid event data")
+	ErrDateBusy        = errors.New("time slot is already busy")
+	ErrEventNotFound   = errors.New("event not found")
+	ErrInvalidEvent    = errors.New("invalid event data")
+	ErrVersionConflict = errors.New("event version conflict")
+	ErrQuotaExceeded   = errors.New("event quota exceeded")
 )
 
+// Priority — важность события.
+type Priority string
+
+const (
+	PriorityLow    Priority = "low"
+	PriorityNormal Priority = "normal"
+	PriorityHigh   Priority = "high"
+	PriorityUrgent Priority = "urgent"
+)
+
+// Valid сообщает, является ли приоритет одним из допустимых значений. Пустой приоритет допустим.
+func (p Priority) Valid() bool {
+	switch p {
+	case "", PriorityLow, PriorityNormal, PriorityHigh, PriorityUrgent:
+		return true
+	default:
+		return false
+	}
+}
+
 type Event struct {
 	ID          string    `json:"id"`
 	Title       string    `json:"title"`
@@ -19,4 +43,157 @@ type Event struct {
 	EndTime     time.Time `json:"end_time"`
 	UserID      string    `json:"user_id"`
 	Reminder    time.Time `json:"reminder"`
+	Tags        []string  `json:"tags"`
+	Category    string    `json:"category"`
+	// Color — цвет события в формате #RRGGBB.
+	Color    string   `json:"color"`
+	Priority Priority `json:"priority"`
+	// ResourceIDs — забронированные событием ресурсы.
+	ResourceIDs []string `json:"resource_ids"`
+	// Version увеличивается при каждом изменении. Ненулевая версия,
+	// переданная в UpdateEvent/DeleteEvent, должна совпадать с текущей.
+	Version int64 `json:"version"`
+	// DeletedAt — момент переноса события в корзину, нулевое значение для активных событий.
+	DeletedAt time.Time `json:"deleted_at"`
+	// ReminderDeferral — перенос напоминания планировщиком. Поле принадлежит планировщику:
+	// оно не меняет Version, не выдается в API и сохраняется при изменении события.
+	ReminderDeferral *ReminderDeferral `json:"reminder_deferral,omitempty"`
+}
+
+// ReminderDeferral — напоминание, перенесенное с нерабочего времени пользователя на To.
+// Перенос действует, пока время напоминания события равно From: если пользователь
+// изменил напоминание, оно отправляется в новый срок.
+type ReminderDeferral struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// ReminderAt возвращает момент отправки напоминания с учетом действующего переноса.
+func (e *Event) ReminderAt() time.Time {
+	if e.ReminderDeferral != nil && e.ReminderDeferral.From.Equal(e.Reminder) {
+		return e.ReminderDeferral.To
+	}
+	return e.Reminder
+}
+
+// EventField — имя поля события, изменяемого частичным обновлением.
+type EventField string
+
+const (
+	EventFieldTitle       EventField = "title"
+	EventFieldDescription EventField = "description"
+	EventFieldStartTime   EventField = "start_time"
+	EventFieldEndTime     EventField = "end_time"
+	EventFieldUserID      EventField = "user_id"
+	EventFieldReminder    EventField = "reminder"
+	EventFieldTags        EventField = "tags"
+	EventFieldCategory    EventField = "category"
+	EventFieldColor       EventField = "color"
+	EventFieldPriority    EventField = "priority"
+	EventFieldResourceIDs EventField = "resource_ids"
+)
+
+// ChangedFields возвращает поля, значения которых в after отличаются от before.
+func ChangedFields(before, after *Event) []EventField {
+	var fields []EventField
+	if before.Title != after.Title {
+		fields = append(fields, EventFieldTitle)
+	}
+	if before.Description != after.Description {
+		fields = append(fields, EventFieldDescription)
+	}
+	if !before.StartTime.Equal(after.StartTime) {
+		fields = append(fields, EventFieldStartTime)
+	}
+	if !before.EndTime.Equal(after.EndTime) {
+		fields = append(fields, EventFieldEndTime)
+	}
+	if before.UserID != after.UserID {
+		fields = append(fields, EventFieldUserID)
+	}
+	if !before.Reminder.Equal(after.Reminder) {
+		fields = append(fields, EventFieldReminder)
+	}
+	if !slices.Equal(before.Tags, after.Tags) {
+		fields = append(fields, EventFieldTags)
+	}
+	if before.Category != after.Category {
+		fields = append(fields, EventFieldCategory)
+	}
+	if before.Color != after.Color {
+		fields = append(fields, EventFieldColor)
+	}
+	if before.Priority != after.Priority {
+		fields = append(fields, EventFieldPriority)
+	}
+	if !slices.Equal(before.ResourceIDs, after.ResourceIDs) {
+		fields = append(fields, EventFieldResourceIDs)
+	}
+	return fields
+}
+
+// ApplyFields копирует в событие перечисленные поля из src.
+func (e *Event) ApplyFields(src *Event, fields []EventField) error {
+	for _, field := range fields {
+		switch field {
+		case EventFieldTitle:
+			e.Title = src.Title
+		case EventFieldDescription:
+			e.Description = src.Description
+		case EventFieldStartTime:
+			e.StartTime = src.StartTime
+		case EventFieldEndTime:
+			e.EndTime = src.EndTime
+		case EventFieldUserID:
+			e.UserID = src.UserID
+		case EventFieldReminder:
+			e.Reminder = src.Reminder
+		case EventFieldTags:
+			e.Tags = slices.Clone(src.Tags)
+		case EventFieldCategory:
+			e.Category = src.Category
+		case EventFieldColor:
+			e.Color = src.Color
+		case EventFieldPriority:
+			e.Priority = src.Priority
+		case EventFieldResourceIDs:
+			e.ResourceIDs = slices.Clone(src.ResourceIDs)
+		default:
+			return fmt.Errorf("%w: unknown field %q", ErrInvalidEvent, field)
+		}
+	}
+	return nil
+}
+
+// EventFilter — дополнительные условия выборки событий. Пустые поля не ограничивают выборку.
+type EventFilter struct {
+	// Tags — событие должно содержать все перечисленные теги.
+	Tags     []string
+	Category string
+	Priority Priority
+	UserID   string
+	// EndsAfter — событие должно заканчиваться позже этого момента.
+	EndsAfter time.Time
+}
+
+// Matches проверяет, удовлетворяет ли событие фильтру.
+func (f EventFilter) Matches(e *Event) bool {
+	if f.UserID != "" && e.UserID != f.UserID {
+		return false
+	}
+	if !f.EndsAfter.IsZero() && !e.EndTime.After(f.EndsAfter) {
+		return false
+	}
+	if f.Category != "" && e.Category != f.Category {
+		return false
+	}
+	if f.Priority != "" && e.Priority != f.Priority {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !slices.Contains(e.Tags, tag) {
+			return false
+		}
+	}
+	return true
 }
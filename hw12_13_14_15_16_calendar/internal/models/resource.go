@@ -0,0 +1,94 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrResourceNotFound = errors.New("resource not found")
+	ErrResourceBusy     = errors.New("resource is already booked for this time")
+	ErrResourceInUse    = errors.New("resource is booked by events")
+	ErrInvalidResource  = errors.New("invalid resource data")
+)
+
+type ResourceKind string
+
+const (
+	ResourceKindRoom      ResourceKind = "room"
+	ResourceKindEquipment ResourceKind = "equipment"
+)
+
+// Valid сообщает, является ли тип ресурса одним из допустимых значений.
+func (k ResourceKind) Valid() bool {
+	return k == ResourceKindRoom || k == ResourceKindEquipment
+}
+
+// Resource — бронируемый ресурс: переговорная или оборудование.
+type Resource struct {
+	ID   string       `json:"id"`
+	Name string       `json:"name"`
+	Kind ResourceKind `json:"kind"`
+	// Capacity — вместимость переговорной, для оборудования обычно 0.
+	Capacity    int       `json:"capacity"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ResourceFilter — условия выборки ресурсов. Пустые поля не ограничивают выборку.
+type ResourceFilter struct {
+	Kind        ResourceKind
+	MinCapacity int
+}
+
+// Matches проверяет, удовлетворяет ли ресурс фильтру.
+func (f ResourceFilter) Matches(r *Resource) bool {
+	if f.Kind != "" && r.Kind != f.Kind {
+		return false
+	}
+	return r.Capacity >= f.MinCapacity
+}
+
+// Booking — занятость ресурса событием.
+type Booking struct {
+	ResourceID string
+	EventID    string
+	StartTime  time.Time
+	EndTime    time.Time
+}
+
+// TimeSlot — интервал времени [Start, End).
+type TimeSlot struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Overlaps сообщает, пересекаются ли полуинтервалы [aStart, aEnd) и [bStart, bEnd).
+func Overlaps(aStart, aEnd, bStart, bEnd time.Time) bool {
+	return aStart.Before(bEnd) && bStart.Before(aEnd)
+}
+
+// FreeSlots возвращает свободные интервалы внутри [from, to) с учетом занятых интервалов busy,
+// отсортированных по времени начала.
+func FreeSlots(from, to time.Time, busy []TimeSlot) []TimeSlot {
+	var free []TimeSlot
+	cursor := from
+	for _, slot := range busy {
+		if slot.Start.After(cursor) {
+			end := slot.Start
+			if end.After(to) {
+				end = to
+			}
+			if end.After(cursor) {
+				free = append(free, TimeSlot{Start: cursor, End: end})
+			}
+		}
+		if slot.End.After(cursor) {
+			cursor = slot.End
+		}
+	}
+	if to.After(cursor) {
+		free = append(free, TimeSlot{Start: cursor, End: to})
+	}
+	return free
+}
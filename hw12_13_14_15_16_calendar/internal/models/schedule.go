@@ -0,0 +1,163 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+var (
+	ErrWorkingHoursNotFound = errors.New("working hours not found")
+	ErrOutOfOfficeNotFound  = errors.New("out-of-office period not found")
+	ErrInvalidSchedule      = errors.New("invalid working hours")
+)
+
+// WorkingWindow — рабочий интервал в один из дней недели. Start и End отсчитываются
+// от полуночи в часовом поясе пользователя, End не больше 24 часов.
+type WorkingWindow struct {
+	Weekday time.Weekday  `json:"weekday"`
+	Start   time.Duration `json:"start"`
+	End     time.Duration `json:"end"`
+}
+
+// WorkingHours — рабочее время пользователя. Пустой список Windows означает,
+// что пользователь доступен в любое время.
+type WorkingHours struct {
+	UserID   string          `json:"user_id"`
+	TimeZone string          `json:"time_zone"`
+	Windows  []WorkingWindow `json:"windows"`
+	// DeferReminders — переносить несрочные напоминания, выпавшие на нерабочее время
+	// или отсутствие, на начало следующего рабочего интервала.
+	DeferReminders bool `json:"defer_reminders"`
+}
+
+// Validate проверяет часовой пояс и рабочие интервалы.
+func (w *WorkingHours) Validate() error {
+	if _, err := time.LoadLocation(w.TimeZone); err != nil {
+		return fmt.Errorf("%w: unknown time zone %q", ErrInvalidSchedule, w.TimeZone)
+	}
+	for _, window := range w.Windows {
+		if window.Weekday < time.Sunday || window.Weekday > time.Saturday {
+			return fmt.Errorf("%w: unknown weekday %d", ErrInvalidSchedule, window.Weekday)
+		}
+		if window.Start < 0 || window.End > 24*time.Hour || window.Start >= window.End {
+			return fmt.Errorf("%w: window must end after it starts within a day", ErrInvalidSchedule)
+		}
+	}
+	return nil
+}
+
+// NextWorkingTime возвращает ближайший момент не раньше t, попадающий в рабочие часы
+// и не попадающий в периоды отсутствия away. ok=false, если такого момента нет раньше limit.
+func (w *WorkingHours) NextWorkingTime(t, limit time.Time, away []*OutOfOffice) (time.Time, bool) {
+	for t.Before(limit) {
+		if end, absent := awayUntil(away, t); absent {
+			t = end
+			continue
+		}
+
+		start, ok := w.nextWindowStart(t)
+		if !ok {
+			return time.Time{}, false
+		}
+		if !start.After(t) {
+			return t, true
+		}
+		t = start
+	}
+	return time.Time{}, false
+}
+
+// nextWindowStart возвращает t, если оно попадает в рабочий интервал, иначе начало
+// ближайшего следующего интервала.
+func (w *WorkingHours) nextWindowStart(t time.Time) (time.Time, bool) {
+	if len(w.Windows) == 0 {
+		return t, true
+	}
+
+	loc, err := time.LoadLocation(w.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+
+	// Интервалы не выходят за пределы суток, поэтому достаточно просмотреть неделю вперед
+	for day := 0; day <= 7; day++ {
+		midnight := time.Date(local.Year(), local.Month(), local.Day()+day, 0, 0, 0, 0, loc)
+
+		var next time.Time
+		for _, window := range w.Windows {
+			if window.Weekday != midnight.Weekday() {
+				continue
+			}
+			start, end := wallClock(midnight, window.Start), wallClock(midnight, window.End)
+			if !end.After(t) {
+				continue
+			}
+			if start.Before(t) {
+				start = t
+			}
+			if next.IsZero() || start.Before(next) {
+				next = start
+			}
+		}
+		if !next.IsZero() {
+			return next, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// wallClock возвращает время суток offset в день midnight по местным часам. В дни
+// перехода на летнее время сутки короче или длиннее 24 часов, поэтому прибавлять
+// offset к полуночи нельзя: 09:00 оказалось бы 08:00 или 10:00.
+func wallClock(midnight time.Time, offset time.Duration) time.Time {
+	h := offset / time.Hour
+	m := offset % time.Hour / time.Minute
+	sec := offset % time.Minute / time.Second
+	return time.Date(midnight.Year(), midnight.Month(), midnight.Day(),
+		int(h), int(m), int(sec), int(offset%time.Second), midnight.Location())
+}
+
+// OutOfOffice — период отсутствия пользователя [StartTime, EndTime).
+type OutOfOffice struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// awayUntil возвращает конец периода отсутствия, в который попадает t.
+func awayUntil(away []*OutOfOffice, t time.Time) (time.Time, bool) {
+	for _, period := range away {
+		if !t.Before(period.StartTime) && t.Before(period.EndTime) {
+			return period.EndTime, true
+		}
+	}
+	return time.Time{}, false
+}
+
+type BusyKind string
+
+const (
+	BusyKindEvent       BusyKind = "event"
+	BusyKindOutOfOffice BusyKind = "out_of_office"
+)
+
+// BusySlot — занятый интервал пользователя: событие или период отсутствия с ID источника.
+type BusySlot struct {
+	Start time.Time
+	End   time.Time
+	Kind  BusyKind
+	ID    string
+}
+
+// SortBusySlots упорядочивает занятые интервалы по времени начала.
+func SortBusySlots(slots []BusySlot) {
+	sort.SliceStable(slots, func(i, j int) bool {
+		return slots[i].Start.Before(slots[j].Start)
+	})
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkingHours_NextWorkingTime(t *testing.T) {
+	hours := &WorkingHours{
+		TimeZone: "Europe/Moscow",
+		Windows: []WorkingWindow{
+			{Weekday: time.Monday, Start: 9 * time.Hour, End: 13 * time.Hour},
+			{Weekday: time.Monday, Start: 14 * time.Hour, End: 18 * time.Hour},
+			{Weekday: time.Tuesday, Start: 9 * time.Hour, End: 18 * time.Hour},
+		},
+	}
+	require.NoError(t, hours.Validate())
+
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+	// 2024-01-01 — понедельник
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, time.January, day, hour, minute, 0, 0, moscow)
+	}
+	limit := at(31, 0, 0)
+
+	tests := []struct {
+		name string
+		t    time.Time
+		away []*OutOfOffice
+		want time.Time
+	}{
+		{name: "inside window", t: at(1, 10, 30), want: at(1, 10, 30)},
+		{name: "before window", t: at(1, 7, 0), want: at(1, 9, 0)},
+		{name: "lunch break", t: at(1, 13, 15), want: at(1, 14, 0)},
+		{name: "after last window of day", t: at(1, 19, 0), want: at(2, 9, 0)},
+		{name: "weekend", t: at(6, 12, 0), want: at(8, 9, 0)},
+		{name: "utc input", t: at(1, 7, 0).UTC(), want: at(1, 9, 0)},
+		{
+			name: "out of office",
+			t:    at(1, 10, 0),
+			away: []*OutOfOffice{{StartTime: at(1, 9, 0), EndTime: at(2, 12, 0)}},
+			want: at(2, 12, 0),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := hours.NextWorkingTime(tt.t, limit, tt.away)
+			require.True(t, ok)
+			assert.True(t, tt.want.Equal(got), "want %s, got %s", tt.want, got)
+		})
+	}
+
+	t.Run("no working time before limit", func(t *testing.T) {
+		_, ok := hours.NextWorkingTime(at(1, 19, 0), at(2, 8, 0), nil)
+		assert.False(t, ok)
+	})
+
+	t.Run("empty windows mean always available", func(t *testing.T) {
+		got, ok := (&WorkingHours{}).NextWorkingTime(at(6, 3, 0), limit, nil)
+		require.True(t, ok)
+		assert.True(t, at(6, 3, 0).Equal(got))
+	})
+}
+
+func TestWorkingHours_NextWorkingTimeDST(t *testing.T) {
+	hours := &WorkingHours{
+		TimeZone: "Europe/Berlin",
+		Windows: []WorkingWindow{
+			{Weekday: time.Sunday, Start: 9 * time.Hour, End: 17 * time.Hour},
+		},
+	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	// 2024-03-31 сутки длятся 23 часа, 2024-10-27 — 25 часов
+	for _, day := range []time.Time{
+		time.Date(2024, time.March, 31, 0, 0, 0, 0, berlin),
+		time.Date(2024, time.October, 27, 0, 0, 0, 0, berlin),
+	} {
+		t.Run(day.Format(time.DateOnly), func(t *testing.T) {
+			at := func(hour int) time.Time {
+				return time.Date(day.Year(), day.Month(), day.Day(), hour, 0, 0, 0, berlin)
+			}
+
+			got, ok := hours.NextWorkingTime(day.Add(-4*time.Hour), day.AddDate(0, 0, 1), nil)
+			require.True(t, ok)
+			assert.True(t, at(9).Equal(got), "want %s, got %s", at(9), got)
+
+			// Окончание интервала тоже считается по местным часам
+			_, ok = hours.NextWorkingTime(at(17), day.AddDate(0, 0, 1), nil)
+			assert.False(t, ok)
+			got, ok = hours.NextWorkingTime(at(16), day.AddDate(0, 0, 1), nil)
+			require.True(t, ok)
+			assert.True(t, at(16).Equal(got), "want %s, got %s", at(16), got)
+		})
+	}
+}
+
+func TestWorkingHours_Validate(t *testing.T) {
+	assert.ErrorIs(t, (&WorkingHours{TimeZone: "Mars/Olympus"}).Validate(), ErrInvalidSchedule)
+	assert.ErrorIs(t, (&WorkingHours{Windows: []WorkingWindow{{Start: 10 * time.Hour, End: 9 * time.Hour}}}).Validate(),
+		ErrInvalidSchedule)
+	assert.ErrorIs(t, (&WorkingHours{Windows: []WorkingWindow{{Start: 0, End: 25 * time.Hour}}}).Validate(),
+		ErrInvalidSchedule)
+	assert.NoError(t, (&WorkingHours{Windows: []WorkingWindow{{Start: 0, End: 24 * time.Hour}}}).Validate())
+}
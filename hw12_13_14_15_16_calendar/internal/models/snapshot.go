@@ -0,0 +1,19 @@
+package models
+
+import "errors"
+
+// ErrStorageNotEmpty — снимок загружается только в пустое хранилище.
+var ErrStorageNotEmpty = errors.New("storage is not empty")
+
+// Snapshot — полное содержимое хранилища календаря для резервного копирования
+// и переноса данных между реализациями хранилища. События включают корзину,
+// сохраняя ID, версии и моменты удаления; журнал аудита упорядочен по времени.
+type Snapshot struct {
+	Resources    []*Resource
+	Templates    []*EventTemplate
+	WorkingHours []*WorkingHours
+	OutOfOffice  []*OutOfOffice
+	Events       []*Event
+	Attachments  []*Attachment
+	Audit        []*AuditEntry
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+var (
+	ErrTemplateNotFound = errors.New("event template not found")
+	ErrInvalidTemplate  = errors.New("invalid event template")
+)
+
+// templateVariable — подстановка {name} в шаблоне заголовка
+var templateVariable = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// EventTemplate — заготовка для часто повторяющихся событий (1:1, ретроспектива,
+// передача дежурства). Событие из шаблона получает заголовок по TitlePattern,
+// длительность Duration и остальные поля шаблона, если они не переопределены.
+type EventTemplate struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// TitlePattern — заголовок с подстановками {name}, например "1:1 {user} / {with}"
+	TitlePattern string        `json:"title_pattern"`
+	Duration     time.Duration `json:"duration"`
+	Description  string        `json:"description"`
+	// NotifyBefore — напоминание по умолчанию; 0 — без напоминания
+	NotifyBefore time.Duration `json:"notify_before"`
+	Tags         []string      `json:"tags"`
+	Category     string        `json:"category"`
+	Color        string        `json:"color"`
+	Priority     Priority      `json:"priority"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+// Variables возвращает имена подстановок заголовка в порядке появления без повторов.
+func (t *EventTemplate) Variables() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, match := range templateVariable.FindAllStringSubmatch(t.TitlePattern, -1) {
+		if name := match[1]; !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Title подставляет значения vars в шаблон заголовка. Текст в фигурных скобках,
+// не похожий на имя подстановки, остается как есть.
+func (t *EventTemplate) Title(vars map[string]string) (string, error) {
+	for _, name := range t.Variables() {
+		if _, ok := vars[name]; !ok {
+			return "", fmt.Errorf("%w: variable %q of the title pattern is not set", ErrInvalidTemplate, name)
+		}
+	}
+
+	return templateVariable.ReplaceAllStringFunc(t.TitlePattern, func(match string) string {
+		return vars[match[1:len(match)-1]]
+	}), nil
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventTemplate_Title(t *testing.T) {
+	template := &EventTemplate{TitlePattern: "1:1 {user} / {with} ({date}, {user}) {not a variable}"}
+	assert.Equal(t, []string{"user", "with", "date"}, template.Variables())
+
+	title, err := template.Title(map[string]string{"user": "alice", "with": "bob", "date": "2024-05-01"})
+	require.NoError(t, err)
+	assert.Equal(t, "1:1 alice / bob (2024-05-01, alice) {not a variable}", title)
+
+	_, err = template.Title(map[string]string{"user": "alice", "date": "2024-05-01"})
+	assert.ErrorIs(t, err, ErrInvalidTemplate)
+	assert.Contains(t, err.Error(), `"with"`)
+
+	title, err = (&EventTemplate{TitlePattern: "Retro"}).Title(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Retro", title)
+}
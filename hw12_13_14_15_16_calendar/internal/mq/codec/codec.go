@@ -0,0 +1,98 @@
+// Package codec кодирует уведомления для очереди. Сообщение сопровождается конвертом:
+// типом содержимого и версией схемы, которые kafka передает в заголовках.
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+)
+
+// Типы содержимого сообщения
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+// SchemaVersion — текущая версия схемы уведомления. Версия повышается только при
+// несовместимых изменениях; добавление полей совместимо и версию не меняет, так как
+// JSON и Protobuf пропускают неизвестные поля.
+const SchemaVersion = 1
+
+// ErrUnsupportedVersion — сообщение записано более новой версией схемы, чем знает сервис.
+var ErrUnsupportedVersion = errors.New("unsupported schema version")
+
+// ErrUnsupportedContentType — сообщение записано в формате, которого сервис не знает.
+var ErrUnsupportedContentType = errors.New("unsupported content type")
+
+// Envelope — сообщение с описанием его формата.
+type Envelope struct {
+	ContentType   string
+	SchemaVersion int
+	Payload       []byte
+}
+
+// Legacy возвращает конверт сообщения без заголовков: такие сообщения отправляли
+// версии планировщика до появления конверта, они содержат JSON схемы 1.
+func Legacy(payload []byte) Envelope {
+	return Envelope{ContentType: ContentTypeJSON, SchemaVersion: 1, Payload: payload}
+}
+
+// Encode кодирует уведомление в формате contentType текущей версии схемы.
+func Encode(n *models.Notification, contentType string) (Envelope, error) {
+	var (
+		payload []byte
+		err     error
+	)
+	switch contentType {
+	case ContentTypeJSON:
+		payload, err = json.Marshal(n)
+	case ContentTypeProtobuf:
+		payload, err = marshalProtobuf(n)
+	default:
+		return Envelope{}, fmt.Errorf("unknown content type %q", contentType)
+	}
+	if err != nil {
+		return Envelope{}, fmt.Errorf("encode %s: %w", contentType, err)
+	}
+
+	return Envelope{ContentType: contentType, SchemaVersion: SchemaVersion, Payload: payload}, nil
+}
+
+// Decode декодирует уведомление любой поддерживаемой версии схемы.
+func Decode(env Envelope) (*models.Notification, error) {
+	if env.SchemaVersion < 1 || env.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("%w %d, supported up to %d", ErrUnsupportedVersion, env.SchemaVersion, SchemaVersion)
+	}
+
+	var (
+		n   models.Notification
+		err error
+	)
+	switch env.ContentType {
+	case ContentTypeJSON:
+		err = json.Unmarshal(env.Payload, &n)
+	case ContentTypeProtobuf:
+		err = unmarshalProtobuf(env.Payload, &n)
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedContentType, env.ContentType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", env.ContentType, err)
+	}
+	return &n, nil
+}
+
+// ContentType возвращает тип содержимого для значения kafka.encoding из конфигурации.
+func ContentType(encoding string) (string, error) {
+	switch encoding {
+	case "", "json":
+		return ContentTypeJSON, nil
+	case "protobuf":
+		return ContentTypeProtobuf, nil
+	default:
+		return "", fmt.Errorf("unknown encoding %q", encoding)
+	}
+}
@@ -0,0 +1,140 @@
+package codec
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// -update перезаписывает golden-файлы. Делать это можно только для новых файлов:
+// существующие фиксируют формат сообщений, которые уже могут лежать в очереди.
+var update = flag.Bool("update", false, "write golden files")
+
+func goldenNotification() *models.Notification {
+	return &models.Notification{
+		ID:         "4f1c2d9e-8a7b-4c3d-9e2f-1a2b3c4d5e6f",
+		EventID:    "9b8a7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d",
+		EventTitle: "Планерка",
+		UserID:     "user-42",
+		Message:    "Напоминание: Планерка начнется в 10:00",
+		NotifyAt:   time.Date(2025, 3, 14, 9, 45, 0, 0, time.UTC),
+		CreatedAt:  time.Date(2025, 3, 14, 9, 30, 12, 345000000, time.UTC),
+	}
+}
+
+func readGolden(t *testing.T, name string, encoded []byte) []byte {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update && encoded != nil {
+		require.NoError(t, os.WriteFile(path, encoded, 0o644))
+	}
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return data
+}
+
+// Golden-файлы — сообщения, записанные прежними версиями сервиса. Каждый должен
+// декодироваться текущим кодом; при добавлении версии схемы добавляйте новые файлы,
+// не изменяя старые.
+func TestGoldenCompatibility(t *testing.T) {
+	want := goldenNotification()
+
+	tests := []struct {
+		golden string
+		env    func(payload []byte) Envelope
+		// contentType — формат, в котором текущий код должен кодировать сообщение
+		// побайтно так же, как в golden-файле; пустое значение — только декодирование
+		contentType string
+	}{
+		{
+			golden: "notification_legacy.json",
+			env:    Legacy,
+		},
+		{
+			golden:      "notification_v1.json",
+			env:         func(p []byte) Envelope { return Envelope{ContentTypeJSON, 1, p} },
+			contentType: ContentTypeJSON,
+		},
+		{
+			golden:      "notification_v1.pb",
+			env:         func(p []byte) Envelope { return Envelope{ContentTypeProtobuf, 1, p} },
+			contentType: ContentTypeProtobuf,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			var encoded []byte
+			if tt.contentType != "" {
+				env, err := Encode(want, tt.contentType)
+				require.NoError(t, err)
+				assert.Equal(t, SchemaVersion, env.SchemaVersion)
+				encoded = env.Payload
+			}
+
+			golden := readGolden(t, tt.golden, encoded)
+			if encoded != nil {
+				assert.Equal(t, golden, encoded, "wire format changed")
+			}
+
+			got, err := Decode(tt.env(golden))
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestProtobufRoundTrip(t *testing.T) {
+	for _, n := range []*models.Notification{
+		goldenNotification(),
+		{},
+		{ID: "only-id", NotifyAt: time.Unix(0, 0).UTC()},
+		{ID: "before-epoch", CreatedAt: time.Date(1960, 1, 1, 0, 0, 0, 5, time.UTC)},
+	} {
+		env, err := Encode(n, ContentTypeProtobuf)
+		require.NoError(t, err)
+		got, err := Decode(env)
+		require.NoError(t, err)
+		assert.Equal(t, n, got)
+	}
+}
+
+func TestProtobufUnknownFields(t *testing.T) {
+	env, err := Encode(goldenNotification(), ContentTypeProtobuf)
+	require.NoError(t, err)
+
+	// Поля, добавленные будущей версией схемы, пропускаются
+	payload := protowire.AppendTag(env.Payload, 100, protowire.BytesType)
+	payload = protowire.AppendString(payload, "future field")
+	payload = protowire.AppendTag(payload, 101, protowire.VarintType)
+	payload = protowire.AppendVarint(payload, 7)
+
+	got, err := Decode(Envelope{ContentTypeProtobuf, SchemaVersion, payload})
+	require.NoError(t, err)
+	assert.Equal(t, goldenNotification(), got)
+
+	_, err = Decode(Envelope{ContentTypeProtobuf, SchemaVersion, []byte{0x0a, 0x10, 'x'}})
+	assert.Error(t, err, "truncated message")
+}
+
+func TestDecodeErrors(t *testing.T) {
+	_, err := Decode(Envelope{ContentTypeJSON, SchemaVersion + 1, []byte("{}")})
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+
+	_, err = Decode(Envelope{"application/avro", 1, nil})
+	assert.ErrorIs(t, err, ErrUnsupportedContentType)
+	assert.ErrorContains(t, err, `"application/avro"`)
+
+	_, err = Decode(Envelope{ContentTypeJSON, 1, []byte("{")})
+	assert.ErrorContains(t, err, "decode application/json")
+
+	_, err = ContentType("avro")
+	assert.Error(t, err)
+}
@@ -0,0 +1,71 @@
+package codec
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/mq/codec/notificationpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// marshalProtobuf кодирует уведомление сообщением Notification из api/notification.proto.
+func marshalProtobuf(n *models.Notification) ([]byte, error) {
+	return proto.Marshal(&notificationpb.Notification{
+		Id:         n.ID,
+		EventId:    n.EventID,
+		EventTitle: n.EventTitle,
+		UserId:     n.UserID,
+		Message:    n.Message,
+		NotifyAt:   timestamp(n.NotifyAt),
+		CreatedAt:  timestamp(n.CreatedAt),
+	})
+}
+
+// unmarshalProtobuf декодирует уведомление. Неизвестные поля пропускаются, чтобы
+// сообщения с полями, добавленными позже, оставались читаемыми.
+func unmarshalProtobuf(b []byte, n *models.Notification) error {
+	var msg notificationpb.Notification
+	if err := proto.Unmarshal(b, &msg); err != nil {
+		return err
+	}
+
+	notifyAt, err := timeOf(msg.NotifyAt)
+	if err != nil {
+		return fmt.Errorf("notify_at: %w", err)
+	}
+	createdAt, err := timeOf(msg.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("created_at: %w", err)
+	}
+
+	*n = models.Notification{
+		ID:         msg.Id,
+		EventID:    msg.EventId,
+		EventTitle: msg.EventTitle,
+		UserID:     msg.UserId,
+		Message:    msg.Message,
+		NotifyAt:   notifyAt,
+		CreatedAt:  createdAt,
+	}
+	return nil
+}
+
+// timestamp переводит время в Timestamp; нулевое время не записывается.
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func timeOf(ts *timestamppb.Timestamp) (time.Time, error) {
+	if ts == nil {
+		return time.Time{}, nil
+	}
+	if err := ts.CheckValid(); err != nil {
+		return time.Time{}, err
+	}
+	return ts.AsTime(), nil
+}
@@ -0,0 +1,2 @@
+
+$4f1c2d9e-8a7b-4c3d-9e2f-1a2b3c4d5e6f$9b8a7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6dПланерка"user-42*BНапоминание: Планерка начнется в 10:002Ͼ:Ͼ
\ No newline at end of file
@@ -0,0 +1,105 @@
+//go:build integration
+
+package kafka
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/config"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/logger"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/metrics"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Тесты с настоящим брокером: make test-kafka поднимает Kafka из docker-compose
+// и передает его адрес в KAFKA_TEST_BROKERS.
+func testBrokers(t *testing.T) []string {
+	t.Helper()
+	brokers := os.Getenv("KAFKA_TEST_BROKERS")
+	if brokers == "" {
+		t.Skip("KAFKA_TEST_BROKERS is not set")
+	}
+	return strings.Split(brokers, ",")
+}
+
+func TestBrokerRoundTrip(t *testing.T) {
+	cfg := config.Default().Kafka
+	cfg.Brokers = testBrokers(t)
+	cfg.Topic = "calendar-test-" + uuid.NewString()
+	cfg.GroupID = "calendar-test-" + uuid.NewString()
+	cfg.Partitions = 2
+	cfg.Compression = "gzip"
+	cfg.Acks = "all"
+	// Брокер, только что поднятый make test-kafka, может еще запускаться
+	cfg.MaxAttempts = 20
+	cfg.RetryBackoff = 500 * time.Millisecond
+	cfg.MaxRetryBackoff = 5 * time.Second
+	m := metrics.NewMetrics(prometheus.NewRegistry())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	producer, err := NewProducer(cfg, m)
+	require.NoError(t, err)
+	require.NoError(t, producer.WaitForConnect(ctx, cfg.MaxAttempts, cfg.RetryBackoff))
+	defer producer.Close()
+	require.NoError(t, producer.Ping(ctx))
+
+	// Connect создал топик с заданным числом разделов
+	conn, err := kafka.DialContext(ctx, "tcp", cfg.Brokers[0])
+	require.NoError(t, err)
+	partitions, err := conn.ReadPartitions(cfg.Topic)
+	conn.Close()
+	require.NoError(t, err)
+	assert.Len(t, partitions, 2)
+
+	sent := &models.Notification{
+		ID:         uuid.NewString(),
+		EventID:    uuid.NewString(),
+		EventTitle: "Standup",
+		UserID:     "user-1",
+		Message:    "Standup in 15 minutes",
+		NotifyAt:   time.Now().UTC().Truncate(time.Second),
+	}
+	require.NoError(t, producer.SendNotification(ctx, sent))
+
+	consumer, err := NewConsumer(cfg, logger.Nop(), m)
+	require.NoError(t, err)
+	require.NoError(t, consumer.WaitForConnect(ctx, cfg.MaxAttempts, cfg.RetryBackoff))
+	defer consumer.Close()
+
+	deliveries, err := consumer.Consume(ctx)
+	require.NoError(t, err)
+
+	select {
+	case delivery := <-deliveries:
+		assert.Equal(t, sent.ID, delivery.Notification.ID)
+		assert.Equal(t, sent.Message, delivery.Notification.Message)
+		assert.True(t, sent.NotifyAt.Equal(delivery.Notification.NotifyAt))
+	case <-ctx.Done():
+		t.Fatal("notification was not delivered")
+	}
+}
+
+func TestBrokerMissingTopicWithoutAutoCreate(t *testing.T) {
+	cfg := config.Default().Kafka
+	cfg.Brokers = testBrokers(t)
+	cfg.Topic = "calendar-test-" + uuid.NewString()
+	cfg.AutoCreateTopic = false
+
+	producer, err := NewProducer(cfg, metrics.NewMetrics(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	assert.ErrorContains(t, producer.Connect(ctx), "does not exist")
+}
@@ -0,0 +1,175 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/config"
+	"github.com/segmentio/kafka-go"
+)
+
+// dialTimeout ограничивает установку соединения, если у контекста нет своего дедлайна
+const dialTimeout = 10 * time.Second
+
+// ensureTopic проверяет по метаданным, что топик существует и у него есть разделы.
+// Если топика нет и включен auto_create_topic, создает его с заданным числом разделов
+// и фактором репликации. Метаданные запрашиваются без автосоздания на стороне брокера,
+// чтобы топик не получил настройки брокера по умолчанию.
+func ensureTopic(ctx context.Context, dialer *kafka.Dialer, cfg config.KafkaConfig) error {
+	if len(cfg.Brokers) == 0 {
+		return errors.New("no brokers configured")
+	}
+
+	transport := newTransport(dialer)
+	defer transport.CloseIdleConnections()
+	client := &kafka.Client{Addr: kafka.TCP(cfg.Brokers...), Transport: transport}
+
+	meta, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{cfg.Topic}})
+	if err != nil {
+		return fmt.Errorf("read topic %q metadata: %w", cfg.Topic, err)
+	}
+
+	topicErr := error(kafka.UnknownTopicOrPartition)
+	var partitions int
+	if len(meta.Topics) > 0 {
+		topicErr, partitions = meta.Topics[0].Error, len(meta.Topics[0].Partitions)
+	}
+
+	switch {
+	case errors.Is(topicErr, kafka.UnknownTopicOrPartition):
+		if !cfg.AutoCreateTopic {
+			return fmt.Errorf("topic %q does not exist", cfg.Topic)
+		}
+		return createTopic(ctx, client, cfg)
+	case topicErr != nil:
+		return fmt.Errorf("topic %q metadata: %w", cfg.Topic, topicErr)
+	case partitions == 0:
+		return fmt.Errorf("topic %q has no partitions", cfg.Topic)
+	}
+	return nil
+}
+
+func createTopic(ctx context.Context, client *kafka.Client, cfg config.KafkaConfig) error {
+	res, err := client.CreateTopics(ctx, &kafka.CreateTopicsRequest{
+		Topics: []kafka.TopicConfig{{
+			Topic:             cfg.Topic,
+			NumPartitions:     cfg.Partitions,
+			ReplicationFactor: cfg.ReplicationFactor,
+		}},
+	})
+	if err == nil {
+		err = res.Errors[cfg.Topic]
+	}
+	// Топик мог успеть создать другой экземпляр сервиса
+	if err != nil && !errors.Is(err, kafka.TopicAlreadyExists) {
+		return fmt.Errorf("create topic %q: %w", cfg.Topic, err)
+	}
+	return nil
+}
+
+// retriable сообщает, что ошибка вызвана временной недоступностью брокера или
+// кластера и запрос стоит повторить.
+func retriable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var writeErrs kafka.WriteErrors
+	if errors.As(err, &writeErrs) {
+		for _, werr := range writeErrs {
+			if werr != nil && !retriable(werr) {
+				return false
+			}
+		}
+		return true
+	}
+
+	var kafkaErr kafka.Error
+	if errors.As(err, &kafkaErr) {
+		return kafkaErr.Temporary()
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE)
+}
+
+// backoff вычисляет экспоненциальную задержку между попытками со случайным разбросом,
+// чтобы экземпляры сервиса не переподключались одновременно.
+type backoff struct {
+	base, max time.Duration
+	attempt   int
+}
+
+func newBackoff(base, maxDelay time.Duration) *backoff {
+	if base <= 0 {
+		base = 100 * time.Millisecond
+	}
+	if maxDelay < base {
+		maxDelay = base
+	}
+	return &backoff{base: base, max: maxDelay}
+}
+
+// next возвращает задержку перед очередной попыткой: случайное значение
+// от половины до полной экспоненциальной задержки.
+func (b *backoff) next() time.Duration {
+	d := b.max
+	if b.attempt < 32 {
+		if exp := b.base << b.attempt; exp > 0 && exp < b.max {
+			d = exp
+		}
+	}
+	b.attempt++
+
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
+
+func (b *backoff) reset() {
+	b.attempt = 0
+}
+
+// wait ждет очередную задержку или отмену ctx.
+func (b *backoff) wait(ctx context.Context) error {
+	timer := time.NewTimer(b.next())
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retry вызывает connect, пока он не завершится успешно, но не больше maxAttempts раз.
+func retry(ctx context.Context, maxAttempts int, b *backoff, connect func(context.Context) error) error {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if err = connect(ctx); err == nil {
+			return nil
+		}
+		if attempt == maxAttempts {
+			break
+		}
+		if b.wait(ctx) != nil {
+			return fmt.Errorf("failed to connect after %d attempts, %w: %w", attempt, ctx.Err(), err)
+		}
+	}
+	return fmt.Errorf("failed to connect after %d attempts: %w", maxAttempts, err)
+}
@@ -0,0 +1,110 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/config"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/logger"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff(t *testing.T) {
+	b := newBackoff(100*time.Millisecond, time.Second)
+
+	for _, want := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		want *= time.Millisecond
+		d := b.next()
+		assert.GreaterOrEqual(t, d, want/2)
+		assert.LessOrEqual(t, d, want)
+	}
+
+	b.reset()
+	assert.LessOrEqual(t, b.next(), 100*time.Millisecond)
+
+	// Большое число попыток не переполняет задержку
+	b.attempt = 100
+	assert.LessOrEqual(t, b.next(), time.Second)
+}
+
+func TestRetry(t *testing.T) {
+	b := newBackoff(time.Millisecond, time.Millisecond)
+
+	calls := 0
+	err := retry(context.Background(), 3, b, func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("broker down")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err = retry(context.Background(), 2, b, func(context.Context) error {
+		calls++
+		return errors.New("broker down")
+	})
+	assert.EqualError(t, err, "failed to connect after 2 attempts: broker down")
+	assert.Equal(t, 2, calls)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = retry(ctx, 5, newBackoff(time.Hour, time.Hour), func(context.Context) error {
+		return errors.New("broker down")
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorContains(t, err, "broker down")
+}
+
+func TestRetriable(t *testing.T) {
+	assert.True(t, retriable(io.EOF))
+	assert.True(t, retriable(fmt.Errorf("write: %w", syscall.ECONNREFUSED)))
+	assert.True(t, retriable(&net.OpError{Op: "dial", Err: errors.New("no route to host")}))
+	assert.True(t, retriable(kafka.LeaderNotAvailable))
+	assert.True(t, retriable(kafka.WriteErrors{kafka.NotLeaderForPartition}))
+
+	assert.False(t, retriable(context.Canceled))
+	assert.False(t, retriable(kafka.MessageSizeTooLarge))
+	assert.False(t, retriable(kafka.WriteErrors{kafka.MessageSizeTooLarge}))
+	assert.False(t, retriable(errors.New("marshal notification")))
+}
+
+func TestConnectFailsWithoutBroker(t *testing.T) {
+	// Порт, на котором гарантированно никто не слушает
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	cfg := config.Default().Kafka
+	cfg.Brokers = []string{addr}
+	cfg.RetryBackoff = time.Millisecond
+	cfg.MaxRetryBackoff = time.Millisecond
+	m := metrics.NewMetrics(prometheus.NewRegistry())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	producer, err := NewProducer(cfg, m)
+	require.NoError(t, err)
+	assert.ErrorContains(t, producer.WaitForConnect(ctx, 2, cfg.RetryBackoff), "failed to connect after 2 attempts")
+	assert.Error(t, producer.Ping(ctx))
+
+	consumer, err := NewConsumer(cfg, logger.Nop(), m)
+	require.NoError(t, err)
+	assert.Error(t, consumer.Connect(ctx))
+	_, err = consumer.Consume(ctx)
+	assert.EqualError(t, err, "consumer not connected")
+}
@@ -2,92 +2,313 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/config"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/logger"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/metrics"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/mq"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/mq/codec"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/tracing"
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Consumer struct {
-	reader  *kafka.Reader
-	brokers []string
-	topic   string
-	groupID string
+	cfg         config.KafkaConfig
+	topic       string
+	dialer      *kafka.Dialer
+	startOffset int64
+	logger      *logger.Logger
+	metrics     *metrics.Metrics
+
+	// reader пересоздается при переподключении из горутины Consume, поэтому
+	// Close отмечает closed, чтобы переподключение не открыло reader заново
+	mu     sync.Mutex
+	reader *kafka.Reader
+	closed bool
+	done   chan struct{} // закрывается в Close
+	halted error         // причина остановки чтения, см. halt
 }
 
-func NewConsumer(brokers []string, topic, groupID string) *Consumer {
-	return &Consumer{
-		brokers: brokers,
-		topic:   topic,
-		groupID: groupID,
+// NewConsumer создает consumer; ошибка означает неверные параметры TLS, SASL
+// или начальной позиции. К брокерам consumer подключается в Connect.
+func NewConsumer(cfg config.KafkaConfig, logger *logger.Logger, metrics *metrics.Metrics) (*Consumer, error) {
+	dialer, err := newDialer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := startOffset(cfg.StartOffset)
+	if err != nil {
+		return nil, err
 	}
+
+	return &Consumer{
+		cfg:         cfg,
+		topic:       cfg.Topic,
+		dialer:      dialer,
+		startOffset: offset,
+		logger:      logger,
+		metrics:     metrics,
+		done:        make(chan struct{}),
+	}, nil
 }
 
+// Connect проверяет доступность брокеров и метаданные топика (создавая его при
+// необходимости) и только после этого создает reader группы потребителей.
 func (c *Consumer) Connect(ctx context.Context) error {
-	c.reader = kafka.NewReader(kafka.ReaderConfig{
-		Brokers: c.brokers,
+	if err := ensureTopic(ctx, c.dialer, c.cfg); err != nil {
+		return err
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: c.cfg.Brokers,
 		Topic:   c.topic,
-		GroupID: c.groupID,
+		GroupID: c.cfg.GroupID,
+		Dialer:  c.dialer,
+		// Учитывается, только пока у группы нет сохраненной позиции
+		StartOffset: c.startOffset,
 	})
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		_ = reader.Close()
+		return errConsumerClosed
+	}
+	old := c.reader
+	c.reader = reader
+	c.mu.Unlock()
+
+	if old != nil {
+		// Старый reader уже не работает, ошибка его закрытия ничего не меняет
+		_ = old.Close()
+	}
 	return nil
 }
 
-func (c *Consumer) WaitForConnect(ctx context.Context, maxAttempts int, backoff time.Duration) error {
-	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		if err := c.Connect(ctx); err == nil {
-			return nil
-		}
+var errConsumerClosed = errors.New("consumer closed")
 
-		if attempt == maxAttempts {
-			return fmt.Errorf("failed to connect after %d attempts", maxAttempts)
-		}
+// errStaleDelivery — сообщения получены reader, который заменен при переподключении.
+// Позицию новой группы они не сдвигают: после переподключения их получат заново.
+var errStaleDelivery = errors.New("deliveries were fetched before reconnect and will be redelivered")
 
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(backoff * time.Duration(attempt)):
-			// Exponential backoff
-		}
-	}
-	return nil
+// fetched — сообщение вместе с reader, который его получил: подтверждать сообщение
+// можно только в том же поколении группы.
+type fetched struct {
+	reader *kafka.Reader
+	msg    kafka.Message
 }
 
-func (c *Consumer) Consume(ctx context.Context) (<-chan *models.Notification, error) {
-	if c.reader == nil {
+// WaitForConnect повторяет Connect с экспоненциальной задержкой от backoff
+// до kafka.max_retry_backoff со случайным разбросом.
+func (c *Consumer) WaitForConnect(ctx context.Context, maxAttempts int, backoff time.Duration) error {
+	return retry(ctx, maxAttempts, newBackoff(backoff, c.cfg.MaxRetryBackoff), c.Connect)
+}
+
+// Consume читает сообщения до отмены ctx. Позиция группы сдвигается только через
+// Commit. Если чтение прерывается, например из-за потери соединения с брокером,
+// consumer переподключается с растущей задержкой. На сообщении, которое сервис
+// не умеет читать, чтение останавливается до Close или отмены ctx (см. skip).
+func (c *Consumer) Consume(ctx context.Context) (<-chan mq.Delivery, error) {
+	if c.currentReader() == nil {
 		return nil, fmt.Errorf("consumer not connected")
 	}
 
-	notifications := make(chan *models.Notification)
+	deliveries := make(chan mq.Delivery)
 
 	go func() {
-		defer close(notifications)
+		defer close(deliveries)
 
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-				msg, err := c.reader.ReadMessage(ctx)
-				if err != nil {
-					continue
+		b := newBackoff(c.cfg.RetryBackoff, c.cfg.MaxRetryBackoff)
+		for ctx.Err() == nil {
+			reader := c.currentReader()
+			msg, err := reader.FetchMessage(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
 				}
+				// После Close чтение не возобновляется
+				if err := c.reconnect(ctx, b); err != nil {
+					return
+				}
+				continue
+			}
+			b.reset()
+			c.observe(reader, msg)
 
-				var notification models.Notification
-				if err := json.Unmarshal(msg.Value, &notification); err != nil {
+			delivery, err := c.receive(ctx, reader, msg)
+			if err != nil {
+				c.metrics.IncKafkaMessageConsumed(c.topic, metrics.StatusError)
+				if c.skip(msg, err) {
 					continue
 				}
+				c.halt(ctx, fmt.Errorf("consuming %s stopped at partition %d, offset %d: %w",
+					c.topic, msg.Partition, msg.Offset, err))
+				return
+			}
+			c.metrics.IncKafkaMessageConsumed(c.topic, metrics.StatusSuccess)
 
-				notifications <- &notification
+			select {
+			case deliveries <- delivery:
+			case <-ctx.Done():
+				return
 			}
 		}
 	}()
 
-	return notifications, nil
+	return deliveries, nil
+}
+
+// Commit сохраняет позицию группы после сообщений deliveries. Позиция в разделе
+// общая для всех предшествующих сообщений, поэтому подтверждать нужно только
+// полностью обработанный префикс полученных сообщений. Сообщения, полученные до
+// переподключения, не подтверждаются: Commit возвращает errStaleDelivery.
+func (c *Consumer) Commit(ctx context.Context, deliveries ...mq.Delivery) error {
+	reader := c.currentReader()
+	if reader == nil {
+		return fmt.Errorf("consumer not connected")
+	}
+
+	msgs := make([]kafka.Message, 0, len(deliveries))
+	stale := 0
+	for _, d := range deliveries {
+		f, ok := d.Raw.(fetched)
+		if !ok {
+			return fmt.Errorf("delivery was not received from kafka: %T", d.Raw)
+		}
+		if f.reader != reader {
+			stale++
+			continue
+		}
+		msgs = append(msgs, f.msg)
+	}
+
+	if len(msgs) > 0 {
+		if err := reader.CommitMessages(ctx, msgs...); err != nil {
+			return err
+		}
+	}
+	if stale > 0 {
+		return fmt.Errorf("%d of %d: %w", stale, len(deliveries), errStaleDelivery)
+	}
+	return nil
+}
+
+// skip решает, можно ли пропустить сообщение, которое не удалось декодировать.
+// Поврежденное сообщение не прочитать и после обновления, поэтому оно пропускается,
+// и следующее подтверждение сдвинет позицию за него. Сообщение новой версии схемы
+// или неизвестного формата прочитает обновленный сервис: пропускать его нельзя.
+func (c *Consumer) skip(msg kafka.Message, err error) bool {
+	if errors.Is(err, codec.ErrUnsupportedVersion) || errors.Is(err, codec.ErrUnsupportedContentType) {
+		c.logger.Errorf("Stopped consuming %s at partition %d, offset %d: %v; "+
+			"the message and all later ones will be read after the service is updated",
+			c.topic, msg.Partition, msg.Offset, err)
+		return false
+	}
+	c.logger.Errorf("Skipping malformed message %s at partition %d, offset %d: %v",
+		c.topic, msg.Partition, msg.Offset, err)
+	return true
+}
+
+// halt ждет отмены ctx или закрытия consumer. Канал Consume остается открытым,
+// чтобы уже полученные сообщения были сохранены и подтверждены, а позиция группы
+// не сдвинулась за сообщение, на котором чтение остановлено. Пока чтение стоит,
+// Ping возвращает err, и сервис не проходит проверку готовности.
+func (c *Consumer) halt(ctx context.Context, err error) {
+	c.mu.Lock()
+	c.halted = err
+	c.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+	case <-c.done:
+	}
+}
+
+// reconnect пересоздает reader, пока это не удастся. Возвращает ошибку, если ctx
+// отменен или consumer закрыт.
+func (c *Consumer) reconnect(ctx context.Context, b *backoff) error {
+	for {
+		if c.isClosed() {
+			return errConsumerClosed
+		}
+		if err := b.wait(ctx); err != nil {
+			return err
+		}
+		c.metrics.IncKafkaReconnect(c.topic)
+		err := c.Connect(ctx)
+		if err == nil || errors.Is(err, errConsumerClosed) {
+			return err
+		}
+	}
+}
+
+func (c *Consumer) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+func (c *Consumer) currentReader() *kafka.Reader {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.reader
+}
+
+// observe записывает отставание потребителя и время доставки сообщения.
+func (c *Consumer) observe(reader *kafka.Reader, msg kafka.Message) {
+	c.metrics.SetKafkaConsumerLag(c.topic, reader.Stats().Lag)
+	if !msg.Time.IsZero() {
+		c.metrics.ObserveKafkaMessageLatency(c.topic, time.Since(msg.Time).Seconds())
+	}
+}
+
+// decode декодирует уведомление по конверту из заголовков сообщения.
+func decode(msg kafka.Message) (*models.Notification, error) {
+	env, err := envelopeFromMessage(msg)
+	if err != nil {
+		return nil, err
+	}
+	return codec.Decode(env)
+}
+
+// receive декодирует сообщение в спане, продолжающем трассу отправителя из заголовков.
+func (c *Consumer) receive(ctx context.Context, reader *kafka.Reader, msg kafka.Message) (mq.Delivery, error) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, headerCarrier{headers: &msg.Headers})
+	ctx, span := tracing.Start(ctx, c.topic+" receive",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypeReceive,
+			semconv.MessagingDestinationName(c.topic),
+			semconv.MessagingKafkaMessageKey(string(msg.Key)),
+		),
+	)
+
+	notification, err := decode(msg)
+	tracing.End(span, err)
+	if err != nil {
+		return mq.Delivery{}, err
+	}
+
+	return mq.Delivery{Ctx: ctx, Notification: notification, Raw: fetched{reader: reader, msg: msg}}, nil
 }
 
 func (c *Consumer) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed && c.done != nil {
+		close(c.done)
+	}
+	c.closed = true
 	if c.reader != nil {
 		return c.reader.Close()
 	}
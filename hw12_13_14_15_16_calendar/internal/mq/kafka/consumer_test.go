@@ -0,0 +1,129 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/config"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/logger"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/metrics"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/mq"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/mq/codec"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestReader(t *testing.T) *kafka.Reader {
+	t.Helper()
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{"127.0.0.1:1"},
+		Topic:   "notifications",
+		GroupID: "storer",
+	})
+	t.Cleanup(func() { reader.Close() })
+	return reader
+}
+
+func TestConsumer_CommitAfterReconnect(t *testing.T) {
+	old := newTestReader(t)
+	c := &Consumer{reader: old}
+
+	deliveries := []mq.Delivery{
+		{Raw: fetched{reader: old, msg: kafka.Message{Partition: 0, Offset: 1}}},
+		{Raw: fetched{reader: old, msg: kafka.Message{Partition: 0, Offset: 2}}},
+	}
+
+	// Переподключение заменило reader: сообщения старого поколения группы
+	// не подтверждаются ни через старый, ни через новый reader
+	c.reader = newTestReader(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	err := c.Commit(ctx, deliveries...)
+	assert.ErrorIs(t, err, errStaleDelivery)
+	assert.ErrorContains(t, err, "2 of 2")
+	assert.NoError(t, ctx.Err(), "stale deliveries must not reach the broker")
+
+	require.NoError(t, c.Commit(ctx))
+	assert.ErrorContains(t, c.Commit(ctx, mq.Delivery{Raw: "message"}), "delivery was not received from kafka")
+}
+
+func TestConsumer_StopsAfterClose(t *testing.T) {
+	cfg := config.Default().Kafka
+	cfg.Brokers = []string{"127.0.0.1:1"}
+	cfg.RetryBackoff = time.Millisecond
+	cfg.MaxRetryBackoff = time.Millisecond
+	c, err := NewConsumer(cfg, logger.Nop(), metrics.NewMetrics(prometheus.NewRegistry()))
+	require.NoError(t, err)
+	c.reader = newTestReader(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	deliveries, err := c.Consume(ctx)
+	require.NoError(t, err)
+	require.NoError(t, c.Close())
+
+	// Канал закрывается сразу, а не после отмены ctx
+	select {
+	case _, ok := <-deliveries:
+		assert.False(t, ok)
+	case <-ctx.Done():
+		t.Fatal("consumer kept reconnecting after Close")
+	}
+	assert.ErrorIs(t, c.reconnect(ctx, newBackoff(time.Millisecond, time.Millisecond)), errConsumerClosed)
+}
+
+func TestConsumer_Skip(t *testing.T) {
+	c := &Consumer{topic: "notifications", logger: logger.Nop()}
+
+	// Поврежденное сообщение не прочитать и после обновления
+	msg := kafka.Message{Value: []byte("{"), Offset: 7}
+	_, err := decode(msg)
+	require.Error(t, err)
+	assert.True(t, c.skip(msg, err))
+
+	// Сообщения более новой схемы и неизвестного формата дожидаются обновления сервиса
+	for _, headers := range [][]kafka.Header{
+		{{Key: headerContentType, Value: []byte("application/json")}, {Key: headerSchemaVersion, Value: []byte("99")}},
+		{{Key: headerContentType, Value: []byte("application/avro")}},
+	} {
+		msg := kafka.Message{Value: []byte("{}"), Headers: headers, Offset: 8}
+		_, err := decode(msg)
+		require.Error(t, err)
+		assert.False(t, c.skip(msg, err))
+	}
+}
+
+func TestConsumer_HaltUntilClose(t *testing.T) {
+	cfg := config.Default().Kafka
+	cfg.Brokers = []string{"127.0.0.1:1"}
+	c, err := NewConsumer(cfg, logger.Nop(), metrics.NewMetrics(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	halted := make(chan struct{})
+	go func() {
+		c.halt(context.Background(), codec.ErrUnsupportedVersion)
+		close(halted)
+	}()
+
+	select {
+	case <-halted:
+		t.Fatal("halt returned before Close")
+	case <-time.After(10 * time.Millisecond):
+	}
+	// Остановленный consumer не готов, даже если брокеры доступны
+	assert.Eventually(t, func() bool {
+		return errors.Is(c.Ping(context.Background()), codec.ErrUnsupportedVersion)
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, c.Close())
+	require.NoError(t, c.Close())
+	<-halted
+}
@@ -0,0 +1,44 @@
+package kafka
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/mq/codec"
+	"github.com/segmentio/kafka-go"
+)
+
+// Заголовки конверта сообщения
+const (
+	headerContentType   = "content-type"
+	headerSchemaVersion = "schema-version"
+)
+
+// envelopeHeaders возвращает заголовки, описывающие формат сообщения.
+func envelopeHeaders(env codec.Envelope) []kafka.Header {
+	return []kafka.Header{
+		{Key: headerContentType, Value: []byte(env.ContentType)},
+		{Key: headerSchemaVersion, Value: []byte(strconv.Itoa(env.SchemaVersion))},
+	}
+}
+
+// envelopeFromMessage восстанавливает конверт по заголовкам. Сообщения без
+// content-type отправлены до появления конверта и содержат JSON схемы 1.
+func envelopeFromMessage(msg kafka.Message) (codec.Envelope, error) {
+	headers := headerCarrier{headers: &msg.Headers}
+
+	contentType := headers.Get(headerContentType)
+	if contentType == "" {
+		return codec.Legacy(msg.Value), nil
+	}
+
+	version := 1
+	if v := headers.Get(headerSchemaVersion); v != "" {
+		var err error
+		if version, err = strconv.Atoi(v); err != nil {
+			return codec.Envelope{}, fmt.Errorf("invalid %s header %q", headerSchemaVersion, v)
+		}
+	}
+
+	return codec.Envelope{ContentType: contentType, SchemaVersion: version, Payload: msg.Value}, nil
+}
@@ -0,0 +1,56 @@
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/mq/codec"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeEnvelope(t *testing.T) {
+	notification := &models.Notification{
+		ID:       "n-1",
+		UserID:   "user-1",
+		Message:  "Standup",
+		NotifyAt: time.Date(2025, 3, 14, 9, 45, 0, 0, time.UTC),
+	}
+
+	for _, contentType := range []string{codec.ContentTypeJSON, codec.ContentTypeProtobuf} {
+		t.Run(contentType, func(t *testing.T) {
+			env, err := codec.Encode(notification, contentType)
+			require.NoError(t, err)
+
+			// Заголовки трассировки не мешают конверту
+			headers := append(envelopeHeaders(env), kafka.Header{Key: "traceparent", Value: []byte("00-...")})
+			got, err := decode(kafka.Message{Value: env.Payload, Headers: headers})
+			require.NoError(t, err)
+			assert.Equal(t, notification, got)
+		})
+	}
+
+	t.Run("legacy message without headers", func(t *testing.T) {
+		got, err := decode(kafka.Message{Value: []byte(`{"id":"n-1","user_id":"user-1"}`)})
+		require.NoError(t, err)
+		assert.Equal(t, "n-1", got.ID)
+	})
+
+	t.Run("newer schema", func(t *testing.T) {
+		_, err := decode(kafka.Message{Value: []byte(`{}`), Headers: []kafka.Header{
+			{Key: headerContentType, Value: []byte(codec.ContentTypeJSON)},
+			{Key: headerSchemaVersion, Value: []byte("99")},
+		}})
+		assert.ErrorIs(t, err, codec.ErrUnsupportedVersion)
+	})
+
+	t.Run("invalid version header", func(t *testing.T) {
+		_, err := decode(kafka.Message{Value: []byte(`{}`), Headers: []kafka.Header{
+			{Key: headerContentType, Value: []byte(codec.ContentTypeJSON)},
+			{Key: headerSchemaVersion, Value: []byte("v2")},
+		}})
+		assert.EqualError(t, err, `invalid schema-version header "v2"`)
+	})
+}
@@ -0,0 +1,135 @@
+package kafka
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/config"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/logger"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCertificate создает самоподписанный сертификат и ключ в формате PEM.
+func writeCertificate(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "kafka"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func TestNewDialerTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCertificate(t, dir)
+
+	t.Run("disabled", func(t *testing.T) {
+		dialer, err := newDialer(config.KafkaConfig{ClientID: "scheduler"})
+		require.NoError(t, err)
+		assert.Nil(t, dialer.TLS)
+		assert.Nil(t, dialer.SASLMechanism)
+		assert.Equal(t, "scheduler", dialer.ClientID)
+	})
+
+	t.Run("CA and client certificate", func(t *testing.T) {
+		dialer, err := newDialer(config.KafkaConfig{TLS: config.KafkaTLSConfig{
+			Enabled:    true,
+			CAFile:     certFile,
+			CertFile:   certFile,
+			KeyFile:    keyFile,
+			ServerName: "kafka.internal",
+		}})
+		require.NoError(t, err)
+		require.NotNil(t, dialer.TLS)
+		assert.NotNil(t, dialer.TLS.RootCAs)
+		assert.Len(t, dialer.TLS.Certificates, 1)
+		assert.Equal(t, "kafka.internal", dialer.TLS.ServerName)
+
+		transport := newTransport(dialer)
+		assert.Same(t, dialer.TLS, transport.TLS)
+	})
+
+	t.Run("invalid CA", func(t *testing.T) {
+		_, err := newDialer(config.KafkaConfig{TLS: config.KafkaTLSConfig{Enabled: true, CAFile: keyFile}})
+		assert.ErrorContains(t, err, "no certificates found")
+
+		_, err = newDialer(config.KafkaConfig{TLS: config.KafkaTLSConfig{Enabled: true, CAFile: filepath.Join(dir, "missing.pem")}})
+		assert.ErrorContains(t, err, "read CA file")
+	})
+}
+
+func TestNewDialerSASL(t *testing.T) {
+	dialer, err := newDialer(config.KafkaConfig{SASL: config.KafkaSASLConfig{
+		Mechanism: "plain", Username: "calendar", Password: "secret",
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, plain.Mechanism{Username: "calendar", Password: "secret"}, dialer.SASLMechanism)
+
+	for mechanism, name := range map[string]string{"scram-sha-256": "SCRAM-SHA-256", "scram-sha-512": "SCRAM-SHA-512"} {
+		dialer, err := newDialer(config.KafkaConfig{SASL: config.KafkaSASLConfig{
+			Mechanism: mechanism, Username: "calendar", Password: "secret",
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, name, dialer.SASLMechanism.Name())
+		assert.Equal(t, name, newTransport(dialer).SASL.Name())
+	}
+
+	_, err = newDialer(config.KafkaConfig{SASL: config.KafkaSASLConfig{Mechanism: "kerberos"}})
+	assert.ErrorContains(t, err, `unknown mechanism "kerberos"`)
+}
+
+func TestProducerAndConsumerOptions(t *testing.T) {
+	m := metrics.NewMetrics(prometheus.NewRegistry())
+
+	cfg := config.Default().Kafka
+	cfg.Compression = "zstd"
+	cfg.Acks = "all"
+	cfg.StartOffset = "latest"
+
+	producer, err := NewProducer(cfg, m)
+	require.NoError(t, err)
+	assert.Equal(t, kafka.Zstd, producer.compression)
+	assert.Equal(t, kafka.RequireAll, producer.acks)
+
+	consumer, err := NewConsumer(cfg, logger.Nop(), m)
+	require.NoError(t, err)
+	assert.Equal(t, kafka.LastOffset, consumer.startOffset)
+
+	cfg.Compression = "brotli"
+	_, err = NewProducer(cfg, m)
+	assert.ErrorContains(t, err, `unknown kafka compression "brotli"`)
+
+	cfg.StartOffset = "middle"
+	_, err = NewConsumer(cfg, logger.Nop(), m)
+	assert.ErrorContains(t, err, `unknown kafka start offset "middle"`)
+}
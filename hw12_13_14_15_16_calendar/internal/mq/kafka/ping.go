@@ -0,0 +1,62 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Ping проверяет, что хотя бы один из брокеров принимает соединения и отвечает
+// на запрос метаданных, а не только открывает TCP-порт.
+func (p *Producer) Ping(ctx context.Context) error {
+	return ping(ctx, p.dialer, p.cfg.Brokers)
+}
+
+// Ping проверяет доступность брокеров для проверок готовности. Если чтение
+// остановлено на сообщении неподдерживаемой схемы, Ping возвращает причину остановки.
+func (c *Consumer) Ping(ctx context.Context) error {
+	c.mu.Lock()
+	halted := c.halted
+	c.mu.Unlock()
+	if halted != nil {
+		return halted
+	}
+	return ping(ctx, c.dialer, c.cfg.Brokers)
+}
+
+func ping(ctx context.Context, dialer *kafka.Dialer, brokers []string) error {
+	conn, err := dialAny(ctx, dialer, brokers)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Brokers()
+	return err
+}
+
+// dialAny подключается к первому доступному брокеру из списка.
+func dialAny(ctx context.Context, dialer *kafka.Dialer, brokers []string) (*kafka.Conn, error) {
+	if len(brokers) == 0 {
+		return nil, errors.New("no brokers configured")
+	}
+
+	var errs []error
+	for _, broker := range brokers {
+		conn, err := dialer.DialContext(ctx, "tcp", broker)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			if err := conn.SetDeadline(deadline); err != nil {
+				conn.Close()
+				errs = append(errs, err)
+				continue
+			}
+		}
+		return conn, nil
+	}
+	return nil, errors.Join(errs...)
+}
@@ -2,84 +2,179 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/config"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/metrics"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/mq/codec"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/tracing"
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Producer struct {
-	writer  *kafka.Writer
-	topic   string
-	brokers []string
+	cfg         config.KafkaConfig
+	topic       string
+	dialer      *kafka.Dialer
+	compression kafka.Compression
+	acks        kafka.RequiredAcks
+	contentType string
+	metrics     *metrics.Metrics
+
+	// writer пересоздается при переподключении
+	mu     sync.RWMutex
+	writer *kafka.Writer
 }
 
-func NewProducer(brokers []string, topic string) *Producer {
-	return &Producer{
-		brokers: brokers,
-		topic:   topic,
+// NewProducer создает producer; ошибка означает неверные параметры TLS, SASL,
+// сжатия, подтверждений или кодирования. К брокерам producer подключается в Connect.
+func NewProducer(cfg config.KafkaConfig, metrics *metrics.Metrics) (*Producer, error) {
+	dialer, err := newDialer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	compression, err := compressionCodec(cfg.Compression)
+	if err != nil {
+		return nil, err
 	}
+	acks, err := requiredAcks(cfg.Acks)
+	if err != nil {
+		return nil, err
+	}
+	contentType, err := codec.ContentType(cfg.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Producer{
+		cfg:         cfg,
+		topic:       cfg.Topic,
+		dialer:      dialer,
+		compression: compression,
+		acks:        acks,
+		contentType: contentType,
+		metrics:     metrics,
+	}, nil
 }
 
+// Connect проверяет доступность брокеров и метаданные топика (создавая его при
+// необходимости) и только после этого создает writer.
 func (p *Producer) Connect(ctx context.Context) error {
-	p.writer = &kafka.Writer{
-		Addr:         kafka.TCP(p.brokers...),
+	if err := ensureTopic(ctx, p.dialer, p.cfg); err != nil {
+		return err
+	}
+
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(p.cfg.Brokers...),
 		Topic:        p.topic,
 		Balancer:     &kafka.LeastBytes{},
-		BatchTimeout: 10 * time.Millisecond,
-		RequiredAcks: kafka.RequireOne,
+		BatchSize:    p.cfg.BatchSize,
+		BatchTimeout: p.cfg.BatchTimeout,
+		RequiredAcks: p.acks,
+		Compression:  p.compression,
+		// Повторы с задержкой выполняет SendNotification, переподключаясь между ними
+		MaxAttempts: 1,
+		Transport:   newTransport(p.dialer),
+	}
+
+	p.mu.Lock()
+	old := p.writer
+	p.writer = writer
+	p.mu.Unlock()
+
+	if old != nil {
+		// Старый writer уже не работает, ошибка его закрытия ничего не меняет
+		_ = old.Close()
 	}
 	return nil
 }
 
+// WaitForConnect повторяет Connect с экспоненциальной задержкой от backoff
+// до kafka.max_retry_backoff со случайным разбросом.
 func (p *Producer) WaitForConnect(ctx context.Context, maxAttempts int, backoff time.Duration) error {
-	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		if err := p.Connect(ctx); err == nil {
-			return nil
-		}
+	return retry(ctx, maxAttempts, newBackoff(backoff, p.cfg.MaxRetryBackoff), p.Connect)
+}
 
-		if attempt == maxAttempts {
-			return fmt.Errorf("failed to connect after %d attempts", maxAttempts)
-		}
+func (p *Producer) SendNotification(ctx context.Context, notification *models.Notification) error {
+	if p.currentWriter() == nil {
+		return fmt.Errorf("producer not connected")
+	}
 
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(backoff * time.Duration(attempt)):
-			// Exponential backoff
-		}
+	ctx, span := tracing.Start(ctx, p.topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypePublish,
+			semconv.MessagingDestinationName(p.topic),
+			semconv.MessagingKafkaMessageKey(notification.UserID),
+		),
+	)
+
+	start := time.Now()
+	err := p.sendWithRetry(ctx, notification)
+	tracing.End(span, err)
+
+	p.metrics.ObserveKafkaProduceDuration(p.topic, time.Since(start).Seconds())
+	if err != nil {
+		p.metrics.IncKafkaMessageProduced(p.topic, metrics.StatusError)
+		return err
 	}
+	p.metrics.IncKafkaMessageProduced(p.topic, metrics.StatusSuccess)
 	return nil
 }
 
-func (p *Producer) SendNotification(ctx context.Context, notification *models.Notification) error {
-	if p.writer == nil {
-		return fmt.Errorf("producer not connected")
+// sendWithRetry отправляет сообщение до kafka.max_attempts раз. Если брокер недоступен,
+// перед следующей попыткой producer ждет и переподключается.
+func (p *Producer) sendWithRetry(ctx context.Context, notification *models.Notification) error {
+	env, err := codec.Encode(notification, p.contentType)
+	if err != nil {
+		return err
 	}
 
-	message, err := json.Marshal(notification)
-	if err != nil {
-		return fmt.Errorf("marshal notification: %w", err)
+	headers := envelopeHeaders(env)
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier{headers: &headers})
+
+	msg := kafka.Message{
+		Key:     []byte(notification.UserID),
+		Value:   env.Payload,
+		Headers: headers,
+		Time:    time.Now(),
 	}
 
-	err = p.writer.WriteMessages(ctx, kafka.Message{
-		Key:   []byte(notification.UserID),
-		Value: message,
-		Time:  time.Now(),
-	})
+	b := newBackoff(p.cfg.RetryBackoff, p.cfg.MaxRetryBackoff)
+	attempts := max(p.cfg.MaxAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		err = p.currentWriter().WriteMessages(ctx, msg)
+		if err == nil {
+			return nil
+		}
+		if attempt == attempts || !retriable(err) {
+			return fmt.Errorf("write message: %w", err)
+		}
 
-	if err != nil {
-		return fmt.Errorf("write message: %w", err)
+		if werr := b.wait(ctx); werr != nil {
+			return fmt.Errorf("write message: %w", err)
+		}
+		// Если переподключиться не удалось, следующая попытка пойдет через прежний writer
+		p.metrics.IncKafkaReconnect(p.topic)
+		_ = p.Connect(ctx)
 	}
+}
 
-	return nil
+func (p *Producer) currentWriter() *kafka.Writer {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.writer
 }
 
 func (p *Producer) Close() error {
-	if p.writer != nil {
-		return p.writer.Close()
+	if writer := p.currentWriter(); writer != nil {
+		return writer.Close()
 	}
 	return nil
 }
@@ -13,9 +13,21 @@ type Producer interface {
 	Close() error
 }
 
-// Consumer получает сообщения из очереди
+// Delivery — полученное из очереди уведомление. Ctx содержит контекст трассировки
+// отправителя, чтобы обработка продолжала его трассу.
+type Delivery struct {
+	Ctx          context.Context
+	Notification *models.Notification
+	// Raw — исходное сообщение очереди, по которому Consumer.Commit подтверждает обработку
+	Raw any
+}
+
+// Consumer получает сообщения из очереди. Полученные сообщения считаются
+// обработанными только после Commit: без него они будут получены повторно
+// после перезапуска.
 type Consumer interface {
-	Consume(ctx context.Context) (<-chan *models.Notification, error)
+	Consume(ctx context.Context) (<-chan Delivery, error)
+	Commit(ctx context.Context, deliveries ...Delivery) error
 	Close() error
 }
 
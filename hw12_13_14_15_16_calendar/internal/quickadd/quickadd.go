@@ -0,0 +1,516 @@
+package quickadd
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultDuration — длительность события, если в строке нет ни окончания, ни длительности.
+const DefaultDuration = time.Hour
+
+var (
+	ErrNoTime  = errors.New("event time not recognized")
+	ErrNoTitle = errors.New("event title not recognized")
+)
+
+// Result — событие, распознанное в строке быстрого добавления.
+type Result struct {
+	Title string
+	Start time.Time
+	End   time.Time
+	// NotifyBefore — напоминание; 0 — без напоминания
+	NotifyBefore time.Duration
+	Tags         []string
+}
+
+// Parse распознает событие в строке вроде "Lunch with Anna tomorrow 13:00 for 1h remind 15m"
+// или "Обед с Анной завтра в 13:00 на 1ч напомнить за 15 мин". Относительные даты и время
+// отсчитываются от now в его часовом поясе. Слова, не относящиеся к дате, времени,
+// длительности, напоминанию или тегам (#tag), составляют заголовок.
+//
+// Время без даты означает ближайшее будущее (сегодня или завтра), день недели —
+// ближайший такой день; "next monday" / "в следующий понедельник" — не сегодняшний.
+func Parse(text string, now time.Time) (*Result, error) {
+	p := &parser{now: now}
+	for _, field := range strings.Fields(text) {
+		text := strings.Trim(field, ",.;!?()")
+		if text == "" {
+			text = field
+		}
+		word := strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+		p.tokens = append(p.tokens, token{text: text, raw: field, word: word})
+	}
+
+	for i := 0; i < len(p.tokens); {
+		if n := p.match(i); n > 0 {
+			i += n
+			continue
+		}
+		p.title = append(p.title, p.tokens[i].raw)
+		i++
+	}
+	return p.result()
+}
+
+type token struct {
+	raw  string // как в исходной строке
+	text string // без знаков препинания по краям
+	word string // text в нижнем регистре
+}
+
+type parser struct {
+	tokens []token
+	now    time.Time
+
+	date       time.Time // полночь явно заданного дня
+	weekday    time.Weekday
+	hasWeekday bool
+	nextWeek   bool
+	clock      time.Duration // время начала от полуночи
+	hasClock   bool
+	endClock   time.Duration
+	hasEnd     bool
+	duration   time.Duration
+	remind     time.Duration
+	tags       []string
+	title      []string
+}
+
+// Служебные слова английского и русского языков
+var (
+	// prepositions входят в распознанный фрагмент вместе со следующей за ними датой или временем
+	prepositions  = wordSet("at", "on", "from", "в", "во", "с", "со", "на")
+	rangeWords    = wordSet("-", "–", "—", "to", "till", "until", "до", "по")
+	durationWords = wordSet("for", "на")
+	reminderWords = wordSet("remind", "reminder", "напомнить", "напомни", "напоминание")
+	// reminderFillers и reminderSuffixes допускаются вокруг длительности напоминания
+	reminderFillers  = wordSet("me", "за")
+	reminderSuffixes = wordSet("before", "earlier", "ahead", "заранее", "раньше", "до")
+	nextWords        = wordSet("next", "следующий", "следующую", "следующее", "следующая")
+)
+
+var relativeDays = map[string]int{
+	"today": 0, "tomorrow": 1,
+	"сегодня": 0, "завтра": 1, "послезавтра": 2,
+}
+
+var weekdays = map[string]time.Weekday{
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"понедельник": time.Monday, "вторник": time.Tuesday,
+	"среда": time.Wednesday, "среду": time.Wednesday, "четверг": time.Thursday,
+	"пятница": time.Friday, "пятницу": time.Friday,
+	"суббота": time.Saturday, "субботу": time.Saturday, "воскресенье": time.Sunday,
+}
+
+var months = map[string]time.Month{
+	"january": time.January, "jan": time.January,
+	"february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March,
+	"april": time.April, "apr": time.April,
+	"may":  time.May,
+	"june": time.June, "jun": time.June,
+	"july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October,
+	"november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+	"января": time.January, "февраля": time.February, "марта": time.March,
+	"апреля": time.April, "мая": time.May, "июня": time.June,
+	"июля": time.July, "августа": time.August, "сентября": time.September,
+	"октября": time.October, "ноября": time.November, "декабря": time.December,
+}
+
+// namedClocks — время, заданное словом
+var namedClocks = map[string]time.Duration{
+	"noon": 12 * time.Hour, "midnight": 0,
+	"полдень": 12 * time.Hour, "полночь": 0,
+}
+
+// dayPeriods — части суток после часа: "1 pm", "7 вечера"
+var dayPeriods = map[string]string{
+	"am": "am", "pm": "pm",
+	"утра": "am", "ночи": "am", "дня": "pm", "вечера": "pm",
+}
+
+var durationUnits = map[string]time.Duration{
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"м": time.Minute, "мин": time.Minute, "минута": time.Minute, "минуту": time.Minute,
+	"минуты": time.Minute, "минут": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"ч": time.Hour, "час": time.Hour, "часа": time.Hour, "часов": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"д": 24 * time.Hour, "день": 24 * time.Hour, "дня": 24 * time.Hour, "дней": 24 * time.Hour,
+}
+
+var (
+	clockPattern    = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+	numberPattern   = regexp.MustCompile(`^\d+(?:[.,]\d+)?$`)
+	compactPart     = regexp.MustCompile(`(\d+(?:[.,]\d+)?)([a-zа-я]+)`)
+	isoDatePattern  = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+	dotDatePattern  = regexp.MustCompile(`^(\d{1,2})\.(\d{1,2})(?:\.(\d{4}))?$`)
+	dayOfMonth      = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th|-?го|-?е)?$`)
+	yearPattern     = regexp.MustCompile(`^\d{4}$`)
+	rangeSeparators = regexp.MustCompile(`[-–—]`)
+)
+
+func wordSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[word] = true
+	}
+	return set
+}
+
+func (p *parser) word(i int) string {
+	if i < 0 || i >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[i].word
+}
+
+// match распознает фрагмент, начинающийся с i-го слова, и возвращает число его слов.
+// Каждое поле задается первым подходящим фрагментом, следующие остаются в заголовке.
+func (p *parser) match(i int) int {
+	word := p.word(i)
+
+	if strings.HasPrefix(word, "#") && len(word) > 1 {
+		p.tags = append(p.tags, p.tokens[i].text[1:])
+		return 1
+	}
+	if reminderWords[word] && p.remind == 0 {
+		if n := p.matchReminder(i + 1); n > 0 {
+			return n + 1
+		}
+	}
+	if durationWords[word] && p.duration == 0 && !p.hasEnd {
+		if d, n := p.parseDuration(i + 1); n > 0 {
+			p.duration = d
+			return n + 1
+		}
+	}
+
+	j, prep := i, false
+	if prepositions[word] {
+		j, prep = i+1, true
+	}
+	if !p.hasClock {
+		if n := p.matchClock(j, prep); n > 0 {
+			return j - i + n
+		}
+	}
+	if p.date.IsZero() && !p.hasWeekday {
+		if n := p.matchDate(j); n > 0 {
+			return j - i + n
+		}
+	}
+	return 0
+}
+
+// matchReminder распознает длительность напоминания: "remind me 15 minutes before",
+// "напомнить за 15 мин".
+func (p *parser) matchReminder(i int) int {
+	k := i
+	for reminderFillers[p.word(k)] {
+		k++
+	}
+	d, n := p.parseDuration(k)
+	if n == 0 {
+		return 0
+	}
+	k += n
+	if reminderSuffixes[p.word(k)] {
+		k++
+	}
+	p.remind = d
+	return k - i
+}
+
+// parseDuration распознает длительность из нескольких частей: "1h30m", "1 h 30 m",
+// "90 minutes", "полтора часа".
+func (p *parser) parseDuration(i int) (time.Duration, int) {
+	var total time.Duration
+	n := 0
+	for {
+		d, m := p.durationPart(i + n)
+		if m == 0 {
+			break
+		}
+		total += d
+		n += m
+	}
+	if total <= 0 {
+		return 0, 0
+	}
+	return total, n
+}
+
+func (p *parser) durationPart(i int) (time.Duration, int) {
+	word, next := p.word(i), p.word(i+1)
+	switch {
+	case word == "":
+		return 0, 0
+	case word == "полчаса":
+		return 30 * time.Minute, 1
+	case word == "полтора" && durationUnits[next] == time.Hour:
+		return 90 * time.Minute, 2
+	case word == "half" && next == "an" && durationUnits[p.word(i+2)] == time.Hour:
+		return 30 * time.Minute, 3
+	case (word == "a" || word == "an") && durationUnits[next] != 0:
+		return durationUnits[next], 2
+	case word == "час" || word == "hour" || word == "день" || word == "day":
+		return durationUnits[word], 1
+	case numberPattern.MatchString(word) && durationUnits[next] != 0:
+		return scale(word, durationUnits[next]), 2
+	}
+
+	// Слитная запись: "1h30m", "1ч", "15мин"
+	var total time.Duration
+	matched := 0
+	for _, part := range compactPart.FindAllStringSubmatch(word, -1) {
+		unit, ok := durationUnits[part[2]]
+		if !ok {
+			return 0, 0
+		}
+		total += scale(part[1], unit)
+		matched += len(part[0])
+	}
+	if matched == 0 || matched != len(word) {
+		return 0, 0
+	}
+	return total, 1
+}
+
+// scale умножает unit на число из слова; десятичный разделитель — точка или запятая.
+func scale(word string, unit time.Duration) time.Duration {
+	f, _ := strconv.ParseFloat(strings.ReplaceAll(word, ",", "."), 64)
+	return time.Duration(f * float64(unit))
+}
+
+// matchClock распознает время начала и, если указано, время окончания: "13:00",
+// "at 1pm", "в 7 вечера", "13:00-14:00", "с 13:00 до 14:30".
+func (p *parser) matchClock(i int, prep bool) int {
+	// Интервал одним словом
+	if parts := rangeSeparators.Split(p.word(i), -1); len(parts) == 2 {
+		start, ok := clockValue(parts[0], "", false)
+		end, endOK := clockValue(parts[1], "", false)
+		if ok && endOK {
+			p.clock, p.hasClock = start, true
+			p.endClock, p.hasEnd = end, true
+			return 1
+		}
+	}
+
+	start, n := p.parseClock(i, prep)
+	if n == 0 {
+		return 0
+	}
+	p.clock, p.hasClock = start, true
+
+	if k := i + n; rangeWords[p.word(k)] {
+		if end, m := p.parseClock(k+1, true); m > 0 {
+			p.endClock, p.hasEnd = end, true
+			n += m + 1
+		}
+	}
+	return n
+}
+
+// parseClock распознает время из одного или двух слов. Число без минут и части суток
+// считается временем только после предлога: "at 5", но не "Top 5".
+func (p *parser) parseClock(i int, bare bool) (time.Duration, int) {
+	word := p.word(i)
+	if clock, ok := namedClocks[word]; ok {
+		return clock, 1
+	}
+	if period := dayPeriods[p.word(i+1)]; period != "" {
+		if clock, ok := clockValue(word, period, true); ok {
+			return clock, 2
+		}
+	}
+	if clock, ok := clockValue(word, "", bare); ok {
+		return clock, 1
+	}
+	return 0, 0
+}
+
+// clockValue разбирает "13", "13:30", "1pm", "1:30pm"; period задает часть суток
+// отдельным словом.
+func clockValue(word, period string, bare bool) (time.Duration, bool) {
+	m := clockPattern.FindStringSubmatch(word)
+	if m == nil {
+		return 0, false
+	}
+	if m[3] != "" {
+		if period != "" {
+			return 0, false
+		}
+		period = m[3]
+	}
+	if m[2] == "" && period == "" && !bare {
+		return 0, false
+	}
+
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	switch period {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, false
+		}
+		hour %= 12
+		if period == "pm" {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0, false
+	}
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute, true
+}
+
+// matchDate распознает день: "tomorrow", "послезавтра", "friday", "next monday",
+// "в следующую среду", "2024-05-07", "07.05", "May 7", "7 мая".
+func (p *parser) matchDate(i int) int {
+	word, next := p.word(i), p.word(i+1)
+
+	if days, ok := relativeDays[word]; ok {
+		p.date = p.today().AddDate(0, 0, days)
+		return 1
+	}
+	if word == "day" && next == "after" && p.word(i+2) == "tomorrow" {
+		p.date = p.today().AddDate(0, 0, 2)
+		return 3
+	}
+	if weekday, ok := weekdays[next]; ok && nextWords[word] {
+		p.weekday, p.hasWeekday, p.nextWeek = weekday, true, true
+		return 2
+	}
+	if weekday, ok := weekdays[word]; ok {
+		p.weekday, p.hasWeekday = weekday, true
+		return 1
+	}
+
+	if m := isoDatePattern.FindStringSubmatch(word); m != nil {
+		return p.setDayMonth(atoi(m[1]), atoi(m[2]), atoi(m[3]), 1)
+	}
+	if m := dotDatePattern.FindStringSubmatch(word); m != nil {
+		return p.setDayMonth(atoi(m[3]), atoi(m[2]), atoi(m[1]), 1)
+	}
+
+	// "7 мая [2025]" и "May 7 [2025]"
+	var (
+		day   int
+		month time.Month
+	)
+	if m := dayOfMonth.FindStringSubmatch(word); m != nil && months[next] != 0 {
+		day, month = atoi(m[1]), months[next]
+	} else if m := dayOfMonth.FindStringSubmatch(next); m != nil && months[word] != 0 {
+		day, month = atoi(m[1]), months[word]
+	} else {
+		return 0
+	}
+	if yearPattern.MatchString(p.word(i + 2)) {
+		return p.setDayMonth(atoi(p.word(i+2)), int(month), day, 3)
+	}
+	return p.setDayMonth(0, int(month), day, 2)
+}
+
+// setDayMonth задает дату и возвращает n при ее корректности. Без года берется
+// ближайшая такая дата, начиная с сегодняшней.
+func (p *parser) setDayMonth(year, month, day, n int) int {
+	today := p.today()
+	explicitYear := year != 0
+	if !explicitYear {
+		year = today.Year()
+	}
+
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, today.Location())
+	if date.Day() != day || date.Month() != time.Month(month) {
+		return 0
+	}
+	if !explicitYear && date.Before(today) {
+		date = date.AddDate(1, 0, 0)
+	}
+	p.date = date
+	return n
+}
+
+func (p *parser) today() time.Time {
+	return time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.now.Location())
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// result собирает событие из распознанных фрагментов.
+func (p *parser) result() (*Result, error) {
+	if !p.hasClock {
+		return nil, ErrNoTime
+	}
+	title := strings.Trim(strings.Join(p.title, " "), " ,.;:-–—")
+	if title == "" {
+		return nil, ErrNoTitle
+	}
+
+	day := p.today()
+	switch {
+	case !p.date.IsZero():
+		day = p.date
+	case p.hasWeekday:
+		ahead := (int(p.weekday) - int(day.Weekday()) + 7) % 7
+		if ahead == 0 && p.nextWeek {
+			ahead = 7
+		}
+		day = day.AddDate(0, 0, ahead)
+	}
+
+	start := at(day, p.clock)
+	// Без явной даты прошедшее время означает ближайший такой же день в будущем
+	if p.date.IsZero() && !start.After(p.now) {
+		if p.hasWeekday {
+			day = day.AddDate(0, 0, 7)
+		} else {
+			day = day.AddDate(0, 0, 1)
+		}
+		start = at(day, p.clock)
+	}
+
+	end := start.Add(DefaultDuration)
+	switch {
+	case p.hasEnd:
+		end = at(day, p.endClock)
+		if !end.After(start) {
+			end = at(day.AddDate(0, 0, 1), p.endClock)
+		}
+	case p.duration > 0:
+		end = start.Add(p.duration)
+	}
+
+	return &Result{
+		Title:        title,
+		Start:        start,
+		End:          end,
+		NotifyBefore: p.remind,
+		Tags:         p.tags,
+	}, nil
+}
+
+func at(day time.Time, clock time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(),
+		int(clock/time.Hour), int(clock%time.Hour/time.Minute), 0, 0, day.Location())
+}
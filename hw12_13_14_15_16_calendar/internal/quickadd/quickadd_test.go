@@ -0,0 +1,117 @@
+package quickadd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	// Понедельник, 10:00 по Москве
+	loc := time.FixedZone("MSK", 3*60*60)
+	now := time.Date(2024, time.May, 6, 10, 0, 0, 0, loc)
+	day := func(d, hour, minute int) time.Time {
+		return time.Date(2024, time.May, d, hour, minute, 0, 0, loc)
+	}
+
+	tests := []struct {
+		text   string
+		title  string
+		start  time.Time
+		end    time.Time
+		remind time.Duration
+		tags   []string
+	}{
+		{
+			text:  "Lunch with Anna tomorrow 13:00 for 1h remind 15m",
+			title: "Lunch with Anna", start: day(7, 13, 0), end: day(7, 14, 0), remind: 15 * time.Minute,
+		},
+		{
+			text:  "Обед с Анной завтра в 13:00 на полтора часа напомнить за 15 мин #личное",
+			title: "Обед с Анной", start: day(7, 13, 0), end: day(7, 14, 30), remind: 15 * time.Minute,
+			tags: []string{"личное"},
+		},
+		{
+			text:  "Retro on friday 3pm-4:30pm #team",
+			title: "Retro", start: day(10, 15, 0), end: day(10, 16, 30), tags: []string{"team"},
+		},
+		{
+			text:  "Созвон в пятницу с 15:00 до 16:30",
+			title: "Созвон", start: day(10, 15, 0), end: day(10, 16, 30),
+		},
+		{
+			text:  "Dentist May 20 at 9am, remind me 1 day before",
+			title: "Dentist", start: day(20, 9, 0), end: day(20, 10, 0), remind: 24 * time.Hour,
+		},
+		{
+			text:  "Стоматолог 20 мая в 9 утра",
+			title: "Стоматолог", start: day(20, 9, 0), end: day(20, 10, 0),
+		},
+		{
+			text:  "Standup 9:30 for 15 minutes",
+			title: "Standup", start: day(7, 9, 30), end: day(7, 9, 45),
+		},
+		{
+			text:  "Planning monday 11:00",
+			title: "Planning", start: day(6, 11, 0), end: day(6, 12, 0),
+		},
+		{
+			text:  "Planning monday 9:00",
+			title: "Planning", start: day(13, 9, 0), end: day(13, 10, 0),
+		},
+		{
+			text:  "Планирование в следующий понедельник в 11",
+			title: "Планирование", start: day(13, 11, 0), end: day(13, 12, 0),
+		},
+		{
+			text:  "Top 5 ideas at 5pm",
+			title: "Top 5 ideas", start: day(6, 17, 0), end: day(6, 18, 0),
+		},
+		{
+			text:  "Release 23:00-01:00 07.05",
+			title: "Release", start: day(7, 23, 0), end: day(8, 1, 0),
+		},
+		{
+			text:  "Call 2024-06-01 10:00 for 1h30m",
+			title: "Call", start: time.Date(2024, time.June, 1, 10, 0, 0, 0, loc),
+			end: time.Date(2024, time.June, 1, 11, 30, 0, 0, loc),
+		},
+		{
+			text:  "New year party 31 december 22:00 for 4 hours",
+			title: "New year party", start: time.Date(2024, time.December, 31, 22, 0, 0, 0, loc),
+			end: time.Date(2025, time.January, 1, 2, 0, 0, 0, loc),
+		},
+		{
+			text:  "Встреча 1 мая в 7 вечера",
+			title: "Встреча", start: time.Date(2025, time.May, 1, 19, 0, 0, 0, loc),
+			end: time.Date(2025, time.May, 1, 20, 0, 0, 0, loc),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			result, err := Parse(tt.text, now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.title, result.Title)
+			assert.Equal(t, tt.start, result.Start)
+			assert.Equal(t, tt.end, result.End)
+			assert.Equal(t, tt.remind, result.NotifyBefore)
+			assert.Equal(t, tt.tags, result.Tags)
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	now := time.Date(2024, time.May, 6, 10, 0, 0, 0, time.UTC)
+
+	_, err := Parse("Lunch tomorrow", now)
+	assert.ErrorIs(t, err, ErrNoTime)
+
+	_, err = Parse("завтра в 13:00 на 1ч", now)
+	assert.ErrorIs(t, err, ErrNoTitle)
+
+	_, err = Parse("", now)
+	assert.ErrorIs(t, err, ErrNoTime)
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/archive"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+)
+
+// errArchiveDisabled — маршруты архива не включены в конфигурации
+var errArchiveDisabled = errors.New("archive endpoints are disabled, see server.archive.enabled")
+
+// ExportArchive выгружает резервную копию данных календаря (GET /archive)
+func (s *Server) ExportArchive(w http.ResponseWriter, r *http.Request) {
+	if !s.archive.Enabled {
+		s.sendError(w, http.StatusNotFound, "Archive is disabled", errArchiveDisabled)
+		return
+	}
+
+	snapshot, err := s.app.ExportSnapshot(r.Context())
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to export archive", err)
+		return
+	}
+
+	// Выгрузка большого хранилища дольше WriteTimeout сервера: снимаем предел записи,
+	// чтобы архив не обрывался. Ошибку игнорируем — без поддержки дедлайнов предела нет
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	now := time.Now().UTC()
+	filename := "calendar-" + now.Format("20060102-150405") + ".jsonl"
+	w.Header().Set("Content-Type", archive.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.WriteHeader(http.StatusOK)
+
+	// Статус уже отправлен; при ошибке записи архив останется без итоговой записи,
+	// и archive.Read отклонит его как обрезанный
+	_ = archive.Write(w, &archive.Archive{CreatedAt: now, Snapshot: *snapshot})
+}
+
+// RestoreArchive восстанавливает резервную копию в пустое хранилище (POST /archive)
+func (s *Server) RestoreArchive(w http.ResponseWriter, r *http.Request) {
+	if !s.archive.Enabled {
+		s.sendError(w, http.StatusNotFound, "Archive is disabled", errArchiveDisabled)
+		return
+	}
+
+	// Загрузка архива размером до MaxSize дольше ReadTimeout сервера
+	_ = http.NewResponseController(w).SetReadDeadline(time.Time{})
+	if s.archive.MaxSize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, s.archive.MaxSize)
+	}
+	backup, err := archive.Read(r.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			s.sendError(w, http.StatusRequestEntityTooLarge, "Archive is too large", err)
+			return
+		}
+		s.sendError(w, http.StatusBadRequest, "Invalid archive", err)
+		return
+	}
+
+	if err := s.app.ImportSnapshot(r.Context(), &backup.Snapshot); err != nil {
+		if errors.Is(err, models.ErrStorageNotEmpty) {
+			s.sendError(w, http.StatusConflict, "Storage is not empty", err)
+			return
+		}
+		s.sendError(w, http.StatusInternalServerError, "Failed to restore archive", err)
+		return
+	}
+
+	// Уведомления хранятся отдельно от календаря, сервер их не восстанавливает
+	restored := backup.Counts()
+	delete(restored, archive.TypeNotification)
+	s.sendJSON(w, http.StatusOK, ArchiveRestoreResult{
+		Restored: restored,
+		Skipped:  map[string]int{archive.TypeNotification: len(backup.Notifications)},
+	})
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/blobstore"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+)
+
+// multipartOverhead — запас на заголовки частей и прочие поля multipart-запроса сверх размера файла
+const multipartOverhead = 1 << 20
+
+// ListAttachments возвращает вложения события
+// (GET /events/{id}/attachments)
+func (s *Server) ListAttachments(w http.ResponseWriter, r *http.Request, id string) {
+	attachments, err := s.app.ListAttachments(r.Context(), id)
+	if err != nil {
+		s.sendAttachmentError(w, "Failed to list attachments", err)
+		return
+	}
+
+	apiAttachments := make([]Attachment, len(attachments))
+	for i, attachment := range attachments {
+		apiAttachments[i] = convertToAPIAttachment(attachment)
+	}
+
+	s.sendJSON(w, http.StatusOK, apiAttachments)
+}
+
+// UploadAttachment загружает файл или добавляет ссылку к событию
+// (POST /events/{id}/attachments)
+func (s *Server) UploadAttachment(w http.ResponseWriter, r *http.Request, id string) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		s.sendError(w, http.StatusUnsupportedMediaType, "Unsupported content type", err)
+		return
+	}
+
+	var attachment *models.Attachment
+	switch mediaType {
+	case "application/json":
+		var req AttachmentLink
+		if err := s.decodeJSON(w, r, &req); err != nil {
+			s.sendBodyError(w, err)
+			return
+		}
+
+		var name string
+		if req.Name != nil {
+			name = *req.Name
+		}
+		attachment, err = s.app.AddAttachmentLink(r.Context(), id, name, req.Url)
+	case "multipart/form-data":
+		r.Body = http.MaxBytesReader(w, r.Body, s.app.MaxAttachmentSize()+multipartOverhead)
+		attachment, err = s.uploadFile(r, id)
+	default:
+		s.sendError(w, http.StatusUnsupportedMediaType, "Unsupported content type",
+			errors.New("expected multipart/form-data or application/json"))
+		return
+	}
+
+	if err != nil {
+		s.sendAttachmentError(w, "Failed to add attachment", err)
+		return
+	}
+
+	s.sendJSON(w, http.StatusCreated, convertToAPIAttachment(attachment))
+}
+
+// uploadFile сохраняет содержимое части file, не загружая его целиком в память
+func (s *Server) uploadFile(r *http.Request, eventID string) (*models.Attachment, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, errors.Join(models.ErrInvalidAttachment, err)
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.Join(models.ErrInvalidAttachment, errors.New("file part is required"))
+		}
+		if err != nil {
+			return nil, errors.Join(models.ErrInvalidAttachment, err)
+		}
+
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+
+		name := path.Base(part.FileName())
+		if name == "." || name == "/" {
+			name = "attachment"
+		}
+
+		defer part.Close()
+		return s.app.AddAttachment(r.Context(), eventID, name, part.Header.Get("Content-Type"), part)
+	}
+}
+
+// DownloadAttachment отдает содержимое вложения или перенаправляет на ссылку
+// (GET /events/{id}/attachments/{attachmentId})
+func (s *Server) DownloadAttachment(w http.ResponseWriter, r *http.Request, id string, attachmentId string) {
+	attachment, content, err := s.app.OpenAttachment(r.Context(), id, attachmentId)
+	if err != nil {
+		s.sendAttachmentError(w, "Failed to open attachment", err)
+		return
+	}
+
+	if content == nil {
+		http.Redirect(w, r, attachment.URL, http.StatusFound)
+		return
+	}
+	defer content.Close()
+
+	// Содержимое всегда отдается как файл для скачивания, чтобы браузер
+	// не исполнял загруженный пользователем HTML или скрипты
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	// Статус уже отправлен, ошибка записи означает лишь разрыв соединения клиентом
+	_, _ = io.Copy(w, content)
+}
+
+// DeleteAttachment удаляет вложение и его содержимое
+// (DELETE /events/{id}/attachments/{attachmentId})
+func (s *Server) DeleteAttachment(w http.ResponseWriter, r *http.Request, id string, attachmentId string) {
+	if err := s.app.DeleteAttachment(r.Context(), id, attachmentId); err != nil {
+		s.sendAttachmentError(w, "Failed to delete attachment", err)
+		return
+	}
+
+	success := true
+	message := "Attachment deleted"
+	s.sendJSON(w, http.StatusOK, SuccessResponse{Success: &success, Message: &message})
+}
+
+// sendAttachmentError отправляет ошибку операции с вложением с соответствующим статусом
+func (s *Server) sendAttachmentError(w http.ResponseWriter, message string, err error) {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.Is(err, models.ErrEventNotFound):
+		s.sendError(w, http.StatusNotFound, "Event not found", err)
+	case errors.Is(err, models.ErrAttachmentNotFound), errors.Is(err, blobstore.ErrNotFound):
+		s.sendError(w, http.StatusNotFound, "Attachment not found", err)
+	case errors.Is(err, models.ErrAttachmentTooLarge), errors.As(err, &maxBytesErr):
+		s.sendError(w, http.StatusRequestEntityTooLarge, "Attachment is too large", models.ErrAttachmentTooLarge)
+	case errors.Is(err, models.ErrUnsupportedContentType):
+		s.sendError(w, http.StatusUnsupportedMediaType, "Unsupported attachment type", err)
+	case errors.Is(err, models.ErrInvalidAttachment):
+		s.sendError(w, http.StatusBadRequest, "Invalid attachment", err)
+	case errors.Is(err, models.ErrAttachmentsDisabled):
+		s.sendError(w, http.StatusNotImplemented, "Attachments are disabled", err)
+	default:
+		s.sendError(w, http.StatusInternalServerError, message, err)
+	}
+}
+
+// convertToAPIAttachment преобразует метаданные вложения в API модель
+func convertToAPIAttachment(attachment *models.Attachment) Attachment {
+	apiAttachment := Attachment{
+		Id:        attachment.ID,
+		EventId:   attachment.EventID,
+		Name:      attachment.Name,
+		CreatedAt: attachment.CreatedAt,
+	}
+
+	if attachment.IsLink() {
+		link := attachment.URL
+		apiAttachment.Url = &link
+		return apiAttachment
+	}
+
+	contentType := attachment.ContentType
+	size := attachment.Size
+	checksum := attachment.Checksum
+	apiAttachment.ContentType = &contentType
+	apiAttachment.Size = &size
+	apiAttachment.Checksum = &checksum
+	return apiAttachment
+}
@@ -1,14 +1,24 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	jsonpatch "github.com/evanphx/json-patch/v5"
 	"github.com/google/uuid"
 
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/app"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/config"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/metrics"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
 )
@@ -17,26 +27,81 @@ import (
 type Server struct {
 	app     *app.App
 	metrics *metrics.Metrics
+	archive config.ArchiveConfig
+	// maxBatchOperations — максимальное количество операций в одном пакете
+	maxBatchOperations int
 }
 
+type Option func(*Server)
+
+// WithArchive включает маршруты /archive; без этой опции они отвечают 404
+func WithArchive(cfg config.ArchiveConfig) Option {
+	return func(s *Server) {
+		s.archive = cfg
+	}
+}
+
+// WithMaxBatchOperations задает максимальное количество операций в пакете
+// /events:batch; без этой опции и при n <= 0 действует defaultMaxBatchOperations
+func WithMaxBatchOperations(n int) Option {
+	return func(s *Server) {
+		if n > 0 {
+			s.maxBatchOperations = n
+		}
+	}
+}
+
+// defaultMaxBatchOperations совпадает с server.max_batch_operations по умолчанию
+const defaultMaxBatchOperations = 100
+
 // NewServer создает новый обработчик для gorilla/mux
-func NewServer(app *app.App, metrics *metrics.Metrics) *Server {
-	return &Server{
-		app:     app,
-		metrics: metrics,
+func NewServer(app *app.App, metrics *metrics.Metrics, opts ...Option) *Server {
+	s := &Server{
+		app:                app,
+		metrics:            metrics,
+		maxBatchOperations: defaultMaxBatchOperations,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
-// ListEvents возвращает список всех событий
+// ListEvents возвращает список всех событий с фильтрацией по тегам, категории и приоритету
 // (GET /events)
-func (s *Server) ListEvents(w http.ResponseWriter, r *http.Request) {
+func (s *Server) ListEvents(w http.ResponseWriter, r *http.Request, params ListEventsParams) {
 	ctx := r.Context()
 
-	// Для простоты возвращаем все события (без фильтрации по времени)
-	from := time.Now().AddDate(0, -1, 0) // события за последний месяц
-	to := time.Now().AddDate(0, 1, 0)    // и на месяц вперед
+	// По умолчанию события за последний месяц и на месяц вперед
+	from := time.Now().AddDate(0, -1, 0)
+	to := time.Now().AddDate(0, 1, 0)
+	if params.From != nil {
+		from = *params.From
+	}
+	if params.To != nil {
+		to = *params.To
+	}
+	if to.Before(from) {
+		s.sendError(w, http.StatusBadRequest, "Validation failed", fmt.Errorf("to must not be before from"))
+		return
+	}
+
+	var filter models.EventFilter
+	if params.Tag != nil {
+		filter.Tags = normalizeValues(*params.Tag)
+	}
+	if params.Category != nil {
+		filter.Category = *params.Category
+	}
+	if params.Priority != nil {
+		filter.Priority = models.Priority(*params.Priority)
+		if !filter.Priority.Valid() {
+			s.sendError(w, http.StatusBadRequest, "Validation failed", fmt.Errorf("unknown priority %q", *params.Priority))
+			return
+		}
+	}
 
-	events, err := s.app.ListEvents(ctx, from, to)
+	events, err := s.app.ListEvents(ctx, from, to, filter)
 	if err != nil {
 		s.sendError(w, http.StatusInternalServerError, "Failed to list events", err)
 		return
@@ -53,14 +118,19 @@ func (s *Server) ListEvents(w http.ResponseWriter, r *http.Request) {
 	s.sendJSON(w, http.StatusOK, apiEvents)
 }
 
-// CreateEvent создает новое событие
+// CreateEvent создает новое событие, с параметром template — из шаблона
 // (POST /events)
-func (s *Server) CreateEvent(w http.ResponseWriter, r *http.Request) {
+func (s *Server) CreateEvent(w http.ResponseWriter, r *http.Request, params CreateEventParams) {
 	ctx := r.Context()
 
 	var req CreateEventRequest
-	if err := s.decodeJSON(r, &req); err != nil {
-		s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
+	if params.Template != nil {
+		var ok bool
+		if req, ok = s.decodeTemplateEventRequest(w, r, *params.Template); !ok {
+			return
+		}
+	} else if err := s.decodeJSON(w, r, &req); err != nil {
+		s.sendBodyError(w, err)
 		return
 	}
 
@@ -71,31 +141,122 @@ func (s *Server) CreateEvent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Создаем событие используя сгенерированные типы
-	event := &models.Event{
-		ID:        uuid.New().String(),
-		Title:     req.Title,
-		StartTime: req.StartTime,
-		EndTime:   req.EndTime,
-		UserID:    req.UserId,
-		Reminder:  s.calculateReminder(req.StartTime, req.NotifyBefore),
-	}
-
-	// Обрабатываем опциональные поля (указатели)
-	if req.Description != nil {
-		event.Description = *req.Description
-	}
+	event := s.convertToModelEvent(uuid.New().String(), UpdateEventRequest(req))
 
 	if err := s.app.CreateEvent(ctx, event); err != nil {
-		s.sendError(w, http.StatusInternalServerError, "Failed to create event", err)
+		s.sendError(w, eventErrorStatus(err), "Failed to create event", err)
 		return
 	}
 
 	// Увеличиваем счетчик созданных событий
 	s.metrics.IncEventCreated()
 
+	w.Header().Set("ETag", eventETag(event.Version))
 	s.sendJSON(w, http.StatusCreated, s.convertToAPIEvent(event))
 }
 
+// BatchEvents выполняет пакет операций create/update/delete
+// (POST /events:batch)
+func (s *Server) BatchEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req BatchRequest
+	if err := s.decodeJSON(w, r, &req); err != nil {
+		s.sendBodyError(w, err)
+		return
+	}
+
+	if len(req.Operations) == 0 {
+		s.sendError(w, http.StatusBadRequest, "Validation failed", errors.New("batch must contain at least one operation"))
+		return
+	}
+	if len(req.Operations) > s.maxBatchOperations {
+		s.sendLimitError(w, http.StatusRequestEntityTooLarge, "Too many operations in batch",
+			fmt.Errorf("batch contains %d operations, limit is %d", len(req.Operations), s.maxBatchOperations),
+			s.maxBatchOperations)
+		return
+	}
+
+	atomic := true
+	if req.Mode != nil {
+		switch *req.Mode {
+		case BatchAtomic:
+		case BatchBestEffort:
+			atomic = false
+		default:
+			s.sendError(w, http.StatusBadRequest, "Validation failed", fmt.Errorf("unknown batch mode %q", *req.Mode))
+			return
+		}
+	}
+
+	// Невалидные операции не передаются в хранилище; в атомарном режиме
+	// они отменяют весь пакет еще до его выполнения
+	results := make([]BatchResult, len(req.Operations))
+	ops := make([]models.BatchOperation, 0, len(req.Operations))
+	indexes := make([]int, 0, len(req.Operations))
+	valid := true
+	for i, apiOp := range req.Operations {
+		results[i].Index = i
+		op, err := s.convertBatchOperation(apiOp)
+		if err != nil {
+			results[i].Status = http.StatusBadRequest
+			results[i].Error = stringPtr(err.Error())
+			valid = false
+			continue
+		}
+		ops = append(ops, op)
+		indexes = append(indexes, i)
+	}
+
+	if atomic && !valid {
+		for i := range results {
+			if results[i].Status == 0 {
+				results[i].Status = http.StatusFailedDependency
+				results[i].Error = stringPtr(models.ErrBatchAborted.Error())
+			}
+		}
+		s.sendJSON(w, http.StatusOK, BatchResponse{Committed: false, Results: results})
+		return
+	}
+
+	applied, err := s.app.ApplyBatch(ctx, ops, atomic)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to apply batch", err)
+		return
+	}
+
+	committed := true
+	for j, result := range applied {
+		i := indexes[j]
+		if result.Err != nil {
+			results[i].Status = eventErrorStatus(result.Err)
+			results[i].Error = stringPtr(result.Err.Error())
+			if atomic {
+				committed = false
+			}
+			continue
+		}
+
+		switch ops[j].Op {
+		case models.BatchOpCreate:
+			s.metrics.IncEventCreated()
+			results[i].Status = http.StatusCreated
+		case models.BatchOpUpdate:
+			s.metrics.IncEventUpdated()
+			results[i].Status = http.StatusOK
+		case models.BatchOpDelete:
+			s.metrics.IncEventDeleted()
+			results[i].Status = http.StatusOK
+		}
+		if result.After != nil {
+			event := s.convertToAPIEvent(result.After)
+			results[i].Event = &event
+		}
+	}
+
+	s.sendJSON(w, http.StatusOK, BatchResponse{Committed: committed, Results: results})
+}
+
 // GetEvent возвращает событие по ID
 // (GET /events/{id})
 func (s *Server) GetEvent(w http.ResponseWriter, r *http.Request, id string) {
@@ -112,12 +273,13 @@ func (s *Server) GetEvent(w http.ResponseWriter, r *http.Request, id string) {
 		return
 	}
 
+	w.Header().Set("ETag", eventETag(event.Version))
 	s.sendJSON(w, http.StatusOK, s.convertToAPIEvent(event))
 }
 
 // UpdateEvent обновляет существующее событие
 // (PUT /events/{id})
-func (s *Server) UpdateEvent(w http.ResponseWriter, r *http.Request, id string) {
+func (s *Server) UpdateEvent(w http.ResponseWriter, r *http.Request, id string, params UpdateEventParams) {
 	ctx := r.Context()
 
 	if id == "" {
@@ -126,8 +288,8 @@ func (s *Server) UpdateEvent(w http.ResponseWriter, r *http.Request, id string)
 	}
 
 	var req UpdateEventRequest
-	if err := s.decodeJSON(r, &req); err != nil {
-		s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
+	if err := s.decodeJSON(w, r, &req); err != nil {
+		s.sendBodyError(w, err)
 		return
 	}
 
@@ -138,41 +300,116 @@ func (s *Server) UpdateEvent(w http.ResponseWriter, r *http.Request, id string)
 	}
 
 	// Проверяем существование события
-	_, err := s.app.GetEvent(ctx, id)
+	existing, err := s.app.GetEvent(ctx, id)
 	if err != nil {
 		s.sendError(w, http.StatusNotFound, "Event not found", err)
 		return
 	}
 
-	// Обновляем событие
-	updatedEvent := &models.Event{
-		ID:        id,
-		Title:     req.Title,
-		StartTime: req.StartTime,
-		EndTime:   req.EndTime,
-		UserID:    req.UserId,
-		Reminder:  s.calculateReminder(req.StartTime, req.NotifyBefore),
+	if params.IfMatch != nil && !matchesETag(*params.IfMatch, existing.Version) {
+		s.sendError(w, http.StatusPreconditionFailed, "Event has been modified", models.ErrVersionConflict)
+		return
 	}
 
-	// Обрабатываем опциональные поля (указатели)
-	if req.Description != nil {
-		updatedEvent.Description = *req.Description
+	// Обновляем событие
+	updatedEvent := s.convertToModelEvent(id, req)
+
+	// При условном запросе хранилище повторно сверит версию атомарно
+	if params.IfMatch != nil {
+		updatedEvent.Version = existing.Version
 	}
 
 	if err := s.app.UpdateEvent(ctx, updatedEvent); err != nil {
-		s.sendError(w, http.StatusInternalServerError, "Failed to update event", err)
+		if errors.Is(err, models.ErrVersionConflict) {
+			s.sendError(w, http.StatusPreconditionFailed, "Event has been modified", err)
+			return
+		}
+		s.sendError(w, eventErrorStatus(err), "Failed to update event", err)
 		return
 	}
 
 	// Увеличиваем счетчик обновленных событий
 	s.metrics.IncEventUpdated()
 
+	w.Header().Set("ETag", eventETag(updatedEvent.Version))
 	s.sendJSON(w, http.StatusOK, s.convertToAPIEvent(updatedEvent))
 }
 
+// PatchEvent частично обновляет событие
+// (PATCH /events/{id})
+func (s *Server) PatchEvent(w http.ResponseWriter, r *http.Request, id string, params PatchEventParams) {
+	ctx := r.Context()
+
+	if id == "" {
+		s.sendError(w, http.StatusBadRequest, "Event ID is required", errors.New("empty event id"))
+		return
+	}
+
+	existing, err := s.app.GetEvent(ctx, id)
+	if err != nil {
+		s.sendError(w, http.StatusNotFound, "Event not found", err)
+		return
+	}
+
+	if params.IfMatch != nil && !matchesETag(*params.IfMatch, existing.Version) {
+		s.sendError(w, http.StatusPreconditionFailed, "Event has been modified", models.ErrVersionConflict)
+		return
+	}
+
+	patch, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxJSONBodySize))
+	if err != nil {
+		s.sendBodyError(w, err)
+		return
+	}
+
+	req, err := s.applyPatch(r.Header.Get("Content-Type"), s.convertToUpdateRequest(existing), patch)
+	if errors.Is(err, errUnsupportedPatch) {
+		s.sendError(w, http.StatusUnsupportedMediaType, "Unsupported patch format", err)
+		return
+	}
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, "Invalid patch", err)
+		return
+	}
+
+	// Результат патча проверяется по тем же правилам, что и PUT
+	if err := s.validateUpdateEventRequest(req); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	patchedEvent := s.convertToModelEvent(id, req)
+
+	fields := models.ChangedFields(existing, patchedEvent)
+	if len(fields) == 0 {
+		w.Header().Set("ETag", eventETag(existing.Version))
+		s.sendJSON(w, http.StatusOK, s.convertToAPIEvent(existing))
+		return
+	}
+
+	if params.IfMatch != nil {
+		patchedEvent.Version = existing.Version
+	}
+
+	if err := s.app.PatchEvent(ctx, patchedEvent, fields); err != nil {
+		if errors.Is(err, models.ErrVersionConflict) {
+			s.sendError(w, http.StatusPreconditionFailed, "Event has been modified", err)
+			return
+		}
+		s.sendError(w, eventErrorStatus(err), "Failed to update event", err)
+		return
+	}
+
+	// Увеличиваем счетчик обновленных событий
+	s.metrics.IncEventUpdated()
+
+	w.Header().Set("ETag", eventETag(patchedEvent.Version))
+	s.sendJSON(w, http.StatusOK, s.convertToAPIEvent(patchedEvent))
+}
+
 // DeleteEvent удаляет событие по ID
 // (DELETE /events/{id})
-func (s *Server) DeleteEvent(w http.ResponseWriter, r *http.Request, id string) {
+func (s *Server) DeleteEvent(w http.ResponseWriter, r *http.Request, id string, params DeleteEventParams) {
 	ctx := r.Context()
 
 	if id == "" {
@@ -181,14 +418,28 @@ func (s *Server) DeleteEvent(w http.ResponseWriter, r *http.Request, id string)
 	}
 
 	// Проверяем существование события
-	_, err := s.app.GetEvent(ctx, id)
+	existing, err := s.app.GetEvent(ctx, id)
 	if err != nil {
 		s.sendError(w, http.StatusNotFound, "Event not found", err)
 		return
 	}
 
-	if err := s.app.DeleteEvent(ctx, id); err != nil {
-		s.sendError(w, http.StatusInternalServerError, "Failed to delete event", err)
+	var version int64
+	if params.IfMatch != nil {
+		if !matchesETag(*params.IfMatch, existing.Version) {
+			s.sendError(w, http.StatusPreconditionFailed, "Event has been modified", models.ErrVersionConflict)
+			return
+		}
+		version = existing.Version
+	}
+
+	if err := s.app.DeleteEvent(ctx, id, version); err != nil {
+		if errors.Is(err, models.ErrVersionConflict) {
+			s.sendError(w, http.StatusPreconditionFailed, "Event has been modified", err)
+			return
+		}
+		// Событие могло быть удалено после проверки выше
+		s.sendError(w, eventErrorStatus(err), "Failed to delete event", err)
 		return
 	}
 
@@ -196,7 +447,7 @@ func (s *Server) DeleteEvent(w http.ResponseWriter, r *http.Request, id string)
 	s.metrics.IncEventDeleted()
 
 	success := true
-	message := "Event deleted successfully"
+	message := "Event moved to trash"
 
 	response := SuccessResponse{
 		Success: &success,
@@ -206,18 +457,93 @@ func (s *Server) DeleteEvent(w http.ResponseWriter, r *http.Request, id string)
 	s.sendJSON(w, http.StatusOK, response)
 }
 
+// RestoreEvent восстанавливает событие из корзины
+// (POST /events/{id}/restore)
+func (s *Server) RestoreEvent(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	if id == "" {
+		s.sendError(w, http.StatusBadRequest, "Event ID is required", errors.New("empty event id"))
+		return
+	}
+
+	event, err := s.app.RestoreEvent(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrEventNotFound):
+			s.sendError(w, http.StatusNotFound, "Event not found in trash", err)
+		case errors.Is(err, models.ErrDateBusy):
+			s.sendError(w, http.StatusConflict, "Event time slot is busy", err)
+		case errors.Is(err, models.ErrResourceBusy), errors.Is(err, models.ErrResourceNotFound):
+			s.sendError(w, http.StatusConflict, "Event resources are unavailable", err)
+		case errors.Is(err, models.ErrQuotaExceeded):
+			s.sendError(w, http.StatusForbidden, "Event quota exceeded", err)
+		default:
+			s.sendError(w, http.StatusInternalServerError, "Failed to restore event", err)
+		}
+		return
+	}
+
+	w.Header().Set("ETag", eventETag(event.Version))
+	s.sendJSON(w, http.StatusOK, s.convertToAPIEvent(event))
+}
+
+// ListTrash возвращает список событий в корзине
+// (GET /trash)
+func (s *Server) ListTrash(w http.ResponseWriter, r *http.Request) {
+	events, err := s.app.ListDeletedEvents(r.Context())
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to list trash", err)
+		return
+	}
+
+	apiEvents := make([]Event, len(events))
+	for i, event := range events {
+		apiEvents[i] = s.convertToAPIEvent(event)
+	}
+
+	s.sendJSON(w, http.StatusOK, apiEvents)
+}
+
+// defaultAuditLimit — количество записей журнала, если limit не указан
+const defaultAuditLimit = 100
+
+// ListAudit возвращает журнал изменений по событию и/или инициатору
+// (GET /audit)
+func (s *Server) ListAudit(w http.ResponseWriter, r *http.Request, params ListAuditParams) {
+	filter := models.AuditFilter{Limit: defaultAuditLimit}
+	if params.EventId != nil {
+		filter.EventID = *params.EventId
+	}
+	if params.Actor != nil {
+		filter.Actor = *params.Actor
+	}
+	if params.Limit != nil {
+		if *params.Limit < 1 || *params.Limit > 1000 {
+			s.sendError(w, http.StatusBadRequest, "Validation failed", errors.New("limit must be between 1 and 1000"))
+			return
+		}
+		filter.Limit = *params.Limit
+	}
+
+	entries, err := s.app.ListAudit(r.Context(), filter)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to list audit log", err)
+		return
+	}
+
+	apiEntries := make([]AuditEntry, len(entries))
+	for i, entry := range entries {
+		apiEntries[i] = s.convertToAPIAuditEntry(entry)
+	}
+
+	s.sendJSON(w, http.StatusOK, apiEntries)
+}
+
 // Вспомогательные функции
 
 // convertToAPIEvent преобразует внутреннюю модель события в API модель
 func (s *Server) convertToAPIEvent(event *models.Event) Event {
-	// Рассчитываем NotifyBefore на основе Reminder
-	var notifyBefore *int
-	if !event.Reminder.IsZero() {
-		duration := event.StartTime.Sub(event.Reminder)
-		notifyBeforeInt := int(duration.Seconds())
-		notifyBefore = &notifyBeforeInt
-	}
-
 	// Создаем API событие с указателями для опциональных полей
 	apiEvent := Event{
 		Id:           event.ID,
@@ -225,7 +551,8 @@ func (s *Server) convertToAPIEvent(event *models.Event) Event {
 		StartTime:    event.StartTime,
 		EndTime:      event.EndTime,
 		UserId:       event.UserID,
-		NotifyBefore: notifyBefore,
+		NotifyBefore: s.calculateNotifyBefore(event),
+		Version:      event.Version,
 	}
 
 	// Обрабатываем опциональные поля
@@ -233,10 +560,285 @@ func (s *Server) convertToAPIEvent(event *models.Event) Event {
 		desc := event.Description
 		apiEvent.Description = &desc
 	}
+	if !event.DeletedAt.IsZero() {
+		deletedAt := event.DeletedAt
+		apiEvent.DeletedAt = &deletedAt
+	}
+	apiEvent.Tags, apiEvent.Category, apiEvent.Color, apiEvent.Priority = s.convertToAPIAttributes(event)
+	apiEvent.ResourceIds = convertToAPIResourceIDs(event)
 
 	return apiEvent
 }
 
+// convertToAPIAttributes возвращает теги, категорию, цвет и приоритет события;
+// незаполненные значения не выводятся
+func (s *Server) convertToAPIAttributes(event *models.Event) (*[]string, *string, *string, *Priority) {
+	var tags *[]string
+	var category, color *string
+	var priority *Priority
+
+	if len(event.Tags) > 0 {
+		eventTags := append([]string(nil), event.Tags...)
+		tags = &eventTags
+	}
+	if event.Category != "" {
+		eventCategory := event.Category
+		category = &eventCategory
+	}
+	if event.Color != "" {
+		eventColor := event.Color
+		color = &eventColor
+	}
+	if event.Priority != "" {
+		eventPriority := Priority(event.Priority)
+		priority = &eventPriority
+	}
+
+	return tags, category, color, priority
+}
+
+// convertToAPIResourceIDs возвращает ресурсы события; пустой список не выводится
+func convertToAPIResourceIDs(event *models.Event) *[]string {
+	if len(event.ResourceIDs) == 0 {
+		return nil
+	}
+	resourceIDs := append([]string(nil), event.ResourceIDs...)
+	return &resourceIDs
+}
+
+// convertToAPIAuditEntry преобразует запись журнала в API модель
+func (s *Server) convertToAPIAuditEntry(entry *models.AuditEntry) AuditEntry {
+	return AuditEntry{
+		Id:        entry.ID,
+		EventId:   entry.EventID,
+		Actor:     entry.Actor,
+		Action:    AuditEntryAction(entry.Action),
+		Before:    jsonObject(entry.Before),
+		After:     jsonObject(entry.After),
+		Diff:      jsonObject(entry.Diff),
+		RequestId: entry.RequestID,
+		CreatedAt: entry.CreatedAt,
+	}
+}
+
+// jsonObject разбирает JSON объект для API ответа; пустой или некорректный JSON опускается
+func jsonObject(raw json.RawMessage) *map[string]interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(raw, &object); err != nil || object == nil {
+		return nil
+	}
+	return &object
+}
+
+// convertToUpdateRequest представляет событие в виде запроса на обновление,
+// к которому применяются патчи
+func (s *Server) convertToUpdateRequest(event *models.Event) UpdateEventRequest {
+	req := UpdateEventRequest{
+		Title:        event.Title,
+		StartTime:    event.StartTime,
+		EndTime:      event.EndTime,
+		UserId:       event.UserID,
+		NotifyBefore: s.calculateNotifyBefore(event),
+	}
+
+	if event.Description != "" {
+		desc := event.Description
+		req.Description = &desc
+	}
+	req.Tags, req.Category, req.Color, req.Priority = s.convertToAPIAttributes(event)
+	req.ResourceIds = convertToAPIResourceIDs(event)
+
+	return req
+}
+
+// errUnsupportedPatch возвращается для патчей с неизвестным Content-Type
+var errUnsupportedPatch = errors.New("unsupported patch content type")
+
+// applyPatch применяет JSON Merge Patch (RFC 7396) или JSON Patch (RFC 6902) к запросу
+func (s *Server) applyPatch(contentType string, target UpdateEventRequest, patch []byte) (UpdateEventRequest, error) {
+	var result UpdateEventRequest
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return result, fmt.Errorf("%w: %s", errUnsupportedPatch, contentType)
+	}
+
+	doc, err := json.Marshal(target)
+	if err != nil {
+		return result, err
+	}
+
+	switch mediaType {
+	case "application/merge-patch+json":
+		doc, err = jsonpatch.MergePatch(doc, patch)
+	case "application/json-patch+json":
+		var operations jsonpatch.Patch
+		operations, err = jsonpatch.DecodePatch(patch)
+		if err == nil {
+			doc, err = operations.Apply(doc)
+		}
+	default:
+		return result, fmt.Errorf("%w: %s", errUnsupportedPatch, mediaType)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&result)
+	return result, err
+}
+
+// convertBatchOperation проверяет операцию пакета по правилам одиночных запросов
+// и преобразует ее во внутреннюю модель
+func (s *Server) convertBatchOperation(op BatchOperation) (models.BatchOperation, error) {
+	var id string
+	if op.Id != nil {
+		id = *op.Id
+	}
+	var version int64
+	if op.Version != nil {
+		version = *op.Version
+	}
+
+	switch op.Op {
+	case BatchCreate:
+		if op.Event == nil {
+			return models.BatchOperation{}, errors.New("event is required for create")
+		}
+		if err := s.validateCreateEventRequest(CreateEventRequest(*op.Event)); err != nil {
+			return models.BatchOperation{}, err
+		}
+		return models.BatchOperation{Op: models.BatchOpCreate, Event: s.convertToModelEvent("", *op.Event)}, nil
+	case BatchUpdate:
+		if id == "" {
+			return models.BatchOperation{}, errors.New("id is required for update")
+		}
+		if op.Event == nil {
+			return models.BatchOperation{}, errors.New("event is required for update")
+		}
+		if err := s.validateUpdateEventRequest(*op.Event); err != nil {
+			return models.BatchOperation{}, err
+		}
+		event := s.convertToModelEvent(id, *op.Event)
+		event.Version = version
+		return models.BatchOperation{Op: models.BatchOpUpdate, Event: event}, nil
+	case BatchDelete:
+		if id == "" {
+			return models.BatchOperation{}, errors.New("id is required for delete")
+		}
+		return models.BatchOperation{Op: models.BatchOpDelete, ID: id, Version: version}, nil
+	default:
+		return models.BatchOperation{}, fmt.Errorf("unknown operation %q", op.Op)
+	}
+}
+
+// convertToModelEvent преобразует тело запроса во внутреннюю модель события
+func (s *Server) convertToModelEvent(id string, req UpdateEventRequest) *models.Event {
+	event := &models.Event{
+		ID:        id,
+		Title:     req.Title,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		UserID:    req.UserId,
+		Reminder:  s.calculateReminder(req.StartTime, req.NotifyBefore),
+	}
+	if req.Description != nil {
+		event.Description = *req.Description
+	}
+	if req.Tags != nil {
+		event.Tags = normalizeValues(*req.Tags)
+	}
+	if req.Category != nil {
+		event.Category = *req.Category
+	}
+	if req.Color != nil {
+		event.Color = strings.ToLower(*req.Color)
+	}
+	if req.Priority != nil {
+		event.Priority = models.Priority(*req.Priority)
+	}
+	if req.ResourceIds != nil {
+		event.ResourceIDs = normalizeValues(*req.ResourceIds)
+	}
+	return event
+}
+
+// normalizeValues убирает пробелы по краям и повторяющиеся значения (теги, ID ресурсов),
+// сохраняя порядок
+func normalizeValues(values []string) []string {
+	normalized := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		normalized = append(normalized, value)
+	}
+	return normalized
+}
+
+// eventErrorStatus возвращает HTTP-статус, соответствующий ошибке изменения события
+// или операции пакета
+func eventErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrEventNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrVersionConflict):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, models.ErrDateBusy), errors.Is(err, models.ErrResourceBusy):
+		return http.StatusConflict
+	case errors.Is(err, models.ErrInvalidEvent), errors.Is(err, models.ErrResourceNotFound):
+		return http.StatusBadRequest
+	case errors.Is(err, models.ErrQuotaExceeded):
+		return http.StatusForbidden
+	case errors.Is(err, models.ErrBatchAborted):
+		return http.StatusFailedDependency
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+// eventETag формирует значение заголовка ETag по версии события
+func eventETag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// matchesETag проверяет, соответствует ли заголовок If-Match текущей версии события.
+// Слабые ETag (W/"...") по RFC 7232 для If-Match не подходят.
+func matchesETag(ifMatch string, version int64) bool {
+	current := eventETag(version)
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == current {
+			return true
+		}
+	}
+	return false
+}
+
+// calculateNotifyBefore рассчитывает NotifyBefore на основе Reminder
+func (s *Server) calculateNotifyBefore(event *models.Event) *int {
+	if event.Reminder.IsZero() {
+		return nil
+	}
+
+	duration := event.StartTime.Sub(event.Reminder)
+	notifyBefore := int(duration.Seconds())
+	return &notifyBefore
+}
+
 // calculateReminder вычисляет время напоминания на основе времени начала и NotifyBefore
 func (s *Server) calculateReminder(startTime time.Time, notifyBefore *int) time.Time {
 	if notifyBefore == nil {
@@ -267,7 +869,10 @@ func (s *Server) validateCreateEventRequest(req CreateEventRequest) error {
 	if req.StartTime.Before(time.Now()) {
 		return errors.New("start_time must be in the future")
 	}
-	return nil
+	if err := validateResourceIDs(req.ResourceIds); err != nil {
+		return err
+	}
+	return validateEventAttributes(req.Tags, req.Category, req.Color, req.Priority)
 }
 
 // validateUpdateEventRequest валидирует запрос на обновление события
@@ -287,16 +892,87 @@ func (s *Server) validateUpdateEventRequest(req UpdateEventRequest) error {
 	if req.EndTime.Before(req.StartTime) {
 		return errors.New("end_time must be after start_time")
 	}
+	if err := validateResourceIDs(req.ResourceIds); err != nil {
+		return err
+	}
+	return validateEventAttributes(req.Tags, req.Category, req.Color, req.Priority)
+}
+
+// Ограничения на теги, категорию и ресурсы, совпадающие с описанием в openapi.yaml
+const (
+	maxEventTags      = 20
+	maxTagLength      = 50
+	maxCategoryLength = 100
+	maxEventResources = 10
+)
+
+// colorPattern — формат цвета события #RRGGBB
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// validateEventAttributes валидирует теги, категорию, цвет и приоритет события
+func validateEventAttributes(tags *[]string, category, color *string, priority *Priority) error {
+	if tags != nil {
+		if len(*tags) > maxEventTags {
+			return fmt.Errorf("at most %d tags are allowed", maxEventTags)
+		}
+		for _, tag := range *tags {
+			if strings.TrimSpace(tag) == "" {
+				return errors.New("tags must not be empty")
+			}
+			if utf8.RuneCountInString(tag) > maxTagLength {
+				return fmt.Errorf("tag %q is longer than %d characters", tag, maxTagLength)
+			}
+		}
+	}
+	if category != nil && utf8.RuneCountInString(*category) > maxCategoryLength {
+		return fmt.Errorf("category is longer than %d characters", maxCategoryLength)
+	}
+	if color != nil && !colorPattern.MatchString(*color) {
+		return errors.New("color must be in #RRGGBB format")
+	}
+	if priority != nil && (*priority == "" || !models.Priority(*priority).Valid()) {
+		return fmt.Errorf("unknown priority %q", *priority)
+	}
 	return nil
 }
 
-// decodeJSON декодирует JSON тело запроса
-func (s *Server) decodeJSON(r *http.Request, v interface{}) error {
-	decoder := json.NewDecoder(r.Body)
+// validateResourceIDs валидирует список ресурсов события
+func validateResourceIDs(resourceIDs *[]string) error {
+	if resourceIDs == nil {
+		return nil
+	}
+	if len(*resourceIDs) > maxEventResources {
+		return fmt.Errorf("at most %d resources are allowed", maxEventResources)
+	}
+	for _, resourceID := range *resourceIDs {
+		if strings.TrimSpace(resourceID) == "" {
+			return errors.New("resource_ids must not be empty")
+		}
+	}
+	return nil
+}
+
+// maxJSONBodySize — максимальный размер JSON тела запроса и JSON Patch
+const maxJSONBodySize = 1 << 20
+
+// decodeJSON декодирует JSON тело запроса не больше maxJSONBodySize
+func (s *Server) decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxJSONBodySize))
 	decoder.DisallowUnknownFields()
 	return decoder.Decode(v)
 }
 
+// sendBodyError отвечает на ошибку чтения тела запроса: 413, если тело превысило
+// предел, иначе 400
+func (s *Server) sendBodyError(w http.ResponseWriter, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		s.sendError(w, http.StatusRequestEntityTooLarge, "Request body is too large", err)
+		return
+	}
+	s.sendError(w, http.StatusBadRequest, "Invalid request body", err)
+}
+
 // sendJSON отправляет JSON ответ
 func (s *Server) sendJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
@@ -319,3 +995,15 @@ func (s *Server) sendError(w http.ResponseWriter, status int, message string, er
 	}
 	s.sendJSON(w, status, errorResponse)
 }
+
+// sendLimitError отправляет ошибку о превышении предела limit; клиент может разбить
+// запрос по значению limit из ответа
+func (s *Server) sendLimitError(w http.ResponseWriter, status int, message string, err error, limit int) {
+	errorMsg := err.Error()
+	s.sendJSON(w, status, ErrorResponse{
+		Error:   &errorMsg,
+		Message: &message,
+		Code:    &status,
+		Limit:   &limit,
+	})
+}
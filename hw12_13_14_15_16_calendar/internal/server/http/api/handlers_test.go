@@ -4,19 +4,32 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/textproto"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/app"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/blobstore"
+	localblob "github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/blobstore/local"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/config"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/logger"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/metrics"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/requestctx"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/storage"
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 )
 
+// Метрики регистрируются в глобальном registry, поэтому создаются один раз на пакет
+var testMetrics = metrics.NewMetrics(prometheus.NewRegistry())
+
 // Простой тест для проверки базовой функциональности
 func TestBasicFunctionality(t *testing.T) {
 	// Создаем mock storage
@@ -30,9 +43,6 @@ func TestBasicFunctionality(t *testing.T) {
 	// Создаем приложение
 	app := app.New(testLogger, mockStorage)
 
-	// Создаем метрики
-	testMetrics := metrics.NewMetrics()
-
 	// Создаем сервер
 	server := NewServer(app, testMetrics)
 
@@ -51,7 +61,7 @@ func TestBasicFunctionality(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	server.CreateEvent(w, req)
+	server.CreateEvent(w, req, CreateEventParams{})
 
 	resp := w.Result()
 	defer resp.Body.Close()
@@ -59,32 +69,1050 @@ func TestBasicFunctionality(t *testing.T) {
 	assert.Equal(t, http.StatusCreated, resp.StatusCode)
 }
 
+func TestConditionalUpdate(t *testing.T) {
+	mockStorage := &mockStorage{
+		events: make(map[string]*models.Event),
+	}
+	testLogger, _ := logger.NewLogger("info")
+	server := NewServer(app.New(testLogger, mockStorage), testMetrics)
+
+	event := &models.Event{
+		Title:     "Versioned Event",
+		StartTime: time.Now().Add(24 * time.Hour),
+		EndTime:   time.Now().Add(25 * time.Hour),
+		UserID:    "user123",
+	}
+	err := mockStorage.CreateEvent(context.Background(), event)
+	assert.NoError(t, err)
+
+	updateReq := UpdateEventRequest{
+		Title:     "Updated Event",
+		StartTime: event.StartTime,
+		EndTime:   event.EndTime,
+		UserId:    event.UserID,
+	}
+	body, _ := json.Marshal(updateReq)
+
+	t.Run("stale If-Match is rejected", func(t *testing.T) {
+		req := httptest.NewRequest("PUT", "/events/"+event.ID, bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+
+		server.UpdateEvent(w, req, event.ID, UpdateEventParams{IfMatch: stringPtr(`"42"`)})
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	t.Run("matching If-Match updates event and returns new ETag", func(t *testing.T) {
+		req := httptest.NewRequest("PUT", "/events/"+event.ID, bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+
+		server.UpdateEvent(w, req, event.ID, UpdateEventParams{IfMatch: stringPtr(`"1"`)})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	})
+
+	t.Run("stale If-Match on delete is rejected", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/events/"+event.ID, nil)
+		w := httptest.NewRecorder()
+
+		server.DeleteEvent(w, req, event.ID, DeleteEventParams{IfMatch: stringPtr(`"1"`)})
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+}
+
+// concurrentDeleteStorage удаляет событие непосредственно перед DeleteEvent,
+// как если бы его удалил параллельный запрос после проверки в обработчике.
+type concurrentDeleteStorage struct {
+	*mockStorage
+}
+
+func (m concurrentDeleteStorage) DeleteEvent(ctx context.Context, id string, version int64) error {
+	delete(m.events, id)
+	return m.mockStorage.DeleteEvent(ctx, id, version)
+}
+
+func TestDeleteEventConcurrentlyDeleted(t *testing.T) {
+	mockStorage := &mockStorage{
+		events: map[string]*models.Event{"e1": {ID: "e1", Title: "Sync", UserID: "user123", Version: 1}},
+	}
+	testLogger, _ := logger.NewLogger("info")
+	server := NewServer(app.New(testLogger, concurrentDeleteStorage{mockStorage}), testMetrics)
+
+	w := httptest.NewRecorder()
+	server.DeleteEvent(w, httptest.NewRequest("DELETE", "/events/e1", nil), "e1", DeleteEventParams{})
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPatchEvent(t *testing.T) {
+	mockStorage := &mockStorage{
+		events: make(map[string]*models.Event),
+	}
+	testLogger, _ := logger.NewLogger("info")
+	server := NewServer(app.New(testLogger, mockStorage), testMetrics)
+
+	event := &models.Event{
+		Title:       "Original Title",
+		Description: "Original Description",
+		StartTime:   time.Now().Add(24 * time.Hour).Truncate(time.Second),
+		EndTime:     time.Now().Add(25 * time.Hour).Truncate(time.Second),
+		UserID:      "user123",
+	}
+	err := mockStorage.CreateEvent(context.Background(), event)
+	assert.NoError(t, err)
+
+	patch := func(contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PATCH", "/events/"+event.ID, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		server.PatchEvent(w, req, event.ID, PatchEventParams{})
+		return w
+	}
+
+	t.Run("merge patch changes only given fields", func(t *testing.T) {
+		w := patch("application/merge-patch+json", `{"title": "Merged Title", "description": null}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var got Event
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+		assert.Equal(t, "Merged Title", got.Title)
+		assert.Nil(t, got.Description)
+		assert.Equal(t, "user123", got.UserId)
+		assert.True(t, event.StartTime.Equal(got.StartTime))
+	})
+
+	t.Run("json patch applies operations", func(t *testing.T) {
+		w := patch("application/json-patch+json",
+			`[{"op": "test", "path": "/title", "value": "Merged Title"}, {"op": "add", "path": "/notify_before", "value": 600}]`)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var got Event
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+		assert.Equal(t, 600, *got.NotifyBefore)
+		assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	})
+
+	t.Run("patched event is validated", func(t *testing.T) {
+		w := patch("application/merge-patch+json", `{"title": null}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("unknown fields are rejected", func(t *testing.T) {
+		w := patch("application/json-patch+json", `[{"op": "add", "path": "/id", "value": "other"}]`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("unsupported content type", func(t *testing.T) {
+		w := patch("application/json", `{"title": "Plain JSON"}`)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+
+	t.Run("oversized patch is rejected", func(t *testing.T) {
+		body := `{"description": "` + strings.Repeat("x", maxJSONBodySize) + `"}`
+		w := patch("application/merge-patch+json", body)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+}
+
+func TestAuditLog(t *testing.T) {
+	mockStorage := &mockStorage{
+		events: make(map[string]*models.Event),
+	}
+	testLogger, _ := logger.NewLogger("info")
+	server := NewServer(app.New(testLogger, mockStorage), testMetrics)
+
+	eventReq := CreateEventRequest{
+		Title:     "Audited Event",
+		StartTime: time.Now().Add(24 * time.Hour),
+		EndTime:   time.Now().Add(25 * time.Hour),
+		UserId:    "user123",
+	}
+	body, _ := json.Marshal(eventReq)
+	req := httptest.NewRequest("POST", "/events", bytes.NewBuffer(body))
+	req = req.WithContext(requestctx.WithRequestID(requestctx.WithActor(req.Context(), "alice"), "req-1"))
+	w := httptest.NewRecorder()
+	server.CreateEvent(w, req, CreateEventParams{})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var created Event
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&created))
+
+	req = httptest.NewRequest("PATCH", "/events/"+created.Id, bytes.NewBufferString(`{"title": "Renamed"}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	w = httptest.NewRecorder()
+	server.PatchEvent(w, req, created.Id, PatchEventParams{})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest("GET", "/audit?event_id="+created.Id, nil)
+	w = httptest.NewRecorder()
+	server.ListAudit(w, req, ListAuditParams{EventId: &created.Id})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var entries []AuditEntry
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&entries))
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, AuditCreate, entries[0].Action)
+		assert.Equal(t, "alice", entries[0].Actor)
+		assert.Equal(t, "req-1", entries[0].RequestId)
+		assert.Nil(t, entries[0].Before)
+
+		assert.Equal(t, AuditPatch, entries[1].Action)
+		assert.Equal(t, "unknown", entries[1].Actor)
+		assert.Equal(t, map[string]interface{}{"title": "Renamed", "version": float64(2)}, *entries[1].Diff)
+	}
+}
+
+func TestBatchEvents(t *testing.T) {
+	mockStorage := &mockStorage{
+		events: make(map[string]*models.Event),
+	}
+	testLogger, _ := logger.NewLogger("info")
+	server := NewServer(app.New(testLogger, mockStorage), testMetrics)
+
+	existing := &models.Event{Title: "Existing", StartTime: time.Now().Add(time.Hour), UserID: "user123"}
+	assert.NoError(t, mockStorage.CreateEvent(context.Background(), existing))
+
+	newEvent := UpdateEventRequest{
+		Title:     "Batch Event",
+		StartTime: time.Now().Add(24 * time.Hour),
+		EndTime:   time.Now().Add(25 * time.Hour),
+		UserId:    "user123",
+	}
+	staleVersion := int64(5)
+
+	batch := func(mode BatchRequestMode, ops ...BatchOperation) BatchResponse {
+		body, _ := json.Marshal(BatchRequest{Mode: &mode, Operations: ops})
+		req := httptest.NewRequest("POST", "/events:batch", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		server.BatchEvents(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var resp BatchResponse
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		return resp
+	}
+
+	t.Run("atomic batch is rolled back on conflict", func(t *testing.T) {
+		resp := batch(BatchAtomic,
+			BatchOperation{Op: BatchCreate, Event: &newEvent},
+			BatchOperation{Op: BatchDelete, Id: &existing.ID, Version: &staleVersion},
+		)
+		assert.False(t, resp.Committed)
+		assert.Equal(t, http.StatusFailedDependency, resp.Results[0].Status)
+		assert.Equal(t, http.StatusPreconditionFailed, resp.Results[1].Status)
+		assert.Len(t, mockStorage.events, 1)
+	})
+
+	t.Run("atomic batch with invalid operation is not executed", func(t *testing.T) {
+		resp := batch(BatchAtomic,
+			BatchOperation{Op: BatchCreate, Event: &newEvent},
+			BatchOperation{Op: BatchUpdate, Event: &newEvent},
+		)
+		assert.False(t, resp.Committed)
+		assert.Equal(t, http.StatusFailedDependency, resp.Results[0].Status)
+		assert.Equal(t, http.StatusBadRequest, resp.Results[1].Status)
+		assert.Len(t, mockStorage.events, 1)
+	})
+
+	t.Run("best effort batch applies successful operations", func(t *testing.T) {
+		missing := "missing"
+		resp := batch(BatchBestEffort,
+			BatchOperation{Op: BatchCreate, Event: &newEvent},
+			BatchOperation{Op: BatchDelete, Id: &missing},
+			BatchOperation{Op: BatchDelete, Id: &existing.ID},
+		)
+		assert.True(t, resp.Committed)
+		assert.Equal(t, http.StatusCreated, resp.Results[0].Status)
+		if assert.NotNil(t, resp.Results[0].Event) {
+			assert.Equal(t, "Batch Event", resp.Results[0].Event.Title)
+		}
+		assert.Equal(t, http.StatusNotFound, resp.Results[1].Status)
+		assert.Equal(t, http.StatusOK, resp.Results[2].Status)
+		assert.Len(t, mockStorage.events, 1)
+		// Создание existing и две успешные операции пакета
+		assert.Len(t, mockStorage.audit, 3)
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/events:batch", bytes.NewBufferString(`{"operations": []}`))
+		w := httptest.NewRecorder()
+		server.BatchEvents(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("batch over configured limit reports the limit", func(t *testing.T) {
+		limited := NewServer(app.New(testLogger, mockStorage), testMetrics, WithMaxBatchOperations(2))
+		op := BatchOperation{Op: BatchDelete, Id: &existing.ID}
+		body, _ := json.Marshal(BatchRequest{Operations: []BatchOperation{op, op, op}})
+		w := httptest.NewRecorder()
+		limited.BatchEvents(w, httptest.NewRequest("POST", "/events:batch", bytes.NewBuffer(body)))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+		var resp ErrorResponse
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		if assert.NotNil(t, resp.Limit) {
+			assert.Equal(t, 2, *resp.Limit)
+		}
+	})
+}
+
+func TestEventAttributes(t *testing.T) {
+	mockStorage := &mockStorage{
+		events: make(map[string]*models.Event),
+	}
+	testLogger, _ := logger.NewLogger("info")
+	server := NewServer(app.New(testLogger, mockStorage), testMetrics)
+
+	create := func(req CreateEventRequest) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		server.CreateEvent(w, httptest.NewRequest("POST", "/events", bytes.NewBuffer(body)), CreateEventParams{})
+		return w
+	}
+
+	priority := PriorityHigh
+	w := create(CreateEventRequest{
+		Title:     "Tagged Event",
+		StartTime: time.Now().Add(24 * time.Hour),
+		EndTime:   time.Now().Add(25 * time.Hour),
+		UserId:    "user123",
+		Tags:      &[]string{"work", " work ", "planning"},
+		Category:  stringPtr("meetings"),
+		Color:     stringPtr("#FF8800"),
+		Priority:  &priority,
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var created Event
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&created))
+	assert.Equal(t, []string{"work", "planning"}, *created.Tags)
+	assert.Equal(t, "#ff8800", *created.Color)
+	assert.Equal(t, PriorityHigh, *created.Priority)
+
+	w = create(CreateEventRequest{
+		Title:     "Plain Event",
+		StartTime: time.Now().Add(48 * time.Hour),
+		EndTime:   time.Now().Add(49 * time.Hour),
+		UserId:    "user123",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	t.Run("invalid attributes", func(t *testing.T) {
+		badPriority := Priority("critical")
+		for _, req := range []CreateEventRequest{
+			{Color: stringPtr("orange")},
+			{Priority: &badPriority},
+			{Tags: &[]string{""}},
+		} {
+			req.Title = "Invalid Event"
+			req.StartTime = time.Now().Add(24 * time.Hour)
+			req.EndTime = time.Now().Add(25 * time.Hour)
+			req.UserId = "user123"
+			assert.Equal(t, http.StatusBadRequest, create(req).Code)
+		}
+	})
+
+	t.Run("list filter", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		server.ListEvents(w, httptest.NewRequest("GET", "/events?tag=work&priority=high", nil),
+			ListEventsParams{Tag: &[]string{"work"}, Priority: &priority})
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var events []Event
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&events))
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, created.Id, events[0].Id)
+		}
+	})
+
+	t.Run("list range", func(t *testing.T) {
+		list := func(from, to time.Time) (int, []Event) {
+			w := httptest.NewRecorder()
+			server.ListEvents(w, httptest.NewRequest("GET", "/events", nil), ListEventsParams{From: &from, To: &to})
+			var events []Event
+			_ = json.NewDecoder(w.Body).Decode(&events)
+			return w.Code, events
+		}
+
+		code, events := list(time.Now().Add(36*time.Hour), time.Now().AddDate(1, 0, 0))
+		assert.Equal(t, http.StatusOK, code)
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, "Plain Event", events[0].Title)
+		}
+
+		code, _ = list(time.Now(), time.Now().Add(-time.Hour))
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
+
+func TestAttachments(t *testing.T) {
+	mockStorage := &mockStorage{
+		events: make(map[string]*models.Event),
+	}
+	testLogger, _ := logger.NewLogger("info")
+	blobs, err := localblob.NewStore(t.TempDir())
+	assert.NoError(t, err)
+	server := NewServer(app.New(testLogger, mockStorage, app.WithAttachments(blobs, config.AttachmentsConfig{
+		MaxSize:      16,
+		AllowedTypes: []string{"text/*", "application/pdf"},
+	})), testMetrics)
+
+	event := &models.Event{Title: "Meeting", StartTime: time.Now().Add(time.Hour), UserID: "user123"}
+	assert.NoError(t, mockStorage.CreateEvent(context.Background(), event))
+
+	upload := func(filename, contentType, content string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", `form-data; name="file"; filename="`+filename+`"`)
+		if contentType != "" {
+			header.Set("Content-Type", contentType)
+		}
+		part, _ := writer.CreatePart(header)
+		_, _ = part.Write([]byte(content))
+		_ = writer.Close()
+
+		req := httptest.NewRequest("POST", "/events/"+event.ID+"/attachments", &body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+		server.UploadAttachment(w, req, event.ID)
+		return w
+	}
+
+	w := upload("agenda.txt", "", "1. Intro")
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var attachment Attachment
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&attachment))
+	assert.Equal(t, "agenda.txt", attachment.Name)
+	assert.Equal(t, "text/plain", *attachment.ContentType)
+	assert.Equal(t, int64(8), *attachment.Size)
+
+	t.Run("download", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		server.DownloadAttachment(w, httptest.NewRequest("GET", "/", nil), event.ID, attachment.Id)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "1. Intro", w.Body.String())
+		assert.Equal(t, `attachment; filename=agenda.txt`, w.Header().Get("Content-Disposition"))
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		assert.Equal(t, http.StatusRequestEntityTooLarge, upload("big.txt", "text/plain", strings.Repeat("x", 17)).Code)
+		assert.Equal(t, http.StatusUnsupportedMediaType, upload("archive.zip", "application/zip", "PK").Code)
+		assert.Equal(t, http.StatusUnsupportedMediaType, upload("image.png", "", "\x89PNG\r\n\x1a\n").Code)
+		assert.Len(t, mockStorage.attachments, 1)
+	})
+
+	t.Run("link", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/events/"+event.ID+"/attachments",
+			bytes.NewBufferString(`{"name": "Docs", "url": "https://example.com/docs"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		server.UploadAttachment(w, req, event.ID)
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var link Attachment
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&link))
+
+		w = httptest.NewRecorder()
+		server.DownloadAttachment(w, httptest.NewRequest("GET", "/", nil), event.ID, link.Id)
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, "https://example.com/docs", w.Header().Get("Location"))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		server.DeleteAttachment(w, httptest.NewRequest("DELETE", "/", nil), event.ID, attachment.Id)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		_, err := blobs.Get(context.Background(), event.ID+"/"+attachment.Id)
+		assert.ErrorIs(t, err, blobstore.ErrNotFound)
+	})
+}
+
+func TestResources(t *testing.T) {
+	mockStorage := &mockStorage{
+		events:    make(map[string]*models.Event),
+		resources: make(map[string]*models.Resource),
+	}
+	testLogger, _ := logger.NewLogger("info")
+	server := NewServer(app.New(testLogger, mockStorage), testMetrics)
+
+	createResource := func(req ResourceRequest) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		server.CreateResource(w, httptest.NewRequest("POST", "/resources", bytes.NewBuffer(body)))
+		return w
+	}
+
+	w := createResource(ResourceRequest{Name: "Room 1", Kind: ResourceRoom, Capacity: intPtr(8)})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var room Resource
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&room))
+	assert.Equal(t, 8, room.Capacity)
+	assert.False(t, room.CreatedAt.IsZero())
+
+	t.Run("validation", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, createResource(ResourceRequest{Kind: ResourceRoom}).Code)
+		assert.Equal(t, http.StatusBadRequest, createResource(ResourceRequest{Name: "Car", Kind: "vehicle"}).Code)
+		assert.Equal(t, http.StatusBadRequest,
+			createResource(ResourceRequest{Name: "Room 2", Kind: ResourceRoom, Capacity: intPtr(-1)}).Code)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		w := createResource(ResourceRequest{Name: "Projector", Kind: ResourceEquipment})
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		w = httptest.NewRecorder()
+		kind := ResourceRoom
+		server.ListResources(w, httptest.NewRequest("GET", "/resources?kind=room&min_capacity=5", nil),
+			ListResourcesParams{Kind: &kind, MinCapacity: intPtr(5)})
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var resources []Resource
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&resources))
+		if assert.Len(t, resources, 1) {
+			assert.Equal(t, room.Id, resources[0].Id)
+		}
+	})
+
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	event := &models.Event{
+		Title:       "Planning",
+		StartTime:   start.Add(time.Hour),
+		EndTime:     start.Add(2 * time.Hour),
+		UserID:      "user123",
+		ResourceIDs: []string{room.Id},
+	}
+	assert.NoError(t, mockStorage.CreateEvent(context.Background(), event))
+
+	t.Run("availability", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		server.GetResourceAvailability(w, httptest.NewRequest("GET", "/", nil), room.Id,
+			GetResourceAvailabilityParams{From: start, To: start.Add(4 * time.Hour)})
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var availability ResourceAvailability
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&availability))
+		if assert.Len(t, availability.Busy, 1) {
+			assert.Equal(t, event.ID, availability.Busy[0].EventId)
+		}
+		if assert.Len(t, availability.Free, 2) {
+			assert.True(t, availability.Free[0].End.Equal(event.StartTime))
+			assert.True(t, availability.Free[1].Start.Equal(event.EndTime))
+		}
+
+		w = httptest.NewRecorder()
+		server.GetResourceAvailability(w, httptest.NewRequest("GET", "/", nil), "missing",
+			GetResourceAvailabilityParams{From: start, To: start.Add(time.Hour)})
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		server.DeleteResource(w, httptest.NewRequest("DELETE", "/", nil), room.Id)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		delete(mockStorage.events, event.ID)
+		w = httptest.NewRecorder()
+		server.DeleteResource(w, httptest.NewRequest("DELETE", "/", nil), room.Id)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestTemplates(t *testing.T) {
+	mockStorage := &mockStorage{
+		events:    make(map[string]*models.Event),
+		templates: make(map[string]*models.EventTemplate),
+	}
+	testLogger, _ := logger.NewLogger("info")
+	server := NewServer(app.New(testLogger, mockStorage), testMetrics)
+
+	createTemplate := func(req EventTemplateRequest) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		server.CreateTemplate(w, httptest.NewRequest("POST", "/templates", bytes.NewBuffer(body)))
+		return w
+	}
+
+	tags := []string{"team", "sync"}
+	priority := PriorityHigh
+	w := createTemplate(EventTemplateRequest{
+		Name:         "1:1",
+		TitlePattern: "1:1 {user} / {with}",
+		Duration:     1800,
+		Description:  stringPtr("Weekly sync"),
+		NotifyBefore: intPtr(600),
+		Tags:         &tags,
+		Priority:     &priority,
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var template EventTemplate
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&template))
+	assert.Equal(t, 1800, template.Duration)
+	assert.False(t, template.CreatedAt.IsZero())
+
+	t.Run("validation", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, createTemplate(EventTemplateRequest{TitlePattern: "X", Duration: 60}).Code)
+		assert.Equal(t, http.StatusBadRequest, createTemplate(EventTemplateRequest{Name: "X", Duration: 60}).Code)
+		assert.Equal(t, http.StatusBadRequest, createTemplate(EventTemplateRequest{Name: "X", TitlePattern: "X"}).Code)
+		assert.Equal(t, http.StatusBadRequest,
+			createTemplate(EventTemplateRequest{Name: "X", TitlePattern: "X", Duration: 60, Color: stringPtr("red")}).Code)
+	})
+
+	createFromTemplate := func(id string, req TemplateEventRequest) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		server.CreateEvent(w, httptest.NewRequest("POST", "/events?template="+id, bytes.NewBuffer(body)),
+			CreateEventParams{Template: &id})
+		return w
+	}
+
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	vars := map[string]string{"with": "bob"}
+
+	t.Run("instantiate", func(t *testing.T) {
+		w := createFromTemplate(template.Id, TemplateEventRequest{StartTime: start, UserId: "alice", Variables: &vars})
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var event Event
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&event))
+		assert.Equal(t, "1:1 alice / bob", event.Title)
+		assert.True(t, event.EndTime.Equal(start.Add(30*time.Minute)))
+		assert.Equal(t, "Weekly sync", *event.Description)
+		assert.Equal(t, 600, *event.NotifyBefore)
+		assert.Equal(t, tags, *event.Tags)
+		assert.Equal(t, PriorityHigh, *event.Priority)
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		overrideTags := []string{"urgent"}
+		w := createFromTemplate(template.Id, TemplateEventRequest{
+			StartTime: start,
+			UserId:    "alice",
+			Title:     stringPtr("Hiring sync"),
+			EndTime:   timePtr(start.Add(time.Hour)),
+			Tags:      &overrideTags,
+		})
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var event Event
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&event))
+		assert.Equal(t, "Hiring sync", event.Title)
+		assert.True(t, event.EndTime.Equal(start.Add(time.Hour)))
+		assert.Equal(t, overrideTags, *event.Tags)
+		assert.Equal(t, "Weekly sync", *event.Description)
+	})
+
+	t.Run("instantiate errors", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound,
+			createFromTemplate("missing", TemplateEventRequest{StartTime: start, UserId: "alice"}).Code)
+		// Подстановка {with} не задана
+		assert.Equal(t, http.StatusBadRequest,
+			createFromTemplate(template.Id, TemplateEventRequest{StartTime: start, UserId: "alice"}).Code)
+		assert.Equal(t, http.StatusBadRequest,
+			createFromTemplate(template.Id, TemplateEventRequest{StartTime: start.Add(-48 * time.Hour), UserId: "alice", Variables: &vars}).Code)
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		body, _ := json.Marshal(EventTemplateRequest{Name: "1:1", TitlePattern: "1:1 {user}", Duration: 3600})
+		w := httptest.NewRecorder()
+		server.UpdateTemplate(w, httptest.NewRequest("PUT", "/", bytes.NewBuffer(body)), template.Id)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var updated EventTemplate
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&updated))
+		assert.Equal(t, 3600, updated.Duration)
+		assert.Nil(t, updated.Tags)
+
+		w = httptest.NewRecorder()
+		server.DeleteTemplate(w, httptest.NewRequest("DELETE", "/", nil), template.Id)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = httptest.NewRecorder()
+		server.GetTemplate(w, httptest.NewRequest("GET", "/", nil), template.Id)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestQuickAddEvent(t *testing.T) {
+	mockStorage := &mockStorage{
+		events: make(map[string]*models.Event),
+		hours:  make(map[string]*models.WorkingHours),
+	}
+	testLogger, _ := logger.NewLogger("info")
+	server := NewServer(app.New(testLogger, mockStorage), testMetrics)
+
+	quickAdd := func(req QuickEventRequest, create bool) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		server.QuickAddEvent(w, httptest.NewRequest("POST", "/events/quick", bytes.NewBuffer(body)),
+			QuickAddEventParams{Create: &create})
+		return w
+	}
+
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	assert.NoError(t, err)
+	tomorrow := time.Now().In(moscow).AddDate(0, 0, 1)
+
+	t.Run("interpretation", func(t *testing.T) {
+		mockStorage.hours["alice"] = &models.WorkingHours{UserID: "alice", TimeZone: "Europe/Moscow"}
+		w := quickAdd(QuickEventRequest{Text: "Lunch with Anna tomorrow 13:00 for 1h remind 15m #team", UserId: "alice"}, false)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var resp QuickEventResponse
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Nil(t, resp.Event)
+		assert.Equal(t, "Lunch with Anna", resp.Request.Title)
+		start := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 13, 0, 0, 0, moscow)
+		assert.True(t, resp.Request.StartTime.Equal(start))
+		assert.True(t, resp.Request.EndTime.Equal(start.Add(time.Hour)))
+		assert.Equal(t, 900, *resp.Request.NotifyBefore)
+		assert.Equal(t, []string{"team"}, *resp.Request.Tags)
+		assert.Empty(t, mockStorage.events)
+	})
+
+	t.Run("create", func(t *testing.T) {
+		tz := "UTC"
+		w := quickAdd(QuickEventRequest{Text: "Обед завтра в 13:00 на 30 минут", UserId: "bob", TimeZone: &tz}, true)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+
+		var resp QuickEventResponse
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		if assert.NotNil(t, resp.Event) {
+			assert.Equal(t, "Обед", resp.Event.Title)
+			assert.Equal(t, 13, resp.Event.StartTime.UTC().Hour())
+			assert.Equal(t, 30*time.Minute, resp.Event.EndTime.Sub(resp.Event.StartTime))
+		}
+		assert.Len(t, mockStorage.events, 1)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, quickAdd(QuickEventRequest{Text: "Lunch tomorrow", UserId: "bob"}, false).Code)
+		assert.Equal(t, http.StatusBadRequest, quickAdd(QuickEventRequest{Text: "Lunch at 13:00"}, false).Code)
+		tz := "Mars/Olympus"
+		assert.Equal(t, http.StatusBadRequest,
+			quickAdd(QuickEventRequest{Text: "Lunch at 13:00", UserId: "bob", TimeZone: &tz}, false).Code)
+	})
+}
+
+func TestArchive(t *testing.T) {
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	source := &mockStorage{
+		events: map[string]*models.Event{
+			"e1": {ID: "e1", Title: "Sync", StartTime: start, EndTime: start.Add(time.Hour), UserID: "alice", Version: 4},
+		},
+		resources: map[string]*models.Resource{"r1": {ID: "r1", Name: "Room", Kind: models.ResourceKindRoom}},
+	}
+	testLogger, _ := logger.NewLogger("info")
+	archiveCfg := WithArchive(config.ArchiveConfig{Enabled: true, MaxSize: 1 << 20})
+	server := NewServer(app.New(testLogger, source), testMetrics, archiveCfg)
+
+	w := httptest.NewRecorder()
+	server.ExportArchive(w, httptest.NewRequest("GET", "/archive", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "calendar-")
+	exported := w.Body.String()
+
+	// Уведомление в архиве добавляет calendarctl backup; сервер его пропускает
+	notification := `{"type":"notification","data":{"id":"n1","user_id":"alice"}}` + "\n"
+	withNotification := strings.Replace(exported, `{"type":"footer","records":2}`, `{"type":"footer","records":3}`, 1)
+	footer := strings.Index(withNotification, `{"type":"footer"`)
+	withNotification = withNotification[:footer] + notification + withNotification[footer:]
+
+	target := &mockStorage{}
+	server = NewServer(app.New(testLogger, target), testMetrics, archiveCfg)
+
+	t.Run("should be disabled by default", func(t *testing.T) {
+		disabled := NewServer(app.New(testLogger, source), testMetrics)
+
+		w := httptest.NewRecorder()
+		disabled.ExportArchive(w, httptest.NewRequest("GET", "/archive", nil))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = httptest.NewRecorder()
+		disabled.RestoreArchive(w, httptest.NewRequest("POST", "/archive", strings.NewReader(exported)))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("should reject archive over max size", func(t *testing.T) {
+		limited := NewServer(app.New(testLogger, &mockStorage{}), testMetrics,
+			WithArchive(config.ArchiveConfig{Enabled: true, MaxSize: int64(len(exported) - 1)}))
+
+		w := httptest.NewRecorder()
+		limited.RestoreArchive(w, httptest.NewRequest("POST", "/archive", strings.NewReader(exported)))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("should restore into empty storage", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		server.RestoreArchive(w, httptest.NewRequest("POST", "/archive", strings.NewReader(withNotification)))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var result ArchiveRestoreResult
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&result))
+		assert.Equal(t, 1, result.Restored["event"])
+		assert.Equal(t, 1, result.Restored["resource"])
+		assert.Equal(t, map[string]int{"notification": 1}, result.Skipped)
+
+		if assert.Contains(t, target.events, "e1") {
+			assert.Equal(t, int64(4), target.events["e1"].Version)
+		}
+	})
+
+	t.Run("should refuse non-empty storage", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		server.RestoreArchive(w, httptest.NewRequest("POST", "/archive", strings.NewReader(exported)))
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("should reject truncated archive", func(t *testing.T) {
+		truncated := exported[:strings.Index(exported, `{"type":"footer"`)]
+		w := httptest.NewRecorder()
+		server.RestoreArchive(w, httptest.NewRequest("POST", "/archive", strings.NewReader(truncated)))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "truncated")
+	})
+}
+
+func TestWorkingHours(t *testing.T) {
+	mockStorage := &mockStorage{
+		events: make(map[string]*models.Event),
+		hours:  make(map[string]*models.WorkingHours),
+	}
+	testLogger, _ := logger.NewLogger("info")
+	server := NewServer(app.New(testLogger, mockStorage), testMetrics)
+
+	setHours := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.SetWorkingHours(w, httptest.NewRequest("PUT", "/", bytes.NewBufferString(body)), "user123")
+		return w
+	}
+
+	w := httptest.NewRecorder()
+	server.GetWorkingHours(w, httptest.NewRequest("GET", "/", nil), "user123")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = setHours(`{"time_zone": "Europe/Moscow", "defer_reminders": true,
+		"windows": [{"weekday": "monday", "start": "09:00", "end": "18:00"}]}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var hours WorkingHours
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&hours))
+	assert.True(t, hours.DeferReminders)
+	assert.Equal(t, []WorkingWindow{{Weekday: Monday, Start: "09:00", End: "18:00"}}, hours.Windows)
+	assert.Equal(t, time.Monday, mockStorage.hours["user123"].Windows[0].Weekday)
+
+	t.Run("validation", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, setHours(`{"windows": [{"weekday": "monday", "start": "9:00", "end": "18:00"}]}`).Code)
+		assert.Equal(t, http.StatusBadRequest, setHours(`{"windows": [{"weekday": "monday", "start": "18:00", "end": "09:00"}]}`).Code)
+		assert.Equal(t, http.StatusBadRequest, setHours(`{"time_zone": "Mars/Olympus", "windows": []}`).Code)
+	})
+
+	t.Run("freebusy includes out of office", func(t *testing.T) {
+		start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+		event := &models.Event{Title: "Meeting", StartTime: start, EndTime: start.Add(time.Hour), UserID: "user123"}
+		assert.NoError(t, mockStorage.CreateEvent(context.Background(), event))
+
+		body, _ := json.Marshal(OutOfOfficeRequest{StartTime: start.Add(2 * time.Hour), EndTime: start.Add(3 * time.Hour)})
+		w := httptest.NewRecorder()
+		server.AddOutOfOffice(w, httptest.NewRequest("POST", "/", bytes.NewBuffer(body)), "user123")
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		w = httptest.NewRecorder()
+		server.GetFreeBusy(w, httptest.NewRequest("GET", "/", nil), "user123",
+			GetFreeBusyParams{From: start, To: start.Add(4 * time.Hour)})
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var freeBusy FreeBusy
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&freeBusy))
+		if assert.Len(t, freeBusy.Busy, 2) {
+			assert.Equal(t, BusyEvent, freeBusy.Busy[0].Kind)
+			assert.Equal(t, BusyOutOfOffice, freeBusy.Busy[1].Kind)
+		}
+		assert.Len(t, freeBusy.Free, 2)
+	})
+}
+
+func TestEventQuota(t *testing.T) {
+	mockStorage := &mockStorage{
+		events: make(map[string]*models.Event),
+	}
+	testLogger, _ := logger.NewLogger("info")
+	server := NewServer(app.New(testLogger, mockStorage, app.WithQuotas(config.QuotaConfig{MaxEventsPerUser: 2})), testMetrics)
+
+	newEvent := func(hours int) UpdateEventRequest {
+		return UpdateEventRequest{
+			Title:     "Event",
+			StartTime: time.Now().Add(time.Duration(hours) * time.Hour),
+			EndTime:   time.Now().Add(time.Duration(hours+1) * time.Hour),
+			UserId:    "user123",
+		}
+	}
+
+	body, _ := json.Marshal(CreateEventRequest(newEvent(24)))
+	w := httptest.NewRecorder()
+	server.CreateEvent(w, httptest.NewRequest("POST", "/events", bytes.NewBuffer(body)), CreateEventParams{})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	batch := func(mode BatchRequestMode) []BatchResult {
+		first, second := newEvent(48), newEvent(72)
+		body, _ := json.Marshal(BatchRequest{
+			Mode: &mode,
+			Operations: []BatchOperation{
+				{Op: BatchCreate, Event: &first},
+				{Op: BatchCreate, Event: &second},
+			},
+		})
+		w := httptest.NewRecorder()
+		server.BatchEvents(w, httptest.NewRequest("POST", "/events:batch", bytes.NewBuffer(body)))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var resp BatchResponse
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		return resp.Results
+	}
+
+	results := batch(BatchAtomic)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, http.StatusFailedDependency, results[0].Status)
+		assert.Equal(t, http.StatusForbidden, results[1].Status)
+	}
+	assert.Len(t, mockStorage.events, 1)
+
+	results = batch(BatchBestEffort)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, http.StatusCreated, results[0].Status)
+		assert.Equal(t, http.StatusForbidden, results[1].Status)
+	}
+	assert.Len(t, mockStorage.events, 2)
+
+	body, _ = json.Marshal(CreateEventRequest(newEvent(96)))
+	w = httptest.NewRecorder()
+	server.CreateEvent(w, httptest.NewRequest("POST", "/events", bytes.NewBuffer(body)), CreateEventParams{})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestEventQuota_OwnerChange(t *testing.T) {
+	mockStorage := &mockStorage{
+		events: make(map[string]*models.Event),
+	}
+	testLogger, _ := logger.NewLogger("info")
+	server := NewServer(app.New(testLogger, mockStorage, app.WithQuotas(config.QuotaConfig{MaxEventsPerUser: 1})), testMetrics)
+
+	request := func(user string, hours int) UpdateEventRequest {
+		return UpdateEventRequest{
+			Title:     "Event",
+			StartTime: time.Now().Add(time.Duration(hours) * time.Hour),
+			EndTime:   time.Now().Add(time.Duration(hours+1) * time.Hour),
+			UserId:    user,
+		}
+	}
+
+	var created Event
+	for _, user := range []string{"alice", "bob"} {
+		body, _ := json.Marshal(CreateEventRequest(request(user, 24)))
+		w := httptest.NewRecorder()
+		server.CreateEvent(w, httptest.NewRequest("POST", "/events", bytes.NewBuffer(body)), CreateEventParams{})
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&created))
+	}
+
+	// Передача события пользователю, у которого квота исчерпана
+	body, _ := json.Marshal(request("alice", 48))
+	w := httptest.NewRecorder()
+	server.UpdateEvent(w, httptest.NewRequest("PUT", "/events/"+created.Id, bytes.NewBuffer(body)), created.Id, UpdateEventParams{})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest("PATCH", "/events/"+created.Id, strings.NewReader(`{"user_id":"alice"}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	server.PatchEvent(w, req, created.Id, PatchEventParams{})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "bob", mockStorage.events[created.Id].UserID)
+
+	// Обновление без смены владельца квоту не проверяет
+	body, _ = json.Marshal(request("bob", 48))
+	w = httptest.NewRecorder()
+	server.UpdateEvent(w, httptest.NewRequest("PUT", "/events/"+created.Id, bytes.NewBuffer(body)), created.Id, UpdateEventParams{})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
 // Mock storage
 type mockStorage struct {
-	events map[string]*models.Event
+	events      map[string]*models.Event
+	audit       []*models.AuditEntry
+	attachments []*models.Attachment
+	resources   map[string]*models.Resource
+	hours       map[string]*models.WorkingHours
+	away        []*models.OutOfOffice
+	templates   map[string]*models.EventTemplate
 }
 
 func (m *mockStorage) CreateEvent(ctx context.Context, event *models.Event) error {
 	if event.ID == "" {
 		event.ID = uuid.New().String()
 	}
+	event.Version = 1
 	m.events[event.ID] = event
+	m.recordAudit(ctx, models.AuditActionCreate, nil, event)
 	return nil
 }
 
+// recordAudit записывает аудит изменения, как это делают настоящие хранилища.
+func (m *mockStorage) recordAudit(ctx context.Context, action models.AuditAction, before, after *models.Event) {
+	m.audit = append(m.audit, storage.NewAuditEntry(ctx, action, before, after))
+}
+
 func (m *mockStorage) UpdateEvent(ctx context.Context, event *models.Event) error {
-	if _, exists := m.events[event.ID]; !exists {
+	current, exists := m.events[event.ID]
+	if !exists {
 		return models.ErrEventNotFound
 	}
+	if event.Version != 0 && event.Version != current.Version {
+		return models.ErrVersionConflict
+	}
+	event.Version = current.Version + 1
 	m.events[event.ID] = event
+	m.recordAudit(ctx, models.AuditActionUpdate, current, event)
+	return nil
+}
+
+func (m *mockStorage) PatchEvent(ctx context.Context, event *models.Event, fields []models.EventField) error {
+	current, exists := m.events[event.ID]
+	if !exists {
+		return models.ErrEventNotFound
+	}
+	if event.Version != 0 && event.Version != current.Version {
+		return models.ErrVersionConflict
+	}
+	patched := *current
+	if err := patched.ApplyFields(event, fields); err != nil {
+		return err
+	}
+	patched.Version = current.Version + 1
+	m.events[event.ID] = &patched
+	*event = patched
+	m.recordAudit(ctx, models.AuditActionPatch, current, &patched)
 	return nil
 }
 
-func (m *mockStorage) DeleteEvent(ctx context.Context, id string) error {
-	if _, exists := m.events[id]; !exists {
+func (m *mockStorage) DeleteEvent(ctx context.Context, id string, version int64) error {
+	current, exists := m.events[id]
+	if !exists {
 		return models.ErrEventNotFound
 	}
+	if version != 0 && version != current.Version {
+		return models.ErrVersionConflict
+	}
 	delete(m.events, id)
+	m.recordAudit(ctx, models.AuditActionDelete, current, nil)
 	return nil
 }
 
@@ -96,26 +1124,356 @@ func (m *mockStorage) GetEvent(ctx context.Context, id string) (*models.Event, e
 	return event, nil
 }
 
-func (m *mockStorage) ListEvents(ctx context.Context, from, to time.Time) ([]*models.Event, error) {
+func (m *mockStorage) ListEvents(ctx context.Context, from, to time.Time, filter models.EventFilter) ([]*models.Event, error) {
 	events := make([]*models.Event, 0, len(m.events))
 	for _, event := range m.events {
 		if (event.StartTime.After(from) || event.StartTime.Equal(from)) &&
-			(event.StartTime.Before(to) || event.StartTime.Equal(to)) {
+			(event.StartTime.Before(to) || event.StartTime.Equal(to)) &&
+			filter.Matches(event) {
 			events = append(events, event)
 		}
 	}
 	return events, nil
 }
 
-func (m *mockStorage) Close() error {
+func (m *mockStorage) CountEvents(ctx context.Context, userID string) (int, error) {
+	count := 0
+	for _, event := range m.events {
+		if event.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockStorage) ListReminders(ctx context.Context, from, to time.Time) ([]*models.Event, error) {
+	var events []*models.Event
+	for _, event := range m.events {
+		if at := event.ReminderAt(); !at.Before(from) && at.Before(to) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (m *mockStorage) DeferReminder(ctx context.Context, id string, deferral models.ReminderDeferral) error {
+	event, exists := m.events[id]
+	if !exists {
+		return models.ErrEventNotFound
+	}
+	event.ReminderDeferral = &deferral
 	return nil
 }
 
-// Вспомогательные функции
-func stringPtr(s string) *string {
-	return &s
+func (m *mockStorage) ListDeletedEvents(ctx context.Context) ([]*models.Event, error) {
+	return nil, nil
+}
+
+func (m *mockStorage) RestoreEvent(ctx context.Context, id string) (*models.Event, error) {
+	return nil, models.ErrEventNotFound
+}
+
+func (m *mockStorage) PurgeDeletedEvents(ctx context.Context, deletedBefore time.Time) (int, error) {
+	return 0, nil
+}
+
+func (m *mockStorage) ApplyBatch(ctx context.Context, ops []models.BatchOperation, atomic bool) ([]models.BatchResult, error) {
+	saved := make(map[string]*models.Event, len(m.events))
+	for id, event := range m.events {
+		saved[id] = event
+	}
+	savedAudit := m.audit
+
+	results := make([]models.BatchResult, len(ops))
+	for i, op := range ops {
+		var err error
+		switch op.Op {
+		case models.BatchOpCreate:
+			err = m.CreateEvent(ctx, op.Event)
+			results[i].After = op.Event
+		case models.BatchOpUpdate:
+			results[i].Before = m.events[op.Event.ID]
+			err = m.UpdateEvent(ctx, op.Event)
+			results[i].After = op.Event
+		case models.BatchOpDelete:
+			results[i].Before = m.events[op.ID]
+			err = m.DeleteEvent(ctx, op.ID, op.Version)
+		}
+		if err == nil {
+			continue
+		}
+		results[i] = models.BatchResult{Err: err}
+		if atomic {
+			m.events, m.audit = saved, savedAudit
+			for j := range results {
+				if j != i {
+					results[j] = models.BatchResult{Err: models.ErrBatchAborted}
+				}
+			}
+			return results, nil
+		}
+	}
+	return results, nil
+}
+
+func (m *mockStorage) AppendAudit(ctx context.Context, entry *models.AuditEntry) error {
+	m.audit = append(m.audit, entry)
+	return nil
 }
 
+func (m *mockStorage) ListAudit(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, error) {
+	var entries []*models.AuditEntry
+	for _, entry := range m.audit {
+		if filter.EventID == "" || entry.EventID == filter.EventID {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (m *mockStorage) PurgeAudit(ctx context.Context, createdBefore time.Time) (int, error) {
+	return 0, nil
+}
+
+func (m *mockStorage) AddAttachment(ctx context.Context, attachment *models.Attachment) error {
+	if _, exists := m.events[attachment.EventID]; !exists {
+		return models.ErrEventNotFound
+	}
+	m.attachments = append(m.attachments, attachment)
+	return nil
+}
+
+func (m *mockStorage) ListAttachments(ctx context.Context, eventID string) ([]*models.Attachment, error) {
+	var attachments []*models.Attachment
+	for _, attachment := range m.attachments {
+		if attachment.EventID == eventID {
+			attachments = append(attachments, attachment)
+		}
+	}
+	return attachments, nil
+}
+
+func (m *mockStorage) GetAttachment(ctx context.Context, eventID, id string) (*models.Attachment, error) {
+	for _, attachment := range m.attachments {
+		if attachment.EventID == eventID && attachment.ID == id {
+			return attachment, nil
+		}
+	}
+	return nil, models.ErrAttachmentNotFound
+}
+
+func (m *mockStorage) DeleteAttachment(ctx context.Context, eventID, id string) error {
+	for i, attachment := range m.attachments {
+		if attachment.EventID == eventID && attachment.ID == id {
+			m.attachments = append(m.attachments[:i], m.attachments[i+1:]...)
+			return nil
+		}
+	}
+	return models.ErrAttachmentNotFound
+}
+
+func (m *mockStorage) CreateResource(ctx context.Context, resource *models.Resource) error {
+	m.resources[resource.ID] = resource
+	return nil
+}
+
+func (m *mockStorage) UpdateResource(ctx context.Context, resource *models.Resource) error {
+	if _, exists := m.resources[resource.ID]; !exists {
+		return models.ErrResourceNotFound
+	}
+	m.resources[resource.ID] = resource
+	return nil
+}
+
+func (m *mockStorage) DeleteResource(ctx context.Context, id string) error {
+	if _, exists := m.resources[id]; !exists {
+		return models.ErrResourceNotFound
+	}
+	for _, event := range m.events {
+		if slices.Contains(event.ResourceIDs, id) {
+			return models.ErrResourceInUse
+		}
+	}
+	delete(m.resources, id)
+	return nil
+}
+
+func (m *mockStorage) GetResource(ctx context.Context, id string) (*models.Resource, error) {
+	resource, exists := m.resources[id]
+	if !exists {
+		return nil, models.ErrResourceNotFound
+	}
+	return resource, nil
+}
+
+func (m *mockStorage) ListResources(ctx context.Context, filter models.ResourceFilter) ([]*models.Resource, error) {
+	var resources []*models.Resource
+	for _, resource := range m.resources {
+		if filter.Matches(resource) {
+			resources = append(resources, resource)
+		}
+	}
+	return resources, nil
+}
+
+func (m *mockStorage) ListBookings(ctx context.Context, resourceID string, from, to time.Time) ([]*models.Booking, error) {
+	if _, exists := m.resources[resourceID]; !exists {
+		return nil, models.ErrResourceNotFound
+	}
+	var bookings []*models.Booking
+	for _, event := range m.events {
+		if slices.Contains(event.ResourceIDs, resourceID) &&
+			models.Overlaps(event.StartTime, event.EndTime, from, to) {
+			bookings = append(bookings, &models.Booking{
+				ResourceID: resourceID,
+				EventID:    event.ID,
+				StartTime:  event.StartTime,
+				EndTime:    event.EndTime,
+			})
+		}
+	}
+	return bookings, nil
+}
+
+func (m *mockStorage) GetWorkingHours(ctx context.Context, userID string) (*models.WorkingHours, error) {
+	hours, exists := m.hours[userID]
+	if !exists {
+		return nil, models.ErrWorkingHoursNotFound
+	}
+	return hours, nil
+}
+
+func (m *mockStorage) SetWorkingHours(ctx context.Context, hours *models.WorkingHours) error {
+	m.hours[hours.UserID] = hours
+	return nil
+}
+
+func (m *mockStorage) AddOutOfOffice(ctx context.Context, period *models.OutOfOffice) error {
+	period.ID = uuid.New().String()
+	m.away = append(m.away, period)
+	return nil
+}
+
+func (m *mockStorage) ListOutOfOffice(ctx context.Context, userID string, from, to time.Time) ([]*models.OutOfOffice, error) {
+	var periods []*models.OutOfOffice
+	for _, period := range m.away {
+		if period.UserID == userID && models.Overlaps(period.StartTime, period.EndTime, from, to) {
+			periods = append(periods, period)
+		}
+	}
+	return periods, nil
+}
+
+func (m *mockStorage) DeleteOutOfOffice(ctx context.Context, userID, id string) error {
+	for i, period := range m.away {
+		if period.UserID == userID && period.ID == id {
+			m.away = append(m.away[:i], m.away[i+1:]...)
+			return nil
+		}
+	}
+	return models.ErrOutOfOfficeNotFound
+}
+
+func (m *mockStorage) CreateTemplate(ctx context.Context, template *models.EventTemplate) error {
+	template.ID = uuid.New().String()
+	m.templates[template.ID] = template
+	return nil
+}
+
+func (m *mockStorage) UpdateTemplate(ctx context.Context, template *models.EventTemplate) error {
+	current, exists := m.templates[template.ID]
+	if !exists {
+		return models.ErrTemplateNotFound
+	}
+	template.CreatedAt = current.CreatedAt
+	m.templates[template.ID] = template
+	return nil
+}
+
+func (m *mockStorage) DeleteTemplate(ctx context.Context, id string) error {
+	if _, exists := m.templates[id]; !exists {
+		return models.ErrTemplateNotFound
+	}
+	delete(m.templates, id)
+	return nil
+}
+
+func (m *mockStorage) GetTemplate(ctx context.Context, id string) (*models.EventTemplate, error) {
+	template, exists := m.templates[id]
+	if !exists {
+		return nil, models.ErrTemplateNotFound
+	}
+	return template, nil
+}
+
+func (m *mockStorage) ListTemplates(ctx context.Context) ([]*models.EventTemplate, error) {
+	var templates []*models.EventTemplate
+	for _, template := range m.templates {
+		templates = append(templates, template)
+	}
+	return templates, nil
+}
+
+func (m *mockStorage) Snapshot(ctx context.Context) (*models.Snapshot, error) {
+	snapshot := &models.Snapshot{
+		OutOfOffice: m.away,
+		Attachments: m.attachments,
+		Audit:       m.audit,
+	}
+	for _, event := range m.events {
+		snapshot.Events = append(snapshot.Events, event)
+	}
+	for _, resource := range m.resources {
+		snapshot.Resources = append(snapshot.Resources, resource)
+	}
+	for _, template := range m.templates {
+		snapshot.Templates = append(snapshot.Templates, template)
+	}
+	for _, hours := range m.hours {
+		snapshot.WorkingHours = append(snapshot.WorkingHours, hours)
+	}
+	return snapshot, nil
+}
+
+func (m *mockStorage) LoadSnapshot(ctx context.Context, snapshot *models.Snapshot) error {
+	if len(m.events) > 0 || len(m.resources) > 0 || len(m.templates) > 0 {
+		return models.ErrStorageNotEmpty
+	}
+	m.events = make(map[string]*models.Event)
+	for _, event := range snapshot.Events {
+		m.events[event.ID] = event
+	}
+	m.resources = make(map[string]*models.Resource)
+	for _, resource := range snapshot.Resources {
+		m.resources[resource.ID] = resource
+	}
+	m.templates = make(map[string]*models.EventTemplate)
+	for _, template := range snapshot.Templates {
+		m.templates[template.ID] = template
+	}
+	m.hours = make(map[string]*models.WorkingHours)
+	for _, hours := range snapshot.WorkingHours {
+		m.hours[hours.UserID] = hours
+	}
+	m.away = snapshot.OutOfOffice
+	m.attachments = snapshot.Attachments
+	m.audit = snapshot.Audit
+	return nil
+}
+
+func (m *mockStorage) Ping(_ context.Context) error {
+	return nil
+}
+
+func (m *mockStorage) Close() error {
+	return nil
+}
+
+// Вспомогательные функции
 func intPtr(i int) *int {
 	return &i
 }
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
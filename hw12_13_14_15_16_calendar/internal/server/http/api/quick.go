@@ -0,0 +1,112 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/quickadd"
+	"github.com/google/uuid"
+)
+
+// maxQuickTextLength совпадает с ограничением в openapi.yaml
+const maxQuickTextLength = 500
+
+// QuickAddEvent распознает событие в строке на естественном языке и, если create=true, создает его
+// (POST /events/quick)
+func (s *Server) QuickAddEvent(w http.ResponseWriter, r *http.Request, params QuickAddEventParams) {
+	ctx := r.Context()
+
+	var req QuickEventRequest
+	if err := s.decodeJSON(w, r, &req); err != nil {
+		s.sendBodyError(w, err)
+		return
+	}
+
+	if strings.TrimSpace(req.Text) == "" {
+		s.sendError(w, http.StatusBadRequest, "Validation failed", errors.New("text is required"))
+		return
+	}
+	if utf8.RuneCountInString(req.Text) > maxQuickTextLength {
+		s.sendError(w, http.StatusBadRequest, "Validation failed", errors.New("text is too long"))
+		return
+	}
+	if req.UserId == "" {
+		s.sendError(w, http.StatusBadRequest, "Validation failed", errors.New("user_id is required"))
+		return
+	}
+	if req.TimeZone != nil {
+		if _, err := time.LoadLocation(*req.TimeZone); err != nil || *req.TimeZone == "" {
+			s.sendError(w, http.StatusBadRequest, "Validation failed", errors.New("unknown time_zone"))
+			return
+		}
+	}
+
+	loc, err := s.quickAddLocation(r, req)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to get working hours", err)
+		return
+	}
+
+	result, err := quickadd.Parse(req.Text, time.Now().In(loc))
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, "Could not parse event", err)
+		return
+	}
+
+	createReq := CreateEventRequest{
+		Title:     result.Title,
+		StartTime: result.Start,
+		EndTime:   result.End,
+		UserId:    req.UserId,
+	}
+	if result.NotifyBefore > 0 {
+		notifyBefore := int(result.NotifyBefore / time.Second)
+		createReq.NotifyBefore = &notifyBefore
+	}
+	if len(result.Tags) > 0 {
+		createReq.Tags = &result.Tags
+	}
+
+	if err := s.validateCreateEventRequest(createReq); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	if params.Create == nil || !*params.Create {
+		s.sendJSON(w, http.StatusOK, QuickEventResponse{Request: createReq})
+		return
+	}
+
+	event := s.convertToModelEvent(uuid.New().String(), UpdateEventRequest(createReq))
+	if err := s.app.CreateEvent(ctx, event); err != nil {
+		s.sendError(w, eventErrorStatus(err), "Failed to create event", err)
+		return
+	}
+
+	s.metrics.IncEventCreated()
+
+	apiEvent := s.convertToAPIEvent(event)
+	w.Header().Set("ETag", eventETag(event.Version))
+	s.sendJSON(w, http.StatusCreated, QuickEventResponse{Request: createReq, Event: &apiEvent})
+}
+
+// quickAddLocation возвращает часовой пояс для относительных дат: из запроса,
+// из рабочего времени пользователя или UTC.
+func (s *Server) quickAddLocation(r *http.Request, req QuickEventRequest) (*time.Location, error) {
+	if req.TimeZone != nil {
+		return time.LoadLocation(*req.TimeZone)
+	}
+
+	hours, err := s.app.GetWorkingHours(r.Context(), req.UserId)
+	if errors.Is(err, models.ErrWorkingHoursNotFound) {
+		return time.UTC, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return time.LoadLocation(hours.TimeZone)
+}
@@ -0,0 +1,221 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/google/uuid"
+)
+
+// maxResourceNameLength совпадает с ограничением в openapi.yaml
+const maxResourceNameLength = 255
+
+// ListResources возвращает список ресурсов
+// (GET /resources)
+func (s *Server) ListResources(w http.ResponseWriter, r *http.Request, params ListResourcesParams) {
+	var filter models.ResourceFilter
+	if params.Kind != nil {
+		filter.Kind = models.ResourceKind(*params.Kind)
+		if !filter.Kind.Valid() {
+			s.sendError(w, http.StatusBadRequest, "Validation failed", models.ErrInvalidResource)
+			return
+		}
+	}
+	if params.MinCapacity != nil {
+		filter.MinCapacity = *params.MinCapacity
+	}
+
+	resources, err := s.app.ListResources(r.Context(), filter)
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to list resources", err)
+		return
+	}
+
+	apiResources := make([]Resource, len(resources))
+	for i, resource := range resources {
+		apiResources[i] = convertToAPIResource(resource)
+	}
+
+	s.sendJSON(w, http.StatusOK, apiResources)
+}
+
+// CreateResource создает ресурс
+// (POST /resources)
+func (s *Server) CreateResource(w http.ResponseWriter, r *http.Request) {
+	var req ResourceRequest
+	if err := s.decodeJSON(w, r, &req); err != nil {
+		s.sendBodyError(w, err)
+		return
+	}
+
+	if err := validateResourceRequest(req); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	resource := convertToModelResource(uuid.New().String(), req)
+	if err := s.app.CreateResource(r.Context(), resource); err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to create resource", err)
+		return
+	}
+
+	s.sendJSON(w, http.StatusCreated, convertToAPIResource(resource))
+}
+
+// GetResource возвращает ресурс по ID
+// (GET /resources/{id})
+func (s *Server) GetResource(w http.ResponseWriter, r *http.Request, id string) {
+	resource, err := s.app.GetResource(r.Context(), id)
+	if err != nil {
+		s.sendResourceError(w, "Failed to get resource", err)
+		return
+	}
+
+	s.sendJSON(w, http.StatusOK, convertToAPIResource(resource))
+}
+
+// UpdateResource обновляет ресурс
+// (PUT /resources/{id})
+func (s *Server) UpdateResource(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	var req ResourceRequest
+	if err := s.decodeJSON(w, r, &req); err != nil {
+		s.sendBodyError(w, err)
+		return
+	}
+
+	if err := validateResourceRequest(req); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	existing, err := s.app.GetResource(ctx, id)
+	if err != nil {
+		s.sendResourceError(w, "Failed to get resource", err)
+		return
+	}
+
+	resource := convertToModelResource(id, req)
+	resource.CreatedAt = existing.CreatedAt
+	if err := s.app.UpdateResource(ctx, resource); err != nil {
+		s.sendResourceError(w, "Failed to update resource", err)
+		return
+	}
+
+	s.sendJSON(w, http.StatusOK, convertToAPIResource(resource))
+}
+
+// DeleteResource удаляет ресурс, не забронированный активными событиями
+// (DELETE /resources/{id})
+func (s *Server) DeleteResource(w http.ResponseWriter, r *http.Request, id string) {
+	if err := s.app.DeleteResource(r.Context(), id); err != nil {
+		s.sendResourceError(w, "Failed to delete resource", err)
+		return
+	}
+
+	success := true
+	message := "Resource deleted"
+	s.sendJSON(w, http.StatusOK, SuccessResponse{Success: &success, Message: &message})
+}
+
+// GetResourceAvailability возвращает бронирования ресурса и свободные промежутки за интервал
+// (GET /resources/{id}/availability)
+func (s *Server) GetResourceAvailability(w http.ResponseWriter, r *http.Request, id string,
+	params GetResourceAvailabilityParams,
+) {
+	if !params.To.After(params.From) {
+		s.sendError(w, http.StatusBadRequest, "Validation failed", errors.New("to must be after from"))
+		return
+	}
+
+	bookings, free, err := s.app.ResourceAvailability(r.Context(), id, params.From, params.To)
+	if err != nil {
+		s.sendResourceError(w, "Failed to get resource availability", err)
+		return
+	}
+
+	availability := ResourceAvailability{
+		ResourceId: id,
+		Busy:       make([]Booking, len(bookings)),
+		Free:       make([]TimeSlot, len(free)),
+	}
+	for i, booking := range bookings {
+		availability.Busy[i] = Booking{
+			EventId:   booking.EventID,
+			StartTime: booking.StartTime,
+			EndTime:   booking.EndTime,
+		}
+	}
+	for i, slot := range free {
+		availability.Free[i] = TimeSlot{Start: slot.Start, End: slot.End}
+	}
+
+	s.sendJSON(w, http.StatusOK, availability)
+}
+
+// sendResourceError отправляет ошибку операции с ресурсом с соответствующим статусом
+func (s *Server) sendResourceError(w http.ResponseWriter, message string, err error) {
+	switch {
+	case errors.Is(err, models.ErrResourceNotFound):
+		s.sendError(w, http.StatusNotFound, "Resource not found", err)
+	case errors.Is(err, models.ErrResourceInUse):
+		s.sendError(w, http.StatusConflict, "Resource is booked by events", err)
+	case errors.Is(err, models.ErrInvalidResource):
+		s.sendError(w, http.StatusBadRequest, "Invalid resource", err)
+	default:
+		s.sendError(w, http.StatusInternalServerError, message, err)
+	}
+}
+
+// validateResourceRequest валидирует запрос на создание или обновление ресурса
+func validateResourceRequest(req ResourceRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return errors.New("name is required")
+	}
+	if utf8.RuneCountInString(req.Name) > maxResourceNameLength {
+		return errors.New("name is too long")
+	}
+	if !models.ResourceKind(req.Kind).Valid() {
+		return errors.New("unknown resource kind")
+	}
+	if req.Capacity != nil && *req.Capacity < 0 {
+		return errors.New("capacity must not be negative")
+	}
+	return nil
+}
+
+// convertToModelResource преобразует тело запроса во внутреннюю модель ресурса
+func convertToModelResource(id string, req ResourceRequest) *models.Resource {
+	resource := &models.Resource{
+		ID:   id,
+		Name: strings.TrimSpace(req.Name),
+		Kind: models.ResourceKind(req.Kind),
+	}
+	if req.Capacity != nil {
+		resource.Capacity = *req.Capacity
+	}
+	if req.Description != nil {
+		resource.Description = *req.Description
+	}
+	return resource
+}
+
+// convertToAPIResource преобразует внутреннюю модель ресурса в API модель
+func convertToAPIResource(resource *models.Resource) Resource {
+	apiResource := Resource{
+		Id:        resource.ID,
+		Name:      resource.Name,
+		Kind:      ResourceKind(resource.Kind),
+		Capacity:  resource.Capacity,
+		CreatedAt: resource.CreatedAt,
+	}
+	if resource.Description != "" {
+		description := resource.Description
+		apiResource.Description = &description
+	}
+	return apiResource
+}
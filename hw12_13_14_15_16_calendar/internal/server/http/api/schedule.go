@@ -0,0 +1,272 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+)
+
+// maxOutOfOfficeReasonLength совпадает с ограничением в openapi.yaml
+const maxOutOfOfficeReasonLength = 255
+
+// endOfTime — верхняя граница выборки периодов отсутствия без параметра to
+var endOfTime = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// weekdays сопоставляет дни недели API с time.Weekday
+var weekdays = map[Weekday]time.Weekday{
+	Monday:    time.Monday,
+	Tuesday:   time.Tuesday,
+	Wednesday: time.Wednesday,
+	Thursday:  time.Thursday,
+	Friday:    time.Friday,
+	Saturday:  time.Saturday,
+	Sunday:    time.Sunday,
+}
+
+// GetWorkingHours возвращает рабочее время пользователя
+// (GET /users/{userId}/working-hours)
+func (s *Server) GetWorkingHours(w http.ResponseWriter, r *http.Request, userId string) {
+	hours, err := s.app.GetWorkingHours(r.Context(), userId)
+	if err != nil {
+		s.sendScheduleError(w, "Failed to get working hours", err)
+		return
+	}
+
+	s.sendJSON(w, http.StatusOK, convertToAPIWorkingHours(hours))
+}
+
+// SetWorkingHours задает рабочее время пользователя
+// (PUT /users/{userId}/working-hours)
+func (s *Server) SetWorkingHours(w http.ResponseWriter, r *http.Request, userId string) {
+	var req WorkingHoursRequest
+	if err := s.decodeJSON(w, r, &req); err != nil {
+		s.sendBodyError(w, err)
+		return
+	}
+
+	hours, err := convertToModelWorkingHours(userId, req)
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	if err := s.app.SetWorkingHours(r.Context(), hours); err != nil {
+		s.sendScheduleError(w, "Failed to set working hours", err)
+		return
+	}
+
+	s.sendJSON(w, http.StatusOK, convertToAPIWorkingHours(hours))
+}
+
+// ListOutOfOffice возвращает периоды отсутствия пользователя
+// (GET /users/{userId}/out-of-office)
+func (s *Server) ListOutOfOffice(w http.ResponseWriter, r *http.Request, userId string, params ListOutOfOfficeParams) {
+	from, to := time.Now(), endOfTime
+	if params.From != nil {
+		from = *params.From
+	}
+	if params.To != nil {
+		to = *params.To
+	}
+	if !to.After(from) {
+		s.sendError(w, http.StatusBadRequest, "Validation failed", errors.New("to must be after from"))
+		return
+	}
+
+	periods, err := s.app.ListOutOfOffice(r.Context(), userId, from, to)
+	if err != nil {
+		s.sendScheduleError(w, "Failed to list out-of-office periods", err)
+		return
+	}
+
+	apiPeriods := make([]OutOfOffice, len(periods))
+	for i, period := range periods {
+		apiPeriods[i] = convertToAPIOutOfOffice(period)
+	}
+
+	s.sendJSON(w, http.StatusOK, apiPeriods)
+}
+
+// AddOutOfOffice добавляет период отсутствия пользователя
+// (POST /users/{userId}/out-of-office)
+func (s *Server) AddOutOfOffice(w http.ResponseWriter, r *http.Request, userId string) {
+	var req OutOfOfficeRequest
+	if err := s.decodeJSON(w, r, &req); err != nil {
+		s.sendBodyError(w, err)
+		return
+	}
+
+	period := &models.OutOfOffice{
+		UserID:    userId,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+	}
+	if req.Reason != nil {
+		if utf8.RuneCountInString(*req.Reason) > maxOutOfOfficeReasonLength {
+			s.sendError(w, http.StatusBadRequest, "Validation failed",
+				fmt.Errorf("reason is longer than %d characters", maxOutOfOfficeReasonLength))
+			return
+		}
+		period.Reason = *req.Reason
+	}
+
+	if err := s.app.AddOutOfOffice(r.Context(), period); err != nil {
+		s.sendScheduleError(w, "Failed to add out-of-office period", err)
+		return
+	}
+
+	s.sendJSON(w, http.StatusCreated, convertToAPIOutOfOffice(period))
+}
+
+// DeleteOutOfOffice удаляет период отсутствия пользователя
+// (DELETE /users/{userId}/out-of-office/{id})
+func (s *Server) DeleteOutOfOffice(w http.ResponseWriter, r *http.Request, userId string, id string) {
+	if err := s.app.DeleteOutOfOffice(r.Context(), userId, id); err != nil {
+		s.sendScheduleError(w, "Failed to delete out-of-office period", err)
+		return
+	}
+
+	success := true
+	message := "Out-of-office period deleted"
+	s.sendJSON(w, http.StatusOK, SuccessResponse{Success: &success, Message: &message})
+}
+
+// GetFreeBusy возвращает занятые и свободные интервалы пользователя
+// (GET /users/{userId}/freebusy)
+func (s *Server) GetFreeBusy(w http.ResponseWriter, r *http.Request, userId string, params GetFreeBusyParams) {
+	if !params.To.After(params.From) {
+		s.sendError(w, http.StatusBadRequest, "Validation failed", errors.New("to must be after from"))
+		return
+	}
+
+	busy, free, err := s.app.FreeBusy(r.Context(), userId, params.From, params.To)
+	if err != nil {
+		s.sendScheduleError(w, "Failed to get free/busy", err)
+		return
+	}
+
+	freeBusy := FreeBusy{
+		UserId: userId,
+		Busy:   make([]BusySlot, len(busy)),
+		Free:   make([]TimeSlot, len(free)),
+	}
+	for i, slot := range busy {
+		freeBusy.Busy[i] = BusySlot{
+			Start: slot.Start,
+			End:   slot.End,
+			Kind:  BusySlotKind(slot.Kind),
+			Id:    slot.ID,
+		}
+	}
+	for i, slot := range free {
+		freeBusy.Free[i] = TimeSlot{Start: slot.Start, End: slot.End}
+	}
+
+	s.sendJSON(w, http.StatusOK, freeBusy)
+}
+
+// sendScheduleError отправляет ошибку операции с рабочим временем с соответствующим статусом
+func (s *Server) sendScheduleError(w http.ResponseWriter, message string, err error) {
+	switch {
+	case errors.Is(err, models.ErrWorkingHoursNotFound):
+		s.sendError(w, http.StatusNotFound, "Working hours are not set", err)
+	case errors.Is(err, models.ErrOutOfOfficeNotFound):
+		s.sendError(w, http.StatusNotFound, "Out-of-office period not found", err)
+	case errors.Is(err, models.ErrInvalidSchedule):
+		s.sendError(w, http.StatusBadRequest, "Validation failed", err)
+	default:
+		s.sendError(w, http.StatusInternalServerError, message, err)
+	}
+}
+
+// convertToModelWorkingHours преобразует тело запроса во внутреннюю модель рабочего времени
+func convertToModelWorkingHours(userID string, req WorkingHoursRequest) (*models.WorkingHours, error) {
+	hours := &models.WorkingHours{
+		UserID:  userID,
+		Windows: make([]models.WorkingWindow, len(req.Windows)),
+	}
+	if req.TimeZone != nil {
+		hours.TimeZone = *req.TimeZone
+	}
+	if req.DeferReminders != nil {
+		hours.DeferReminders = *req.DeferReminders
+	}
+
+	for i, window := range req.Windows {
+		weekday, ok := weekdays[window.Weekday]
+		if !ok {
+			return nil, fmt.Errorf("unknown weekday %q", window.Weekday)
+		}
+		start, err := parseClock(window.Start)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseClock(window.End)
+		if err != nil {
+			return nil, err
+		}
+		hours.Windows[i] = models.WorkingWindow{Weekday: weekday, Start: start, End: end}
+	}
+
+	return hours, nil
+}
+
+// convertToAPIWorkingHours преобразует внутреннюю модель рабочего времени в API модель
+func convertToAPIWorkingHours(hours *models.WorkingHours) WorkingHours {
+	apiHours := WorkingHours{
+		UserId:         hours.UserID,
+		TimeZone:       hours.TimeZone,
+		DeferReminders: hours.DeferReminders,
+		Windows:        make([]WorkingWindow, len(hours.Windows)),
+	}
+	for i, window := range hours.Windows {
+		apiHours.Windows[i] = WorkingWindow{
+			// Значения Weekday в API совпадают с английскими названиями дней в нижнем регистре
+			Weekday: Weekday(strings.ToLower(window.Weekday.String())),
+			Start:   formatClock(window.Start),
+			End:     formatClock(window.End),
+		}
+	}
+	return apiHours
+}
+
+// convertToAPIOutOfOffice преобразует период отсутствия в API модель
+func convertToAPIOutOfOffice(period *models.OutOfOffice) OutOfOffice {
+	apiPeriod := OutOfOffice{
+		Id:        period.ID,
+		UserId:    period.UserID,
+		StartTime: period.StartTime,
+		EndTime:   period.EndTime,
+		CreatedAt: period.CreatedAt,
+	}
+	if period.Reason != "" {
+		reason := period.Reason
+		apiPeriod.Reason = &reason
+	}
+	return apiPeriod
+}
+
+// parseClock разбирает время суток в формате ЧЧ:ММ; допускается 24:00 как конец дня
+func parseClock(clock string) (time.Duration, error) {
+	var hours, minutes int
+	if len(clock) != 5 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", clock)
+	}
+	if _, err := fmt.Sscanf(clock, "%02d:%02d", &hours, &minutes); err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", clock)
+	}
+	if minutes < 0 || minutes > 59 || hours < 0 || hours > 24 || (hours == 24 && minutes != 0) {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", clock)
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute, nil
+}
+
+// formatClock форматирует смещение от полуночи как ЧЧ:ММ
+func formatClock(offset time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(offset.Hours()), int(offset.Minutes())%60)
+}
@@ -18,25 +18,144 @@ import (
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+xX3WobRxR+lWXay62lNCmEvWtitzWUEtL0Khgz1h7bG7Qzm5mRwJgF/bQkEIMhN71q",
-	"2uAXUFwLyz+SX+HMG5WZWf2sNU7sxsQh+MbW7uye8835zvfN2W1S42nGGTAlSbRNBMiMMwn24gGNH8Pz",
-	"BkhlrmqcKWD2J82yelKjKuGs8kxyZu7J2iak1Pz6WsA6ichXlWnoiluVlSUhuHhcJCF5nockBlkTSWaC",
-	"kYjgX9jHfezrFg71KzwK8BB7eKZbONJtkodkmSkQjNZtpE+I6zUOdVd3dAv7OMSh3tW7AY70SxzgOzzG",
-	"XqDbFrXD3jNQf+HqB95g8SdE+Q/2dVt3dUu3Axxi3/zp4REeGNTEvFDEMqkeCqAKlprA1AzPmeAZCJW4",
-	"HijF3z6f7g2e4UC3sYdDHGDf1GCE7/Qr3cGB3iUhUVsZkIhIJRK2YWoCLF5VSQqeYK9tbU9tXfEYRzjU",
-	"L1xkvTsfeZ2LlCoSkZgq+MaG9KRjXCXrW6trsM6FL+efjjiT7UTvmP+WRzzWXRziQaC7hlA8wBGe4kB3",
-	"9E7gHpmAwcE0b8IUbIAwiaWiQl1ip0Ps2V2eOCD/Z48qUfUL9ob/mo3hCPdNSS9BT0OCWE3i+WjLiwGe",
-	"FVU6tAF7uoN9PPHFyUMi4HkjERCT6GkBsFSTmUaYJl2ZBOJrz6CmDKByy8+1Z43H9u48ATD2h7k9piAl",
-	"3QDPWu4D0Cxk+0Xpwkcx7lm0x6Yb9c7YfgfOO2z8392y7uBIty6xqVv1fQbqS2ISfpQEf23UaiDlxSK8",
-	"WFAhke7lmbU1zutAmV9tv2Xx7ZF0K4rP+EgykRK2zufx/PTkyaPg+0fLAR4YDNOB0LZj4IzVTo8H2NMt",
-	"h9IVijykdWAxFeZ9EpImCOmC3lmoLlRNGXgGjGYJicjdherCXRKSjKpNK4gKNMfz+wZYwRi52AFzOSYR",
-	"+TmRask9EpYn/G+r1SsNp4mCVH5wSrUn5lTdVAi65Z1W93Qbz7CvX44Pm5Hu2AG6Y/sisMuDQgyn5R4/",
-	"Mhm+c/h9aCb7rJS/GOwM3EhTKrYMhL9tA3X1i0JKk5SmOXHfcviHJ3PGpafSMxM1cQ0HUj3g8da1fQJ4",
-	"Zva83NxKNCCf4/nO9X2EOHo9dL6dKVI/0N0pu2ODOjStby5NCe9dhryZb9Br4fvtGIUzzmFhROVzAvv2",
-	"tUJXle0kzp3c66BgnvVFe3/MekYFTUGBkCR66vGsOcdLzIIRMwkJo6l17ZicpzScoee8t618pKzfR/f5",
-	"0//qxHct6c75CuLvfZjFycfztdC+V2CYiLzEdej3zR9BfaGkXqzh91ryucLh6Q2x6THt2QY0U0GwvGht",
-	"uuHhdWbIvEFqr/9s8AzPlzobqjd9Npi1wopLNnHl8+EGevHNBLrfW+zjIJr+/lqEJtR5lgJTgXvKDJ+i",
-	"TiKyqVQWVSp1XqP1TS5VdL96v1oxI2C+kv8XAAD//7J6Odi4FQAA",
+	"H4sIAAAAAAAC/+x9627b2LnoqxBsfyQ4lC3nMmdGwfnhTJIZn5NMchwHLXacbTASFbORSA1JJXG9Dfgy",
+	"00yRIG6DAbpRTKeTFtjdfzYgO9ZEsS35FRZfYT/JxvettchFcvFi2ZaV1H8SSSbX5Vvf/baW1ardbNmW",
+	"YXmuWllWFw29Zjj48fqc/gj+rxlu1TFbnmlbakUlr0nXX/XXSM/fVPw1MiBb/gt/Hb+SHbIHv274a2SP",
+	"DMg26fsv/G8V8o50yIG/Sgb4wrZybqZeuqV71cXzqqa61UWjqcNM3lLLUCuq6zmm9UhdWdHUWd0zbppN",
+	"08N/JKv5iXTIO7IPa1LILhn4q+Qd6cG8yVnJLtkjPdIlfX+ddBTSh3/2Scdf9b/zV/0Nf510ZesxLc94",
+	"ZDjRBc0aTd20YJ3JRf2ZDGAm/znp+mv+Otkmg8RqNIDWAP7sb5ADDqgt0iXvFDIgP5Me2SEd0gfAHmJR",
+	"riGD0j8APji0vwZQInv+S/gfvnbJrr9B+mQnAj/SSaxYIQe4rz5b9kv/lQJb89fgK64Vjrznr/trBdZs",
+	"eM7SdN0znOHXu4+A6pMBXdq2v44bgBW8jCw/ezErmtrSHb1peAzzZ+qInMmFAUnEkF5jYPE34LhJH06S",
+	"vFf8VQRIl3SvKKRHURS/9wEDFbLtv+Dg9DdJl8JM8deFvQLyABpFJoR3+/APjudvAp35a/5LVVNNWCGl",
+	"X1VTLb0Ju+SElklnK5rqGG7LtlwD939Vr80aX7cNF3GpalueYeFHvdVqmFUdoDH5GxdAsiwM+0vHqKsV",
+	"9ReTIUuZpH91J687ju3MsknolLEz/wvpkm08dwrByPmtaOrntlVvmNVRLuk1IuG+hNH16MGsIoFvAD9U",
+	"/A3yM+nSZff9TX/dfwHLnrE8w7H0Bs42yrWTPnA0f5Uh5SYse+B/R3pki+ySDpKSv8pA3oGlfmV7N+y2",
+	"VRvhKn8SAEjxuk865D3ZgVWzNc00Ww2jaVieURsp/PaQu3QpCwbYrYMA8V9ROgcBgyJlQPr+N6RH3sI2",
+	"SMf/HemRHiz9jr7UsPXanG3f1J1HxgjX/jfShdVHOXiHYy3ZFrcGMD9ALNn2X/jfkQ4wI5RN5AAEOTKd",
+	"/ZCnMWGLG3SMqm3VTJj2hm42Rno+b6I8cct/Qbcc5bUDTSHbgsICf1Y4T1TY/jq4/T1KBP+/bXv69WdV",
+	"w6iNdD9/Fc6gS+XvLkrXdUqr4W7fc4nzkrxDidsBxQU0L1j/nG3f0q0lxr/d09uBgsrWPkhjxX9OOgjq",
+	"AbDFhGahaqLeGWgzpUDpky2MvTEZUxFFdagUUdIKDRK+ERuIKVYFB4GnuZJTCrSczJdDfQiBe89y262W",
+	"7XhG7ZZRM/U5lNkjlcaAZTvAiv1V1Ee3gTlwXvANqlqgPa8DPnb8dTjkQDICoh7EWA0g6ArXQ/Cop53q",
+	"ovnEmDVcz3bgv3YDd9Zy7JbheCZVRxz6Z6RGvUb5jd64E3kmrtRpSZWyR60Smc66xzW3wFjBp7uM1th2",
+	"SIfsqxqfyn74G6OKp+w+Nlut41seEgYy398fYVmo033dNhFu90MYhst9INnKtOfp1cWmYUmOobpoVB+7",
+	"7WZSLb775XTpwuVPKJsK8QXU87cIcGXReKZqcb1T48i84DHkjg57a+bW9RLDJPnQ0jEdQ/eM2oIuM4RC",
+	"jQ4l3BbpBKdP7ay67TThTbWme0bJM5uGbA7jCazarCVnmLkW0xVlr8teJH+HNYBmhpyd6cA9qgnhUN/Q",
+	"P1MjJ0laknmoDZCY6d+pSvsNalp7IdGi7vUOyZwqBkglIFd3SU82vmv+1sixydNRAoH/HsXvtyLgTcv7",
+	"5JKqSUim7TSSs92bvamFplJCt/E34SfRxAr3NCDvk7uK0Y1ZU4XjZiCNIFk2Gd00rcdJUko5mL9kwF9j",
+	"JL+BMNzzn9On/FcA4g7ZV+7N3pSdkRRo5A9kC08G1Nl1hmyLntc6556XDxQDC4wq3Xi7ZnrXLc9ZSm5a",
+	"r9LZl5PqKsqKmJWMKG1YwG/uM3irmtpu1eiHFrNqa0bDwB8Yh4M/tUHdfhDfg6Y+K8F4pSe6AwfgwsC4",
+	"4M/56PjtXqsmfLvD5sEv1/hk+G02mJE+Sadd0WCntiMlPNgXGAgBFcs2nThDnSsQcvHiOW0jTz8+QF1r",
+	"j3QzpwwP8qFRh60dac4dMig4W1GeLRusGMeumfX64Xbzf+/e/kq5ZTiPDAVxAC1AhcJFIbsKPRTJZjKF",
+	"Q2wLfcYPRyQzQh1Cys8dajSkrj1iToJlRU3CfZziW+o/xJkCLh96uag5JvGFHZoFU+rSOD+JLDuXM1+F",
+	"o7zdMhydM6Mok8J58tRnyiGuw6PcT5ZyQgl1gPvIKR9TSE8JGFjiOOwWriiVBbI3izE63HjA6PBbwOjw",
+	"G+NtwL+eGI4rZ9U/Bo7pLur+m1HzutheNQVlVwdF9VtFcFHmKgEx1LBb6YcseDCjR9y0a0z41nW0N1Td",
+	"s5tmVY1zAPqz8t+r3wMmr1HeyRQldO4DkmvKQ8A9o163HY8/G2I980XT1zEwckC6/ndIq10F9Hzqf2Nu",
+	"o1DiBWsShj/MUU/z9/HbVcP1rrMxELMYAbhSLQT8KdTDAKa84hrOE8OZaOrPFh7CYAvh68q5NK1kqlw+",
+	"H98fqFumZzTdPAKLUemKpjZNa4a+ORXAQHccfUmCE8HeMnCDmb5JI8duNk2PuRpjgPkj6mI9JXDK+98G",
+	"bn70BybEE1rGZBeUT9IJ0fmhbTcM3aIsF2xenLo4ZJihvJIDiXAv4TxZIJEa3wb3W8uNoFxPAz4E3NGq",
+	"Gc+k2Dbg4bsYMaCRIIqcrtQqcD3da0vw+Mu5uTsl5t9bB8rTBAFFJSUPN2yQPfQgKmir9DDC9VwShlDO",
+	"XbpwidJ4ZK3cPRyefQcG32fhP1DkRUwYkP3z+byNAizYn/TgbPsxc27FDs2qLaAmVFk+fotWozDZQjEP",
+	"aL5KvZBkGx1/qM4IgRHZbK6nO96hVhgDjqAOCGNp4cal4Gq7S3cbtieFV3FQFZTzTFRQNOkhZnUQTRAw",
+	"6ywwnKLtPTatmsyAYE7U51TvE4JNNCYbkSCUPjXVbnsLdn3BrtfNamFtoe0uXWfvw+fbbe92/TYbgR/g",
+	"kGdH38WzUtlOEaiyI6MKC67kql1Do1K3lm7X1cr9bL4jvBiqaNmvzBnNViPx0oPU6Mqd23fnlEmEsnuF",
+	"kzhTiLsYeRuQfcVjoyLbkE8R2Waq2lLVPeORTQ3rRL4BDQG8ZeHvzaQ90dSf3TSsR96iWpkql6WuuIbU",
+	"Zv0P5JLrCfTejriASVf5xezsF19cvUpNc4h5qhX1X39xv1z6TC/Vp0s3Hix/svJLqWkmTijROKnBEvpE",
+	"8iwlkfmlm5IDFrrjGosEZsXYgWV7Zn1pITSYY3P+icZvUrMY/A2AMLoj97m6GDUISU8iLTS15Zi2Y3pL",
+	"eSL4Dn+Oqht226kaC2bNTbfxkqyde6FFxk4zeRiDQ+RDz/oqfVQDEG/Bd3+D7ATD9PzN86IWmABnU3/G",
+	"Fb1yXL+JC4700+2TDp7sHukMe66e/siVOqtgq73kqMGWBFK7XNaydnhBskPP9BopaIQgpnlVA7JbgBLa",
+	"ruGkGvNpMcQ89k0XmCZ3w0ll7Dwad5Io39QyS+J6uhbaSEkMi8dTmTZHQ93gINnTEE3wlx5XQfdBSQNj",
+	"Fj+hU4UMgOs8D+MzMZtGIduicidXUpuG6+qPDHmSWxJMTwzrn0sCNAzms8mkas5q+iyhIbmiSArgRnHv",
+	"4Ictgo7DN5i/qTNBdyboPmpBl+X4TM961jhi04xflibBoo1UuihIhj9T1E+6wHtDeD7R9C0qisONPUiT",
+	"NtwyOW6pc5JSZtjQP07xLpJefTpiotZ29JTBvkd0oqj6Mky5loFH4LEslp5kocciISA5cAvpsk86sv0U",
+	"DWvnD/QhiZqh+WeSE8tY5EKgTB2aVTKnxIDsRLKddtFF0VOW4bxWJhTyGv6MIpBryl35e72KsgxMZQW9",
+	"GTPX5q007qopy0BKKxD1WQZ6oq8gimLxBbh3pWLsikKRXUBLllUN777ijHVbeaI7pv6wYbgT85ZUKLRq",
+	"RbhBGBonO6TPHJhDR5plbJrljERPU6D+COOKrDuXXZ95i8aVBzdNy2y2m2K8SuAzw3JK4WAuXL58epwz",
+	"2F351LnoMWihJ81i8w8uxjbyOIaML9xwDANc9Ule8JD9WizAyIMkEnlUdwyj8EBzZtNIG0jQ13PyzAIN",
+	"FjfBliDbPqTr3MlJ7qg7drNIVIWmLSQcPU37CWYxVO3WUn6+hl6jcVd4Cz+0GnoVPrEf+CjAvosFZXB7",
+	"0zjsHRqyZUOxb3wC/HpL+NPndCr8PIfzYcmbJ6lww6ynOzYQs8MqJFHCJ3ipxHs2ye2RpF2lN9pGSvB3",
+	"Gx1s5B3TAYKiFHoIeq2mKQx2AHsElyQNhO1Hhhli7CopJyMmQ8FY6aGjq1JUhz3oLG3/GCKkh6AqJKiQ",
+	"tNLMx5xUKgGuqWrI4UEVwkTi0KUGdj89kJorH48ceS4ebkZyi8f04ptCObypJfbjb/iv/N+zWtFYGoTG",
+	"SuUCHdXf5HrxFcVqNxoKeqI6PO8ZH0fa3YSBRI2GK/p76Lc+SRUS1gXaOk+2PEmVMj7XaFXM3J1+nAHK",
+	"lG2f+XE/Mj9uyjmPdwAzyZwFXJQJGt5IQMJ6hBSbhv0UMB+OpwFFjOYjyGKFOgCrsFLHFnITh+LfvuJD",
+	"8h++pEPzr/fYFFiwalYfZ6eOeMYz79CcTKLgzcM7W8AL0Oz5A0tgZ0ly2+hDgsw3ZepipVym6uOU/5wN",
+	"hPyjL3RnUKYuQ8S1R/rzalRiXJY6HQDxF35rW4a0Y0SHEjhdD/h0Nv01ZWb6q+lQn4dSFxShglWPXIK6",
+	"pSQ7vt4GQE7est0qns+IQuxwXtmRdPHU08Lph0vQdELsOWxiVaLWkP4uW/cs4+cyj1VLr8op8nWQSokV",
+	"ZMwNI2Pk0ZouQfaMWWhAlEqdEwvp5s7C0wyzDpyf2P+DZw/husqZPMNRylICA4TItUT4Eqef6GZDf2g2",
+	"GBodxQ3CUmtP0gsi6Db5Npv4cL43JHJoaRV3iSPiYs2x7SZ8/bpttprFRRmfdJa+zr9eD4cRVpbhuT4+",
+	"PpDtpjxumh0lNQ3lT8QFytDlbrtaNVw3XZSk5y9pqktfFv4W1DfINC9pDmxlOaeWEPp2RL3hV4KgUDRm",
+	"tSdmCg3EhC9qJsdcTf5mbNwTtoPPEm/jdm16ZbNgFWGLL6hWXkN1Th6XiZ/jWQLvmT08xnlNQokQ4EtA",
+	"1pFT46W0SCICgmN248klQvHYeoGmJikMBXYc5bOSONmAF7GEkNkVhWtKN5OIEzbLTArUr6OV/BxXuYts",
+	"iZJi4rNo/lntx5noOBMdH1Xtx68M43FNX5Lm2YD/4iUNU+3QTFrBGm3aFrynqV7bcOmnp0bN4p+9xbbD",
+	"PtYdk35wda/tsI9tfLuYBXuLTzUXTPUrYaq5cKobfKq74VR32VSwWdsB98GXdttxk+y8ZtQNZ8ExmlDj",
+	"60gtp5i38xCxXk19alo1+2nxmnK22F/ha7lV5WHcOFxhOKeW2JwUFwTwpAo9CZQS0VOxHoTxzz5yqdWg",
+	"hLsrup8xeMzVEXBPY73mFj7cpX15GR+RVusP6YFOt3DuzX0OmP5Mhx6nakXN9TcLZyvpukU3wgzmvr/O",
+	"OryCv/DFFSVo6AkL9NeYcEUVbBAYxWH/z7A3dehq2VbACIN54sAaDtNCfni5nIN3fOcZ+MTGTdP1JF26",
+	"AVl+F0UClv4ZgR7pKOfIP8g/KuQH8gMPvx8g1KDn74VLlXL5fOQgpz6tlMtRfejcufvlqQegFD34twv3",
+	"y6WLD85X7pdLl/lPOMgvM9XPpJcoMI8Pt4foYsufJRebtVbpIp+G/D0TBdhjieNlv2s5CvMK9pKo2/KG",
+	"D8r0nZmgDT1vcNyj3W/Rb47cYgd7vm9OzFvzFqVb2ppU2vR8QN6yLh+S8g46k1DegZCP9pk/9+vSPddw",
+	"SjPXeFOAmTvnMQE62nu+MzFvkR8xmaWLbVIjvfM61HMVtZR6SqxZqqZImp8qkeewOekVyNympSkHsULJ",
+	"HutwQjVS2skCEB2IveP/Xtj9pQufYRwwvioochE6n1Iw/5mCiMYsBnyX0T32knsE7vTrEpMRpZlrFUmm",
+	"GPgGE72pgo6AQOSDeYvSLLqO0KwGbj914VOFFXtu8xnpcPenS/+il35bLn1WWpioPNAU0uOzwhtvcfKu",
+	"vxpXk8l75d69mWsTCvkvilJhkvwBc1bCrmGan0GTpm2QouwWVo+dkaimGcHhXuy9DirYPYY9QUlQRf1c",
+	"bxhWTXeAIIT6n4o6NVGeKNNsRcPSW6ZaUS9OlCcusgw65JeTOu3LCp8fSS8U+IO/6n8LxCAxBjGF8KZp",
+	"GW5FdpqAMUHPKOxlGnm/o0XalCWsDi3q5oNf5q0k82OiiSKJJkkXI++1eFvlc4i9tMN3h5K1UEx6Xot3",
+	"l3wfPw/SiZyINm/hhwGzj3DMYG+YSC4UFpN94Y8AlwmFvBGJA5WHbti3E5E1saI+v13gLVpfP4f0Cuzl",
+	"B5HjRNGuS/paygUEoaOd94JiCDJhWOAlqlHcCzovzdRAlXkG3YtZh181dsPAhXI5o5nxs5JVSzY0Dky3",
+	"h6alO5IMXEkbYwFPdwRTNikLgCIulS+lSa9g8ZNBk/wVTb1cLue/EL0AYAUDJs0mbADNVn5Q75j+Su+9",
+	"YFQPLfxfIR4iWrxKWXjLdr00s1HEAoV0Qnhsi/pgVwl6WaGIgtbDSKmAqN8iFgJ3g5R2FGORtm/w84SC",
+	"0wVUKzo29jh2MqHDOsvDXnjeYoXy6V68OTNtz4tCVyz9kS1WOiOF3T4+u4N6b5UxxqrXUFjL0BMkjCuR",
+	"pvkC/DGFNaJZdEl/3pK2kIkNDX3YoPOujOhYS1KR6lBy8uY5x09woQ4H6WArh6LzwzUtlzYLzyH6lFbf",
+	"lNoLEK9wE8owDOJS+bP8F4KLTeCFqYsjbPIuACp5BUQK1h0T35NeGzQM91vR1Ekd2u+mKysiY4orsyFX",
+	"GdBE6QHZZiJC4ZdDAONLUNpN0/Ww7a8avT3ofpGO4HhVz9dtA4mK3dQjtjRLvxFriJ7CqNnItOrAKono",
+	"zudT1sfbrh5mcT+ktFOhHnHpDVlc+0lZBW33Iq4iaOOJsY2m/oyV3ZXLZSEBRFKFB629jsSsCrk8hI7Y",
+	"Sc9akiT/HukQGjOVRO0RpW5M+xySrR2dnP8a3IBFyThqpCTxMap4UyJG/HfTqfh10gqVZqzyEhFeP8F1",
+	"hoQ/pKvch4IwTfHsBxMK7EGagxB7DcqY5y0WuN30f8fbXCviT2G3bX+dhntAR6GWyT5teEn6odGW5CzX",
+	"KTByWEvEAZT092QkVghrpU3/O2QnheIARqomUwsyY6AZ7rbDrBQ5BL0KLlx0yko9+zjWmWxiTvbYLXNx",
+	"h0zY2ZdHwgJzLiykZ9FZjGg8azWwxRXNnJfuQX+kajIek9sswPWW0PKHbatS+OcGimULCkLPh+P8BZLo",
+	"ZbMFcU2toJITBjhHw86DdOmjcXKKTFTYUYdZnCWOCSePhgkQ2SEUnFhsign6JqcpZuxqQyH9OnCxJjP/",
+	"KpSmJDecIW+WJRhSn31GwuAWmrMb6ZPKOLWQC19EC4yMV4weOKAyqe9BUTPvcPZDvPdqIXNv6vjMF0pp",
+	"uVe/iU3VyUBEoT4ZDG3oXcx/JXpT3CjNw+wX4vcOwnsXCkwUvznuWPjJG34aPDjLC76jlC+qgJNfQ20L",
+	"zJvCVOj9Qlvoe+d6IGMtu/4Gq1qH43+LvqP3AY8Nru1Evxf/Fapi/RdklxXWRgqA5q159Wbbqi4qT01v",
+	"UZm2LF3x7KbtOPZTVuVUtx1lalGhcWpl6nJzXtUUiaVJF7qKUeIDHnDlUYN5K1lXE0SXaNrcOmtX/jOt",
+	"9wCmAR5ihdZG/B8gxyQ7pd4nf0PCWXm0SVIRRfWeDr2rj3rOqTx4kXDEPRei3vtB1Jt0lSBUTv35OPx+",
+	"5iu5bnwlvdlQmvoIPYfuzX0uY95YQTVdqxVi3zE8lh2jDKtTVCp+C4fEiK3rDddIph6cFI9PFg+O2Kkn",
+	"qWMrwPKT4B8g7Q5o/CMpA45TMB3bkhXSkyx1hOLqo5c+P0WBnujeRLsrhNKDJQsp/OIG/Hc7uKlJlBUR",
+	"ibVs1lYoGeMdOpXlGK+hd+sUVhSlmiH2Nwl4iFlT42SaY6DJwBiuZJLfeX5kMyqLdOJ1RofX7lhjC5r0",
+	"NZzSNXWhABYnrzY+FoT8O1t+T4aK54QGx6zMLdnT+DwshHnIokj2heGdIoadJNqkmwLZ7tIofNGJL9wz",
+	"DJf5593Ki8+srAyDZydii8fv9FNmrqkr/DLEtFYGfeZ/B6gkbrU7N3vjc+V/X/zsE00RD6gJj5Rw3P8F",
+	"h3UechlQe6YdosJ3P/msfCH6LjwvvqopoUoNzWrYJcLdeYvs0j91g7KQ8DbWV/llB8nSCZmiF/bh+TB4",
+	"b1EtTwDxEI4sSb80WeZvFk4UJ+BkK6QRq5lDOhPgb5ErogdHYyFjGXEeWhwOrQ9OXc5/T3r/+bGwVZ7w",
+	"2fOfxw9ZxmeRv7Yl8lbgPh8XYylOVrKLMM8I+5+WsI9Omz/mkGLM5prUg4u2xcixJFNDeO6DVI+L5RoE",
+	"uywUoXodvUFeom+hc27V3yQ7ZJcHQ+N35o+NbizGqWJZsbEDTY1XNdsNz2zpjjcJUdRSTfd0lsIcSaPk",
+	"KbgsTvScCxSlbjaMpC4cgRtryRhe7e5vgCc2cme+cDN/IkdQEVA+SMrC/ERo6QL+TvEBvdGwnxq1BcAF",
+	"F5L+eeYie15YO/WcbWCOFWy5j1WcfSXeQ0O42yIeEqcpyvv+hkwNv9cCdiGg6OnR4fELwtiF/4CsElyK",
+	"jhfrTWw2jGJJl9G6GXxPXiYzulidyHjyGI2MjwxGlpD5oais8NZUob3NQBEXgN6oxZnk9wGY+TXU8YOI",
+	"VRK/ypSwk8vhl5kiDs/TJnZNMk0UBmkTiRsdGzdYEe9pnNSOwWF63F7POBYKDs3kBQz+ZigqmWiXF6MJ",
+	"vlMawRW0FO7Wvzd7UxC8mJIVQ1v7qTUOUmrsEdeueoZXcj3H0JtHr9KRlTkltruiqRfLF6SJoTGU3wz1",
+	"LEQN4chpWzjB0LtpV4PLAtKBc0oO4DdYIvM8SDWM002cV7N6FjF1Qloe8k8XJojldKZUhRzVCTCeOUMn",
+	"WKyxluzRFwlWvRBRtPKQBylS0np+jF3/T9MkNIXehwTBcqpt0PJG2rVeaH2v0GLtHdaSmDYjJn20ZXbp",
+	"kHDVFS0xoXymq+ie3TSrEDb6jvTIFtDbvBW0OHgfvwOkJ9zzzww5jDat+S+FO2ChUDQ6zUPD9RaMet12",
+	"vMQIlEVFysv4auiVJJ3EXSQ0eef7eO25bLlp4pIeJ1S4+WusEDYIncVuIBCjav4GhTEElejqWMl7JA9T",
+	"Zv5dDUIArnoyNthVegvKqbgh2dwZitlfOXag7zHIdFqnljZNcfc3AAfowVDzOvN0hzWaRlpj9jd5pq6k",
+	"4Izs8MpQ2uAVA7rR8skeL+mP3cC8RakHOhjwujVwjSDHWQjwEB0g2zStDEuleOI1dSR1SFfB8iJJU4LY",
+	"0vLuhJ63Tjfv5XXIILnSfRDiX7F6HN4uLNuxOhs8lVAnZKlvrLVzMeSK9sqVlpeFwW1aXIa8clvWJjgl",
+	"F69pWgtCo+mE4jLiajG+5UL+25/Czgj+C97jAB2264m+FCxdgAfhh2YeJ+y5jXd7EF21stz7AF4nI1Li",
+	"zapH7FYLsSH79CNZhEeTCqcQ80mk1gb7ijEiSZpdOky0zHaGIDRALVzHWnze2iBq78BPWuhACduL0RRk",
+	"NIhkXi8BJ3MNrHhb7/E2sIo4oCJoKTifPhRjJ5ahF8HG9MS7j/fMi/KgcYkDCtCNZMilZ3CMwdmNieQa",
+	"PdYkEjHGPRB0AikOOQJvUo/dapLHgyK3oJwKTmuHLFTPrjxPn/cEKtGzSsuPuJBRcOnI2cto748SfQhz",
+	"PyJYwCpTtmkbZ7ITmBDUZgbPFrYs3CW9URHs8YsKmuXgb/rr3EZMQAEuKYvjCKVRXpibbR3PBU+NrDad",
+	"T1nIgvxP4T6BoSzIkzYHY63+MgrPZdbhXFg9fRIyVnoT/2lUSYdnnn3GH5Wd+F18X0J+V4RC883GNyFU",
+	"OKonGwAk0uP6Qng9DEOkGIUCKh6uT8BHYRRGcPCoRuGx23hZmJRu8n28JzoET4lAbBzMwPQzlViFx8YO",
+	"4tdxS5Iga/pYMINxkoanhblntme+EHV0dzG9J9ubCAnwrqz+JsX+ePEqy/yM3L8TuYz+bbzCl5HrHjNT",
+	"UCHFmPRu0DtCwSUuOAZgDyxL1k5tDrfxYfeHEuHCLuRJKMMnrY7nrwGQBm4IcSeX4b+Z2sokXFLK716V",
+	"oxG9x6OHbU5eSHp103yF57SrdpjvEdhv4L6P9grBXiN91nlqmzWFkPj3JxLo8oXh3XAM4yq9YDWPPWfc",
+	"pCNh1BQiZ66UsXalBKcvI9o/CSjXTfOSJK5eGZ++nEmXRxoGZzk/YgRut72SXS/Z9bpZNTL9Ibfb3u36",
+	"bfrcB0hcGW0qxTaf8Ztxxq6pJt7h/05SXJTVic5WR06mhSSuiFNF5G6+sNHCdp54WV+sl6y/lgT2gOyP",
+	"D5Ef5IvTVNJK9ahN12pjRr0nZMcIuzwln14Eo7MxOOV0Y/VVH5yrL1G4dJCz5Xy5VLBH07hLqJlrEWBg",
+	"iagEHB+D0zAXzcfOkzgcmj6ll9eVFvllkWkex8ilkuPCgE8IQSJ7lScRpNzbODZ5KPL1ZYvetjfEzY7B",
+	"NTShaYyRuh0sMdiP3Kua3bQzbW1QboC9pJTYpZzFr9vUeNlGh2xjeQN9UGH3qMmBFTSdyqUsTSwABNsm",
+	"8EGxSUK9nvVn32EFDrTTaIHrE2VVDXfHlCqPXy2SXdk6Yu/u0DwBHT/BnVFHKjg/NZXoT4zEh2UuOByW",
+	"RsgQ85rxxGjYLagbZQUUqqa2nYZaURc9r1WZnGzYVb2xaLte5dPyp+VJvWWqKw9W/mcAQd6b6D/SAAA=",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
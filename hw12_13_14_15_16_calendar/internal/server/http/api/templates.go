@@ -0,0 +1,294 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/google/uuid"
+)
+
+// Ограничения шаблона, совпадающие с описанием в openapi.yaml
+const (
+	maxTemplateNameLength = 255
+	maxTitlePatternLength = 255
+)
+
+// ListTemplates возвращает список шаблонов событий
+// (GET /templates)
+func (s *Server) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := s.app.ListTemplates(r.Context())
+	if err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to list templates", err)
+		return
+	}
+
+	apiTemplates := make([]EventTemplate, len(templates))
+	for i, template := range templates {
+		apiTemplates[i] = convertToAPITemplate(template)
+	}
+
+	s.sendJSON(w, http.StatusOK, apiTemplates)
+}
+
+// CreateTemplate создает шаблон события
+// (POST /templates)
+func (s *Server) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	var req EventTemplateRequest
+	if err := s.decodeJSON(w, r, &req); err != nil {
+		s.sendBodyError(w, err)
+		return
+	}
+
+	if err := validateTemplateRequest(req); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	template := convertToModelTemplate(uuid.New().String(), req)
+	if err := s.app.CreateTemplate(r.Context(), template); err != nil {
+		s.sendError(w, http.StatusInternalServerError, "Failed to create template", err)
+		return
+	}
+
+	s.sendJSON(w, http.StatusCreated, convertToAPITemplate(template))
+}
+
+// GetTemplate возвращает шаблон события по ID
+// (GET /templates/{id})
+func (s *Server) GetTemplate(w http.ResponseWriter, r *http.Request, id string) {
+	template, err := s.app.GetTemplate(r.Context(), id)
+	if err != nil {
+		s.sendTemplateError(w, "Failed to get template", err)
+		return
+	}
+
+	s.sendJSON(w, http.StatusOK, convertToAPITemplate(template))
+}
+
+// UpdateTemplate обновляет шаблон события; созданные из него события не меняются
+// (PUT /templates/{id})
+func (s *Server) UpdateTemplate(w http.ResponseWriter, r *http.Request, id string) {
+	var req EventTemplateRequest
+	if err := s.decodeJSON(w, r, &req); err != nil {
+		s.sendBodyError(w, err)
+		return
+	}
+
+	if err := validateTemplateRequest(req); err != nil {
+		s.sendError(w, http.StatusBadRequest, "Validation failed", err)
+		return
+	}
+
+	template := convertToModelTemplate(id, req)
+	if err := s.app.UpdateTemplate(r.Context(), template); err != nil {
+		s.sendTemplateError(w, "Failed to update template", err)
+		return
+	}
+
+	s.sendJSON(w, http.StatusOK, convertToAPITemplate(template))
+}
+
+// DeleteTemplate удаляет шаблон события
+// (DELETE /templates/{id})
+func (s *Server) DeleteTemplate(w http.ResponseWriter, r *http.Request, id string) {
+	if err := s.app.DeleteTemplate(r.Context(), id); err != nil {
+		s.sendTemplateError(w, "Failed to delete template", err)
+		return
+	}
+
+	success := true
+	message := "Template deleted"
+	s.sendJSON(w, http.StatusOK, SuccessResponse{Success: &success, Message: &message})
+}
+
+// decodeTemplateEventRequest читает TemplateEventRequest и собирает из него и шаблона
+// запрос на создание события. При ошибке ответ уже отправлен.
+func (s *Server) decodeTemplateEventRequest(w http.ResponseWriter, r *http.Request, templateID string,
+) (CreateEventRequest, bool) {
+	var req TemplateEventRequest
+	if err := s.decodeJSON(w, r, &req); err != nil {
+		s.sendBodyError(w, err)
+		return CreateEventRequest{}, false
+	}
+
+	template, err := s.app.GetTemplate(r.Context(), templateID)
+	if err != nil {
+		s.sendTemplateError(w, "Failed to get template", err)
+		return CreateEventRequest{}, false
+	}
+
+	event, err := instantiateTemplate(template, req)
+	if err != nil {
+		s.sendError(w, http.StatusBadRequest, "Validation failed", err)
+		return CreateEventRequest{}, false
+	}
+	return event, true
+}
+
+// instantiateTemplate заполняет запрос на создание события значениями шаблона;
+// заданные в req поля переопределяют их. Заголовок строится по шаблону с
+// подстановками {user}, {date} и {time}, которые можно переопределить в variables.
+func instantiateTemplate(template *models.EventTemplate, req TemplateEventRequest) (CreateEventRequest, error) {
+	event := CreateEventRequest{
+		StartTime:    req.StartTime,
+		UserId:       req.UserId,
+		Description:  req.Description,
+		NotifyBefore: req.NotifyBefore,
+		Tags:         req.Tags,
+		Category:     req.Category,
+		Color:        req.Color,
+		Priority:     req.Priority,
+		ResourceIds:  req.ResourceIds,
+	}
+
+	if req.Title != nil {
+		event.Title = *req.Title
+	} else {
+		vars := map[string]string{
+			"user": req.UserId,
+			"date": req.StartTime.Format(time.DateOnly),
+			"time": req.StartTime.Format("15:04"),
+		}
+		if req.Variables != nil {
+			for name, value := range *req.Variables {
+				vars[name] = value
+			}
+		}
+		title, err := template.Title(vars)
+		if err != nil {
+			return CreateEventRequest{}, err
+		}
+		event.Title = title
+	}
+
+	if req.EndTime != nil {
+		event.EndTime = *req.EndTime
+	} else {
+		event.EndTime = req.StartTime.Add(template.Duration)
+	}
+
+	if event.Description == nil && template.Description != "" {
+		event.Description = stringPtr(template.Description)
+	}
+	if event.NotifyBefore == nil && template.NotifyBefore > 0 {
+		notifyBefore := int(template.NotifyBefore / time.Second)
+		event.NotifyBefore = &notifyBefore
+	}
+	if event.Tags == nil && len(template.Tags) > 0 {
+		tags := append([]string(nil), template.Tags...)
+		event.Tags = &tags
+	}
+	if event.Category == nil && template.Category != "" {
+		event.Category = stringPtr(template.Category)
+	}
+	if event.Color == nil && template.Color != "" {
+		event.Color = stringPtr(template.Color)
+	}
+	if event.Priority == nil && template.Priority != "" {
+		priority := Priority(template.Priority)
+		event.Priority = &priority
+	}
+	return event, nil
+}
+
+// sendTemplateError отправляет ошибку операции с шаблоном с соответствующим статусом
+func (s *Server) sendTemplateError(w http.ResponseWriter, message string, err error) {
+	switch {
+	case errors.Is(err, models.ErrTemplateNotFound):
+		s.sendError(w, http.StatusNotFound, "Template not found", err)
+	case errors.Is(err, models.ErrInvalidTemplate):
+		s.sendError(w, http.StatusBadRequest, "Invalid template", err)
+	default:
+		s.sendError(w, http.StatusInternalServerError, message, err)
+	}
+}
+
+// validateTemplateRequest валидирует запрос на создание или обновление шаблона
+func validateTemplateRequest(req EventTemplateRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return errors.New("name is required")
+	}
+	if utf8.RuneCountInString(req.Name) > maxTemplateNameLength {
+		return errors.New("name is too long")
+	}
+	if strings.TrimSpace(req.TitlePattern) == "" {
+		return errors.New("title_pattern is required")
+	}
+	if utf8.RuneCountInString(req.TitlePattern) > maxTitlePatternLength {
+		return errors.New("title_pattern is too long")
+	}
+	if req.Duration <= 0 {
+		return errors.New("duration must be positive")
+	}
+	if req.NotifyBefore != nil && *req.NotifyBefore < 0 {
+		return errors.New("notify_before must not be negative")
+	}
+	return validateEventAttributes(req.Tags, req.Category, req.Color, req.Priority)
+}
+
+// convertToModelTemplate преобразует тело запроса во внутреннюю модель шаблона
+func convertToModelTemplate(id string, req EventTemplateRequest) *models.EventTemplate {
+	template := &models.EventTemplate{
+		ID:           id,
+		Name:         strings.TrimSpace(req.Name),
+		TitlePattern: req.TitlePattern,
+		Duration:     time.Duration(req.Duration) * time.Second,
+	}
+	if req.Description != nil {
+		template.Description = *req.Description
+	}
+	if req.NotifyBefore != nil {
+		template.NotifyBefore = time.Duration(*req.NotifyBefore) * time.Second
+	}
+	if req.Tags != nil {
+		template.Tags = normalizeValues(*req.Tags)
+	}
+	if req.Category != nil {
+		template.Category = *req.Category
+	}
+	if req.Color != nil {
+		template.Color = strings.ToLower(*req.Color)
+	}
+	if req.Priority != nil {
+		template.Priority = models.Priority(*req.Priority)
+	}
+	return template
+}
+
+// convertToAPITemplate преобразует внутреннюю модель шаблона в API модель
+func convertToAPITemplate(template *models.EventTemplate) EventTemplate {
+	apiTemplate := EventTemplate{
+		Id:           template.ID,
+		Name:         template.Name,
+		TitlePattern: template.TitlePattern,
+		Duration:     int(template.Duration / time.Second),
+		CreatedAt:    template.CreatedAt,
+		UpdatedAt:    template.UpdatedAt,
+	}
+	if template.Description != "" {
+		apiTemplate.Description = stringPtr(template.Description)
+	}
+	if template.NotifyBefore > 0 {
+		notifyBefore := int(template.NotifyBefore / time.Second)
+		apiTemplate.NotifyBefore = &notifyBefore
+	}
+	if len(template.Tags) > 0 {
+		tags := append([]string(nil), template.Tags...)
+		apiTemplate.Tags = &tags
+	}
+	if template.Category != "" {
+		apiTemplate.Category = stringPtr(template.Category)
+	}
+	if template.Color != "" {
+		apiTemplate.Color = stringPtr(template.Color)
+	}
+	if template.Priority != "" {
+		priority := Priority(template.Priority)
+		apiTemplate.Priority = &priority
+	}
+	return apiTemplate
+}
@@ -4,11 +4,238 @@
 package api
 
 import (
+	"encoding/json"
 	"time"
+
+	"github.com/oapi-codegen/runtime"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// Defines values for AuditEntryAction.
+const (
+	AuditCreate  AuditEntryAction = "create"
+	AuditDelete  AuditEntryAction = "delete"
+	AuditPatch   AuditEntryAction = "patch"
+	AuditPurge   AuditEntryAction = "purge"
+	AuditRestore AuditEntryAction = "restore"
+	AuditUpdate  AuditEntryAction = "update"
+)
+
+// Defines values for BatchOperationOp.
+const (
+	BatchCreate BatchOperationOp = "create"
+	BatchDelete BatchOperationOp = "delete"
+	BatchUpdate BatchOperationOp = "update"
 )
 
+// Defines values for BatchRequestMode.
+const (
+	BatchAtomic     BatchRequestMode = "atomic"
+	BatchBestEffort BatchRequestMode = "best_effort"
+)
+
+// Defines values for BusySlotKind.
+const (
+	BusyEvent       BusySlotKind = "event"
+	BusyOutOfOffice BusySlotKind = "out_of_office"
+)
+
+// Defines values for JSONPatchOperationOp.
+const (
+	PatchAdd     JSONPatchOperationOp = "add"
+	PatchCopy    JSONPatchOperationOp = "copy"
+	PatchMove    JSONPatchOperationOp = "move"
+	PatchRemove  JSONPatchOperationOp = "remove"
+	PatchReplace JSONPatchOperationOp = "replace"
+	PatchTest    JSONPatchOperationOp = "test"
+)
+
+// Defines values for Priority.
+const (
+	PriorityHigh   Priority = "high"
+	PriorityLow    Priority = "low"
+	PriorityNormal Priority = "normal"
+	PriorityUrgent Priority = "urgent"
+)
+
+// Defines values for ResourceKind.
+const (
+	ResourceEquipment ResourceKind = "equipment"
+	ResourceRoom      ResourceKind = "room"
+)
+
+// Defines values for Weekday.
+const (
+	Friday    Weekday = "friday"
+	Monday    Weekday = "monday"
+	Saturday  Weekday = "saturday"
+	Sunday    Weekday = "sunday"
+	Thursday  Weekday = "thursday"
+	Tuesday   Weekday = "tuesday"
+	Wednesday Weekday = "wednesday"
+)
+
+// ArchiveRestoreResult defines model for ArchiveRestoreResult.
+type ArchiveRestoreResult struct {
+	// Restored Число восстановленных записей по типам
+	Restored map[string]int `json:"restored"`
+
+	// Skipped Число пропущенных записей по типам
+	Skipped map[string]int `json:"skipped"`
+}
+
+// Attachment defines model for Attachment.
+type Attachment struct {
+	// Checksum SHA-256 содержимого в hex
+	Checksum *string `json:"checksum,omitempty"`
+
+	// ContentType MIME-тип содержимого
+	ContentType *string `json:"content_type,omitempty"`
+
+	// CreatedAt Время добавления
+	CreatedAt time.Time `json:"created_at"`
+
+	// EventId ID события
+	EventId string `json:"event_id"`
+
+	// Id Уникальный идентификатор вложения
+	Id string `json:"id"`
+
+	// Name Имя файла или название ссылки
+	Name string `json:"name"`
+
+	// Size Размер содержимого в байтах
+	Size *int64 `json:"size,omitempty"`
+
+	// Url URL, если вложение является ссылкой
+	Url *string `json:"url,omitempty"`
+}
+
+// AttachmentLink defines model for AttachmentLink.
+type AttachmentLink struct {
+	// Name Название ссылки, по умолчанию сам URL
+	Name *string `json:"name,omitempty"`
+
+	// Url Абсолютный http(s) URL
+	Url string `json:"url"`
+}
+
+// AuditEntry defines model for AuditEntry.
+type AuditEntry struct {
+	// Action Тип изменения
+	Action AuditEntryAction `json:"action"`
+
+	// Actor Инициатор изменения
+	Actor string `json:"actor"`
+
+	// After Событие после изменения
+	After *map[string]interface{} `json:"after,omitempty"`
+
+	// Before Событие до изменения
+	Before *map[string]interface{} `json:"before,omitempty"`
+
+	// CreatedAt Время изменения
+	CreatedAt time.Time `json:"created_at"`
+
+	// Diff JSON Merge Patch от before к after
+	Diff *map[string]interface{} `json:"diff,omitempty"`
+
+	// EventId ID измененного события
+	EventId string `json:"event_id"`
+
+	// Id Уникальный идентификатор записи
+	Id string `json:"id"`
+
+	// RequestId ID запроса, в рамках которого выполнено изменение
+	RequestId string `json:"request_id"`
+}
+
+// AuditEntryAction Тип изменения
+type AuditEntryAction string
+
+// BatchOperation defines model for BatchOperation.
+type BatchOperation struct {
+	Event *UpdateEventRequest `json:"event,omitempty"`
+
+	// Id ID события для update и delete
+	Id *string          `json:"id,omitempty"`
+	Op BatchOperationOp `json:"op"`
+
+	// Version Ожидаемая версия события для update и delete, аналог If-Match
+	Version *int64 `json:"version,omitempty"`
+}
+
+// BatchOperationOp defines model for BatchOperation.Op.
+type BatchOperationOp string
+
+// BatchRequest defines model for BatchRequest.
+type BatchRequest struct {
+	// Mode atomic — все или ничего, best_effort — выполнить все успешные операции
+	Mode *BatchRequestMode `json:"mode,omitempty"`
+
+	// Operations Не больше server.max_batch_operations (по умолчанию 100) операций
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchRequestMode atomic — все или ничего, best_effort — выполнить все успешные операции
+type BatchRequestMode string
+
+// BatchResponse defines model for BatchResponse.
+type BatchResponse struct {
+	// Committed Были ли сохранены изменения пакета
+	Committed bool          `json:"committed"`
+	Results   []BatchResult `json:"results"`
+}
+
+// BatchResult defines model for BatchResult.
+type BatchResult struct {
+	Error *string `json:"error,omitempty"`
+	Event *Event  `json:"event,omitempty"`
+
+	// Index Номер операции в запросе
+	Index int `json:"index"`
+
+	// Status HTTP-статус, который вернул бы одиночный запрос (424 — операция отменена вместе с пакетом)
+	Status int `json:"status"`
+}
+
+// Booking defines model for Booking.
+type Booking struct {
+	EndTime time.Time `json:"end_time"`
+
+	// EventId ID события, забронировавшего ресурс
+	EventId   string    `json:"event_id"`
+	StartTime time.Time `json:"start_time"`
+}
+
+// BusySlot defines model for BusySlot.
+type BusySlot struct {
+	End time.Time `json:"end"`
+
+	// Id ID события или периода отсутствия
+	Id string `json:"id"`
+
+	// Kind Источник занятости
+	Kind  BusySlotKind `json:"kind"`
+	Start time.Time    `json:"start"`
+}
+
+// BusySlotKind Источник занятости
+type BusySlotKind string
+
+// CreateEventBody Тело POST /events; с параметром template — TemplateEventRequest
+type CreateEventBody struct {
+	union json.RawMessage
+}
+
 // CreateEventRequest defines model for CreateEventRequest.
 type CreateEventRequest struct {
+	// Category Категория события
+	Category *string `json:"category,omitempty"`
+
+	// Color Цвет события в формате #RRGGBB
+	Color *string `json:"color,omitempty"`
+
 	// Description Описание события
 	Description *string `json:"description,omitempty"`
 
@@ -18,9 +245,18 @@ type CreateEventRequest struct {
 	// NotifyBefore За сколько секунд уведомить о событии
 	NotifyBefore *int `json:"notify_before,omitempty"`
 
+	// Priority Приоритет события
+	Priority *Priority `json:"priority,omitempty"`
+
+	// ResourceIds ID забронированных ресурсов (переговорных, оборудования)
+	ResourceIds *[]string `json:"resource_ids,omitempty"`
+
 	// StartTime Время начала события
 	StartTime time.Time `json:"start_time"`
 
+	// Tags Теги события
+	Tags *[]string `json:"tags,omitempty"`
+
 	// Title Заголовок события
 	Title string `json:"title"`
 
@@ -30,13 +266,25 @@ type CreateEventRequest struct {
 
 // ErrorResponse defines model for ErrorResponse.
 type ErrorResponse struct {
-	Code    *int    `json:"code,omitempty"`
-	Error   *string `json:"error,omitempty"`
+	Code  *int    `json:"code,omitempty"`
+	Error *string `json:"error,omitempty"`
+
+	// Limit Превышенный предел, например максимальное число операций в пакете
+	Limit   *int    `json:"limit,omitempty"`
 	Message *string `json:"message,omitempty"`
 }
 
 // Event defines model for Event.
 type Event struct {
+	// Category Категория события
+	Category *string `json:"category,omitempty"`
+
+	// Color Цвет события в формате #RRGGBB
+	Color *string `json:"color,omitempty"`
+
+	// DeletedAt Время переноса события в корзину
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
 	// Description Описание события
 	Description *string `json:"description,omitempty"`
 
@@ -49,14 +297,241 @@ type Event struct {
 	// NotifyBefore За сколько секунд уведомить о событии
 	NotifyBefore *int `json:"notify_before,omitempty"`
 
+	// Priority Приоритет события
+	Priority *Priority `json:"priority,omitempty"`
+
+	// ResourceIds ID забронированных ресурсов (переговорных, оборудования)
+	ResourceIds *[]string `json:"resource_ids,omitempty"`
+
 	// StartTime Время начала события
 	StartTime time.Time `json:"start_time"`
 
+	// Tags Теги события
+	Tags *[]string `json:"tags,omitempty"`
+
 	// Title Заголовок события
 	Title string `json:"title"`
 
 	// UserId ID пользователя
 	UserId string `json:"user_id"`
+
+	// Version Версия события, увеличивается при каждом изменении
+	Version int64 `json:"version"`
+}
+
+// EventTemplate defines model for EventTemplate.
+type EventTemplate struct {
+	// Category Категория события
+	Category *string `json:"category,omitempty"`
+
+	// Color Цвет события в формате #RRGGBB
+	Color *string `json:"color,omitempty"`
+
+	// CreatedAt Время создания
+	CreatedAt time.Time `json:"created_at"`
+
+	// Description Описание события
+	Description *string `json:"description,omitempty"`
+
+	// Duration Длительность события в секундах
+	Duration int `json:"duration"`
+
+	// Id Уникальный идентификатор шаблона
+	Id string `json:"id"`
+
+	// Name Название шаблона
+	Name string `json:"name"`
+
+	// NotifyBefore За сколько секунд уведомить о событии
+	NotifyBefore *int `json:"notify_before,omitempty"`
+
+	// Priority Приоритет события
+	Priority *Priority `json:"priority,omitempty"`
+
+	// Tags Теги события
+	Tags *[]string `json:"tags,omitempty"`
+
+	// TitlePattern Заголовок события с подстановками {name}. Встроенные подстановки: {user} — ID
+	// пользователя, {date} и {time} — дата и время начала; остальные задаются в variables.
+	TitlePattern string `json:"title_pattern"`
+
+	// UpdatedAt Время последнего изменения
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EventTemplateRequest defines model for EventTemplateRequest.
+type EventTemplateRequest struct {
+	// Category Категория события
+	Category *string `json:"category,omitempty"`
+
+	// Color Цвет события в формате #RRGGBB
+	Color *string `json:"color,omitempty"`
+
+	// Description Описание события
+	Description *string `json:"description,omitempty"`
+
+	// Duration Длительность события в секундах
+	Duration int `json:"duration"`
+
+	// Name Название шаблона
+	Name string `json:"name"`
+
+	// NotifyBefore За сколько секунд уведомить о событии
+	NotifyBefore *int `json:"notify_before,omitempty"`
+
+	// Priority Приоритет события
+	Priority *Priority `json:"priority,omitempty"`
+
+	// Tags Теги события
+	Tags *[]string `json:"tags,omitempty"`
+
+	// TitlePattern Заголовок события с подстановками {name}
+	TitlePattern string `json:"title_pattern"`
+}
+
+// FreeBusy defines model for FreeBusy.
+type FreeBusy struct {
+	Busy   []BusySlot `json:"busy"`
+	Free   []TimeSlot `json:"free"`
+	UserId string     `json:"user_id"`
+}
+
+// JSONPatchOperation defines model for JSONPatchOperation.
+type JSONPatchOperation struct {
+	// From Источник для операций move и copy
+	From *string              `json:"from,omitempty"`
+	Op   JSONPatchOperationOp `json:"op"`
+
+	// Path JSON Pointer на поле события, например /title
+	Path string `json:"path"`
+
+	// Value Новое значение для add, replace и test
+	Value *interface{} `json:"value,omitempty"`
+}
+
+// JSONPatchOperationOp defines model for JSONPatchOperation.Op.
+type JSONPatchOperationOp string
+
+// OutOfOffice defines model for OutOfOffice.
+type OutOfOffice struct {
+	CreatedAt time.Time `json:"created_at"`
+	EndTime   time.Time `json:"end_time"`
+	Id        string    `json:"id"`
+	Reason    *string   `json:"reason,omitempty"`
+	StartTime time.Time `json:"start_time"`
+	UserId    string    `json:"user_id"`
+}
+
+// OutOfOfficeRequest defines model for OutOfOfficeRequest.
+type OutOfOfficeRequest struct {
+	EndTime time.Time `json:"end_time"`
+
+	// Reason Причина отсутствия
+	Reason    *string   `json:"reason,omitempty"`
+	StartTime time.Time `json:"start_time"`
+}
+
+// PatchEventRequest Поля, отсутствующие в запросе, не изменяются; null удаляет необязательное поле
+type PatchEventRequest struct {
+	// Category Категория события
+	Category *string `json:"category"`
+
+	// Color Цвет события в формате #RRGGBB
+	Color *string `json:"color"`
+
+	// Description Описание события
+	Description *string `json:"description"`
+
+	// EndTime Время окончания события
+	EndTime *time.Time `json:"end_time,omitempty"`
+
+	// NotifyBefore За сколько секунд уведомить о событии
+	NotifyBefore *int `json:"notify_before"`
+
+	// Priority Приоритет события
+	Priority *Priority `json:"priority,omitempty"`
+
+	// ResourceIds ID забронированных ресурсов (переговорных, оборудования)
+	ResourceIds *[]string `json:"resource_ids,omitempty"`
+
+	// StartTime Время начала события
+	StartTime *time.Time `json:"start_time,omitempty"`
+
+	// Tags Теги события
+	Tags *[]string `json:"tags"`
+
+	// Title Заголовок события
+	Title *string `json:"title,omitempty"`
+
+	// UserId ID пользователя
+	UserId *string `json:"user_id,omitempty"`
+}
+
+// Priority Приоритет события
+type Priority string
+
+// QuickEventRequest defines model for QuickEventRequest.
+type QuickEventRequest struct {
+	// Text Описание события, например "Обед с Анной завтра в 13:00 на 1ч напомнить за 15 мин"
+	Text string `json:"text"`
+
+	// TimeZone Часовой пояс IANA для относительных дат, например Europe/Moscow
+	TimeZone *string `json:"time_zone,omitempty"`
+
+	// UserId ID пользователя
+	UserId string `json:"user_id"`
+}
+
+// QuickEventResponse defines model for QuickEventResponse.
+type QuickEventResponse struct {
+	Event   *Event             `json:"event,omitempty"`
+	Request CreateEventRequest `json:"request"`
+}
+
+// Resource defines model for Resource.
+type Resource struct {
+	// Capacity Вместимость переговорной
+	Capacity int `json:"capacity"`
+
+	// CreatedAt Время создания
+	CreatedAt time.Time `json:"created_at"`
+
+	// Description Описание ресурса
+	Description *string `json:"description,omitempty"`
+
+	// Id Уникальный идентификатор ресурса
+	Id string `json:"id"`
+
+	// Kind Тип ресурса
+	Kind ResourceKind `json:"kind"`
+
+	// Name Название ресурса
+	Name string `json:"name"`
+}
+
+// ResourceAvailability defines model for ResourceAvailability.
+type ResourceAvailability struct {
+	Busy       []Booking  `json:"busy"`
+	Free       []TimeSlot `json:"free"`
+	ResourceId string     `json:"resource_id"`
+}
+
+// ResourceKind Тип ресурса
+type ResourceKind string
+
+// ResourceRequest defines model for ResourceRequest.
+type ResourceRequest struct {
+	// Capacity Вместимость переговорной
+	Capacity *int `json:"capacity,omitempty"`
+
+	// Description Описание ресурса
+	Description *string `json:"description,omitempty"`
+
+	// Kind Тип ресурса
+	Kind ResourceKind `json:"kind"`
+
+	// Name Название ресурса
+	Name string `json:"name"`
 }
 
 // SuccessResponse defines model for SuccessResponse.
@@ -65,8 +540,59 @@ type SuccessResponse struct {
 	Success *bool   `json:"success,omitempty"`
 }
 
+// TemplateEventRequest Событие из шаблона; заданные поля переопределяют значения шаблона
+type TemplateEventRequest struct {
+	// Category Категория события
+	Category *string `json:"category,omitempty"`
+
+	// Color Цвет события в формате #RRGGBB
+	Color *string `json:"color,omitempty"`
+
+	// Description Описание события
+	Description *string `json:"description,omitempty"`
+
+	// EndTime Время окончания, по умолчанию начало плюс длительность шаблона
+	EndTime *time.Time `json:"end_time,omitempty"`
+
+	// NotifyBefore За сколько секунд уведомить о событии
+	NotifyBefore *int `json:"notify_before,omitempty"`
+
+	// Priority Приоритет события
+	Priority *Priority `json:"priority,omitempty"`
+
+	// ResourceIds ID забронированных ресурсов (переговорных, оборудования)
+	ResourceIds *[]string `json:"resource_ids,omitempty"`
+
+	// StartTime Время начала события
+	StartTime time.Time `json:"start_time"`
+
+	// Tags Теги события
+	Tags *[]string `json:"tags,omitempty"`
+
+	// Title Заголовок вместо сформированного по шаблону
+	Title *string `json:"title,omitempty"`
+
+	// UserId ID пользователя
+	UserId string `json:"user_id"`
+
+	// Variables Значения подстановок заголовка
+	Variables *map[string]string `json:"variables,omitempty"`
+}
+
+// TimeSlot defines model for TimeSlot.
+type TimeSlot struct {
+	End   time.Time `json:"end"`
+	Start time.Time `json:"start"`
+}
+
 // UpdateEventRequest defines model for UpdateEventRequest.
 type UpdateEventRequest struct {
+	// Category Категория события
+	Category *string `json:"category,omitempty"`
+
+	// Color Цвет события в формате #RRGGBB
+	Color *string `json:"color,omitempty"`
+
 	// Description Описание события
 	Description *string `json:"description,omitempty"`
 
@@ -76,9 +602,18 @@ type UpdateEventRequest struct {
 	// NotifyBefore За сколько секунд уведомить о событии
 	NotifyBefore *int `json:"notify_before,omitempty"`
 
+	// Priority Приоритет события
+	Priority *Priority `json:"priority,omitempty"`
+
+	// ResourceIds ID забронированных ресурсов (переговорных, оборудования)
+	ResourceIds *[]string `json:"resource_ids,omitempty"`
+
 	// StartTime Время начала события
 	StartTime time.Time `json:"start_time"`
 
+	// Tags Теги события
+	Tags *[]string `json:"tags,omitempty"`
+
 	// Title Заголовок события
 	Title string `json:"title"`
 
@@ -86,17 +621,277 @@ type UpdateEventRequest struct {
 	UserId string `json:"user_id"`
 }
 
+// Weekday День недели
+type Weekday string
+
+// WorkingHours defines model for WorkingHours.
+type WorkingHours struct {
+	DeferReminders bool            `json:"defer_reminders"`
+	TimeZone       string          `json:"time_zone"`
+	UserId         string          `json:"user_id"`
+	Windows        []WorkingWindow `json:"windows"`
+}
+
+// WorkingHoursRequest defines model for WorkingHoursRequest.
+type WorkingHoursRequest struct {
+	// DeferReminders Переносить несрочные напоминания на рабочее время
+	DeferReminders *bool `json:"defer_reminders,omitempty"`
+
+	// TimeZone Часовой пояс IANA, по умолчанию UTC
+	TimeZone *string `json:"time_zone,omitempty"`
+
+	// Windows Рабочие интервалы; пустой список означает доступность в любое время
+	Windows []WorkingWindow `json:"windows"`
+}
+
+// WorkingWindow defines model for WorkingWindow.
+type WorkingWindow struct {
+	// End Конец рабочего интервала (ЧЧ:ММ, не позже 24:00)
+	End string `json:"end"`
+
+	// Start Начало рабочего интервала (ЧЧ:ММ)
+	Start string `json:"start"`
+
+	// Weekday День недели
+	Weekday Weekday `json:"weekday"`
+}
+
+// IfMatch defines model for IfMatch.
+type IfMatch = string
+
 // BadRequest defines model for BadRequest.
 type BadRequest = ErrorResponse
 
+// Conflict defines model for Conflict.
+type Conflict = ErrorResponse
+
 // InternalError defines model for InternalError.
 type InternalError = ErrorResponse
 
 // NotFound defines model for NotFound.
 type NotFound = ErrorResponse
 
+// NotImplemented defines model for NotImplemented.
+type NotImplemented = ErrorResponse
+
+// PayloadTooLarge defines model for PayloadTooLarge.
+type PayloadTooLarge = ErrorResponse
+
+// PreconditionFailed defines model for PreconditionFailed.
+type PreconditionFailed = ErrorResponse
+
+// QuotaExceeded defines model for QuotaExceeded.
+type QuotaExceeded = ErrorResponse
+
+// TooManyRequests defines model for TooManyRequests.
+type TooManyRequests = ErrorResponse
+
+// UnsupportedMediaType defines model for UnsupportedMediaType.
+type UnsupportedMediaType = ErrorResponse
+
+// ListAuditParams defines parameters for ListAudit.
+type ListAuditParams struct {
+	// EventId ID события
+	EventId *string `form:"event_id,omitempty" json:"event_id,omitempty"`
+
+	// Actor Инициатор изменений (заголовок X-User-ID запроса)
+	Actor *string `form:"actor,omitempty" json:"actor,omitempty"`
+
+	// Limit Максимальное количество записей
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// ListEventsParams defines parameters for ListEvents.
+type ListEventsParams struct {
+	// From Начало интервала, по умолчанию месяц назад
+	From *time.Time `form:"from,omitempty" json:"from,omitempty"`
+
+	// To Конец интервала, по умолчанию через месяц
+	To *time.Time `form:"to,omitempty" json:"to,omitempty"`
+
+	// Tag Событие должно содержать все перечисленные теги
+	Tag *[]string `form:"tag,omitempty" json:"tag,omitempty"`
+
+	// Category Категория события
+	Category *string `form:"category,omitempty" json:"category,omitempty"`
+
+	// Priority Приоритет события
+	Priority *Priority `form:"priority,omitempty" json:"priority,omitempty"`
+}
+
+// CreateEventParams defines parameters for CreateEvent.
+type CreateEventParams struct {
+	// Template ID шаблона события
+	Template *string `form:"template,omitempty" json:"template,omitempty"`
+}
+
+// QuickAddEventParams defines parameters for QuickAddEvent.
+type QuickAddEventParams struct {
+	// Create Создать распознанное событие
+	Create *bool `form:"create,omitempty" json:"create,omitempty"`
+}
+
+// DeleteEventParams defines parameters for DeleteEvent.
+type DeleteEventParams struct {
+	// IfMatch ETag события, полученный ранее; изменение выполняется только если событие не менялось
+	IfMatch *IfMatch `json:"If-Match,omitempty"`
+}
+
+// PatchEventApplicationJSONPatchPlusJSONBody defines parameters for PatchEvent.
+type PatchEventApplicationJSONPatchPlusJSONBody = []JSONPatchOperation
+
+// PatchEventParams defines parameters for PatchEvent.
+type PatchEventParams struct {
+	// IfMatch ETag события, полученный ранее; изменение выполняется только если событие не менялось
+	IfMatch *IfMatch `json:"If-Match,omitempty"`
+}
+
+// UpdateEventParams defines parameters for UpdateEvent.
+type UpdateEventParams struct {
+	// IfMatch ETag события, полученный ранее; изменение выполняется только если событие не менялось
+	IfMatch *IfMatch `json:"If-Match,omitempty"`
+}
+
+// UploadAttachmentMultipartBody defines parameters for UploadAttachment.
+type UploadAttachmentMultipartBody struct {
+	File openapi_types.File `json:"file"`
+}
+
+// ListResourcesParams defines parameters for ListResources.
+type ListResourcesParams struct {
+	Kind *ResourceKind `form:"kind,omitempty" json:"kind,omitempty"`
+
+	// MinCapacity Минимальная вместимость
+	MinCapacity *int `form:"min_capacity,omitempty" json:"min_capacity,omitempty"`
+}
+
+// GetResourceAvailabilityParams defines parameters for GetResourceAvailability.
+type GetResourceAvailabilityParams struct {
+	// From Начало интервала
+	From time.Time `form:"from" json:"from"`
+
+	// To Конец интервала
+	To time.Time `form:"to" json:"to"`
+}
+
+// GetFreeBusyParams defines parameters for GetFreeBusy.
+type GetFreeBusyParams struct {
+	// From Начало интервала
+	From time.Time `form:"from" json:"from"`
+
+	// To Конец интервала
+	To time.Time `form:"to" json:"to"`
+}
+
+// ListOutOfOfficeParams defines parameters for ListOutOfOffice.
+type ListOutOfOfficeParams struct {
+	// From Начало интервала, по умолчанию текущее время
+	From *time.Time `form:"from,omitempty" json:"from,omitempty"`
+
+	// To Конец интервала, по умолчанию без ограничения
+	To *time.Time `form:"to,omitempty" json:"to,omitempty"`
+}
+
 // CreateEventJSONRequestBody defines body for CreateEvent for application/json ContentType.
-type CreateEventJSONRequestBody = CreateEventRequest
+type CreateEventJSONRequestBody = CreateEventBody
+
+// QuickAddEventJSONRequestBody defines body for QuickAddEvent for application/json ContentType.
+type QuickAddEventJSONRequestBody = QuickEventRequest
+
+// PatchEventApplicationJSONPatchPlusJSONRequestBody defines body for PatchEvent for application/json-patch+json ContentType.
+type PatchEventApplicationJSONPatchPlusJSONRequestBody = PatchEventApplicationJSONPatchPlusJSONBody
+
+// PatchEventApplicationMergePatchPlusJSONRequestBody defines body for PatchEvent for application/merge-patch+json ContentType.
+type PatchEventApplicationMergePatchPlusJSONRequestBody = PatchEventRequest
 
 // UpdateEventJSONRequestBody defines body for UpdateEvent for application/json ContentType.
 type UpdateEventJSONRequestBody = UpdateEventRequest
+
+// UploadAttachmentJSONRequestBody defines body for UploadAttachment for application/json ContentType.
+type UploadAttachmentJSONRequestBody = AttachmentLink
+
+// UploadAttachmentMultipartRequestBody defines body for UploadAttachment for multipart/form-data ContentType.
+type UploadAttachmentMultipartRequestBody UploadAttachmentMultipartBody
+
+// BatchEventsJSONRequestBody defines body for BatchEvents for application/json ContentType.
+type BatchEventsJSONRequestBody = BatchRequest
+
+// CreateResourceJSONRequestBody defines body for CreateResource for application/json ContentType.
+type CreateResourceJSONRequestBody = ResourceRequest
+
+// UpdateResourceJSONRequestBody defines body for UpdateResource for application/json ContentType.
+type UpdateResourceJSONRequestBody = ResourceRequest
+
+// CreateTemplateJSONRequestBody defines body for CreateTemplate for application/json ContentType.
+type CreateTemplateJSONRequestBody = EventTemplateRequest
+
+// UpdateTemplateJSONRequestBody defines body for UpdateTemplate for application/json ContentType.
+type UpdateTemplateJSONRequestBody = EventTemplateRequest
+
+// AddOutOfOfficeJSONRequestBody defines body for AddOutOfOffice for application/json ContentType.
+type AddOutOfOfficeJSONRequestBody = OutOfOfficeRequest
+
+// SetWorkingHoursJSONRequestBody defines body for SetWorkingHours for application/json ContentType.
+type SetWorkingHoursJSONRequestBody = WorkingHoursRequest
+
+// AsCreateEventRequest returns the union data inside the CreateEventBody as a CreateEventRequest
+func (t CreateEventBody) AsCreateEventRequest() (CreateEventRequest, error) {
+	var body CreateEventRequest
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromCreateEventRequest overwrites any union data inside the CreateEventBody as the provided CreateEventRequest
+func (t *CreateEventBody) FromCreateEventRequest(v CreateEventRequest) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeCreateEventRequest performs a merge with any union data inside the CreateEventBody, using the provided CreateEventRequest
+func (t *CreateEventBody) MergeCreateEventRequest(v CreateEventRequest) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JsonMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+// AsTemplateEventRequest returns the union data inside the CreateEventBody as a TemplateEventRequest
+func (t CreateEventBody) AsTemplateEventRequest() (TemplateEventRequest, error) {
+	var body TemplateEventRequest
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromTemplateEventRequest overwrites any union data inside the CreateEventBody as the provided TemplateEventRequest
+func (t *CreateEventBody) FromTemplateEventRequest(v TemplateEventRequest) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeTemplateEventRequest performs a merge with any union data inside the CreateEventBody, using the provided TemplateEventRequest
+func (t *CreateEventBody) MergeTemplateEventRequest(v TemplateEventRequest) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JsonMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+func (t CreateEventBody) MarshalJSON() ([]byte, error) {
+	b, err := t.union.MarshalJSON()
+	return b, err
+}
+
+func (t *CreateEventBody) UnmarshalJSON(b []byte) error {
+	err := t.union.UnmarshalJSON(b)
+	return err
+}
@@ -0,0 +1,123 @@
+package internalhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/logger"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/requestctx"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRequestIDAndAccessLog(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	s := &Server{logger: &logger.Logger{SugaredLogger: zap.New(core).Sugar()}}
+
+	var seenRequestID string
+	router := mux.NewRouter()
+	router.HandleFunc("/api/events/{id}", func(w http.ResponseWriter, r *http.Request) {
+		seenRequestID = requestctx.RequestID(r.Context())
+		logger.FromContext(r.Context(), nil).Info("handler")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("not found"))
+	})
+	router.Use(s.requestIDMiddleware)
+	router.Use(s.accessLogMiddleware)
+
+	do := func(requestID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/events/42", nil)
+		if requestID != "" {
+			req.Header.Set("X-Request-ID", requestID)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("propagated", func(t *testing.T) {
+		logs.TakeAll()
+		w := do("req-1")
+		assert.Equal(t, "req-1", w.Header().Get("X-Request-ID"))
+		assert.Equal(t, "req-1", seenRequestID)
+
+		entries := logs.TakeAll()
+		require.Len(t, entries, 2)
+		assert.Equal(t, "req-1", entries[0].ContextMap()["request_id"])
+
+		access := entries[1]
+		assert.Equal(t, zapcore.WarnLevel, access.Level)
+		fields := access.ContextMap()
+		assert.Equal(t, "req-1", fields["request_id"])
+		assert.Equal(t, "/api/events/{id}", fields["route"])
+		assert.EqualValues(t, http.StatusNotFound, fields["status"])
+		assert.EqualValues(t, len("not found"), fields["size"])
+	})
+
+	t.Run("generated", func(t *testing.T) {
+		w := do("")
+		assert.NotEmpty(t, w.Header().Get("X-Request-ID"))
+		assert.Equal(t, w.Header().Get("X-Request-ID"), seenRequestID)
+	})
+
+	t.Run("invalid replaced", func(t *testing.T) {
+		for _, id := range []string{"bad id\n", strings.Repeat("a", maxRequestIDLength+1)} {
+			w := do(id)
+			assert.NotEqual(t, id, w.Header().Get("X-Request-ID"))
+			assert.NotEmpty(t, w.Header().Get("X-Request-ID"))
+		}
+	})
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	s := &Server{logger: &logger.Logger{SugaredLogger: zap.New(core).Sugar()}}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/events/{id}", func(w http.ResponseWriter, r *http.Request) {
+		logger.FromContext(r.Context(), nil).Info("handler")
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	router.Use(s.requestIDMiddleware)
+	router.Use(tracingMiddleware)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest("GET", "/api/events/42", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "GET /api/events/{id}", span.Name())
+	assert.Equal(t, traceID, span.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	assert.Equal(t, codes.Error, span.Status().Code)
+	assert.Contains(t, span.Attributes(), semconv.HTTPResponseStatusCode(http.StatusInternalServerError))
+
+	entries := logs.TakeAll()
+	require.Len(t, entries, 1)
+	assert.Equal(t, traceID, entries[0].ContextMap()["trace_id"])
+}
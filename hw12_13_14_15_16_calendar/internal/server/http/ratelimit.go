@@ -0,0 +1,195 @@
+package internalhttp
+
+import (
+	"encoding/json"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/config"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/server/http/api"
+	"github.com/gorilla/mux"
+)
+
+// bucketSweepInterval — как часто удаляются корзины простаивающих клиентов
+const bucketSweepInterval = time.Minute
+
+// tokenBucket — корзина токенов клиента на одном маршруте. Пополняется со скоростью
+// rate токенов в секунду, но не больше burst.
+type tokenBucket struct {
+	rate    float64
+	burst   float64
+	tokens  float64
+	updated time.Time
+}
+
+// refill добавляет токены, накопившиеся с последнего обращения.
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.updated).Seconds()*b.rate)
+	b.updated = now
+}
+
+// limitResult — решение ограничителя и значения для заголовков RateLimit.
+type limitResult struct {
+	allowed    bool
+	limit      int
+	remaining  int
+	reset      time.Duration
+	retryAfter time.Duration
+}
+
+// rateLimiter ограничивает частоту запросов по клиенту и маршруту.
+type rateLimiter struct {
+	cfg config.RateLimitConfig
+	now func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+func newRateLimiter(cfg config.RateLimitConfig) *rateLimiter {
+	return &rateLimiter{
+		cfg:     cfg,
+		now:     time.Now,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// rule возвращает правило для метода и шаблона маршрута.
+func (l *rateLimiter) rule(method, route string) config.RateLimitRule {
+	for _, rule := range l.cfg.Routes {
+		if rule.Path == route && (rule.Method == "" || strings.EqualFold(rule.Method, method)) {
+			return rule.RateLimitRule
+		}
+	}
+	return l.cfg.Default
+}
+
+// allow расходует токен из корзины key.
+func (l *rateLimiter) allow(key string, rule config.RateLimitRule) limitResult {
+	burst := float64(rule.Burst)
+	if burst < 1 {
+		burst = math.Max(1, math.Ceil(rule.Rate))
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	bucket, exists := l.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{tokens: burst, updated: now}
+		l.buckets[key] = bucket
+	}
+	bucket.rate, bucket.burst = rule.Rate, burst
+	bucket.refill(now)
+
+	result := limitResult{limit: int(burst)}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.allowed = true
+	} else {
+		result.retryAfter = secondsToDuration((1 - bucket.tokens) / rule.Rate)
+	}
+	result.remaining = int(bucket.tokens)
+	result.reset = secondsToDuration((burst - bucket.tokens) / rule.Rate)
+	return result
+}
+
+// sweep удаляет полностью восстановившиеся корзины, чтобы карта не росла
+// с числом когда-либо обращавшихся клиентов.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < bucketSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, bucket := range l.buckets {
+		bucket.refill(now)
+		if bucket.tokens >= bucket.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// clientKey определяет клиента по IP. X-User-ID не учитывается: заголовок задает сам
+// клиент, и с новым значением в каждом запросе он получал бы новую корзину.
+func (l *rateLimiter) clientKey(r *http.Request) string {
+	if l.cfg.TrustForwardedFor {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			client, _, _ := strings.Cut(forwarded, ",")
+			return "ip:" + strings.TrimSpace(client)
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// middleware отвечает 429 Too Many Requests при исчерпании лимита и добавляет
+// к ответам заголовки RateLimit-Limit, RateLimit-Remaining и RateLimit-Reset.
+func (l *rateLimiter) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routeTemplate(r)
+		rule := l.rule(r.Method, route)
+		if rule.Rate <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		result := l.allow(r.Method+" "+route+" "+l.clientKey(r), rule)
+
+		header := w.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(result.limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(result.remaining))
+		header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.reset)))
+
+		if !result.allowed {
+			header.Set("Retry-After", strconv.Itoa(ceilSeconds(result.retryAfter)))
+			sendTooManyRequests(w)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// routeTemplate возвращает шаблон сработавшего маршрута, чтобы запросы к разным
+// ресурсам одного маршрута расходовали общий лимит.
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return r.URL.Path
+}
+
+func sendTooManyRequests(w http.ResponseWriter) {
+	status := http.StatusTooManyRequests
+	errorMsg := "rate limit exceeded"
+	message := "Too many requests"
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	// Ошибку записи клиенту, уже получившему статус, сообщить некуда
+	_ = json.NewEncoder(w).Encode(api.ErrorResponse{Error: &errorMsg, Message: &message, Code: &status})
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,97 @@
+package internalhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/config"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := newRateLimiter(config.RateLimitConfig{
+		Default: config.RateLimitRule{Rate: 10, Burst: 10},
+		Routes: []config.RouteRateLimit{
+			{Method: "POST", Path: "/api/events", RateLimitRule: config.RateLimitRule{Rate: 1, Burst: 2}},
+			{Path: "/metrics"},
+		},
+	})
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	router := mux.NewRouter()
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	router.HandleFunc("/api/events", ok).Methods("POST", "GET")
+	router.HandleFunc("/api/events/{id}", ok).Methods("GET")
+	router.HandleFunc("/metrics", ok).Methods("GET")
+	router.Use(limiter.middleware)
+
+	do := func(method, path, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = "192.0.2.1:12345"
+		if ip != "" {
+			req.RemoteAddr = ip + ":12345"
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("route rule", func(t *testing.T) {
+		w := do("POST", "/api/events", "192.0.2.10")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+
+		assert.Equal(t, http.StatusOK, do("POST", "/api/events", "192.0.2.10").Code)
+
+		w = do("POST", "/api/events", "192.0.2.10")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "1", w.Header().Get("Retry-After"))
+		assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+
+		// Другой клиент и другой метод расходуют отдельные корзины
+		assert.Equal(t, http.StatusOK, do("POST", "/api/events", "192.0.2.20").Code)
+		assert.Equal(t, http.StatusOK, do("GET", "/api/events", "192.0.2.10").Code)
+
+		now = now.Add(time.Second)
+		assert.Equal(t, http.StatusOK, do("POST", "/api/events", "192.0.2.10").Code)
+	})
+
+	t.Run("route template shares bucket", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			assert.Equal(t, http.StatusOK, do("GET", "/api/events/"+string(rune('a'+i)), "").Code)
+		}
+		assert.Equal(t, http.StatusTooManyRequests, do("GET", "/api/events/z", "").Code)
+	})
+
+	t.Run("unlimited route", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			w := do("GET", "/metrics", "")
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+		}
+	})
+
+	t.Run("idle buckets are swept", func(t *testing.T) {
+		now = now.Add(time.Hour)
+		do("GET", "/api/events", "192.0.2.30")
+		assert.Len(t, limiter.buckets, 1)
+	})
+}
+
+func TestRateLimiter_ClientKey(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "192.0.2.1:12345"
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+
+	assert.Equal(t, "ip:192.0.2.1", newRateLimiter(config.RateLimitConfig{}).clientKey(req))
+	assert.Equal(t, "ip:203.0.113.7", newRateLimiter(config.RateLimitConfig{TrustForwardedFor: true}).clientKey(req))
+
+	// Заголовок задает сам клиент, поэтому он не меняет корзину
+	req.Header.Set("X-User-ID", "alice")
+	assert.Equal(t, "ip:192.0.2.1", newRateLimiter(config.RateLimitConfig{}).clientKey(req))
+}
@@ -5,30 +5,61 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/app"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/config"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/health"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/logger"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/metrics"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/requestctx"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/server/http/api"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/tracing"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Server struct {
 	server  *http.Server
 	app     *app.App
+	logger  *logger.Logger
 	metrics *metrics.Metrics
+	limiter *rateLimiter
+	archive config.ArchiveConfig
+	// maxBatchOperations — предел операций в /api/events:batch
+	maxBatchOperations int
+	// ready — проверки зависимостей для /readyz
+	ready *health.Checker
 }
 
-func NewServer(app *app.App, host string, port int) *Server {
+// NewServer создает HTTP-сервер API. ready проверяет зависимости сервиса для /readyz;
+// nil означает, что проверять нечего.
+func NewServer(app *app.App, cfg config.ServerConfig, logger *logger.Logger, metrics *metrics.Metrics,
+	ready *health.Checker,
+) *Server {
+	if ready == nil {
+		ready = health.NewChecker(health.DefaultTimeout)
+	}
 	s := &Server{
-		app:     app,
-		metrics: metrics.NewMetrics(),
+		app:                app,
+		logger:             logger,
+		metrics:            metrics,
+		limiter:            newRateLimiter(cfg.RateLimit),
+		archive:            cfg.Archive,
+		maxBatchOperations: cfg.MaxBatchOperations,
+		ready:              ready,
 	}
 	router := s.setupRouter()
 
 	s.server = &http.Server{
-		Addr:         fmt.Sprintf("%s:%d", host, port),
+		Addr:         fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
 		Handler:      router,
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
@@ -42,23 +73,33 @@ func (s *Server) setupRouter() *mux.Router {
 	router := mux.NewRouter()
 
 	// API routes
-	apiServer := api.NewServer(s.app, s.metrics)
+	apiServer := api.NewServer(s.app, s.metrics, api.WithArchive(s.archive),
+		api.WithMaxBatchOperations(s.maxBatchOperations))
 	apiRouter := router.PathPrefix("/api").Subrouter()
 	api.HandlerFromMux(apiServer, apiRouter)
+	// Лимит только для API: пробы и сбор метрик не должны получать 429
+	apiRouter.Use(s.limiter.middleware)
 
 	// Health check endpoint
 	router.HandleFunc("/health", s.healthCheckHandler).Methods("GET")
 
+	// Liveness: процесс отвечает на запросы. Readiness: доступны зависимости
+	router.Handle("/livez", health.NewChecker(health.DefaultTimeout)).Methods("GET")
+	router.Handle("/readyz", s.ready).Methods("GET")
+
 	// Metrics endpoint
-	router.Handle("/metrics", promhttp.Handler()).Methods("GET")
+	router.Handle("/metrics", s.metrics.Handler()).Methods("GET")
 
 	// OpenAPI specification
 	router.HandleFunc("/openapi.yaml", s.openAPIHandler).Methods("GET")
 
 	// Apply middleware
+	router.Use(s.requestIDMiddleware)
+	router.Use(tracingMiddleware)
+	router.Use(s.accessLogMiddleware)
 	router.Use(s.metricsMiddleware)
-	router.Use(loggingMiddleware)
 	router.Use(corsMiddleware)
+	router.Use(actorMiddleware)
 
 	return router
 }
@@ -86,21 +127,115 @@ func (s *Server) Stop(ctx context.Context) error {
 	return s.server.Shutdown(ctx)
 }
 
-func loggingMiddleware(next http.Handler) http.Handler {
+// Handler возвращает обработчик всех маршрутов сервера, например для httptest.Server.
+func (s *Server) Handler() http.Handler {
+	return s.server.Handler
+}
+
+// maxRequestIDLength ограничивает длину X-Request-ID, принятого от клиента
+const maxRequestIDLength = 128
+
+// requestIDMiddleware принимает X-Request-ID клиента или создает новый, возвращает его
+// в ответе и сохраняет в контексте вместе с логгером, добавляющим request_id к записям
+func (s *Server) requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get("X-Request-ID")
+		if !validRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
+		w.Header().Set("X-Request-ID", requestID)
+
+		ctx := requestctx.WithRequestID(r.Context(), requestID)
+		ctx = logger.WithContext(ctx, s.logger.WithField("request_id", requestID))
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// validRequestID допускает только короткие идентификаторы из безопасных символов,
+// чтобы значение клиента не портило логи
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		isAlnum := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+		if !isAlnum && !strings.ContainsRune("-_.:", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// tracingMiddleware продолжает трассу клиента из заголовка traceparent или начинает новую,
+// создает серверный спан на запрос и добавляет trace_id к логгеру запроса
+func tracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routeTemplate(r)
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracing.Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(r.URL.Path),
+				attribute.String("request_id", requestctx.RequestID(ctx)),
+			),
+		)
+		defer span.End()
+
+		if sc := span.SpanContext(); sc.IsValid() {
+			ctx = logger.WithContext(ctx, logger.FromContext(ctx, nil).WithField("trace_id", sc.TraceID().String()))
+		}
+
+		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(wrapped, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(wrapped.statusCode))
+		if wrapped.statusCode >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(wrapped.statusCode))
+		}
+	})
+}
+
+// accessLogMiddleware пишет структурированную запись о каждом запросе
+func (s *Server) accessLogMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Логирование запросов
 		start := time.Now()
-		next.ServeHTTP(w, r)
-		duration := time.Since(start)
-		fmt.Printf("%s %s %s\n", r.Method, r.URL.Path, duration)
+		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+		next.ServeHTTP(wrapped, r)
+
+		fields := []interface{}{
+			"method", r.Method,
+			"path", r.URL.Path,
+			"route", routeTemplate(r),
+			"status", wrapped.statusCode,
+			"size", wrapped.size,
+			"duration", time.Since(start),
+			"remote_addr", r.RemoteAddr,
+			"user_agent", r.UserAgent(),
+		}
+
+		log := logger.FromContext(r.Context(), s.logger)
+		switch {
+		case wrapped.statusCode >= http.StatusInternalServerError:
+			log.Errorw("HTTP request", fields...)
+		case wrapped.statusCode >= http.StatusBadRequest:
+			log.Warnw("HTTP request", fields...)
+		default:
+			log.Infow("HTTP request", fields...)
+		}
 	})
 }
 
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match, X-User-ID, X-Request-ID, traceparent, tracestate")
+		w.Header().Set("Access-Control-Expose-Headers",
+			"ETag, X-Request-ID, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -111,6 +246,17 @@ func corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// actorMiddleware переносит инициатора (X-User-ID) в контекст, откуда его берет журнал аудита
+func actorMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if actor := r.Header.Get("X-User-ID"); actor != "" {
+			r = r.WithContext(requestctx.WithActor(r.Context(), actor))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 func (s *Server) metricsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -122,19 +268,33 @@ func (s *Server) metricsMiddleware(next http.Handler) http.Handler {
 
 		duration := time.Since(start).Seconds()
 
-		// Записываем метрики
-		s.metrics.IncHTTPRequest(r.Method, r.URL.Path, http.StatusText(wrapped.statusCode))
-		s.metrics.ObserveHTTPRequestDuration(r.Method, r.URL.Path, duration)
+		// Записываем метрики по шаблону маршрута, а не по пути с ID ресурсов,
+		// чтобы число временных рядов не росло с числом событий
+		route := routeTemplate(r)
+		s.metrics.IncHTTPRequest(r.Method, route, http.StatusText(wrapped.statusCode))
+		s.metrics.ObserveHTTPRequestDuration(r.Method, route, duration)
 	})
 }
 
-// responseWriter - обертка для захвата статуса ответа
+// responseWriter - обертка для захвата статуса и размера ответа
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
+	size       int
 }
 
 func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	n, err := rw.ResponseWriter.Write(b)
+	rw.size += n
+	return n, err
+}
+
+// Unwrap открывает исходный ResponseWriter для http.ResponseController
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,129 @@
+package internalhttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/app"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/archive"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/config"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/health"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/logger"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/metrics"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	memorystorage "github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerMetrics(t *testing.T) {
+	newServer := func() *Server {
+		calendarApp := app.New(logger.Nop(), memorystorage.NewStorage())
+		return NewServer(calendarApp, config.ServerConfig{}, logger.Nop(), metrics.NewMetrics(metrics.NewRegistry()), nil)
+	}
+
+	// Каждый сервер регистрирует метрики в своем реестре
+	first, second := newServer(), newServer()
+
+	do := func(s *Server, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	assert.Equal(t, http.StatusNotFound, do(first, "/api/events/1").Code)
+	assert.Equal(t, http.StatusNotFound, do(first, "/api/events/2").Code)
+
+	body := do(first, "/metrics").Body.String()
+	assert.Contains(t, body, `calendar_http_requests_total{endpoint="/api/events/{id}",method="GET",status_code="Not Found"} 2`)
+	assert.NotContains(t, body, `endpoint="/api/events/1"`)
+	assert.Contains(t, body, "go_goroutines")
+
+	assert.NotContains(t, do(second, "/metrics").Body.String(), `endpoint="/api/events/{id}"`)
+}
+
+func TestServerProbes(t *testing.T) {
+	var storageErr error
+	ready := health.NewChecker(time.Second).Add("storage", func(context.Context) error { return storageErr })
+	calendarApp := app.New(logger.Nop(), memorystorage.NewStorage())
+	s := NewServer(calendarApp, config.ServerConfig{}, logger.Nop(), metrics.NewMetrics(metrics.NewRegistry()), ready)
+
+	do := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, do("/livez").Code)
+	assert.Equal(t, http.StatusOK, do("/readyz").Code)
+
+	// Недоступное хранилище делает сервис неготовым, но не мертвым
+	storageErr = errors.New("connection refused")
+	w := do("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"storage":{"status":"fail","error":"connection refused"`)
+	assert.Equal(t, http.StatusOK, do("/livez").Code)
+}
+
+func TestServerRateLimitScope(t *testing.T) {
+	calendarApp := app.New(logger.Nop(), memorystorage.NewStorage())
+	cfg := config.ServerConfig{RateLimit: config.RateLimitConfig{Default: config.RateLimitRule{Rate: 1, Burst: 1}}}
+	s := NewServer(calendarApp, cfg, logger.Nop(), metrics.NewMetrics(metrics.NewRegistry()), health.NewChecker(time.Second))
+
+	do := func(path string) int {
+		w := httptest.NewRecorder()
+		s.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w.Code
+	}
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, do("/livez"))
+		assert.Equal(t, http.StatusOK, do("/readyz"))
+		assert.Equal(t, http.StatusOK, do("/metrics"))
+	}
+
+	assert.Equal(t, http.StatusOK, do("/api/events"))
+	assert.Equal(t, http.StatusTooManyRequests, do("/api/events"))
+}
+
+func TestServerArchiveExportOutlivesWriteTimeout(t *testing.T) {
+	ctx := context.Background()
+	storage := memorystorage.NewStorage()
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	description := strings.Repeat("x", 4<<10)
+	// Архив в несколько мегабайт не помещается в буферы сокета за одну запись
+	for i := 0; i < 1000; i++ {
+		event := &models.Event{
+			Title:       fmt.Sprintf("Event %d", i),
+			Description: description,
+			StartTime:   start.Add(time.Duration(i) * time.Hour),
+			EndTime:     start.Add(time.Duration(i)*time.Hour + time.Minute),
+			UserID:      "alice",
+		}
+		require.NoError(t, storage.CreateEvent(ctx, event))
+	}
+
+	cfg := config.ServerConfig{Archive: config.ArchiveConfig{Enabled: true}}
+	s := NewServer(app.New(logger.Nop(), storage), cfg, logger.Nop(), metrics.NewMetrics(metrics.NewRegistry()), nil)
+	server := httptest.NewUnstartedServer(s.server.Handler)
+	server.Config.WriteTimeout = 20 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/archive")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Медленный клиент: выгрузка продолжается после истечения WriteTimeout
+	time.Sleep(100 * time.Millisecond)
+	backup, err := archive.Read(resp.Body)
+	require.NoError(t, err)
+	assert.Len(t, backup.Snapshot.Events, 1000)
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/requestctx"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/google/uuid"
+)
+
+// unknownActor записывается в журнал, если инициатор изменения не передан в контексте
+const unknownActor = "unknown"
+
+// NewAuditEntry строит запись журнала об изменении события. before — событие до
+// изменения (nil при создании и восстановлении), after — после (nil при удалении).
+// Хранилища записывают ее вместе с самим изменением, чтобы журнал не расходился с
+// данными; инициатор и ID запроса берутся из ctx.
+func NewAuditEntry(ctx context.Context, action models.AuditAction, before, after *models.Event) *models.AuditEntry {
+	actor := requestctx.Actor(ctx)
+	if actor == "" {
+		actor = unknownActor
+	}
+
+	entry := &models.AuditEntry{
+		ID:        uuid.New().String(),
+		Actor:     actor,
+		Action:    action,
+		Before:    snapshot(before),
+		After:     snapshot(after),
+		RequestID: requestctx.RequestID(ctx),
+		CreatedAt: time.Now(),
+	}
+	entry.Diff = diff(entry.Before, entry.After)
+
+	if after != nil {
+		entry.EventID = after.ID
+	} else if before != nil {
+		entry.EventID = before.ID
+	}
+	return entry
+}
+
+// BatchAuditAction возвращает действие журнала для операции пакета.
+func BatchAuditAction(op models.BatchOp) models.AuditAction {
+	switch op {
+	case models.BatchOpCreate:
+		return models.AuditActionCreate
+	case models.BatchOpDelete:
+		return models.AuditActionDelete
+	default:
+		return models.AuditActionUpdate
+	}
+}
+
+// snapshot сериализует событие для журнала. Перенос напоминания в журнал не попадает:
+// это состояние планировщика, а не изменение пользователя.
+func snapshot(event *models.Event) json.RawMessage {
+	if event == nil {
+		return nil
+	}
+	copied := *event
+	copied.ReminderDeferral = nil
+	data, err := json.Marshal(&copied)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// diff строит JSON Merge Patch от before к after. Для создания события это
+// само событие, для удаления — пустой diff.
+func diff(before, after json.RawMessage) json.RawMessage {
+	if after == nil {
+		return nil
+	}
+	if before == nil {
+		return after
+	}
+
+	patch, err := jsonpatch.CreateMergePatch(before, after)
+	if err != nil {
+		return nil
+	}
+	return patch
+}
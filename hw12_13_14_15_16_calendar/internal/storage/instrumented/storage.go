@@ -0,0 +1,237 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/metrics"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/storage"
+)
+
+// Storage — декоратор хранилища, записывающий количество, длительность и ошибки операций
+// в метрики calendar_storage_*. Имя операции — имя метода в snake_case.
+type Storage struct {
+	next        storage.Storage
+	metrics     *metrics.Metrics
+	storageType string
+}
+
+var _ storage.Storage = (*Storage)(nil)
+
+// New оборачивает хранилище next; storageType попадает в метку storage_type (memory, sql).
+func New(next storage.Storage, m *metrics.Metrics, storageType string) *Storage {
+	return &Storage{
+		next:        next,
+		metrics:     m,
+		storageType: storageType,
+	}
+}
+
+// observe записывает метрики операции, начатой в момент start. Вызывается через defer
+// с адресом именованной ошибки, чтобы учесть результат операции.
+func (s *Storage) observe(operation string, start time.Time, err *error) {
+	s.metrics.IncStorageOperation(operation, s.storageType)
+	s.metrics.ObserveStorageOperationDuration(operation, s.storageType, time.Since(start).Seconds())
+	if *err != nil {
+		s.metrics.IncStorageError(operation, s.storageType)
+	}
+}
+
+func (s *Storage) CreateEvent(ctx context.Context, event *models.Event) (err error) {
+	defer s.observe("create_event", time.Now(), &err)
+	return s.next.CreateEvent(ctx, event)
+}
+
+func (s *Storage) UpdateEvent(ctx context.Context, event *models.Event) (err error) {
+	defer s.observe("update_event", time.Now(), &err)
+	return s.next.UpdateEvent(ctx, event)
+}
+
+func (s *Storage) PatchEvent(ctx context.Context, event *models.Event, fields []models.EventField) (err error) {
+	defer s.observe("patch_event", time.Now(), &err)
+	return s.next.PatchEvent(ctx, event, fields)
+}
+
+func (s *Storage) DeleteEvent(ctx context.Context, id string, version int64) (err error) {
+	defer s.observe("delete_event", time.Now(), &err)
+	return s.next.DeleteEvent(ctx, id, version)
+}
+
+func (s *Storage) GetEvent(ctx context.Context, id string) (result *models.Event, err error) {
+	defer s.observe("get_event", time.Now(), &err)
+	return s.next.GetEvent(ctx, id)
+}
+
+func (s *Storage) ListEvents(ctx context.Context, from, to time.Time, filter models.EventFilter) (result []*models.Event, err error) {
+	defer s.observe("list_events", time.Now(), &err)
+	return s.next.ListEvents(ctx, from, to, filter)
+}
+
+func (s *Storage) CountEvents(ctx context.Context, userID string) (result int, err error) {
+	defer s.observe("count_events", time.Now(), &err)
+	return s.next.CountEvents(ctx, userID)
+}
+
+func (s *Storage) ListReminders(ctx context.Context, from, to time.Time) (result []*models.Event, err error) {
+	defer s.observe("list_reminders", time.Now(), &err)
+	return s.next.ListReminders(ctx, from, to)
+}
+
+func (s *Storage) DeferReminder(ctx context.Context, id string, deferral models.ReminderDeferral) (err error) {
+	defer s.observe("defer_reminder", time.Now(), &err)
+	return s.next.DeferReminder(ctx, id, deferral)
+}
+
+func (s *Storage) ListDeletedEvents(ctx context.Context) (result []*models.Event, err error) {
+	defer s.observe("list_deleted_events", time.Now(), &err)
+	return s.next.ListDeletedEvents(ctx)
+}
+
+func (s *Storage) RestoreEvent(ctx context.Context, id string) (result *models.Event, err error) {
+	defer s.observe("restore_event", time.Now(), &err)
+	return s.next.RestoreEvent(ctx, id)
+}
+
+func (s *Storage) PurgeDeletedEvents(ctx context.Context, deletedBefore time.Time) (result int, err error) {
+	defer s.observe("purge_deleted_events", time.Now(), &err)
+	return s.next.PurgeDeletedEvents(ctx, deletedBefore)
+}
+
+func (s *Storage) ApplyBatch(ctx context.Context, ops []models.BatchOperation, atomic bool) (result []models.BatchResult, err error) {
+	defer s.observe("apply_batch", time.Now(), &err)
+	return s.next.ApplyBatch(ctx, ops, atomic)
+}
+
+func (s *Storage) AppendAudit(ctx context.Context, entry *models.AuditEntry) (err error) {
+	defer s.observe("append_audit", time.Now(), &err)
+	return s.next.AppendAudit(ctx, entry)
+}
+
+func (s *Storage) ListAudit(ctx context.Context, filter models.AuditFilter) (result []*models.AuditEntry, err error) {
+	defer s.observe("list_audit", time.Now(), &err)
+	return s.next.ListAudit(ctx, filter)
+}
+
+func (s *Storage) PurgeAudit(ctx context.Context, createdBefore time.Time) (result int, err error) {
+	defer s.observe("purge_audit", time.Now(), &err)
+	return s.next.PurgeAudit(ctx, createdBefore)
+}
+
+func (s *Storage) AddAttachment(ctx context.Context, attachment *models.Attachment) (err error) {
+	defer s.observe("add_attachment", time.Now(), &err)
+	return s.next.AddAttachment(ctx, attachment)
+}
+
+func (s *Storage) ListAttachments(ctx context.Context, eventID string) (result []*models.Attachment, err error) {
+	defer s.observe("list_attachments", time.Now(), &err)
+	return s.next.ListAttachments(ctx, eventID)
+}
+
+func (s *Storage) GetAttachment(ctx context.Context, eventID, id string) (result *models.Attachment, err error) {
+	defer s.observe("get_attachment", time.Now(), &err)
+	return s.next.GetAttachment(ctx, eventID, id)
+}
+
+func (s *Storage) DeleteAttachment(ctx context.Context, eventID, id string) (err error) {
+	defer s.observe("delete_attachment", time.Now(), &err)
+	return s.next.DeleteAttachment(ctx, eventID, id)
+}
+
+func (s *Storage) CreateResource(ctx context.Context, resource *models.Resource) (err error) {
+	defer s.observe("create_resource", time.Now(), &err)
+	return s.next.CreateResource(ctx, resource)
+}
+
+func (s *Storage) UpdateResource(ctx context.Context, resource *models.Resource) (err error) {
+	defer s.observe("update_resource", time.Now(), &err)
+	return s.next.UpdateResource(ctx, resource)
+}
+
+func (s *Storage) DeleteResource(ctx context.Context, id string) (err error) {
+	defer s.observe("delete_resource", time.Now(), &err)
+	return s.next.DeleteResource(ctx, id)
+}
+
+func (s *Storage) GetResource(ctx context.Context, id string) (result *models.Resource, err error) {
+	defer s.observe("get_resource", time.Now(), &err)
+	return s.next.GetResource(ctx, id)
+}
+
+func (s *Storage) ListResources(ctx context.Context, filter models.ResourceFilter) (result []*models.Resource, err error) {
+	defer s.observe("list_resources", time.Now(), &err)
+	return s.next.ListResources(ctx, filter)
+}
+
+func (s *Storage) ListBookings(ctx context.Context, resourceID string, from, to time.Time) (result []*models.Booking, err error) {
+	defer s.observe("list_bookings", time.Now(), &err)
+	return s.next.ListBookings(ctx, resourceID, from, to)
+}
+
+func (s *Storage) GetWorkingHours(ctx context.Context, userID string) (result *models.WorkingHours, err error) {
+	defer s.observe("get_working_hours", time.Now(), &err)
+	return s.next.GetWorkingHours(ctx, userID)
+}
+
+func (s *Storage) SetWorkingHours(ctx context.Context, hours *models.WorkingHours) (err error) {
+	defer s.observe("set_working_hours", time.Now(), &err)
+	return s.next.SetWorkingHours(ctx, hours)
+}
+
+func (s *Storage) AddOutOfOffice(ctx context.Context, period *models.OutOfOffice) (err error) {
+	defer s.observe("add_out_of_office", time.Now(), &err)
+	return s.next.AddOutOfOffice(ctx, period)
+}
+
+func (s *Storage) ListOutOfOffice(ctx context.Context, userID string, from, to time.Time) (result []*models.OutOfOffice, err error) {
+	defer s.observe("list_out_of_office", time.Now(), &err)
+	return s.next.ListOutOfOffice(ctx, userID, from, to)
+}
+
+func (s *Storage) DeleteOutOfOffice(ctx context.Context, userID, id string) (err error) {
+	defer s.observe("delete_out_of_office", time.Now(), &err)
+	return s.next.DeleteOutOfOffice(ctx, userID, id)
+}
+
+func (s *Storage) CreateTemplate(ctx context.Context, template *models.EventTemplate) (err error) {
+	defer s.observe("create_template", time.Now(), &err)
+	return s.next.CreateTemplate(ctx, template)
+}
+
+func (s *Storage) UpdateTemplate(ctx context.Context, template *models.EventTemplate) (err error) {
+	defer s.observe("update_template", time.Now(), &err)
+	return s.next.UpdateTemplate(ctx, template)
+}
+
+func (s *Storage) DeleteTemplate(ctx context.Context, id string) (err error) {
+	defer s.observe("delete_template", time.Now(), &err)
+	return s.next.DeleteTemplate(ctx, id)
+}
+
+func (s *Storage) GetTemplate(ctx context.Context, id string) (result *models.EventTemplate, err error) {
+	defer s.observe("get_template", time.Now(), &err)
+	return s.next.GetTemplate(ctx, id)
+}
+
+func (s *Storage) ListTemplates(ctx context.Context) (result []*models.EventTemplate, err error) {
+	defer s.observe("list_templates", time.Now(), &err)
+	return s.next.ListTemplates(ctx)
+}
+
+func (s *Storage) Snapshot(ctx context.Context) (result *models.Snapshot, err error) {
+	defer s.observe("snapshot", time.Now(), &err)
+	return s.next.Snapshot(ctx)
+}
+
+func (s *Storage) LoadSnapshot(ctx context.Context, snapshot *models.Snapshot) (err error) {
+	defer s.observe("load_snapshot", time.Now(), &err)
+	return s.next.LoadSnapshot(ctx, snapshot)
+}
+
+func (s *Storage) Ping(ctx context.Context) error {
+	return s.next.Ping(ctx)
+}
+
+func (s *Storage) Close() error {
+	return s.next.Close()
+}
@@ -0,0 +1,47 @@
+package instrumented
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/metrics"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	memorystorage "github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/storage/memory"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumentedStorage(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	store := New(memorystorage.NewStorage(), metrics.NewMetrics(registry), "memory")
+	ctx := context.Background()
+
+	start := time.Now().Add(time.Hour)
+	event := &models.Event{Title: "Встреча", StartTime: start, EndTime: start.Add(time.Hour), UserID: "user1"}
+	require.NoError(t, store.CreateEvent(ctx, event))
+
+	_, err := store.GetEvent(ctx, event.ID)
+	require.NoError(t, err)
+	_, err = store.GetEvent(ctx, "missing")
+	require.ErrorIs(t, err, models.ErrEventNotFound)
+
+	expected := `
+# HELP calendar_storage_errors_total Общее количество операций с хранилищем, завершившихся ошибкой
+# TYPE calendar_storage_errors_total counter
+calendar_storage_errors_total{operation="get_event",storage_type="memory"} 1
+# HELP calendar_storage_operations_total Общее количество операций с хранилищем
+# TYPE calendar_storage_operations_total counter
+calendar_storage_operations_total{operation="create_event",storage_type="memory"} 1
+calendar_storage_operations_total{operation="get_event",storage_type="memory"} 2
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"calendar_storage_operations_total", "calendar_storage_errors_total"))
+
+	count, err := testutil.GatherAndCount(registry, "calendar_storage_operation_duration_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
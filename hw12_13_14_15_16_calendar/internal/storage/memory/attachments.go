@@ -0,0 +1,83 @@
+package memorystorage
+
+import (
+	"context"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+)
+
+func (s *Storage) AddAttachment(ctx context.Context, attachment *models.Attachment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.writable(); err != nil {
+		return err
+	}
+
+	if _, exists := s.events.active(attachment.EventID); !exists {
+		return models.ErrEventNotFound
+	}
+
+	stored := *attachment
+	s.attachments[attachment.EventID] = append(s.attachments[attachment.EventID], &stored)
+	return s.commit(newChange(opPutAttachment, &stored))
+}
+
+// ListAttachments возвращает вложения события в порядке добавления.
+func (s *Storage) ListAttachments(ctx context.Context, eventID string) ([]*models.Attachment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attachments := make([]*models.Attachment, 0, len(s.attachments[eventID]))
+	for _, attachment := range s.attachments[eventID] {
+		copied := *attachment
+		attachments = append(attachments, &copied)
+	}
+
+	return attachments, nil
+}
+
+func (s *Storage) GetAttachment(ctx context.Context, eventID, id string) (*models.Attachment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, attachment := range s.attachments[eventID] {
+		if attachment.ID == id {
+			copied := *attachment
+			return &copied, nil
+		}
+	}
+
+	return nil, models.ErrAttachmentNotFound
+}
+
+func (s *Storage) DeleteAttachment(ctx context.Context, eventID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.writable(); err != nil {
+		return err
+	}
+
+	if !s.removeAttachment(eventID, id) {
+		return models.ErrAttachmentNotFound
+	}
+
+	return s.commit(newChange(opDeleteAttachment, recordKey{ID: id, EventID: eventID}))
+}
+
+// removeAttachment удаляет вложение события и сообщает, было ли оно найдено.
+func (s *Storage) removeAttachment(eventID, id string) bool {
+	attachments := s.attachments[eventID]
+	for i, attachment := range attachments {
+		if attachment.ID == id {
+			s.attachments[eventID] = append(attachments[:i:i], attachments[i+1:]...)
+			if len(s.attachments[eventID]) == 0 {
+				delete(s.attachments, eventID)
+			}
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,85 @@
+package memorystorage
+
+import (
+	"context"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/storage"
+)
+
+func (s *Storage) AppendAudit(ctx context.Context, entry *models.AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.writable(); err != nil {
+		return err
+	}
+
+	stored := *entry
+	s.audit = append(s.audit, &stored)
+	return s.commit(newChange(opAppendAudit, &stored))
+}
+
+// recordAudit добавляет запись журнала аудита об изменении события и возвращает ее
+// изменение для журнала хранилища. Вызывается под блокировкой вместе с изменением.
+func (s *Storage) recordAudit(ctx context.Context, action models.AuditAction, before, after *models.Event) change {
+	entry := storage.NewAuditEntry(ctx, action, before, after)
+	s.audit = append(s.audit, entry)
+	return newChange(opAppendAudit, entry)
+}
+
+// ListAudit возвращает записи журнала от новых к старым.
+func (s *Storage) ListAudit(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var entries []*models.AuditEntry
+	for i := len(s.audit) - 1; i >= 0; i-- {
+		entry := s.audit[i]
+		if filter.EventID != "" && entry.EventID != filter.EventID {
+			continue
+		}
+		if filter.Actor != "" && entry.Actor != filter.Actor {
+			continue
+		}
+
+		copied := *entry
+		entries = append(entries, &copied)
+		if filter.Limit > 0 && len(entries) == filter.Limit {
+			break
+		}
+	}
+
+	return entries, nil
+}
+
+func (s *Storage) PurgeAudit(ctx context.Context, createdBefore time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.writable(); err != nil {
+		return 0, err
+	}
+
+	purged := s.purgeAudit(createdBefore)
+	if purged == 0 {
+		return 0, nil
+	}
+
+	return purged, s.commit(newChange(opPurgeAudit, purgeAudit{Before: createdBefore}))
+}
+
+// purgeAudit удаляет записи журнала, созданные раньше createdBefore, и возвращает их число.
+func (s *Storage) purgeAudit(createdBefore time.Time) int {
+	kept := s.audit[:0]
+	for _, entry := range s.audit {
+		if !entry.CreatedAt.Before(createdBefore) {
+			kept = append(kept, entry)
+		}
+	}
+
+	purged := len(s.audit) - len(kept)
+	s.audit = kept
+	return purged
+}
@@ -0,0 +1,464 @@
+package memorystorage
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/archive"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/logger"
+)
+
+// FsyncPolicy — когда журнал изменений сбрасывается на диск. Запись в файл выполняется
+// при каждом изменении, поэтому при аварийном завершении процесса данные не теряются
+// при любой политике; политика определяет потери при сбое ОС или питания.
+type FsyncPolicy string
+
+const (
+	// FsyncAlways сбрасывает журнал перед возвратом из каждого изменяющего метода.
+	FsyncAlways FsyncPolicy = "always"
+	// FsyncInterval сбрасывает журнал раз в Options.FsyncInterval.
+	FsyncInterval FsyncPolicy = "interval"
+	// FsyncNever оставляет сброс на усмотрение ОС.
+	FsyncNever FsyncPolicy = "never"
+)
+
+// Options — параметры сохранения хранилища на диск.
+type Options struct {
+	// Dir — каталог журнала и снимков. Каталог должен использоваться одним процессом.
+	Dir           string
+	Fsync         FsyncPolicy
+	FsyncInterval time.Duration
+	// SnapshotInterval — как часто журнал сворачивается в снимок; 0 — только в Close.
+	SnapshotInterval time.Duration
+	// Logger получает сообщения о восстановлении и фоновых ошибках; nil — без записи.
+	Logger *logger.Logger
+}
+
+// Имена файлов каталога. Снимок snapshot-N содержит все изменения до сегмента журнала N,
+// поэтому при запуске загружается последний снимок и применяются сегменты начиная с N.
+const (
+	segmentPattern  = "wal-%08d.log"
+	snapshotPattern = "snapshot-%08d.jsonl"
+	snapshotTemp    = ".snapshot-"
+)
+
+var errClosed = errors.New("memory storage is closed")
+
+// Open создает хранилище, сохраняющее изменения в каталоге opts.Dir: каждое изменение
+// дописывается в журнал, а журнал периодически сворачивается в снимок. Данные, сохраненные
+// в каталоге, восстанавливаются; оборванная при сбое последняя запись журнала отбрасывается.
+func Open(opts Options) (*Storage, error) {
+	if opts.Logger == nil {
+		opts.Logger = logger.Nop()
+	}
+	if err := os.MkdirAll(opts.Dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	s := NewStorage()
+	segment, replayed, err := s.recover(opts.Dir, opts.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(opts.Dir, fmt.Sprintf(segmentPattern, segment))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return nil, err
+	}
+	if err := syncDir(opts.Dir); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	s.wal = &journal{
+		dir:     opts.Dir,
+		policy:  opts.Fsync,
+		file:    file,
+		segment: segment,
+		changed: replayed > 0,
+	}
+	s.logger = opts.Logger
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go s.maintain(opts)
+
+	opts.Logger.Infof("Memory storage restored from %s: %d journal records replayed", opts.Dir, replayed)
+	return s, nil
+}
+
+// recover загружает последний снимок и применяет сегменты журнала после него. Возвращает
+// номер сегмента для новых записей и число примененных записей.
+func (s *Storage) recover(dir string, logg *logger.Logger) (int, int, error) {
+	snapshots, segments, err := listFiles(dir)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	start := 1
+	if len(snapshots) > 0 {
+		start = snapshots[len(snapshots)-1]
+		path := filepath.Join(dir, fmt.Sprintf(snapshotPattern, start))
+		if err := s.loadSnapshotFile(path); err != nil {
+			return 0, 0, fmt.Errorf("load snapshot %s: %w", path, err)
+		}
+	}
+	// Файлы, оставшиеся от прерванной очистки после снимка
+	if err := removeBefore(dir, start); err != nil {
+		return 0, 0, err
+	}
+
+	var pending []int
+	for _, segment := range segments {
+		if segment >= start {
+			pending = append(pending, segment)
+		}
+	}
+
+	replayed := 0
+	apply := func(rec record) error {
+		replayed++
+		return s.apply(rec)
+	}
+	for i, segment := range pending {
+		path := filepath.Join(dir, fmt.Sprintf(segmentPattern, segment))
+		valid, err := readSegment(path, apply)
+		if errors.Is(err, errTornRecord) {
+			// Оборваться при сбое может только последняя запись последнего сегмента:
+			// предыдущие сегменты сбрасываются на диск перед переключением
+			if i != len(pending)-1 {
+				return 0, 0, fmt.Errorf("journal %s is damaged at offset %d", path, valid)
+			}
+			logg.Warnf("Discarding damaged journal tail of %s after offset %d", path, valid)
+			if err := os.Truncate(path, valid); err != nil {
+				return 0, 0, err
+			}
+			continue
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if len(pending) > 0 {
+		return pending[len(pending)-1], replayed, nil
+	}
+	return start, replayed, nil
+}
+
+func (s *Storage) loadSnapshotFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	backup, err := archive.Read(bufio.NewReader(f))
+	if err != nil {
+		return err
+	}
+	s.load(&backup.Snapshot)
+	return nil
+}
+
+// maintain сбрасывает журнал и делает снимки по расписанию до вызова Close.
+func (s *Storage) maintain(opts Options) {
+	defer close(s.done)
+
+	var syncTick, snapshotTick <-chan time.Time
+	if opts.Fsync == FsyncInterval && opts.FsyncInterval > 0 {
+		ticker := time.NewTicker(opts.FsyncInterval)
+		defer ticker.Stop()
+		syncTick = ticker.C
+	}
+	if opts.SnapshotInterval > 0 {
+		ticker := time.NewTicker(opts.SnapshotInterval)
+		defer ticker.Stop()
+		snapshotTick = ticker.C
+	}
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-syncTick:
+			if err := s.wal.sync(); err != nil {
+				s.logger.Errorf("Failed to sync memory storage journal: %v", err)
+			}
+		case <-snapshotTick:
+			if err := s.checkpoint(); err != nil {
+				s.logger.Errorf("Failed to snapshot memory storage: %v", err)
+			}
+		}
+	}
+}
+
+// checkpoint записывает снимок и удаляет вошедшие в него сегменты журнала. Снимок
+// копируется под блокировкой вместе с переключением на новый сегмент, а пишется на диск
+// уже без нее: изменения, сделанные во время записи, попадают в новый сегмент.
+func (s *Storage) checkpoint() error {
+	s.checkpointMu.Lock()
+	defer s.checkpointMu.Unlock()
+
+	s.mu.Lock()
+	if !s.wal.hasChanges() {
+		s.mu.Unlock()
+		return nil
+	}
+	snapshot := s.snapshot()
+	segment, err := s.wal.rotate()
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.wal.dir, snapshotTemp+"*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	if err := archive.Write(w, &archive.Archive{Snapshot: *snapshot}); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(s.wal.dir, fmt.Sprintf(snapshotPattern, segment))); err != nil {
+		return err
+	}
+	if err := syncDir(s.wal.dir); err != nil {
+		return err
+	}
+
+	return removeBefore(s.wal.dir, segment)
+}
+
+// closeJournal останавливает фоновые задачи, сворачивает журнал в снимок, чтобы
+// следующий запуск не применял его заново, и закрывает файл журнала.
+func (s *Storage) closeJournal() error {
+	close(s.stop)
+	<-s.done
+
+	err := s.checkpoint()
+	return errors.Join(err, s.wal.close())
+}
+
+// journal — файл текущего сегмента журнала изменений.
+type journal struct {
+	dir    string
+	policy FsyncPolicy
+
+	mu      sync.Mutex
+	file    *os.File
+	segment int
+	// changed — в журнале есть изменения, не вошедшие в снимок
+	changed  bool
+	unsynced bool
+	// err — ошибка записи; после нее журнал не принимает изменения
+	err error
+}
+
+// append дописывает записи одного вызова commit и сбрасывает их на диск одним fsync.
+func (j *journal) append(frames [][]byte) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.err != nil {
+		return j.err
+	}
+	for _, frame := range frames {
+		if _, err := j.file.Write(frame); err != nil {
+			j.err = fmt.Errorf("write journal: %w", err)
+			return j.err
+		}
+	}
+	j.changed = true
+
+	if j.policy != FsyncAlways {
+		j.unsynced = true
+		return nil
+	}
+	if err := j.file.Sync(); err != nil {
+		j.err = fmt.Errorf("sync journal: %w", err)
+		return j.err
+	}
+	return nil
+}
+
+// fail останавливает прием изменений, если изменения не удалось закодировать.
+func (j *journal) fail(err error) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.err == nil {
+		j.err = fmt.Errorf("encode journal record: %w", err)
+	}
+	return j.err
+}
+
+func (j *journal) failed() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.err
+}
+
+func (j *journal) hasChanges() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.changed && j.err == nil
+}
+
+func (j *journal) sync() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.err != nil || !j.unsynced {
+		return j.err
+	}
+	if err := j.file.Sync(); err != nil {
+		j.err = fmt.Errorf("sync journal: %w", err)
+		return j.err
+	}
+	j.unsynced = false
+	return nil
+}
+
+// rotate сбрасывает и закрывает текущий сегмент и открывает следующий.
+// Возвращает номер нового сегмента.
+func (j *journal) rotate() (int, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.err != nil {
+		return 0, j.err
+	}
+
+	next := j.segment + 1
+	file, err := os.OpenFile(filepath.Join(j.dir, fmt.Sprintf(segmentPattern, next)),
+		os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return 0, err
+	}
+	if err := syncDir(j.dir); err != nil {
+		file.Close()
+		return 0, err
+	}
+	if err := j.file.Sync(); err != nil {
+		file.Close()
+		return 0, err
+	}
+	if err := j.file.Close(); err != nil {
+		file.Close()
+		return 0, err
+	}
+
+	j.file = file
+	j.segment = next
+	j.changed = false
+	j.unsynced = false
+	return next, nil
+}
+
+func (j *journal) close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if errors.Is(j.err, errClosed) {
+		return nil
+	}
+	j.err = errClosed
+	if err := j.file.Sync(); err != nil {
+		j.file.Close()
+		return err
+	}
+	return j.file.Close()
+}
+
+// listFiles возвращает отсортированные номера снимков и сегментов журнала в каталоге
+// и удаляет временные файлы недописанных снимков.
+func listFiles(dir string) (snapshots, segments []int, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		var n int
+		switch {
+		case strings.HasPrefix(name, snapshotTemp):
+			if err := os.Remove(filepath.Join(dir, name)); err != nil {
+				return nil, nil, err
+			}
+		case matchName(name, snapshotPattern, &n):
+			snapshots = append(snapshots, n)
+		case matchName(name, segmentPattern, &n):
+			segments = append(segments, n)
+		}
+	}
+
+	sort.Ints(snapshots)
+	sort.Ints(segments)
+	return snapshots, segments, nil
+}
+
+// removeBefore удаляет снимки и сегменты журнала с номерами меньше segment.
+func removeBefore(dir string, segment int) error {
+	snapshots, segments, err := listFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, n := range snapshots {
+		if n < segment {
+			if err := os.Remove(filepath.Join(dir, fmt.Sprintf(snapshotPattern, n))); err != nil {
+				return err
+			}
+		}
+	}
+	for _, n := range segments {
+		if n < segment {
+			if err := os.Remove(filepath.Join(dir, fmt.Sprintf(segmentPattern, n))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func matchName(name, pattern string, n *int) bool {
+	if _, err := fmt.Sscanf(name, pattern, n); err != nil {
+		return false
+	}
+	return fmt.Sprintf(pattern, *n) == name
+}
+
+// syncDir сбрасывает на диск каталог, чтобы созданные и переименованные файлы
+// сохранились после сбоя питания.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,342 @@
+package memorystorage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openDir(t *testing.T, dir string) *Storage {
+	t.Helper()
+
+	s, err := Open(Options{Dir: dir, Fsync: FsyncAlways})
+	require.NoError(t, err)
+	return s
+}
+
+// newEvent возвращает событие отдельного пользователя, чтобы события не пересекались.
+func newEvent(title string) *models.Event {
+	start := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	return &models.Event{
+		Title:     title,
+		StartTime: start,
+		EndTime:   start.Add(time.Hour),
+		UserID:    title,
+	}
+}
+
+// segmentPath возвращает путь единственного сегмента журнала в каталоге.
+func segmentPath(t *testing.T, dir string) string {
+	t.Helper()
+
+	matches, err := filepath.Glob(filepath.Join(dir, "wal-*.log"))
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	return matches[0]
+}
+
+func TestOpen_RestoresAfterCrash(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	s := openDir(t, dir)
+	kept := newEvent("Kept")
+	require.NoError(t, s.CreateEvent(ctx, kept))
+	kept.Title = "Kept, updated"
+	require.NoError(t, s.UpdateEvent(ctx, kept))
+	removed := newEvent("Removed")
+	require.NoError(t, s.CreateEvent(ctx, removed))
+	require.NoError(t, s.DeleteEvent(ctx, removed.ID, 0))
+
+	require.NoError(t, s.AddAttachment(ctx, &models.Attachment{ID: "a1", EventID: kept.ID, Name: "agenda.pdf"}))
+	require.NoError(t, s.AddAttachment(ctx, &models.Attachment{ID: "a2", EventID: kept.ID, Name: "notes.txt"}))
+	require.NoError(t, s.DeleteAttachment(ctx, kept.ID, "a1"))
+	require.NoError(t, s.AppendAudit(ctx, &models.AuditEntry{
+		ID: "audit1", EventID: kept.ID, Action: models.AuditActionCreate, CreatedAt: time.Now(),
+	}))
+	require.NoError(t, s.CreateTemplate(ctx, &models.EventTemplate{Name: "1:1", Duration: time.Hour}))
+
+	want, err := s.Snapshot(ctx)
+	require.NoError(t, err)
+
+	// Хранилище не закрывается: восстановление только из журнала
+	restored := openDir(t, dir)
+	defer restored.Close()
+
+	got, err := restored.Snapshot(ctx)
+	require.NoError(t, err)
+	// Сравнение в JSON: после восстановления у времени нет монотонных показаний и зоны Local
+	wantJSON, err := json.Marshal(want)
+	require.NoError(t, err)
+	gotJSON, err := json.Marshal(got)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(wantJSON), string(gotJSON))
+
+	event, err := restored.GetEvent(ctx, kept.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Kept, updated", event.Title)
+	_, err = restored.GetEvent(ctx, removed.ID)
+	assert.ErrorIs(t, err, models.ErrEventNotFound)
+	_, err = restored.RestoreEvent(ctx, removed.ID)
+	assert.NoError(t, err)
+}
+
+func TestOpen_CloseCompactsJournal(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	s := openDir(t, dir)
+	event := newEvent("Standup")
+	require.NoError(t, s.CreateEvent(ctx, event))
+	require.NoError(t, s.Close())
+	require.NoError(t, s.Close())
+
+	assert.ErrorIs(t, s.CreateEvent(ctx, newEvent("Late")), errClosed)
+	assert.ErrorIs(t, s.Ping(ctx), errClosed)
+
+	snapshots, err := filepath.Glob(filepath.Join(dir, "snapshot-*.jsonl"))
+	require.NoError(t, err)
+	assert.Len(t, snapshots, 1)
+	info, err := os.Stat(segmentPath(t, dir))
+	require.NoError(t, err)
+	assert.Zero(t, info.Size())
+
+	restored := openDir(t, dir)
+	_, err = restored.GetEvent(ctx, event.ID)
+	require.NoError(t, err)
+
+	// Снимок без новых изменений не перезаписывается
+	require.NoError(t, restored.Close())
+	again, err := filepath.Glob(filepath.Join(dir, "snapshot-*.jsonl"))
+	require.NoError(t, err)
+	assert.Equal(t, snapshots, again)
+}
+
+func TestOpen_PeriodicSnapshot(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	s, err := Open(Options{
+		Dir:              dir,
+		Fsync:            FsyncInterval,
+		FsyncInterval:    time.Millisecond,
+		SnapshotInterval: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer s.Close()
+
+	event := newEvent("Standup")
+	require.NoError(t, s.CreateEvent(ctx, event))
+
+	require.Eventually(t, func() bool {
+		snapshots, err := filepath.Glob(filepath.Join(dir, "snapshot-*.jsonl"))
+		return err == nil && len(snapshots) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	// Изменения после снимка пишутся в новый сегмент и тоже восстанавливаются
+	updated := *event
+	updated.Title = "Retro"
+	require.NoError(t, s.UpdateEvent(ctx, &updated))
+
+	restored := openDir(t, dir)
+	defer restored.Close()
+	got, err := restored.GetEvent(ctx, event.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Retro", got.Title)
+}
+
+func TestOpen_TornTail(t *testing.T) {
+	ctx := context.Background()
+
+	for name, damage := range map[string]func(t *testing.T, path string, lastRecord int64){
+		"truncated record": func(t *testing.T, path string, lastRecord int64) {
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			require.NoError(t, os.Truncate(path, lastRecord+(info.Size()-lastRecord)/2))
+		},
+		"truncated header": func(t *testing.T, path string, lastRecord int64) {
+			require.NoError(t, os.Truncate(path, lastRecord+3))
+		},
+		"zero-filled tail": func(t *testing.T, path string, lastRecord int64) {
+			require.NoError(t, os.Truncate(path, lastRecord))
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+			require.NoError(t, err)
+			_, err = f.Write(make([]byte, 3*frameHeaderSize))
+			require.NoError(t, err)
+			require.NoError(t, f.Close())
+		},
+		"corrupted checksum": func(t *testing.T, path string, lastRecord int64) {
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			data[len(data)-2] ^= 0xff
+			require.NoError(t, os.WriteFile(path, data, 0o600))
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			s := openDir(t, dir)
+			first := newEvent("First")
+			require.NoError(t, s.CreateEvent(ctx, first))
+			path := segmentPath(t, dir)
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			lastRecord := info.Size()
+
+			second := newEvent("Second")
+			require.NoError(t, s.CreateEvent(ctx, second))
+			damage(t, path, lastRecord)
+
+			restored := openDir(t, dir)
+
+			_, err = restored.GetEvent(ctx, first.ID)
+			require.NoError(t, err)
+			_, err = restored.GetEvent(ctx, second.ID)
+			assert.ErrorIs(t, err, models.ErrEventNotFound)
+
+			info, err = os.Stat(path)
+			require.NoError(t, err)
+			assert.Equal(t, lastRecord, info.Size(), "damaged tail must be truncated")
+
+			// После отброшенной записи журнал продолжает принимать изменения
+			third := newEvent("Third")
+			require.NoError(t, restored.CreateEvent(ctx, third))
+			require.NoError(t, restored.Close())
+			again := openDir(t, dir)
+			defer again.Close()
+			_, err = again.GetEvent(ctx, third.ID)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestOpen_TornBatchIsDiscardedWhole(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	s := openDir(t, dir)
+	results, err := s.ApplyBatch(ctx, []models.BatchOperation{
+		{Op: models.BatchOpCreate, Event: newEvent("First")},
+		{Op: models.BatchOpCreate, Event: newEvent("Second")},
+	}, true)
+	require.NoError(t, err)
+
+	// Пакет записан одной записью журнала: повреждение ее конца отбрасывает весь пакет
+	path := segmentPath(t, dir)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(path, info.Size()-1))
+
+	restored := openDir(t, dir)
+	defer restored.Close()
+	for _, result := range results {
+		_, err := restored.GetEvent(ctx, result.After.ID)
+		assert.ErrorIs(t, err, models.ErrEventNotFound)
+	}
+}
+
+// largeSnapshot возвращает снимок, который не помещается в одну запись журнала.
+func largeSnapshot() *models.Snapshot {
+	snapshot := &models.Snapshot{}
+	description := strings.Repeat("x", 1024)
+	for i := 0; len(snapshot.Events)*len(description) < 2*frameSize; i++ {
+		event := newEvent(fmt.Sprintf("Event %d", i))
+		event.ID = fmt.Sprintf("e%05d", i)
+		event.Description = description
+		event.Version = 1
+		snapshot.Events = append(snapshot.Events, event)
+	}
+	return snapshot
+}
+
+func TestOpen_LoadSnapshotSpansFrames(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	s := openDir(t, dir)
+	snapshot := largeSnapshot()
+	require.NoError(t, s.LoadSnapshot(ctx, snapshot))
+
+	// Хранилище не закрывается: архив восстанавливается из нескольких записей журнала
+	restored := openDir(t, dir)
+	got, err := restored.Snapshot(ctx)
+	require.NoError(t, err)
+	wantJSON, err := json.Marshal(snapshot.Events)
+	require.NoError(t, err)
+	gotJSON, err := json.Marshal(got.Events)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(wantJSON), string(gotJSON))
+	require.NoError(t, restored.Close())
+}
+
+func TestOpen_TornSnapshotIsDiscardedWhole(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	s := openDir(t, dir)
+	require.NoError(t, s.LoadSnapshot(ctx, largeSnapshot()))
+
+	// Оборвана последняя запись группы: первые записи целы, но архив отбрасывается целиком
+	path := segmentPath(t, dir)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Greater(t, info.Size(), int64(frameSize))
+	require.NoError(t, os.Truncate(path, info.Size()-1))
+
+	restored := openDir(t, dir)
+	defer restored.Close()
+	got, err := restored.Snapshot(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, got.Events)
+
+	info, err = os.Stat(path)
+	require.NoError(t, err)
+	assert.Zero(t, info.Size(), "incomplete group must be truncated")
+
+	// Загрузку можно повторить
+	assert.NoError(t, restored.LoadSnapshot(ctx, largeSnapshot()))
+}
+
+func TestOpen_DamagedSegmentBeforeLast(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	s := openDir(t, dir)
+	require.NoError(t, s.CreateEvent(ctx, newEvent("First")))
+	path := segmentPath(t, dir)
+	require.NoError(t, s.CreateEvent(ctx, newEvent("Second")))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(path, info.Size()-1))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "wal-00000002.log"), nil, 0o600))
+
+	_, err = Open(Options{Dir: dir, Fsync: FsyncAlways})
+	assert.ErrorContains(t, err, "is damaged")
+}
+
+func TestOpen_FailedWriteStopsChanges(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	s := openDir(t, dir)
+	require.NoError(t, s.CreateEvent(ctx, newEvent("First")))
+
+	// Имитация сбоя диска: файл журнала закрыт под хранилищем
+	require.NoError(t, s.wal.file.Close())
+	assert.Error(t, s.CreateEvent(ctx, newEvent("Second")))
+
+	assert.Error(t, s.Ping(ctx))
+	assert.Error(t, s.CreateTemplate(ctx, &models.EventTemplate{Name: "1:1"}))
+	_, err := s.ListEvents(ctx, time.Time{}, time.Now().AddDate(10, 0, 0), models.EventFilter{})
+	assert.NoError(t, err, "reads keep working")
+}
@@ -0,0 +1,129 @@
+package memorystorage
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/google/uuid"
+)
+
+func (s *Storage) CreateResource(ctx context.Context, resource *models.Resource) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.writable(); err != nil {
+		return err
+	}
+
+	resource.ID = uuid.New().String()
+	stored := *resource
+	s.resources[resource.ID] = &stored
+	return s.commit(newChange(opPutResource, &stored))
+}
+
+func (s *Storage) UpdateResource(ctx context.Context, resource *models.Resource) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.writable(); err != nil {
+		return err
+	}
+
+	current, exists := s.resources[resource.ID]
+	if !exists {
+		return models.ErrResourceNotFound
+	}
+
+	stored := *resource
+	stored.CreatedAt = current.CreatedAt
+	s.resources[resource.ID] = &stored
+	*resource = stored
+	return s.commit(newChange(opPutResource, &stored))
+}
+
+// DeleteResource удаляет ресурс, если его не бронирует ни одно активное событие.
+func (s *Storage) DeleteResource(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.writable(); err != nil {
+		return err
+	}
+
+	if _, exists := s.resources[id]; !exists {
+		return models.ErrResourceNotFound
+	}
+
+	for _, event := range s.events {
+		if event.DeletedAt.IsZero() && slices.Contains(event.ResourceIDs, id) {
+			return models.ErrResourceInUse
+		}
+	}
+
+	delete(s.resources, id)
+	return s.commit(newChange(opDeleteResource, recordKey{ID: id}))
+}
+
+func (s *Storage) GetResource(ctx context.Context, id string) (*models.Resource, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	resource, exists := s.resources[id]
+	if !exists {
+		return nil, models.ErrResourceNotFound
+	}
+
+	copied := *resource
+	return &copied, nil
+}
+
+// ListResources возвращает ресурсы, отсортированные по имени.
+func (s *Storage) ListResources(ctx context.Context, filter models.ResourceFilter) ([]*models.Resource, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	resources := []*models.Resource{}
+	for _, resource := range s.resources {
+		if filter.Matches(resource) {
+			copied := *resource
+			resources = append(resources, &copied)
+		}
+	}
+
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].Name < resources[j].Name
+	})
+	return resources, nil
+}
+
+// ListBookings возвращает бронирования ресурса активными событиями, пересекающиеся
+// с интервалом [from, to), по времени начала.
+func (s *Storage) ListBookings(ctx context.Context, resourceID string, from, to time.Time) ([]*models.Booking, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, exists := s.resources[resourceID]; !exists {
+		return nil, models.ErrResourceNotFound
+	}
+
+	bookings := []*models.Booking{}
+	for _, event := range s.events {
+		if event.DeletedAt.IsZero() && slices.Contains(event.ResourceIDs, resourceID) &&
+			models.Overlaps(event.StartTime, event.EndTime, from, to) {
+			bookings = append(bookings, &models.Booking{
+				ResourceID: resourceID,
+				EventID:    event.ID,
+				StartTime:  event.StartTime,
+				EndTime:    event.EndTime,
+			})
+		}
+	}
+
+	sort.Slice(bookings, func(i, j int) bool {
+		return bookings[i].StartTime.Before(bookings[j].StartTime)
+	})
+	return bookings, nil
+}
@@ -0,0 +1,88 @@
+package memorystorage
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/google/uuid"
+)
+
+func (s *Storage) GetWorkingHours(ctx context.Context, userID string) (*models.WorkingHours, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hours, exists := s.workingHours[userID]
+	if !exists {
+		return nil, models.ErrWorkingHoursNotFound
+	}
+
+	copied := *hours
+	copied.Windows = slices.Clone(hours.Windows)
+	return &copied, nil
+}
+
+func (s *Storage) SetWorkingHours(ctx context.Context, hours *models.WorkingHours) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.writable(); err != nil {
+		return err
+	}
+
+	stored := *hours
+	stored.Windows = slices.Clone(hours.Windows)
+	s.workingHours[hours.UserID] = &stored
+	return s.commit(newChange(opPutWorkingHours, &stored))
+}
+
+func (s *Storage) AddOutOfOffice(ctx context.Context, period *models.OutOfOffice) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.writable(); err != nil {
+		return err
+	}
+
+	period.ID = uuid.New().String()
+	stored := *period
+	s.outOfOffice[period.ID] = &stored
+	return s.commit(newChange(opPutOutOfOffice, &stored))
+}
+
+func (s *Storage) ListOutOfOffice(ctx context.Context, userID string, from, to time.Time) ([]*models.OutOfOffice, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var periods []*models.OutOfOffice
+	for _, period := range s.outOfOffice {
+		if period.UserID == userID && models.Overlaps(period.StartTime, period.EndTime, from, to) {
+			copied := *period
+			periods = append(periods, &copied)
+		}
+	}
+
+	sort.Slice(periods, func(i, j int) bool {
+		return periods[i].StartTime.Before(periods[j].StartTime)
+	})
+	return periods, nil
+}
+
+func (s *Storage) DeleteOutOfOffice(ctx context.Context, userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.writable(); err != nil {
+		return err
+	}
+
+	period, exists := s.outOfOffice[id]
+	if !exists || period.UserID != userID {
+		return models.ErrOutOfOfficeNotFound
+	}
+
+	delete(s.outOfOffice, id)
+	return s.commit(newChange(opDeleteOutOfOffice, recordKey{ID: id}))
+}
@@ -0,0 +1,147 @@
+package memorystorage
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+)
+
+func (s *Storage) Snapshot(ctx context.Context) (*models.Snapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.snapshot(), nil
+}
+
+func (s *Storage) LoadSnapshot(ctx context.Context, snapshot *models.Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.writable(); err != nil {
+		return err
+	}
+
+	if len(s.events) > 0 || len(s.audit) > 0 || len(s.resources) > 0 || len(s.templates) > 0 ||
+		len(s.workingHours) > 0 || len(s.outOfOffice) > 0 {
+		return models.ErrStorageNotEmpty
+	}
+
+	// Архив кодируется до изменения данных: слишком большой или некорректный архив
+	// отклоняется, а хранилище остается пустым и продолжает принимать изменения
+	var frames [][]byte
+	if s.wal != nil {
+		var err error
+		if frames, err = encodeFrames(snapshotChanges(snapshot)); err != nil {
+			return err
+		}
+	}
+
+	s.load(snapshot)
+	return s.commitFrames(frames)
+}
+
+// snapshot копирует все данные хранилища; вызывается под блокировкой.
+func (s *Storage) snapshot() *models.Snapshot {
+	snapshot := &models.Snapshot{}
+	for _, resource := range s.resources {
+		copied := *resource
+		snapshot.Resources = append(snapshot.Resources, &copied)
+	}
+	sort.Slice(snapshot.Resources, func(i, j int) bool {
+		return snapshot.Resources[i].Name < snapshot.Resources[j].Name
+	})
+
+	for _, template := range s.templates {
+		snapshot.Templates = append(snapshot.Templates, copyTemplate(template))
+	}
+	sort.Slice(snapshot.Templates, func(i, j int) bool {
+		return snapshot.Templates[i].Name < snapshot.Templates[j].Name
+	})
+
+	for _, hours := range s.workingHours {
+		copied := *hours
+		copied.Windows = slices.Clone(hours.Windows)
+		snapshot.WorkingHours = append(snapshot.WorkingHours, &copied)
+	}
+	sort.Slice(snapshot.WorkingHours, func(i, j int) bool {
+		return snapshot.WorkingHours[i].UserID < snapshot.WorkingHours[j].UserID
+	})
+
+	for _, period := range s.outOfOffice {
+		copied := *period
+		snapshot.OutOfOffice = append(snapshot.OutOfOffice, &copied)
+	}
+	sort.Slice(snapshot.OutOfOffice, func(i, j int) bool {
+		return snapshot.OutOfOffice[i].StartTime.Before(snapshot.OutOfOffice[j].StartTime)
+	})
+
+	for _, event := range s.events {
+		snapshot.Events = append(snapshot.Events, copyEvent(event))
+	}
+	sort.Slice(snapshot.Events, func(i, j int) bool {
+		a, b := snapshot.Events[i], snapshot.Events[j]
+		if !a.StartTime.Equal(b.StartTime) {
+			return a.StartTime.Before(b.StartTime)
+		}
+		return a.ID < b.ID
+	})
+
+	// Вложения выгружаются в порядке событий, внутри события — в порядке добавления.
+	for _, event := range snapshot.Events {
+		for _, attachment := range s.attachments[event.ID] {
+			copied := *attachment
+			snapshot.Attachments = append(snapshot.Attachments, &copied)
+		}
+	}
+
+	for _, entry := range s.audit {
+		copied := *entry
+		snapshot.Audit = append(snapshot.Audit, &copied)
+	}
+
+	return snapshot
+}
+
+// load добавляет данные снимка в хранилище; вызывается под блокировкой.
+func (s *Storage) load(snapshot *models.Snapshot) {
+	for _, resource := range snapshot.Resources {
+		copied := *resource
+		s.resources[resource.ID] = &copied
+	}
+	for _, template := range snapshot.Templates {
+		s.templates[template.ID] = copyTemplate(template)
+	}
+	for _, hours := range snapshot.WorkingHours {
+		copied := *hours
+		copied.Windows = slices.Clone(hours.Windows)
+		s.workingHours[hours.UserID] = &copied
+	}
+	for _, period := range snapshot.OutOfOffice {
+		copied := *period
+		s.outOfOffice[period.ID] = &copied
+	}
+	for _, event := range snapshot.Events {
+		s.events[event.ID] = copyEvent(event)
+	}
+	for _, attachment := range snapshot.Attachments {
+		copied := *attachment
+		s.attachments[attachment.EventID] = append(s.attachments[attachment.EventID], &copied)
+	}
+	for _, entry := range snapshot.Audit {
+		copied := *entry
+		s.audit = append(s.audit, &copied)
+	}
+}
+
+func copyEvent(event *models.Event) *models.Event {
+	copied := *event
+	copied.Tags = slices.Clone(event.Tags)
+	copied.ResourceIDs = slices.Clone(event.ResourceIDs)
+	if event.ReminderDeferral != nil {
+		deferral := *event.ReminderDeferral
+		copied.ReminderDeferral = &deferral
+	}
+	return &copied
+}
@@ -2,21 +2,47 @@ package memorystorage
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"sync"
 	"time"
 
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/logger"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/storage"
 	"github.com/google/uuid"
 )
 
 type Storage struct {
-	mu     sync.RWMutex
-	events map[string]*models.Event
+	mu          sync.RWMutex
+	events      eventSet
+	audit       []*models.AuditEntry
+	attachments map[string][]*models.Attachment
+	resources   map[string]*models.Resource
+	// workingHours — рабочее время по ID пользователя
+	workingHours map[string]*models.WorkingHours
+	outOfOffice  map[string]*models.OutOfOffice
+	templates    map[string]*models.EventTemplate
+
+	// wal — журнал изменений; nil, если хранилище создано NewStorage и не сохраняется на диск
+	wal          *journal
+	logger       *logger.Logger
+	checkpointMu sync.Mutex
+	stop         chan struct{}
+	done         chan struct{}
+	closeOnce    sync.Once
+	closeErr     error
 }
 
 func NewStorage() *Storage {
 	return &Storage{
-		events: make(map[string]*models.Event),
+		events:      make(eventSet),
+		attachments: make(map[string][]*models.Attachment),
+		resources:   make(map[string]*models.Resource),
+
+		workingHours: make(map[string]*models.WorkingHours),
+		outOfOffice:  make(map[string]*models.OutOfOffice),
+		templates:    make(map[string]*models.EventTemplate),
 	}
 }
 
@@ -24,52 +50,99 @@ func (s *Storage) CreateEvent(ctx context.Context, event *models.Event) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for _, e := range s.events {
-		if e.StartTime.Equal(event.StartTime) && e.UserID == event.UserID {
-			return models.ErrDateBusy
-		}
+	if err := s.writable(); err != nil {
+		return err
 	}
 
-	event.ID = uuid.New().String()
-	s.events[event.ID] = event
-	return nil
+	if err := s.checkResources(event); err != nil {
+		return err
+	}
+
+	if err := s.events.create(event); err != nil {
+		return err
+	}
+
+	return s.commit(newChange(opPutEvent, event), s.recordAudit(ctx, models.AuditActionCreate, nil, event))
 }
 
 func (s *Storage) UpdateEvent(ctx context.Context, event *models.Event) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.events[event.ID]; !exists {
-		return models.ErrEventNotFound
+	if err := s.writable(); err != nil {
+		return err
 	}
 
-	for _, e := range s.events {
-		if e.ID != event.ID && e.StartTime.Equal(event.StartTime) && e.UserID == event.UserID {
-			return models.ErrDateBusy
-		}
+	if err := s.checkResources(event); err != nil {
+		return err
 	}
 
-	s.events[event.ID] = event
-	return nil
+	before, err := s.events.update(event)
+	if err != nil {
+		return err
+	}
+
+	return s.commit(newChange(opPutEvent, event), s.recordAudit(ctx, models.AuditActionUpdate, before, event))
 }
 
-func (s *Storage) DeleteEvent(ctx context.Context, id string) error {
+func (s *Storage) PatchEvent(ctx context.Context, event *models.Event, fields []models.EventField) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.events[id]; !exists {
+	if err := s.writable(); err != nil {
+		return err
+	}
+
+	current, exists := s.events.active(event.ID)
+	if !exists {
 		return models.ErrEventNotFound
 	}
 
-	delete(s.events, id)
-	return nil
+	if event.Version != 0 && event.Version != current.Version {
+		return models.ErrVersionConflict
+	}
+
+	patched := *current
+	if err := patched.ApplyFields(event, fields); err != nil {
+		return err
+	}
+
+	if err := s.checkResources(&patched); err != nil {
+		return err
+	}
+
+	if err := s.events.conflict(&patched); err != nil {
+		return err
+	}
+
+	patched.Version = current.Version + 1
+	s.events[patched.ID] = &patched
+	*event = patched
+	return s.commit(newChange(opPutEvent, &patched), s.recordAudit(ctx, models.AuditActionPatch, current, &patched))
+}
+
+// DeleteEvent переносит событие в корзину.
+func (s *Storage) DeleteEvent(ctx context.Context, id string, version int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.writable(); err != nil {
+		return err
+	}
+
+	before, err := s.events.delete(id, version)
+	if err != nil {
+		return err
+	}
+
+	return s.commit(newChange(opPutEvent, s.events[id]), s.recordAudit(ctx, models.AuditActionDelete, before, nil))
 }
 
 func (s *Storage) GetEvent(ctx context.Context, id string) (*models.Event, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	event, exists := s.events[id]
+	event, exists := s.events.active(id)
 	if !exists {
 		return nil, models.ErrEventNotFound
 	}
@@ -77,14 +150,16 @@ func (s *Storage) GetEvent(ctx context.Context, id string) (*models.Event, error
 	return event, nil
 }
 
-func (s *Storage) ListEvents(ctx context.Context, from, to time.Time) ([]*models.Event, error) {
+func (s *Storage) ListEvents(ctx context.Context, from, to time.Time, filter models.EventFilter) ([]*models.Event, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	var events []*models.Event
 	for _, event := range s.events {
-		if (event.StartTime.After(from) || event.StartTime.Equal(from)) &&
-			(event.StartTime.Before(to) || event.StartTime.Equal(to)) {
+		if event.DeletedAt.IsZero() &&
+			(event.StartTime.After(from) || event.StartTime.Equal(from)) &&
+			(event.StartTime.Before(to) || event.StartTime.Equal(to)) &&
+			filter.Matches(event) {
 			events = append(events, event)
 		}
 	}
@@ -92,6 +167,330 @@ func (s *Storage) ListEvents(ctx context.Context, from, to time.Time) ([]*models
 	return events, nil
 }
 
+func (s *Storage) CountEvents(ctx context.Context, userID string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+	for _, event := range s.events {
+		if event.DeletedAt.IsZero() && event.UserID == userID {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (s *Storage) ListReminders(ctx context.Context, from, to time.Time) ([]*models.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var events []*models.Event
+	for _, event := range s.events {
+		at := event.ReminderAt()
+		if event.DeletedAt.IsZero() && !at.IsZero() && !at.Before(from) && at.Before(to) {
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+func (s *Storage) DeferReminder(ctx context.Context, id string, deferral models.ReminderDeferral) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.writable(); err != nil {
+		return err
+	}
+
+	current, exists := s.events.active(id)
+	if !exists {
+		return models.ErrEventNotFound
+	}
+
+	deferred := *current
+	deferred.ReminderDeferral = &deferral
+	s.events[id] = &deferred
+	return s.commit(newChange(opPutEvent, &deferred))
+}
+
+func (s *Storage) ListDeletedEvents(ctx context.Context) ([]*models.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var events []*models.Event
+	for _, event := range s.events {
+		if !event.DeletedAt.IsZero() {
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+func (s *Storage) RestoreEvent(ctx context.Context, id string) (*models.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.writable(); err != nil {
+		return nil, err
+	}
+
+	current, exists := s.events[id]
+	if !exists || current.DeletedAt.IsZero() {
+		return nil, models.ErrEventNotFound
+	}
+
+	if err := s.checkResources(current); err != nil {
+		return nil, err
+	}
+
+	if err := s.events.conflict(current); err != nil {
+		return nil, err
+	}
+
+	restored := *current
+	restored.DeletedAt = time.Time{}
+	restored.Version = current.Version + 1
+	s.events[id] = &restored
+	if err := s.commit(
+		newChange(opPutEvent, &restored),
+		s.recordAudit(ctx, models.AuditActionRestore, nil, &restored),
+	); err != nil {
+		return nil, err
+	}
+	return &restored, nil
+}
+
+func (s *Storage) PurgeDeletedEvents(ctx context.Context, deletedBefore time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.writable(); err != nil {
+		return 0, err
+	}
+
+	var changes []change
+	purged := 0
+	for id, event := range s.events {
+		if !event.DeletedAt.IsZero() && event.DeletedAt.Before(deletedBefore) {
+			delete(s.events, id)
+			delete(s.attachments, id)
+			changes = append(changes,
+				newChange(opDeleteEvent, recordKey{ID: id}),
+				s.recordAudit(ctx, models.AuditActionPurge, event, nil))
+			purged++
+		}
+	}
+
+	return purged, s.commit(changes...)
+}
+
+// ApplyBatch выполняет операции под одной блокировкой. Атомарный пакет применяется
+// к копии набора событий, которая заменяет исходный только при успехе всех операций.
+func (s *Storage) ApplyBatch(ctx context.Context, ops []models.BatchOperation, atomic bool) ([]models.BatchResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.writable(); err != nil {
+		return nil, err
+	}
+
+	events := s.events
+	if atomic {
+		events = make(eventSet, len(s.events))
+		for id, event := range s.events {
+			events[id] = event
+		}
+	}
+
+	results := make([]models.BatchResult, len(ops))
+	for i, op := range ops {
+		if err := s.checkResources(op.Event); err != nil {
+			results[i] = models.BatchResult{Err: err}
+		} else {
+			results[i] = events.apply(op)
+		}
+		if atomic && results[i].Err != nil {
+			return abortBatch(results, i), nil
+		}
+	}
+
+	s.events = events
+
+	var changes []change
+	for i, op := range ops {
+		if results[i].Err != nil {
+			continue
+		}
+		id := op.ID
+		if op.Op != models.BatchOpDelete {
+			id = op.Event.ID
+		}
+		changes = append(changes,
+			newChange(opPutEvent, s.events[id]),
+			s.recordAudit(ctx, storage.BatchAuditAction(op.Op), results[i].Before, results[i].After))
+	}
+	if err := s.commit(changes...); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// checkResources проверяет, что все ресурсы события существуют. event может быть nil
+// для операций пакета, не содержащих событие.
+func (s *Storage) checkResources(event *models.Event) error {
+	if event == nil {
+		return nil
+	}
+	for _, resourceID := range event.ResourceIDs {
+		if _, exists := s.resources[resourceID]; !exists {
+			return fmt.Errorf("%w: %s", models.ErrResourceNotFound, resourceID)
+		}
+	}
+	return nil
+}
+
+// Ping возвращает ошибку записи журнала, если хранилище сохраняется на диск.
+func (s *Storage) Ping(_ context.Context) error {
+	return s.writable()
+}
+
+// Close сворачивает журнал в снимок и закрывает его. Повторные вызовы возвращают
+// результат первого.
 func (s *Storage) Close() error {
+	if s.wal == nil {
+		return nil
+	}
+	s.closeOnce.Do(func() {
+		s.closeErr = s.closeJournal()
+	})
+	return s.closeErr
+}
+
+// abortBatch помечает все операции атомарного пакета, кроме упавшей, как отмененные.
+func abortBatch(results []models.BatchResult, failed int) []models.BatchResult {
+	for i := range results {
+		if i != failed {
+			results[i] = models.BatchResult{Err: models.ErrBatchAborted}
+		}
+	}
+	return results
+}
+
+// eventSet — события хранилища по ID, включая находящиеся в корзине.
+type eventSet map[string]*models.Event
+
+func (e eventSet) apply(op models.BatchOperation) models.BatchResult {
+	switch op.Op {
+	case models.BatchOpCreate:
+		if err := e.create(op.Event); err != nil {
+			return models.BatchResult{Err: err}
+		}
+		return models.BatchResult{After: op.Event}
+	case models.BatchOpUpdate:
+		before, err := e.update(op.Event)
+		if err != nil {
+			return models.BatchResult{Err: err}
+		}
+		return models.BatchResult{Before: before, After: op.Event}
+	case models.BatchOpDelete:
+		before, err := e.delete(op.ID, op.Version)
+		if err != nil {
+			return models.BatchResult{Err: err}
+		}
+		return models.BatchResult{Before: before}
+	default:
+		return models.BatchResult{Err: models.ErrInvalidEvent}
+	}
+}
+
+func (e eventSet) create(event *models.Event) error {
+	if err := e.conflict(event); err != nil {
+		return err
+	}
+
+	event.ID = uuid.New().String()
+	event.Version = 1
+	e[event.ID] = event
 	return nil
 }
+
+func (e eventSet) update(event *models.Event) (*models.Event, error) {
+	current, exists := e.active(event.ID)
+	if !exists {
+		return nil, models.ErrEventNotFound
+	}
+
+	if event.Version != 0 && event.Version != current.Version {
+		return nil, models.ErrVersionConflict
+	}
+
+	if err := e.conflict(event); err != nil {
+		return nil, err
+	}
+
+	event.Version = current.Version + 1
+	event.ReminderDeferral = current.ReminderDeferral
+	e[event.ID] = event
+	return current, nil
+}
+
+func (e eventSet) delete(id string, version int64) (*models.Event, error) {
+	current, exists := e.active(id)
+	if !exists {
+		return nil, models.ErrEventNotFound
+	}
+
+	if version != 0 && version != current.Version {
+		return nil, models.ErrVersionConflict
+	}
+
+	deleted := *current
+	deleted.DeletedAt = time.Now()
+	deleted.Version = current.Version + 1
+	e[id] = &deleted
+	return current, nil
+}
+
+// active возвращает событие, если оно существует и не находится в корзине.
+func (e eventSet) active(id string) (*models.Event, bool) {
+	event, exists := e[id]
+	if !exists || !event.DeletedAt.IsZero() {
+		return nil, false
+	}
+	return event, true
+}
+
+// conflict проверяет, не занято ли время события другим событием пользователя
+// и не забронированы ли его ресурсы пересекающимися по времени событиями.
+func (e eventSet) conflict(event *models.Event) error {
+	if e.isBusy(event) {
+		return models.ErrDateBusy
+	}
+
+	for _, other := range e {
+		if other.ID == event.ID || !other.DeletedAt.IsZero() ||
+			!models.Overlaps(event.StartTime, event.EndTime, other.StartTime, other.EndTime) {
+			continue
+		}
+		for _, resourceID := range event.ResourceIDs {
+			if slices.Contains(other.ResourceIDs, resourceID) {
+				return models.ErrResourceBusy
+			}
+		}
+	}
+	return nil
+}
+
+// isBusy проверяет, занято ли время начала события другим активным событием пользователя.
+func (e eventSet) isBusy(event *models.Event) bool {
+	for _, other := range e {
+		if other.ID != event.ID && other.DeletedAt.IsZero() &&
+			other.StartTime.Equal(event.StartTime) && other.UserID == event.UserID {
+			return true
+		}
+	}
+	return false
+}
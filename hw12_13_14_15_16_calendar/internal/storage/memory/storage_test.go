@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/requestctx"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -123,6 +124,48 @@ func TestMemoryStorage_UpdateEvent(t *testing.T) {
 	})
 }
 
+func TestMemoryStorage_PatchEvent(t *testing.T) {
+	ctx := context.Background()
+	storage := NewStorage()
+
+	startTime := time.Now().Truncate(time.Second)
+
+	event := &models.Event{
+		Title:       "Original Event",
+		Description: "Original Description",
+		StartTime:   startTime,
+		EndTime:     startTime.Add(time.Hour),
+		UserID:      "user1",
+	}
+	err := storage.CreateEvent(ctx, event)
+	require.NoError(t, err)
+
+	t.Run("should write only listed fields", func(t *testing.T) {
+		patch := &models.Event{
+			ID:          event.ID,
+			Title:       "Patched Event",
+			Description: "ignored",
+		}
+
+		err := storage.PatchEvent(ctx, patch, []models.EventField{models.EventFieldTitle})
+		require.NoError(t, err)
+
+		retrieved, err := storage.GetEvent(ctx, event.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Patched Event", retrieved.Title)
+		assert.Equal(t, "Original Description", retrieved.Description)
+		assert.Equal(t, int64(2), retrieved.Version)
+		assert.Equal(t, retrieved, patch)
+	})
+
+	t.Run("should return error for non-existent event", func(t *testing.T) {
+		patch := &models.Event{ID: "non-existent-id", Title: "Patched"}
+
+		err := storage.PatchEvent(ctx, patch, []models.EventField{models.EventFieldTitle})
+		require.ErrorIs(t, err, models.ErrEventNotFound)
+	})
+}
+
 func TestMemoryStorage_DeleteEvent(t *testing.T) {
 	ctx := context.Background()
 	storage := NewStorage()
@@ -140,7 +183,7 @@ func TestMemoryStorage_DeleteEvent(t *testing.T) {
 	require.NoError(t, err)
 
 	t.Run("should delete event successfully", func(t *testing.T) {
-		err := storage.DeleteEvent(ctx, event.ID)
+		err := storage.DeleteEvent(ctx, event.ID, 0)
 		require.NoError(t, err)
 
 		_, err = storage.GetEvent(ctx, event.ID)
@@ -148,9 +191,131 @@ func TestMemoryStorage_DeleteEvent(t *testing.T) {
 	})
 
 	t.Run("should return error for non-existent event", func(t *testing.T) {
-		err := storage.DeleteEvent(ctx, "non-existent-id")
+		err := storage.DeleteEvent(ctx, "non-existent-id", 0)
+		require.ErrorIs(t, err, models.ErrEventNotFound)
+	})
+}
+
+func TestMemoryStorage_Trash(t *testing.T) {
+	ctx := context.Background()
+	storage := NewStorage()
+
+	startTime := time.Now().Truncate(time.Second)
+	endTime := startTime.Add(time.Hour)
+
+	event := &models.Event{
+		Title:     "Trashed Event",
+		StartTime: startTime,
+		EndTime:   endTime,
+		UserID:    "user1",
+	}
+	err := storage.CreateEvent(ctx, event)
+	require.NoError(t, err)
+
+	err = storage.DeleteEvent(ctx, event.ID, 0)
+	require.NoError(t, err)
+
+	t.Run("should hide trashed event from list", func(t *testing.T) {
+		result, err := storage.ListEvents(ctx, startTime.Add(-time.Hour), endTime, models.EventFilter{})
+		require.NoError(t, err)
+		assert.Empty(t, result)
+
+		trash, err := storage.ListDeletedEvents(ctx)
+		require.NoError(t, err)
+		require.Len(t, trash, 1)
+		assert.Equal(t, event.ID, trash[0].ID)
+		assert.False(t, trash[0].DeletedAt.IsZero())
+	})
+
+	t.Run("should not restore into busy time slot", func(t *testing.T) {
+		other := &models.Event{
+			Title:     "Replacement Event",
+			StartTime: startTime,
+			EndTime:   endTime,
+			UserID:    "user1",
+		}
+		err := storage.CreateEvent(ctx, other)
+		require.NoError(t, err)
+
+		_, err = storage.RestoreEvent(ctx, event.ID)
+		require.ErrorIs(t, err, models.ErrDateBusy)
+
+		err = storage.DeleteEvent(ctx, other.ID, 0)
+		require.NoError(t, err)
+	})
+
+	t.Run("should restore event", func(t *testing.T) {
+		restored, err := storage.RestoreEvent(ctx, event.ID)
+		require.NoError(t, err)
+		assert.True(t, restored.DeletedAt.IsZero())
+
+		_, err = storage.GetEvent(ctx, event.ID)
+		require.NoError(t, err)
+
+		_, err = storage.RestoreEvent(ctx, event.ID)
 		require.ErrorIs(t, err, models.ErrEventNotFound)
 	})
+
+	t.Run("should purge only events deleted before cutoff", func(t *testing.T) {
+		err := storage.DeleteEvent(ctx, event.ID, 0)
+		require.NoError(t, err)
+
+		purged, err := storage.PurgeDeletedEvents(ctx, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 0, purged)
+
+		purged, err = storage.PurgeDeletedEvents(ctx, time.Now().Add(time.Second))
+		require.NoError(t, err)
+		assert.Equal(t, 2, purged)
+
+		trash, err := storage.ListDeletedEvents(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, trash)
+	})
+}
+
+func TestMemoryStorage_Versioning(t *testing.T) {
+	ctx := context.Background()
+	storage := NewStorage()
+
+	startTime := time.Now().Truncate(time.Second)
+
+	event := &models.Event{
+		Title:     "Versioned Event",
+		StartTime: startTime,
+		EndTime:   startTime.Add(time.Hour),
+		UserID:    "user1",
+	}
+	err := storage.CreateEvent(ctx, event)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), event.Version)
+
+	t.Run("should bump version on update", func(t *testing.T) {
+		update := *event
+		update.Title = "Updated"
+
+		err := storage.UpdateEvent(ctx, &update)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), update.Version)
+	})
+
+	t.Run("should reject update with stale version", func(t *testing.T) {
+		stale := *event
+		stale.Version = 1
+
+		err := storage.UpdateEvent(ctx, &stale)
+		require.ErrorIs(t, err, models.ErrVersionConflict)
+	})
+
+	t.Run("should reject delete with stale version", func(t *testing.T) {
+		err := storage.DeleteEvent(ctx, event.ID, 1)
+		require.ErrorIs(t, err, models.ErrVersionConflict)
+	})
+
+	t.Run("should delete with current version", func(t *testing.T) {
+		err := storage.DeleteEvent(ctx, event.ID, 2)
+		require.NoError(t, err)
+	})
 }
 
 func TestMemoryStorage_GetEvent(t *testing.T) {
@@ -201,12 +366,18 @@ func TestMemoryStorage_ListEvents(t *testing.T) {
 			StartTime: now,
 			EndTime:   now.Add(time.Hour),
 			UserID:    "user1",
+			Tags:      []string{"work", "planning"},
+			Category:  "meetings",
+			Priority:  models.PriorityHigh,
 		},
 		{
 			Title:     "Future Event",
 			StartTime: now.Add(2 * time.Hour),
 			EndTime:   now.Add(3 * time.Hour),
 			UserID:    "user1",
+			Tags:      []string{"work"},
+			Category:  "meetings",
+			Priority:  models.PriorityLow,
 		},
 	}
 
@@ -219,7 +390,7 @@ func TestMemoryStorage_ListEvents(t *testing.T) {
 		from := now.Add(-1 * time.Hour)
 		to := now.Add(1 * time.Hour)
 
-		result, err := storage.ListEvents(ctx, from, to)
+		result, err := storage.ListEvents(ctx, from, to, models.EventFilter{})
 		require.NoError(t, err)
 		assert.Len(t, result, 1)
 		assert.Equal(t, "Current Event", result[0].Title)
@@ -229,7 +400,7 @@ func TestMemoryStorage_ListEvents(t *testing.T) {
 		from := now.Add(5 * time.Hour)
 		to := now.Add(6 * time.Hour)
 
-		result, err := storage.ListEvents(ctx, from, to)
+		result, err := storage.ListEvents(ctx, from, to, models.EventFilter{})
 		require.NoError(t, err)
 		assert.Empty(t, result)
 	})
@@ -238,10 +409,28 @@ func TestMemoryStorage_ListEvents(t *testing.T) {
 		from := now.Add(-2 * time.Hour)
 		to := now.Add(3 * time.Hour)
 
-		result, err := storage.ListEvents(ctx, from, to)
+		result, err := storage.ListEvents(ctx, from, to, models.EventFilter{})
 		require.NoError(t, err)
 		assert.Len(t, result, 3)
 	})
+	t.Run("should filter by tags, category and priority", func(t *testing.T) {
+		from := now.Add(-2 * time.Hour)
+		to := now.Add(3 * time.Hour)
+
+		result, err := storage.ListEvents(ctx, from, to, models.EventFilter{Tags: []string{"work"}, Category: "meetings"})
+		require.NoError(t, err)
+		assert.Len(t, result, 2)
+
+		result, err = storage.ListEvents(ctx, from, to, models.EventFilter{Tags: []string{"work", "planning"}})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, "Current Event", result[0].Title)
+
+		result, err = storage.ListEvents(ctx, from, to, models.EventFilter{Priority: models.PriorityLow})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, "Future Event", result[0].Title)
+	})
 }
 
 func TestMemoryStorage_ConcurrentAccess(t *testing.T) {
@@ -276,7 +465,7 @@ func TestMemoryStorage_ConcurrentAccess(t *testing.T) {
 	}
 
 	// Verify all events were created.
-	allEvents, err := storage.ListEvents(ctx, time.Now().Add(-time.Hour), time.Now().Add(time.Hour*24))
+	allEvents, err := storage.ListEvents(ctx, time.Now().Add(-time.Hour), time.Now().Add(time.Hour*24), models.EventFilter{})
 	require.NoError(t, err)
 	assert.Len(t, allEvents, goroutines*eventsPerGoroutine)
 }
@@ -290,3 +479,518 @@ func TestMemoryStorage_Close(t *testing.T) {
 	err = storage.Close()
 	require.NoError(t, err)
 }
+
+func TestMemoryStorage_Audit(t *testing.T) {
+	ctx := context.Background()
+	storage := NewStorage()
+
+	now := time.Now()
+	entries := []*models.AuditEntry{
+		{ID: "1", EventID: "event1", Actor: "alice", Action: models.AuditActionCreate, CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: "2", EventID: "event1", Actor: "bob", Action: models.AuditActionUpdate, CreatedAt: now.Add(-time.Hour)},
+		{ID: "3", EventID: "event2", Actor: "alice", Action: models.AuditActionCreate, CreatedAt: now},
+	}
+	for _, entry := range entries {
+		require.NoError(t, storage.AppendAudit(ctx, entry))
+	}
+
+	t.Run("should filter by event newest first", func(t *testing.T) {
+		result, err := storage.ListAudit(ctx, models.AuditFilter{EventID: "event1"})
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		assert.Equal(t, "2", result[0].ID)
+		assert.Equal(t, "1", result[1].ID)
+	})
+
+	t.Run("should filter by actor with limit", func(t *testing.T) {
+		result, err := storage.ListAudit(ctx, models.AuditFilter{Actor: "alice", Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, "3", result[0].ID)
+	})
+
+	t.Run("should purge old entries", func(t *testing.T) {
+		purged, err := storage.PurgeAudit(ctx, now.Add(-30*time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, 2, purged)
+
+		result, err := storage.ListAudit(ctx, models.AuditFilter{})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, "3", result[0].ID)
+	})
+}
+
+func TestMemoryStorage_AuditEventChanges(t *testing.T) {
+	ctx := requestctx.WithRequestID(requestctx.WithActor(context.Background(), "alice"), "req-1")
+	storage := NewStorage()
+	startTime := time.Now().Add(time.Hour)
+
+	event := &models.Event{Title: "Standup", StartTime: startTime, EndTime: startTime.Add(time.Hour), UserID: "user1"}
+	require.NoError(t, storage.CreateEvent(ctx, event))
+	updated := *event
+	updated.Title = "Retro"
+	require.NoError(t, storage.UpdateEvent(ctx, &updated))
+	require.NoError(t, storage.DeleteEvent(ctx, event.ID, 0))
+	_, err := storage.RestoreEvent(ctx, event.ID)
+	require.NoError(t, err)
+
+	// Неудачное изменение не попадает в журнал
+	assert.ErrorIs(t, storage.DeleteEvent(ctx, event.ID, 42), models.ErrVersionConflict)
+
+	entries, err := storage.ListAudit(ctx, models.AuditFilter{EventID: event.ID})
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+
+	actions := make([]models.AuditAction, 0, len(entries))
+	for _, entry := range entries {
+		actions = append(actions, entry.Action)
+		assert.Equal(t, "alice", entry.Actor)
+		assert.Equal(t, "req-1", entry.RequestID)
+	}
+	assert.Equal(t, []models.AuditAction{
+		models.AuditActionRestore, models.AuditActionDelete, models.AuditActionUpdate, models.AuditActionCreate,
+	}, actions)
+
+	update := entries[2]
+	assert.Contains(t, string(update.Before), `"title":"Standup"`)
+	assert.Contains(t, string(update.After), `"title":"Retro"`)
+	assert.JSONEq(t, `{"title":"Retro","version":2}`, string(update.Diff))
+}
+
+func TestMemoryStorage_ApplyBatch(t *testing.T) {
+	ctx := context.Background()
+	startTime := time.Now().Add(time.Hour)
+
+	newStorage := func(t *testing.T) (*Storage, *models.Event) {
+		t.Helper()
+		storage := NewStorage()
+		existing := &models.Event{Title: "Existing", StartTime: startTime, UserID: "user1"}
+		require.NoError(t, storage.CreateEvent(ctx, existing))
+		return storage, existing
+	}
+
+	t.Run("atomic batch should apply all operations", func(t *testing.T) {
+		storage, existing := newStorage(t)
+
+		updated := *existing
+		updated.Title = "Updated"
+		results, err := storage.ApplyBatch(ctx, []models.BatchOperation{
+			{Op: models.BatchOpCreate, Event: &models.Event{Title: "New", StartTime: startTime.Add(time.Hour), UserID: "user1"}},
+			{Op: models.BatchOpUpdate, Event: &updated},
+		}, true)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		require.NoError(t, results[0].Err)
+		require.NoError(t, results[1].Err)
+		assert.Equal(t, "Existing", results[1].Before.Title)
+		assert.Equal(t, int64(2), results[1].After.Version)
+
+		events, err := storage.ListEvents(ctx, startTime, startTime.Add(2*time.Hour), models.EventFilter{})
+		require.NoError(t, err)
+		assert.Len(t, events, 2)
+	})
+
+	t.Run("atomic batch should roll back on error", func(t *testing.T) {
+		storage, existing := newStorage(t)
+
+		results, err := storage.ApplyBatch(ctx, []models.BatchOperation{
+			{Op: models.BatchOpDelete, ID: existing.ID},
+			{Op: models.BatchOpUpdate, Event: &models.Event{ID: "missing", Title: "Missing"}},
+		}, true)
+		require.NoError(t, err)
+		assert.ErrorIs(t, results[0].Err, models.ErrBatchAborted)
+		assert.ErrorIs(t, results[1].Err, models.ErrEventNotFound)
+
+		results, err = storage.ApplyBatch(ctx, []models.BatchOperation{
+			{Op: models.BatchOpCreate, Event: &models.Event{Title: "Other", StartTime: startTime.Add(time.Hour), UserID: "user1"}},
+			{Op: models.BatchOpCreate, Event: &models.Event{Title: "Busy", StartTime: startTime, UserID: "user1"}},
+		}, true)
+		require.NoError(t, err)
+		assert.ErrorIs(t, results[0].Err, models.ErrBatchAborted)
+		assert.ErrorIs(t, results[1].Err, models.ErrDateBusy)
+
+		events, err := storage.ListEvents(ctx, startTime, startTime.Add(2*time.Hour), models.EventFilter{})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, existing.ID, events[0].ID)
+	})
+
+	t.Run("best effort batch should keep successful operations", func(t *testing.T) {
+		storage, existing := newStorage(t)
+
+		results, err := storage.ApplyBatch(ctx, []models.BatchOperation{
+			{Op: models.BatchOpDelete, ID: existing.ID, Version: 7},
+			{Op: models.BatchOpCreate, Event: &models.Event{Title: "New", StartTime: startTime.Add(time.Hour), UserID: "user1"}},
+		}, false)
+		require.NoError(t, err)
+		assert.ErrorIs(t, results[0].Err, models.ErrVersionConflict)
+		require.NoError(t, results[1].Err)
+
+		events, err := storage.ListEvents(ctx, startTime, startTime.Add(2*time.Hour), models.EventFilter{})
+		require.NoError(t, err)
+		assert.Len(t, events, 2)
+	})
+}
+
+func TestMemoryStorage_Attachments(t *testing.T) {
+	ctx := context.Background()
+	storage := NewStorage()
+
+	event := &models.Event{Title: "Meeting", StartTime: time.Now().Add(time.Hour), UserID: "user1"}
+	require.NoError(t, storage.CreateEvent(ctx, event))
+
+	first := &models.Attachment{ID: "a1", EventID: event.ID, Name: "agenda.pdf", ContentType: "application/pdf"}
+	second := &models.Attachment{ID: "a2", EventID: event.ID, Name: "Docs", URL: "https://example.com/docs"}
+	require.NoError(t, storage.AddAttachment(ctx, first))
+	require.NoError(t, storage.AddAttachment(ctx, second))
+
+	t.Run("should reject attachment for missing event", func(t *testing.T) {
+		err := storage.AddAttachment(ctx, &models.Attachment{ID: "a3", EventID: "missing"})
+		assert.ErrorIs(t, err, models.ErrEventNotFound)
+	})
+
+	t.Run("should list and get attachments", func(t *testing.T) {
+		attachments, err := storage.ListAttachments(ctx, event.ID)
+		require.NoError(t, err)
+		require.Len(t, attachments, 2)
+		assert.Equal(t, "a1", attachments[0].ID)
+
+		attachment, err := storage.GetAttachment(ctx, event.ID, "a2")
+		require.NoError(t, err)
+		assert.True(t, attachment.IsLink())
+
+		_, err = storage.GetAttachment(ctx, "other", "a2")
+		assert.ErrorIs(t, err, models.ErrAttachmentNotFound)
+	})
+
+	t.Run("should delete attachment", func(t *testing.T) {
+		require.NoError(t, storage.DeleteAttachment(ctx, event.ID, "a2"))
+		assert.ErrorIs(t, storage.DeleteAttachment(ctx, event.ID, "a2"), models.ErrAttachmentNotFound)
+	})
+
+	t.Run("should drop attachments with purged event", func(t *testing.T) {
+		require.NoError(t, storage.DeleteEvent(ctx, event.ID, 0))
+		_, err := storage.PurgeDeletedEvents(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+
+		attachments, err := storage.ListAttachments(ctx, event.ID)
+		require.NoError(t, err)
+		assert.Empty(t, attachments)
+	})
+}
+
+func TestMemoryStorage_Resources(t *testing.T) {
+	ctx := context.Background()
+	storage := NewStorage()
+
+	room := &models.Resource{Name: "Room 1", Kind: models.ResourceKindRoom, Capacity: 8}
+	projector := &models.Resource{Name: "Projector", Kind: models.ResourceKindEquipment}
+	require.NoError(t, storage.CreateResource(ctx, room))
+	require.NoError(t, storage.CreateResource(ctx, projector))
+
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	event := &models.Event{
+		Title:       "Planning",
+		StartTime:   start,
+		EndTime:     start.Add(time.Hour),
+		UserID:      "user1",
+		ResourceIDs: []string{room.ID},
+	}
+	require.NoError(t, storage.CreateEvent(ctx, event))
+
+	t.Run("should filter resources", func(t *testing.T) {
+		resources, err := storage.ListResources(ctx, models.ResourceFilter{MinCapacity: 4})
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+		assert.Equal(t, room.ID, resources[0].ID)
+
+		resources, err = storage.ListResources(ctx, models.ResourceFilter{Kind: models.ResourceKindEquipment})
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+		assert.Equal(t, projector.ID, resources[0].ID)
+	})
+
+	t.Run("should reject unknown resource", func(t *testing.T) {
+		err := storage.CreateEvent(ctx, &models.Event{
+			Title:       "Unknown",
+			StartTime:   start.Add(5 * time.Hour),
+			EndTime:     start.Add(6 * time.Hour),
+			UserID:      "user1",
+			ResourceIDs: []string{"missing"},
+		})
+		assert.ErrorIs(t, err, models.ErrResourceNotFound)
+	})
+
+	t.Run("should reject overlapping booking of another user", func(t *testing.T) {
+		err := storage.CreateEvent(ctx, &models.Event{
+			Title:       "Overlap",
+			StartTime:   start.Add(30 * time.Minute),
+			EndTime:     start.Add(90 * time.Minute),
+			UserID:      "user2",
+			ResourceIDs: []string{room.ID, projector.ID},
+		})
+		assert.ErrorIs(t, err, models.ErrResourceBusy)
+	})
+
+	t.Run("should allow adjacent booking", func(t *testing.T) {
+		next := &models.Event{
+			Title:       "Next",
+			StartTime:   start.Add(time.Hour),
+			EndTime:     start.Add(2 * time.Hour),
+			UserID:      "user2",
+			ResourceIDs: []string{room.ID},
+		}
+		require.NoError(t, storage.CreateEvent(ctx, next))
+
+		bookings, err := storage.ListBookings(ctx, room.ID, start, start.Add(3*time.Hour))
+		require.NoError(t, err)
+		require.Len(t, bookings, 2)
+		assert.Equal(t, event.ID, bookings[0].EventID)
+		assert.Equal(t, next.ID, bookings[1].EventID)
+	})
+
+	t.Run("should not delete booked resource", func(t *testing.T) {
+		assert.ErrorIs(t, storage.DeleteResource(ctx, room.ID), models.ErrResourceInUse)
+		require.NoError(t, storage.DeleteResource(ctx, projector.ID))
+		assert.ErrorIs(t, storage.DeleteResource(ctx, projector.ID), models.ErrResourceNotFound)
+	})
+
+	t.Run("should release booking when event is trashed", func(t *testing.T) {
+		require.NoError(t, storage.DeleteEvent(ctx, event.ID, 0))
+
+		bookings, err := storage.ListBookings(ctx, room.ID, start, start.Add(time.Hour))
+		require.NoError(t, err)
+		assert.Empty(t, bookings)
+	})
+}
+
+func TestMemoryStorage_Schedule(t *testing.T) {
+	ctx := context.Background()
+	storage := NewStorage()
+
+	_, err := storage.GetWorkingHours(ctx, "user1")
+	assert.ErrorIs(t, err, models.ErrWorkingHoursNotFound)
+
+	hours := &models.WorkingHours{
+		UserID:   "user1",
+		TimeZone: "UTC",
+		Windows:  []models.WorkingWindow{{Weekday: time.Monday, Start: 9 * time.Hour, End: 18 * time.Hour}},
+	}
+	require.NoError(t, storage.SetWorkingHours(ctx, hours))
+
+	stored, err := storage.GetWorkingHours(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, hours.Windows, stored.Windows)
+
+	start := time.Now().Truncate(time.Hour)
+	later := &models.OutOfOffice{UserID: "user1", StartTime: start.Add(48 * time.Hour), EndTime: start.Add(72 * time.Hour)}
+	sooner := &models.OutOfOffice{UserID: "user1", StartTime: start, EndTime: start.Add(24 * time.Hour)}
+	other := &models.OutOfOffice{UserID: "user2", StartTime: start, EndTime: start.Add(24 * time.Hour)}
+	for _, period := range []*models.OutOfOffice{later, sooner, other} {
+		require.NoError(t, storage.AddOutOfOffice(ctx, period))
+	}
+
+	t.Run("should list overlapping periods of user", func(t *testing.T) {
+		periods, err := storage.ListOutOfOffice(ctx, "user1", start.Add(time.Hour), start.Add(49*time.Hour))
+		require.NoError(t, err)
+		require.Len(t, periods, 2)
+		assert.Equal(t, sooner.ID, periods[0].ID)
+		assert.Equal(t, later.ID, periods[1].ID)
+	})
+
+	t.Run("should delete only own period", func(t *testing.T) {
+		assert.ErrorIs(t, storage.DeleteOutOfOffice(ctx, "user2", sooner.ID), models.ErrOutOfOfficeNotFound)
+		require.NoError(t, storage.DeleteOutOfOffice(ctx, "user1", sooner.ID))
+	})
+
+	t.Run("should list reminders in interval", func(t *testing.T) {
+		event := &models.Event{
+			Title:     "Meeting",
+			StartTime: start.Add(5 * time.Hour),
+			EndTime:   start.Add(6 * time.Hour),
+			UserID:    "user1",
+			Reminder:  start.Add(4 * time.Hour),
+		}
+		require.NoError(t, storage.CreateEvent(ctx, event))
+
+		events, err := storage.ListReminders(ctx, start.Add(4*time.Hour), start.Add(5*time.Hour))
+		require.NoError(t, err)
+		assert.Len(t, events, 1)
+
+		events, err = storage.ListReminders(ctx, start, start.Add(4*time.Hour))
+		require.NoError(t, err)
+		assert.Empty(t, events)
+	})
+
+	t.Run("should defer reminder without changing event", func(t *testing.T) {
+		event := &models.Event{
+			Title:     "Review",
+			StartTime: start.Add(10 * time.Hour),
+			EndTime:   start.Add(11 * time.Hour),
+			UserID:    "user1",
+			Reminder:  start.Add(2 * time.Hour),
+		}
+		require.NoError(t, storage.CreateEvent(ctx, event))
+
+		deferral := models.ReminderDeferral{From: event.Reminder, To: start.Add(9 * time.Hour)}
+		require.NoError(t, storage.DeferReminder(ctx, event.ID, deferral))
+
+		stored, err := storage.GetEvent(ctx, event.ID)
+		require.NoError(t, err)
+		assert.Equal(t, event.Version, stored.Version)
+		assert.Equal(t, event.Reminder, stored.Reminder)
+
+		events, err := storage.ListReminders(ctx, start.Add(2*time.Hour), start.Add(3*time.Hour))
+		require.NoError(t, err)
+		assert.Empty(t, events)
+
+		events, err = storage.ListReminders(ctx, start.Add(9*time.Hour), start.Add(10*time.Hour))
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, event.ID, events[0].ID)
+
+		entries, err := storage.ListAudit(ctx, models.AuditFilter{EventID: event.ID})
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("should keep deferral until reminder changes", func(t *testing.T) {
+		event := &models.Event{
+			Title:     "Retro",
+			StartTime: start.Add(12 * time.Hour),
+			EndTime:   start.Add(13 * time.Hour),
+			UserID:    "user1",
+			Reminder:  start.Add(2 * time.Hour),
+		}
+		require.NoError(t, storage.CreateEvent(ctx, event))
+		deferral := models.ReminderDeferral{From: event.Reminder, To: start.Add(9 * time.Hour)}
+		require.NoError(t, storage.DeferReminder(ctx, event.ID, deferral))
+
+		renamed := *event
+		renamed.Title = "Retrospective"
+		require.NoError(t, storage.UpdateEvent(ctx, &renamed))
+		stored, err := storage.GetEvent(ctx, event.ID)
+		require.NoError(t, err)
+		assert.Equal(t, deferral.To, stored.ReminderAt())
+
+		moved := &models.Event{ID: event.ID, Reminder: start.Add(3 * time.Hour)}
+		require.NoError(t, storage.PatchEvent(ctx, moved, []models.EventField{models.EventFieldReminder}))
+		stored, err = storage.GetEvent(ctx, event.ID)
+		require.NoError(t, err)
+		assert.Equal(t, start.Add(3*time.Hour), stored.ReminderAt())
+	})
+
+	t.Run("should not defer reminder of missing event", func(t *testing.T) {
+		err := storage.DeferReminder(ctx, "missing", models.ReminderDeferral{})
+		assert.ErrorIs(t, err, models.ErrEventNotFound)
+	})
+}
+
+func TestMemoryStorage_Templates(t *testing.T) {
+	ctx := context.Background()
+	storage := NewStorage()
+
+	retro := &models.EventTemplate{Name: "Retro", TitlePattern: "Retro {team}", Duration: time.Hour, Tags: []string{"team"}}
+	oneOnOne := &models.EventTemplate{Name: "1:1", TitlePattern: "1:1 {user}", Duration: 30 * time.Minute}
+	require.NoError(t, storage.CreateTemplate(ctx, retro))
+	require.NoError(t, storage.CreateTemplate(ctx, oneOnOne))
+	assert.NotEmpty(t, retro.ID)
+
+	t.Run("should list templates by name", func(t *testing.T) {
+		templates, err := storage.ListTemplates(ctx)
+		require.NoError(t, err)
+		require.Len(t, templates, 2)
+		assert.Equal(t, oneOnOne.ID, templates[0].ID)
+		assert.Equal(t, retro.ID, templates[1].ID)
+	})
+
+	t.Run("should not share tags with the caller", func(t *testing.T) {
+		retro.Tags[0] = "changed"
+		stored, err := storage.GetTemplate(ctx, retro.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"team"}, stored.Tags)
+	})
+
+	t.Run("should keep creation time on update", func(t *testing.T) {
+		created, err := storage.GetTemplate(ctx, retro.ID)
+		require.NoError(t, err)
+
+		update := &models.EventTemplate{ID: retro.ID, Name: "Retrospective", TitlePattern: "Retro", Duration: 90 * time.Minute}
+		require.NoError(t, storage.UpdateTemplate(ctx, update))
+		assert.Equal(t, created.CreatedAt, update.CreatedAt)
+
+		stored, err := storage.GetTemplate(ctx, retro.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Retrospective", stored.Name)
+		assert.Equal(t, 90*time.Minute, stored.Duration)
+
+		err = storage.UpdateTemplate(ctx, &models.EventTemplate{ID: "missing"})
+		assert.ErrorIs(t, err, models.ErrTemplateNotFound)
+	})
+
+	t.Run("should delete template", func(t *testing.T) {
+		require.NoError(t, storage.DeleteTemplate(ctx, oneOnOne.ID))
+		_, err := storage.GetTemplate(ctx, oneOnOne.ID)
+		assert.ErrorIs(t, err, models.ErrTemplateNotFound)
+		assert.ErrorIs(t, storage.DeleteTemplate(ctx, oneOnOne.ID), models.ErrTemplateNotFound)
+	})
+}
+
+func TestMemoryStorage_Snapshot(t *testing.T) {
+	ctx := context.Background()
+	source := NewStorage()
+
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	room := &models.Resource{Name: "Room", Kind: models.ResourceKindRoom, Capacity: 4}
+	require.NoError(t, source.CreateResource(ctx, room))
+	require.NoError(t, source.CreateTemplate(ctx, &models.EventTemplate{Name: "Retro", TitlePattern: "Retro", Duration: time.Hour}))
+	require.NoError(t, source.SetWorkingHours(ctx, &models.WorkingHours{UserID: "alice", TimeZone: "UTC"}))
+	require.NoError(t, source.AddOutOfOffice(ctx, &models.OutOfOffice{UserID: "alice", StartTime: start, EndTime: start.Add(time.Hour)}))
+
+	event := &models.Event{Title: "Sync", StartTime: start, EndTime: start.Add(time.Hour), UserID: "alice",
+		ResourceIDs: []string{room.ID}}
+	require.NoError(t, source.CreateEvent(ctx, event))
+	require.NoError(t, source.UpdateEvent(ctx, event))
+	require.NoError(t, source.AddAttachment(ctx, &models.Attachment{ID: "a1", EventID: event.ID, Name: "notes.txt"}))
+	require.NoError(t, source.AppendAudit(ctx, &models.AuditEntry{ID: "l1", EventID: event.ID, Action: models.AuditActionCreate}))
+
+	deleted := &models.Event{Title: "Old", StartTime: start.Add(2 * time.Hour), EndTime: start.Add(3 * time.Hour), UserID: "alice"}
+	require.NoError(t, source.CreateEvent(ctx, deleted))
+	require.NoError(t, source.DeleteEvent(ctx, deleted.ID, 0))
+
+	snapshot, err := source.Snapshot(ctx)
+	require.NoError(t, err)
+	require.Len(t, snapshot.Events, 2)
+	assert.Equal(t, event.ID, snapshot.Events[0].ID)
+	assert.Len(t, snapshot.Attachments, 1)
+
+	target := NewStorage()
+	require.NoError(t, target.LoadSnapshot(ctx, snapshot))
+
+	t.Run("should keep ids and versions", func(t *testing.T) {
+		restored, err := target.GetEvent(ctx, event.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), restored.Version)
+		assert.Equal(t, []string{room.ID}, restored.ResourceIDs)
+
+		trash, err := target.ListDeletedEvents(ctx)
+		require.NoError(t, err)
+		require.Len(t, trash, 1)
+		assert.Equal(t, deleted.ID, trash[0].ID)
+
+		copied, err := target.Snapshot(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, snapshot, copied)
+	})
+
+	t.Run("should keep bookings", func(t *testing.T) {
+		clash := &models.Event{Title: "Clash", StartTime: start, EndTime: start.Add(time.Hour), UserID: "bob",
+			ResourceIDs: []string{room.ID}}
+		assert.ErrorIs(t, target.CreateEvent(ctx, clash), models.ErrResourceBusy)
+	})
+
+	t.Run("should refuse non-empty storage", func(t *testing.T) {
+		assert.ErrorIs(t, source.LoadSnapshot(ctx, snapshot), models.ErrStorageNotEmpty)
+	})
+}
@@ -0,0 +1,92 @@
+package memorystorage
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/google/uuid"
+)
+
+func (s *Storage) CreateTemplate(ctx context.Context, template *models.EventTemplate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.writable(); err != nil {
+		return err
+	}
+
+	template.ID = uuid.New().String()
+	s.templates[template.ID] = copyTemplate(template)
+	return s.commit(newChange(opPutTemplate, s.templates[template.ID]))
+}
+
+func (s *Storage) UpdateTemplate(ctx context.Context, template *models.EventTemplate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.writable(); err != nil {
+		return err
+	}
+
+	current, exists := s.templates[template.ID]
+	if !exists {
+		return models.ErrTemplateNotFound
+	}
+
+	template.CreatedAt = current.CreatedAt
+	s.templates[template.ID] = copyTemplate(template)
+	return s.commit(newChange(opPutTemplate, s.templates[template.ID]))
+}
+
+func (s *Storage) DeleteTemplate(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.writable(); err != nil {
+		return err
+	}
+
+	if _, exists := s.templates[id]; !exists {
+		return models.ErrTemplateNotFound
+	}
+
+	delete(s.templates, id)
+	return s.commit(newChange(opDeleteTemplate, recordKey{ID: id}))
+}
+
+func (s *Storage) GetTemplate(ctx context.Context, id string) (*models.EventTemplate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	template, exists := s.templates[id]
+	if !exists {
+		return nil, models.ErrTemplateNotFound
+	}
+
+	return copyTemplate(template), nil
+}
+
+// ListTemplates возвращает шаблоны, отсортированные по имени.
+func (s *Storage) ListTemplates(ctx context.Context) ([]*models.EventTemplate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	templates := make([]*models.EventTemplate, 0, len(s.templates))
+	for _, template := range s.templates {
+		templates = append(templates, copyTemplate(template))
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates, nil
+}
+
+// copyTemplate копирует шаблон вместе с тегами, чтобы вызывающий не менял хранимое значение.
+func copyTemplate(template *models.EventTemplate) *models.EventTemplate {
+	copied := *template
+	copied.Tags = slices.Clone(template.Tags)
+	return &copied
+}
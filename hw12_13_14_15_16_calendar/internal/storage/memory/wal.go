@@ -0,0 +1,370 @@
+package memorystorage
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+)
+
+// Журнал хранит состояние записей после изменения, а не вызовы методов: повторное
+// применение не зависит от uuid и time.Now и дает то же состояние, что и до остановки.
+const (
+	opPutEvent          = "put_event"
+	opDeleteEvent       = "delete_event"
+	opPutAttachment     = "put_attachment"
+	opDeleteAttachment  = "delete_attachment"
+	opAppendAudit       = "append_audit"
+	opPurgeAudit        = "purge_audit"
+	opPutResource       = "put_resource"
+	opDeleteResource    = "delete_resource"
+	opPutWorkingHours   = "put_working_hours"
+	opPutOutOfOffice    = "put_out_of_office"
+	opDeleteOutOfOffice = "delete_out_of_office"
+	opPutTemplate       = "put_template"
+	opDeleteTemplate    = "delete_template"
+	// opFrames открывает изменения одного вызова commit, разбитые на несколько записей
+	opFrames = "frames"
+)
+
+// Запись в файле журнала: длина данных и их CRC-32C (по 4 байта, little endian), затем
+// JSON-массив изменений одного вызова commit. Запись, оборванная при сбое, не проходит
+// проверку длины или суммы и отбрасывается целиком, поэтому изменения одного вызова
+// (например, событие и запись аудита о нем) восстанавливаются все или ни одного.
+//
+// Крупный вызов commit, например загрузка архива, разбивается на записи примерно по
+// frameSize. Первая из них начинается с изменения opFrames с числом записей, и при
+// восстановлении группа применяется только целиком.
+const (
+	frameHeaderSize = 8
+	// frameSize — размер данных, после которого изменения переносятся в следующую запись
+	frameSize = 1 << 20
+	// maxRecordSize защищает от выделения памяти по поврежденной длине
+	maxRecordSize = 64 << 20
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// change — одно изменение хранилища: вид записи журнала и новое состояние объекта.
+type change struct {
+	op    string
+	value any
+}
+
+// record — изменение в записи журнала.
+type record struct {
+	Op   string          `json:"op"`
+	Data json.RawMessage `json:"data"`
+}
+
+// recordKey — данные записей удаления.
+type recordKey struct {
+	ID      string `json:"id"`
+	EventID string `json:"event_id,omitempty"`
+}
+
+type purgeAudit struct {
+	Before time.Time `json:"before"`
+}
+
+// frameGroup — данные изменения opFrames.
+type frameGroup struct {
+	Frames int `json:"frames"`
+}
+
+func newChange(op string, value any) change {
+	return change{op: op, value: value}
+}
+
+// snapshotChanges возвращает изменения, воспроизводящие снимок в пустом хранилище.
+func snapshotChanges(snapshot *models.Snapshot) []change {
+	var changes []change
+	for _, resource := range snapshot.Resources {
+		changes = append(changes, newChange(opPutResource, resource))
+	}
+	for _, template := range snapshot.Templates {
+		changes = append(changes, newChange(opPutTemplate, template))
+	}
+	for _, hours := range snapshot.WorkingHours {
+		changes = append(changes, newChange(opPutWorkingHours, hours))
+	}
+	for _, period := range snapshot.OutOfOffice {
+		changes = append(changes, newChange(opPutOutOfOffice, period))
+	}
+	for _, event := range snapshot.Events {
+		changes = append(changes, newChange(opPutEvent, event))
+	}
+	for _, attachment := range snapshot.Attachments {
+		changes = append(changes, newChange(opPutAttachment, attachment))
+	}
+	for _, entry := range snapshot.Audit {
+		changes = append(changes, newChange(opAppendAudit, entry))
+	}
+	return changes
+}
+
+// commit записывает изменения в журнал, если сохранение включено. Вызывается под
+// блокировкой после изменения данных в памяти, поэтому порядок записей совпадает
+// с порядком изменений. Любая ошибка commit останавливает прием изменений (см. writable):
+// данные в памяти уже изменены, и без записи в журнал они разошлись бы с тем, что
+// восстановится при запуске.
+func (s *Storage) commit(changes ...change) error {
+	if s.wal == nil || len(changes) == 0 {
+		return nil
+	}
+	frames, err := encodeFrames(changes)
+	if err != nil {
+		return s.wal.fail(err)
+	}
+	return s.wal.append(frames)
+}
+
+// commitFrames записывает заранее закодированные изменения, см. encodeFrames.
+func (s *Storage) commitFrames(frames [][]byte) error {
+	if s.wal == nil || len(frames) == 0 {
+		return nil
+	}
+	return s.wal.append(frames)
+}
+
+// writable возвращает ошибку журнала: после сбоя записи изменения не принимаются,
+// чтобы данные в памяти не расходились с тем, что восстановится при запуске.
+func (s *Storage) writable() error {
+	if s.wal == nil {
+		return nil
+	}
+	return s.wal.failed()
+}
+
+// apply применяет запись журнала при восстановлении.
+func (s *Storage) apply(c record) error {
+	switch c.Op {
+	case opPutEvent:
+		var event models.Event
+		if err := json.Unmarshal(c.Data, &event); err != nil {
+			return err
+		}
+		s.events[event.ID] = &event
+	case opDeleteEvent:
+		var key recordKey
+		if err := json.Unmarshal(c.Data, &key); err != nil {
+			return err
+		}
+		delete(s.events, key.ID)
+		delete(s.attachments, key.ID)
+	case opPutAttachment:
+		var attachment models.Attachment
+		if err := json.Unmarshal(c.Data, &attachment); err != nil {
+			return err
+		}
+		s.attachments[attachment.EventID] = append(s.attachments[attachment.EventID], &attachment)
+	case opDeleteAttachment:
+		var key recordKey
+		if err := json.Unmarshal(c.Data, &key); err != nil {
+			return err
+		}
+		s.removeAttachment(key.EventID, key.ID)
+	case opAppendAudit:
+		var entry models.AuditEntry
+		if err := json.Unmarshal(c.Data, &entry); err != nil {
+			return err
+		}
+		s.audit = append(s.audit, &entry)
+	case opPurgeAudit:
+		var purge purgeAudit
+		if err := json.Unmarshal(c.Data, &purge); err != nil {
+			return err
+		}
+		s.purgeAudit(purge.Before)
+	case opPutResource:
+		var resource models.Resource
+		if err := json.Unmarshal(c.Data, &resource); err != nil {
+			return err
+		}
+		s.resources[resource.ID] = &resource
+	case opDeleteResource:
+		var key recordKey
+		if err := json.Unmarshal(c.Data, &key); err != nil {
+			return err
+		}
+		delete(s.resources, key.ID)
+	case opPutWorkingHours:
+		var hours models.WorkingHours
+		if err := json.Unmarshal(c.Data, &hours); err != nil {
+			return err
+		}
+		s.workingHours[hours.UserID] = &hours
+	case opPutOutOfOffice:
+		var period models.OutOfOffice
+		if err := json.Unmarshal(c.Data, &period); err != nil {
+			return err
+		}
+		s.outOfOffice[period.ID] = &period
+	case opDeleteOutOfOffice:
+		var key recordKey
+		if err := json.Unmarshal(c.Data, &key); err != nil {
+			return err
+		}
+		delete(s.outOfOffice, key.ID)
+	case opPutTemplate:
+		var template models.EventTemplate
+		if err := json.Unmarshal(c.Data, &template); err != nil {
+			return err
+		}
+		s.templates[template.ID] = &template
+	case opDeleteTemplate:
+		var key recordKey
+		if err := json.Unmarshal(c.Data, &key); err != nil {
+			return err
+		}
+		delete(s.templates, key.ID)
+	default:
+		return fmt.Errorf("unknown journal record %q", c.Op)
+	}
+	return nil
+}
+
+// encodeFrames кодирует изменения одного вызова commit в записи журнала.
+func encodeFrames(changes []change) ([][]byte, error) {
+	var (
+		groups [][]record
+		group  []record
+		size   int
+	)
+	for _, c := range changes {
+		data, err := json.Marshal(c.value)
+		if err != nil {
+			return nil, fmt.Errorf("encode %s: %w", c.op, err)
+		}
+		if len(group) > 0 && size+len(data) > frameSize {
+			groups = append(groups, group)
+			group, size = nil, 0
+		}
+		group = append(group, record{Op: c.op, Data: data})
+		size += len(data)
+	}
+	groups = append(groups, group)
+
+	if len(groups) > 1 {
+		data, err := json.Marshal(frameGroup{Frames: len(groups)})
+		if err != nil {
+			return nil, err
+		}
+		groups[0] = append([]record{{Op: opFrames, Data: data}}, groups[0]...)
+	}
+
+	frames := make([][]byte, 0, len(groups))
+	for _, records := range groups {
+		frame, err := encodeFrame(records)
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+// encodeFrame кодирует изменения в одну запись журнала.
+func encodeFrame(records []record) ([]byte, error) {
+	payload, err := json.Marshal(records)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) > maxRecordSize {
+		return nil, fmt.Errorf("journal record of %d bytes exceeds %d", len(payload), maxRecordSize)
+	}
+
+	buf := make([]byte, 0, frameHeaderSize+len(payload))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(payload)))
+	buf = binary.LittleEndian.AppendUint32(buf, crc32.Checksum(payload, crcTable))
+	return append(buf, payload...), nil
+}
+
+// errTornRecord — запись журнала оборвана или повреждена.
+var errTornRecord = errors.New("torn journal record")
+
+// readSegment применяет записи сегмента журнала по порядку. Возвращает смещение конца
+// последней целой записи; если за ней следует поврежденная или нечитаемая запись,
+// ошибка — errTornRecord. Группа записей (opFrames), не дописанная до конца сегмента,
+// тоже считается оборванной: смещение указывает на ее начало.
+func readSegment(path string, apply func(record) error) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var (
+		offset int64
+		header [frameHeaderSize]byte
+		// pending — записи незавершенной группы, начавшейся по смещению offset
+		pending   []record
+		remaining int
+		read      int64
+	)
+	for {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			if errors.Is(err, io.EOF) && remaining == 0 {
+				return offset, nil
+			}
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return offset, errTornRecord
+			}
+			return offset, err
+		}
+
+		// Пустых записей журнал не пишет: нулевая длина — хвост, заполненный нулями при сбое
+		// (CRC-32C пустых данных тоже 0)
+		size := binary.LittleEndian.Uint32(header[:4])
+		if size == 0 || size > maxRecordSize {
+			return offset, errTornRecord
+		}
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return offset, errTornRecord
+			}
+			return offset, err
+		}
+		if crc32.Checksum(payload, crcTable) != binary.LittleEndian.Uint32(header[4:]) {
+			return offset, errTornRecord
+		}
+
+		var records []record
+		if err := json.Unmarshal(payload, &records); err != nil {
+			return offset, errTornRecord
+		}
+		read += int64(frameHeaderSize + len(payload))
+
+		if remaining == 0 && len(records) > 0 && records[0].Op == opFrames {
+			var group frameGroup
+			if err := json.Unmarshal(records[0].Data, &group); err != nil || group.Frames < 2 {
+				return offset, fmt.Errorf("%s at offset %d: invalid %s record", path, offset, opFrames)
+			}
+			remaining = group.Frames
+			records = records[1:]
+		}
+		if remaining > 0 {
+			pending = append(pending, records...)
+			if remaining--; remaining > 0 {
+				continue
+			}
+			records, pending = pending, nil
+		}
+
+		for _, rec := range records {
+			if err := apply(rec); err != nil {
+				return offset, fmt.Errorf("%s at offset %d: %w", path, offset, err)
+			}
+		}
+		offset = read
+	}
+}
@@ -4,15 +4,34 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/tracing"
 	_ "github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// maxBatchRows ограничивает число строк в одном INSERT: PostgreSQL принимает
+// не больше 65535 параметров в запросе.
+const maxBatchRows = 1000
+
+// notificationColumns — число сохраняемых полей уведомления
+const notificationColumns = 7
+
+// NotificationStorage сохраняет уведомления. Повторное сохранение уведомления с тем же
+// ID ничего не меняет, поэтому уведомления, полученные из очереди повторно, безопасно
+// сохранять еще раз.
 type NotificationStorage interface {
 	SaveNotification(ctx context.Context, notification *models.Notification) error
+	// SaveNotifications сохраняет пакет уведомлений; при ошибке часть пакета
+	// может быть уже сохранена
+	SaveNotifications(ctx context.Context, notifications []*models.Notification) error
 	GetNotifications(ctx context.Context, userID string, from, to time.Time) ([]*models.Notification, error)
+	// ListAllNotifications возвращает все уведомления, упорядоченные по времени отправки.
+	ListAllNotifications(ctx context.Context) ([]*models.Notification, error)
+	Ping(ctx context.Context) error
 	Close() error
 }
 
@@ -57,8 +76,10 @@ func (s *PostgresNotificationStorage) SaveNotification(ctx context.Context, noti
 	query := `
 		INSERT INTO notifications (id, event_id, event_title, user_id, message, notify_at, created_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id) DO NOTHING
 	`
 
+	ctx, span := tracing.StartQuery(ctx, query)
 	_, err := s.db.ExecContext(ctx, query,
 		notification.ID,
 		notification.EventID,
@@ -68,6 +89,43 @@ func (s *PostgresNotificationStorage) SaveNotification(ctx context.Context, noti
 		notification.NotifyAt,
 		notification.CreatedAt,
 	)
+	tracing.End(span, err)
+
+	return err
+}
+
+func (s *PostgresNotificationStorage) SaveNotifications(ctx context.Context, notifications []*models.Notification) error {
+	for len(notifications) > 0 {
+		n := min(len(notifications), maxBatchRows)
+		if err := s.insertNotifications(ctx, notifications[:n]); err != nil {
+			return err
+		}
+		notifications = notifications[n:]
+	}
+	return nil
+}
+
+// insertNotifications сохраняет уведомления одним многострочным INSERT.
+func (s *PostgresNotificationStorage) insertNotifications(ctx context.Context, notifications []*models.Notification) error {
+	var query strings.Builder
+	query.WriteString("INSERT INTO notifications (id, event_id, event_title, user_id, message, notify_at, created_at) VALUES ")
+
+	args := make([]any, 0, len(notifications)*notificationColumns)
+	for i, n := range notifications {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		base := i * notificationColumns
+		fmt.Fprintf(&query, "($%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+			base+1, base+2, base+3, base+4, base+5, base+6, base+7)
+		args = append(args, n.ID, n.EventID, n.EventTitle, n.UserID, n.Message, n.NotifyAt, n.CreatedAt)
+	}
+	query.WriteString(" ON CONFLICT (id) DO NOTHING")
+
+	ctx, span := tracing.StartQuery(ctx, query.String())
+	span.SetAttributes(attribute.Int("db.operation.batch.size", len(notifications)))
+	_, err := s.db.ExecContext(ctx, query.String(), args...)
+	tracing.End(span, err)
 
 	return err
 }
@@ -80,7 +138,23 @@ func (s *PostgresNotificationStorage) GetNotifications(ctx context.Context, user
 		ORDER BY notify_at
 	`
 
-	rows, err := s.db.QueryContext(ctx, query, userID, from, to)
+	return s.queryNotifications(ctx, query, userID, from, to)
+}
+
+func (s *PostgresNotificationStorage) ListAllNotifications(ctx context.Context) ([]*models.Notification, error) {
+	query := `
+		SELECT id, event_id, event_title, user_id, message, notify_at, created_at
+		FROM notifications
+		ORDER BY notify_at, id
+	`
+
+	return s.queryNotifications(ctx, query)
+}
+
+func (s *PostgresNotificationStorage) queryNotifications(ctx context.Context, query string, args ...any) ([]*models.Notification, error) {
+	ctx, span := tracing.StartQuery(ctx, query)
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	tracing.End(span, err)
 	if err != nil {
 		return nil, err
 	}
@@ -95,7 +169,11 @@ func (s *PostgresNotificationStorage) GetNotifications(ctx context.Context, user
 		notifications = append(notifications, &n)
 	}
 
-	return notifications, nil
+	return notifications, rows.Err()
+}
+
+func (s *PostgresNotificationStorage) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
 }
 
 func (s *PostgresNotificationStorage) Close() error {
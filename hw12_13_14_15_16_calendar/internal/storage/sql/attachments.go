@@ -0,0 +1,98 @@
+package sqlstorage
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+)
+
+const attachmentColumns = "id, event_id, name, content_type, size, checksum, url, created_at"
+
+// AddAttachment добавляет вложение только к активному событию; проверка
+// и вставка выполняются одним запросом.
+func (s *Storage) AddAttachment(ctx context.Context, attachment *models.Attachment) error {
+	query := `INSERT INTO event_attachments (` + attachmentColumns + `)
+	          SELECT $1, id, $3, $4, $5, $6, $7, $8 FROM events WHERE id=$2 AND deleted_at IS NULL`
+
+	result, err := s.q.ExecContext(ctx, query,
+		attachment.ID, attachment.EventID, attachment.Name, attachment.ContentType,
+		attachment.Size, attachment.Checksum, attachment.URL, attachment.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return models.ErrEventNotFound
+	}
+
+	return nil
+}
+
+func (s *Storage) ListAttachments(ctx context.Context, eventID string) ([]*models.Attachment, error) {
+	query := "SELECT " + attachmentColumns + " FROM event_attachments WHERE event_id=$1 ORDER BY created_at"
+
+	rows, err := s.q.QueryContext(ctx, query, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := []*models.Attachment{}
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, rows.Err()
+}
+
+func (s *Storage) GetAttachment(ctx context.Context, eventID, id string) (*models.Attachment, error) {
+	query := "SELECT " + attachmentColumns + " FROM event_attachments WHERE event_id=$1 AND id=$2"
+
+	attachment, err := scanAttachment(s.q.QueryRowContext(ctx, query, eventID, id))
+	if err == sql.ErrNoRows {
+		return nil, models.ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+func (s *Storage) DeleteAttachment(ctx context.Context, eventID, id string) error {
+	result, err := s.q.ExecContext(ctx, "DELETE FROM event_attachments WHERE event_id=$1 AND id=$2", eventID, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return models.ErrAttachmentNotFound
+	}
+
+	return nil
+}
+
+func scanAttachment(row rowScanner) (*models.Attachment, error) {
+	var attachment models.Attachment
+	err := row.Scan(&attachment.ID, &attachment.EventID, &attachment.Name, &attachment.ContentType,
+		&attachment.Size, &attachment.Checksum, &attachment.URL, &attachment.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
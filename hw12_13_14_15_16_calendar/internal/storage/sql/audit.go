@@ -0,0 +1,113 @@
+package sqlstorage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/storage"
+)
+
+const auditColumns = "id, event_id, actor, action, before, after, diff, request_id, created_at"
+
+func (s *Storage) AppendAudit(ctx context.Context, entry *models.AuditEntry) error {
+	return appendAudit(ctx, s.q, entry)
+}
+
+// recordAudit записывает в транзакции изменения события запись журнала о нем.
+func recordAudit(ctx context.Context, q querier, action models.AuditAction, before, after *models.Event) error {
+	return appendAudit(ctx, q, storage.NewAuditEntry(ctx, action, before, after))
+}
+
+func appendAudit(ctx context.Context, q querier, entry *models.AuditEntry) error {
+	query := `INSERT INTO audit_log (` + auditColumns + `)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	_, err := q.ExecContext(ctx, query,
+		entry.ID, entry.EventID, entry.Actor, string(entry.Action),
+		nullableJSON(entry.Before), nullableJSON(entry.After), nullableJSON(entry.Diff),
+		entry.RequestID, entry.CreatedAt)
+	return err
+}
+
+// ListAudit возвращает записи журнала от новых к старым.
+func (s *Storage) ListAudit(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.EventID != "" {
+		args = append(args, filter.EventID)
+		conditions = append(conditions, fmt.Sprintf("event_id = $%d", len(args)))
+	}
+	if filter.Actor != "" {
+		args = append(args, filter.Actor)
+		conditions = append(conditions, fmt.Sprintf("actor = $%d", len(args)))
+	}
+
+	query := "SELECT " + auditColumns + " FROM audit_log"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := s.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*models.AuditEntry
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (s *Storage) PurgeAudit(ctx context.Context, createdBefore time.Time) (int, error) {
+	result, err := s.q.ExecContext(ctx, "DELETE FROM audit_log WHERE created_at < $1", createdBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rows), nil
+}
+
+// nullableJSON передает пустой JSON как NULL.
+func nullableJSON(raw json.RawMessage) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
+
+// scanAuditEntry читает запись журнала из строки, выбранной по auditColumns.
+func scanAuditEntry(row rowScanner) (*models.AuditEntry, error) {
+	var entry models.AuditEntry
+	var action string
+	var before, after, diff []byte
+	if err := row.Scan(&entry.ID, &entry.EventID, &entry.Actor, &action,
+		&before, &after, &diff, &entry.RequestID, &entry.CreatedAt); err != nil {
+		return nil, err
+	}
+	entry.Action = models.AuditAction(action)
+	entry.Before = before
+	entry.After = after
+	entry.Diff = diff
+	return &entry, nil
+}
@@ -0,0 +1,142 @@
+package sqlstorage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const resourceColumns = "id, name, kind, capacity, description, created_at"
+
+func (s *Storage) CreateResource(ctx context.Context, resource *models.Resource) error {
+	query := `INSERT INTO resources (` + resourceColumns + `) VALUES ($1, $2, $3, $4, $5, $6)`
+
+	resource.ID = uuid.New().String()
+	_, err := s.q.ExecContext(ctx, query, resource.ID, resource.Name, string(resource.Kind),
+		resource.Capacity, resource.Description, resource.CreatedAt)
+	return err
+}
+
+func (s *Storage) UpdateResource(ctx context.Context, resource *models.Resource) error {
+	query := `UPDATE resources SET name=$1, kind=$2, capacity=$3, description=$4
+	          WHERE id=$5 RETURNING created_at`
+
+	err := s.q.QueryRowContext(ctx, query, resource.Name, string(resource.Kind),
+		resource.Capacity, resource.Description, resource.ID).Scan(&resource.CreatedAt)
+	if err == sql.ErrNoRows {
+		return models.ErrResourceNotFound
+	}
+	return err
+}
+
+// DeleteResource удаляет ресурс; бронирования активных событий не дают его удалить
+// за счет внешнего ключа event_resources.
+func (s *Storage) DeleteResource(ctx context.Context, id string) error {
+	result, err := s.q.ExecContext(ctx, "DELETE FROM resources WHERE id=$1", id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			return models.ErrResourceInUse
+		}
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return models.ErrResourceNotFound
+	}
+
+	return nil
+}
+
+func (s *Storage) GetResource(ctx context.Context, id string) (*models.Resource, error) {
+	query := "SELECT " + resourceColumns + " FROM resources WHERE id=$1"
+
+	resource, err := scanResource(s.q.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, models.ErrResourceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+func (s *Storage) ListResources(ctx context.Context, filter models.ResourceFilter) ([]*models.Resource, error) {
+	query := "SELECT " + resourceColumns + " FROM resources WHERE capacity >= $1"
+	args := []interface{}{filter.MinCapacity}
+
+	if filter.Kind != "" {
+		args = append(args, string(filter.Kind))
+		query += fmt.Sprintf(" AND kind = $%d", len(args))
+	}
+	query += " ORDER BY name"
+
+	rows, err := s.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	resources := []*models.Resource{}
+	for rows.Next() {
+		resource, err := scanResource(rows)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, resource)
+	}
+
+	return resources, rows.Err()
+}
+
+func (s *Storage) ListBookings(ctx context.Context, resourceID string, from, to time.Time) ([]*models.Booking, error) {
+	if _, err := s.GetResource(ctx, resourceID); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT e.id, e.start_time, e.end_time
+	          FROM event_resources er JOIN events e ON e.id = er.event_id
+	          WHERE er.resource_id=$1 AND er.period && tsrange($2, $3)
+	          ORDER BY e.start_time`
+
+	rows, err := s.q.QueryContext(ctx, query, resourceID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bookings := []*models.Booking{}
+	for rows.Next() {
+		booking := &models.Booking{ResourceID: resourceID}
+		if err := rows.Scan(&booking.EventID, &booking.StartTime, &booking.EndTime); err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, booking)
+	}
+
+	return bookings, rows.Err()
+}
+
+func scanResource(row rowScanner) (*models.Resource, error) {
+	var resource models.Resource
+	var kind string
+	err := row.Scan(&resource.ID, &resource.Name, &kind, &resource.Capacity, &resource.Description, &resource.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	resource.Kind = models.ResourceKind(kind)
+	return &resource, nil
+}
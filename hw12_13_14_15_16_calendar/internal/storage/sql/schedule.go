@@ -0,0 +1,119 @@
+package sqlstorage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/google/uuid"
+)
+
+const workingHoursColumns = "user_id, time_zone, windows, defer_reminders"
+
+const outOfOfficeColumns = "id, user_id, start_time, end_time, reason, created_at"
+
+func (s *Storage) GetWorkingHours(ctx context.Context, userID string) (*models.WorkingHours, error) {
+	query := "SELECT " + workingHoursColumns + " FROM working_hours WHERE user_id=$1"
+
+	hours, err := scanWorkingHours(s.q.QueryRowContext(ctx, query, userID))
+	if err == sql.ErrNoRows {
+		return nil, models.ErrWorkingHoursNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return hours, nil
+}
+
+func (s *Storage) SetWorkingHours(ctx context.Context, hours *models.WorkingHours) error {
+	query := `INSERT INTO working_hours (user_id, time_zone, windows, defer_reminders)
+	          VALUES ($1, $2, $3, $4)
+	          ON CONFLICT (user_id) DO UPDATE
+	          SET time_zone=EXCLUDED.time_zone, windows=EXCLUDED.windows, defer_reminders=EXCLUDED.defer_reminders`
+
+	windows, err := json.Marshal(hours.Windows)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.q.ExecContext(ctx, query, hours.UserID, hours.TimeZone, windows, hours.DeferReminders)
+	return err
+}
+
+func (s *Storage) AddOutOfOffice(ctx context.Context, period *models.OutOfOffice) error {
+	query := `INSERT INTO out_of_office (` + outOfOfficeColumns + `) VALUES ($1, $2, $3, $4, $5, $6)`
+
+	period.ID = uuid.New().String()
+	_, err := s.q.ExecContext(ctx, query, period.ID, period.UserID, period.StartTime, period.EndTime,
+		period.Reason, period.CreatedAt)
+	return err
+}
+
+func (s *Storage) ListOutOfOffice(ctx context.Context, userID string, from, to time.Time) ([]*models.OutOfOffice, error) {
+	query := "SELECT " + outOfOfficeColumns + ` FROM out_of_office
+	          WHERE user_id=$1 AND start_time < $3 AND end_time > $2
+	          ORDER BY start_time`
+
+	rows, err := s.q.QueryContext(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	periods := []*models.OutOfOffice{}
+	for rows.Next() {
+		period, err := scanOutOfOffice(rows)
+		if err != nil {
+			return nil, err
+		}
+		periods = append(periods, period)
+	}
+
+	return periods, rows.Err()
+}
+
+func (s *Storage) DeleteOutOfOffice(ctx context.Context, userID, id string) error {
+	result, err := s.q.ExecContext(ctx, "DELETE FROM out_of_office WHERE id=$1 AND user_id=$2", id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return models.ErrOutOfOfficeNotFound
+	}
+
+	return nil
+}
+
+// scanWorkingHours читает рабочее время из строки, выбранной по workingHoursColumns.
+func scanWorkingHours(row rowScanner) (*models.WorkingHours, error) {
+	hours := &models.WorkingHours{}
+	var windows []byte
+	if err := row.Scan(&hours.UserID, &hours.TimeZone, &windows, &hours.DeferReminders); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(windows, &hours.Windows); err != nil {
+		return nil, err
+	}
+	return hours, nil
+}
+
+// scanOutOfOffice читает период отсутствия из строки, выбранной по outOfOfficeColumns.
+func scanOutOfOffice(row rowScanner) (*models.OutOfOffice, error) {
+	var period models.OutOfOffice
+	err := row.Scan(&period.ID, &period.UserID, &period.StartTime, &period.EndTime,
+		&period.Reason, &period.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &period, nil
+}
@@ -0,0 +1,179 @@
+package sqlstorage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+)
+
+// Snapshot читает все таблицы в одной транзакции REPEATABLE READ, чтобы снимок
+// был согласован при одновременных изменениях.
+func (s *Storage) Snapshot(ctx context.Context) (*models.Snapshot, error) {
+	snapshot := &models.Snapshot{}
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	err := s.withTxOptions(ctx, opts, func(tx querier) error {
+		var err error
+		snapshot.Resources, err = queryAll(ctx, tx,
+			"SELECT "+resourceColumns+" FROM resources ORDER BY name", scanResource)
+		if err != nil {
+			return err
+		}
+		snapshot.Templates, err = queryAll(ctx, tx,
+			"SELECT "+templateColumns+" FROM event_templates ORDER BY name", scanTemplate)
+		if err != nil {
+			return err
+		}
+		snapshot.WorkingHours, err = queryAll(ctx, tx,
+			"SELECT "+workingHoursColumns+" FROM working_hours ORDER BY user_id", scanWorkingHours)
+		if err != nil {
+			return err
+		}
+		snapshot.OutOfOffice, err = queryAll(ctx, tx,
+			"SELECT "+outOfOfficeColumns+" FROM out_of_office ORDER BY start_time", scanOutOfOffice)
+		if err != nil {
+			return err
+		}
+		snapshot.Events, err = queryAll(ctx, tx,
+			"SELECT "+eventColumns+" FROM events ORDER BY start_time, id", scanEvent)
+		if err != nil {
+			return err
+		}
+		snapshot.Attachments, err = queryAll(ctx, tx,
+			"SELECT "+attachmentColumns+" FROM event_attachments ORDER BY event_id, created_at", scanAttachment)
+		if err != nil {
+			return err
+		}
+		snapshot.Audit, err = queryAll(ctx, tx,
+			"SELECT "+auditColumns+" FROM audit_log ORDER BY created_at", scanAuditEntry)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// LoadSnapshot вставляет записи снимка с исходными ID в одной транзакции.
+// Бронирования ресурсов восстанавливаются для активных событий.
+func (s *Storage) LoadSnapshot(ctx context.Context, snapshot *models.Snapshot) error {
+	return s.withTx(ctx, func(tx querier) error {
+		var empty bool
+		err := tx.QueryRowContext(ctx, `SELECT NOT (EXISTS(SELECT 1 FROM events) OR EXISTS(SELECT 1 FROM resources)
+		          OR EXISTS(SELECT 1 FROM event_templates) OR EXISTS(SELECT 1 FROM working_hours)
+		          OR EXISTS(SELECT 1 FROM out_of_office) OR EXISTS(SELECT 1 FROM audit_log))`).Scan(&empty)
+		if err != nil {
+			return err
+		}
+		if !empty {
+			return models.ErrStorageNotEmpty
+		}
+
+		for _, resource := range snapshot.Resources {
+			_, err := tx.ExecContext(ctx, `INSERT INTO resources (`+resourceColumns+`) VALUES ($1, $2, $3, $4, $5, $6)`,
+				resource.ID, resource.Name, string(resource.Kind), resource.Capacity, resource.Description, resource.CreatedAt)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, template := range snapshot.Templates {
+			_, err := tx.ExecContext(ctx, `INSERT INTO event_templates (`+templateColumns+`)
+			          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+				template.ID, template.Name, template.TitlePattern,
+				int64(template.Duration/time.Second), template.Description, int64(template.NotifyBefore/time.Second),
+				textArray(template.Tags), template.Category, template.Color, string(template.Priority),
+				template.CreatedAt, template.UpdatedAt)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, hours := range snapshot.WorkingHours {
+			windows, err := json.Marshal(hours.Windows)
+			if err != nil {
+				return err
+			}
+			_, err = tx.ExecContext(ctx, `INSERT INTO working_hours (`+workingHoursColumns+`) VALUES ($1, $2, $3, $4)`,
+				hours.UserID, hours.TimeZone, windows, hours.DeferReminders)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, period := range snapshot.OutOfOffice {
+			_, err := tx.ExecContext(ctx, `INSERT INTO out_of_office (`+outOfOfficeColumns+`) VALUES ($1, $2, $3, $4, $5, $6)`,
+				period.ID, period.UserID, period.StartTime, period.EndTime, period.Reason, period.CreatedAt)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, event := range snapshot.Events {
+			deletedAt := sql.NullTime{Time: event.DeletedAt, Valid: !event.DeletedAt.IsZero()}
+			var deferredFrom, deferredTo sql.NullTime
+			if event.ReminderDeferral != nil {
+				deferredFrom = sql.NullTime{Time: event.ReminderDeferral.From, Valid: true}
+				deferredTo = sql.NullTime{Time: event.ReminderDeferral.To, Valid: true}
+			}
+			_, err := tx.ExecContext(ctx, `INSERT INTO events (`+eventColumns+`)
+			          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`,
+				event.ID, event.Title, event.Description,
+				event.StartTime, event.EndTime, event.UserID, event.Reminder,
+				textArray(event.Tags), event.Category, event.Color, string(event.Priority),
+				textArray(event.ResourceIDs), event.Version, deletedAt,
+				deferredFrom, deferredTo)
+			if err != nil {
+				return err
+			}
+			if !deletedAt.Valid {
+				if err := syncBookings(ctx, tx, event); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, attachment := range snapshot.Attachments {
+			_, err := tx.ExecContext(ctx, `INSERT INTO event_attachments (`+attachmentColumns+`)
+			          VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+				attachment.ID, attachment.EventID, attachment.Name, attachment.ContentType,
+				attachment.Size, attachment.Checksum, attachment.URL, attachment.CreatedAt)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, entry := range snapshot.Audit {
+			if err := appendAudit(ctx, tx, entry); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// queryAll выполняет запрос и читает все строки функцией scan.
+func queryAll[T any](ctx context.Context, q querier, query string, scan func(rowScanner) (*T, error),
+	args ...interface{},
+) ([]*T, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*T
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
@@ -3,15 +3,32 @@ package sqlstorage
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/logger"
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/storage"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	_ "github.com/jackc/pgx/v5/stdlib"
 )
 
+// Коды ошибок PostgreSQL (SQLSTATE), обрабатываемые хранилищем.
+const (
+	foreignKeyViolation = "23503"
+	exclusionViolation  = "23P01"
+)
+
+const eventColumns = "id, title, description, start_time, end_time, user_id, reminder, tags, category, color, priority, resource_ids, version, deleted_at, reminder_deferred_from, reminder_deferred_to"
+
 type Storage struct {
 	db *sql.DB
+	// q выполняет запросы вне транзакций, записывая спан трассировки на каждый запрос
+	q querier
 }
 
 func NewStorage(dsn string) (*Storage, error) {
@@ -24,27 +41,408 @@ func NewStorage(dsn string) (*Storage, error) {
 		return nil, err
 	}
 
-	return &Storage{db: db}, nil
+	return &Storage{db: db, q: tracedQuerier{q: db}}, nil
 }
 
 func (s *Storage) CreateEvent(ctx context.Context, event *models.Event) error {
-	query := `INSERT INTO events (id, title, description, start_time, end_time, user_id, reminder) 
-	          VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	return s.withTx(ctx, func(tx querier) error {
+		if err := createEvent(ctx, tx, event); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, models.AuditActionCreate, nil, event)
+	})
+}
+
+func (s *Storage) UpdateEvent(ctx context.Context, event *models.Event) error {
+	return s.withTx(ctx, func(tx querier) error {
+		before, err := lockEvent(ctx, tx, event.ID)
+		if err != nil {
+			return err
+		}
+		if err := updateEvent(ctx, tx, event); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, models.AuditActionUpdate, before, event)
+	})
+}
+
+// PatchEvent записывает только перечисленные поля события.
+func (s *Storage) PatchEvent(ctx context.Context, event *models.Event, fields []models.EventField) error {
+	sets := make([]string, 0, len(fields)+1)
+	args := make([]interface{}, 0, len(fields)+2)
+	for _, field := range fields {
+		var column string
+		var value interface{}
+		switch field {
+		case models.EventFieldTitle:
+			column, value = "title", event.Title
+		case models.EventFieldDescription:
+			column, value = "description", event.Description
+		case models.EventFieldStartTime:
+			column, value = "start_time", event.StartTime
+		case models.EventFieldEndTime:
+			column, value = "end_time", event.EndTime
+		case models.EventFieldUserID:
+			column, value = "user_id", event.UserID
+		case models.EventFieldReminder:
+			column, value = "reminder", event.Reminder
+		case models.EventFieldTags:
+			column, value = "tags", textArray(event.Tags)
+		case models.EventFieldCategory:
+			column, value = "category", event.Category
+		case models.EventFieldColor:
+			column, value = "color", event.Color
+		case models.EventFieldPriority:
+			column, value = "priority", string(event.Priority)
+		case models.EventFieldResourceIDs:
+			column, value = "resource_ids", textArray(event.ResourceIDs)
+		default:
+			return fmt.Errorf("%w: unknown field %q", models.ErrInvalidEvent, field)
+		}
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s=$%d", column, len(args)))
+	}
+	sets = append(sets, "version=version+1")
+	args = append(args, event.ID, event.Version)
+
+	query := fmt.Sprintf(`UPDATE events SET %s
+	          WHERE id=$%d AND deleted_at IS NULL AND ($%d = 0 OR version = $%d)
+	          RETURNING %s`,
+		strings.Join(sets, ", "), len(args)-1, len(args), len(args), eventColumns)
+
+	return s.withTx(ctx, func(tx querier) error {
+		before, err := lockEvent(ctx, tx, event.ID)
+		if err != nil {
+			return err
+		}
+
+		patched, err := scanEvent(tx.QueryRowContext(ctx, query, args...))
+		if err == sql.ErrNoRows {
+			return missingOrConflict(ctx, tx, event.ID)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := syncBookings(ctx, tx, patched); err != nil {
+			return err
+		}
+
+		*event = *patched
+		return recordAudit(ctx, tx, models.AuditActionPatch, before, patched)
+	})
+}
+
+// DeleteEvent переносит событие в корзину.
+func (s *Storage) DeleteEvent(ctx context.Context, id string, version int64) error {
+	return s.withTx(ctx, func(tx querier) error {
+		before, err := lockEvent(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if err := deleteEvent(ctx, tx, id, version); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, models.AuditActionDelete, before, nil)
+	})
+}
+
+func (s *Storage) GetEvent(ctx context.Context, id string) (*models.Event, error) {
+	query := "SELECT " + eventColumns + " FROM events WHERE id=$1 AND deleted_at IS NULL"
+
+	event, err := scanEvent(s.q.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, models.ErrEventNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+func (s *Storage) ListEvents(ctx context.Context, from, to time.Time, filter models.EventFilter) ([]*models.Event, error) {
+	query := "SELECT " + eventColumns + ` 
+	          FROM events WHERE start_time >= $1 AND start_time <= $2 AND deleted_at IS NULL`
+	args := []interface{}{from, to}
+
+	if len(filter.Tags) > 0 {
+		args = append(args, textArray(filter.Tags))
+		query += fmt.Sprintf(" AND tags @> $%d", len(args))
+	}
+	if filter.Category != "" {
+		args = append(args, filter.Category)
+		query += fmt.Sprintf(" AND category = $%d", len(args))
+	}
+	if filter.Priority != "" {
+		args = append(args, string(filter.Priority))
+		query += fmt.Sprintf(" AND priority = $%d", len(args))
+	}
+	if filter.UserID != "" {
+		args = append(args, filter.UserID)
+		query += fmt.Sprintf(" AND user_id = $%d", len(args))
+	}
+	if !filter.EndsAfter.IsZero() {
+		args = append(args, filter.EndsAfter)
+		query += fmt.Sprintf(" AND end_time > $%d", len(args))
+	}
+
+	return s.queryEvents(ctx, query, args...)
+}
+
+func (s *Storage) CountEvents(ctx context.Context, userID string) (int, error) {
+	query := "SELECT count(*) FROM events WHERE user_id=$1 AND deleted_at IS NULL"
+
+	var count int
+	err := s.q.QueryRowContext(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+func (s *Storage) ListReminders(ctx context.Context, from, to time.Time) ([]*models.Event, error) {
+	query := "SELECT " + eventColumns + ` 
+	          FROM events WHERE ` + reminderAt + ` >= $1 AND ` + reminderAt + ` < $2 AND deleted_at IS NULL`
+
+	return s.queryEvents(ctx, query, from, to)
+}
+
+// reminderAt — момент отправки напоминания с учетом переноса, как Event.ReminderAt.
+// Выражение совпадает с индексом idx_events_reminder_at.
+const reminderAt = "(CASE WHEN reminder_deferred_from = reminder THEN reminder_deferred_to ELSE reminder END)"
+
+func (s *Storage) DeferReminder(ctx context.Context, id string, deferral models.ReminderDeferral) error {
+	query := `UPDATE events SET reminder_deferred_from=$2, reminder_deferred_to=$3
+	          WHERE id=$1 AND deleted_at IS NULL`
+
+	result, err := s.q.ExecContext(ctx, query, id, deferral.From, deferral.To)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrEventNotFound
+	}
+	return nil
+}
+
+func (s *Storage) ListDeletedEvents(ctx context.Context) ([]*models.Event, error) {
+	query := "SELECT " + eventColumns + ` 
+	          FROM events WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC`
+
+	return s.queryEvents(ctx, query)
+}
+
+func (s *Storage) RestoreEvent(ctx context.Context, id string) (*models.Event, error) {
+	query := `UPDATE events SET deleted_at=NULL, version=version+1
+	          WHERE id=$1 AND deleted_at IS NOT NULL
+	          RETURNING ` + eventColumns
+
+	var event *models.Event
+	err := s.withTx(ctx, func(tx querier) error {
+		restored, err := scanEvent(tx.QueryRowContext(ctx, query, id))
+		if err == sql.ErrNoRows {
+			return models.ErrEventNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		event = restored
+		if err := syncBookings(ctx, tx, restored); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, models.AuditActionRestore, nil, restored)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+func (s *Storage) PurgeDeletedEvents(ctx context.Context, deletedBefore time.Time) (int, error) {
+	query := `DELETE FROM events WHERE deleted_at IS NOT NULL AND deleted_at < $1
+	          RETURNING ` + eventColumns
+
+	purged := 0
+	err := s.withTx(ctx, func(tx querier) error {
+		events, err := queryAll(ctx, tx, query, scanEvent, deletedBefore)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if err := recordAudit(ctx, tx, models.AuditActionPurge, event, nil); err != nil {
+				return err
+			}
+		}
+		purged = len(events)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+// ApplyBatch выполняет операции пакета в одной транзакции. В режиме best-effort каждая
+// операция выполняется в своей точке сохранения, и ее ошибка откатывает только ее саму.
+func (s *Storage) ApplyBatch(ctx context.Context, ops []models.BatchOperation, atomic bool) ([]models.BatchResult, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	q := tracedQuerier{q: tx}
+
+	results := make([]models.BatchResult, len(ops))
+	for i, op := range ops {
+		if !atomic {
+			if _, err := q.ExecContext(ctx, "SAVEPOINT batch_op"); err != nil {
+				return nil, err
+			}
+		}
+
+		results[i] = applyOperation(ctx, q, op)
+		if results[i].Err == nil {
+			if !atomic {
+				if _, err := q.ExecContext(ctx, "RELEASE SAVEPOINT batch_op"); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		if atomic {
+			for j := range results {
+				if j != i {
+					results[j] = models.BatchResult{Err: models.ErrBatchAborted}
+				}
+			}
+			return results, nil
+		}
+
+		if _, err := q.ExecContext(ctx, "ROLLBACK TO SAVEPOINT batch_op"); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// applyOperation выполняет операцию пакета и записывает ее в журнал аудита.
+func applyOperation(ctx context.Context, q querier, op models.BatchOperation) models.BatchResult {
+	result := applyEventOperation(ctx, q, op)
+	if result.Err != nil {
+		return result
+	}
+	if err := recordAudit(ctx, q, storage.BatchAuditAction(op.Op), result.Before, result.After); err != nil {
+		return models.BatchResult{Err: err}
+	}
+	return result
+}
+
+func applyEventOperation(ctx context.Context, q querier, op models.BatchOperation) models.BatchResult {
+	switch op.Op {
+	case models.BatchOpCreate:
+		if err := createEvent(ctx, q, op.Event); err != nil {
+			return models.BatchResult{Err: err}
+		}
+		return models.BatchResult{After: op.Event}
+	case models.BatchOpUpdate:
+		before, err := lockEvent(ctx, q, op.Event.ID)
+		if err != nil {
+			return models.BatchResult{Err: err}
+		}
+		if err := updateEvent(ctx, q, op.Event); err != nil {
+			return models.BatchResult{Err: err}
+		}
+		return models.BatchResult{Before: before, After: op.Event}
+	case models.BatchOpDelete:
+		before, err := lockEvent(ctx, q, op.ID)
+		if err != nil {
+			return models.BatchResult{Err: err}
+		}
+		if err := deleteEvent(ctx, q, op.ID, op.Version); err != nil {
+			return models.BatchResult{Err: err}
+		}
+		return models.BatchResult{Before: before}
+	default:
+		return models.BatchResult{Err: fmt.Errorf("%w: unknown batch operation %q", models.ErrInvalidEvent, op.Op)}
+	}
+}
+
+// querier — общее подмножество *sql.DB и *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func createEvent(ctx context.Context, q querier, event *models.Event) error {
+	query := `INSERT INTO events (id, title, description, start_time, end_time, user_id, reminder,
+	          tags, category, color, priority, resource_ids, version) 
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, 1)`
 
 	event.ID = uuid.New().String()
-	_, err := s.db.ExecContext(ctx, query,
+	_, err := q.ExecContext(ctx, query,
 		event.ID, event.Title, event.Description,
-		event.StartTime, event.EndTime, event.UserID, event.Reminder)
-	return err
+		event.StartTime, event.EndTime, event.UserID, event.Reminder,
+		textArray(event.Tags), event.Category, event.Color, string(event.Priority),
+		textArray(event.ResourceIDs))
+	if err != nil {
+		return err
+	}
+
+	if err := syncBookings(ctx, q, event); err != nil {
+		return err
+	}
+
+	event.Version = 1
+	return nil
 }
 
-func (s *Storage) UpdateEvent(ctx context.Context, event *models.Event) error {
+func updateEvent(ctx context.Context, q querier, event *models.Event) error {
 	query := `UPDATE events SET title=$1, description=$2, start_time=$3, 
-	          end_time=$4, user_id=$5, reminder=$6 WHERE id=$7`
+	          end_time=$4, user_id=$5, reminder=$6, tags=$7, category=$8, color=$9, priority=$10,
+	          resource_ids=$11, version=version+1
+	          WHERE id=$12 AND deleted_at IS NULL AND ($13 = 0 OR version = $13)
+	          RETURNING version, reminder_deferred_from, reminder_deferred_to`
 
-	result, err := s.db.ExecContext(ctx, query,
+	var version int64
+	var deferredFrom, deferredTo sql.NullTime
+	err := q.QueryRowContext(ctx, query,
 		event.Title, event.Description, event.StartTime,
-		event.EndTime, event.UserID, event.Reminder, event.ID)
+		event.EndTime, event.UserID, event.Reminder,
+		textArray(event.Tags), event.Category, event.Color, string(event.Priority),
+		textArray(event.ResourceIDs), event.ID, event.Version).Scan(&version, &deferredFrom, &deferredTo)
+	if err == sql.ErrNoRows {
+		return missingOrConflict(ctx, q, event.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := syncBookings(ctx, q, event); err != nil {
+		return err
+	}
+
+	event.Version = version
+	event.ReminderDeferral = reminderDeferral(deferredFrom, deferredTo)
+	return nil
+}
+
+func deleteEvent(ctx context.Context, q querier, id string, version int64) error {
+	query := `UPDATE events SET deleted_at=$3, version=version+1
+	          WHERE id=$1 AND deleted_at IS NULL AND ($2 = 0 OR version = $2)`
+	result, err := q.ExecContext(ctx, query, id, version, time.Now())
 	if err != nil {
 		return err
 	}
@@ -55,38 +453,79 @@ func (s *Storage) UpdateEvent(ctx context.Context, event *models.Event) error {
 	}
 
 	if rows == 0 {
-		return models.ErrEventNotFound
+		return missingOrConflict(ctx, q, id)
+	}
+
+	// Событие в корзине не занимает ресурсы; бронирования восстанавливаются вместе с событием
+	_, err = q.ExecContext(ctx, "DELETE FROM event_resources WHERE event_id=$1", id)
+	return err
+}
+
+// syncBookings приводит бронирования ресурсов в соответствие с событием. Пересечения
+// бронирований одного ресурса запрещает exclusion constraint таблицы event_resources.
+func syncBookings(ctx context.Context, q querier, event *models.Event) error {
+	if _, err := q.ExecContext(ctx, "DELETE FROM event_resources WHERE event_id=$1", event.ID); err != nil {
+		return err
+	}
+
+	for _, resourceID := range event.ResourceIDs {
+		_, err := q.ExecContext(ctx, `INSERT INTO event_resources (event_id, resource_id, period)
+		          VALUES ($1, $2, tsrange($3, $4))`,
+			event.ID, resourceID, event.StartTime, event.EndTime)
+		if err != nil {
+			return bookingError(err, resourceID)
+		}
 	}
 
 	return nil
 }
 
-func (s *Storage) DeleteEvent(ctx context.Context, id string) error {
-	query := "DELETE FROM events WHERE id=$1"
-	result, err := s.db.ExecContext(ctx, query, id)
-	if err != nil {
+// bookingError переводит нарушения ограничений event_resources в ошибки моделей.
+func bookingError(err error, resourceID string) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
 		return err
 	}
 
-	rows, err := result.RowsAffected()
+	switch pgErr.Code {
+	case exclusionViolation:
+		return fmt.Errorf("%w: %s", models.ErrResourceBusy, resourceID)
+	case foreignKeyViolation:
+		return fmt.Errorf("%w: %s", models.ErrResourceNotFound, resourceID)
+	default:
+		return err
+	}
+}
+
+// withTx выполняет fn в транзакции и фиксирует ее, если fn не вернула ошибку.
+func (s *Storage) withTx(ctx context.Context, fn func(tx querier) error) error {
+	return s.withTxOptions(ctx, nil, fn)
+}
+
+// withTxOptions — withTx с заданными уровнем изоляции и режимом транзакции.
+func (s *Storage) withTxOptions(ctx context.Context, opts *sql.TxOptions, fn func(tx querier) error) error {
+	tx, err := s.db.BeginTx(ctx, opts)
 	if err != nil {
 		return err
 	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			logger.FromContext(ctx, nil).Errorf("Failed to rollback transaction: %v", err)
+		}
+	}()
 
-	if rows == 0 {
-		return models.ErrEventNotFound
+	if err := fn(tracedQuerier{q: tx}); err != nil {
+		return err
 	}
 
-	return nil
+	return tx.Commit()
 }
 
-func (s *Storage) GetEvent(ctx context.Context, id string) (*models.Event, error) {
-	query := "SELECT id, title, description, start_time, end_time, user_id, reminder FROM events WHERE id=$1"
-	row := s.db.QueryRowContext(ctx, query, id)
+// lockEvent читает активное событие и блокирует его строку до конца транзакции.
+func lockEvent(ctx context.Context, q querier, id string) (*models.Event, error) {
+	query := "SELECT " + eventColumns + " FROM events WHERE id=$1 AND deleted_at IS NULL FOR UPDATE"
 
-	var event models.Event
-	err := row.Scan(&event.ID, &event.Title, &event.Description,
-		&event.StartTime, &event.EndTime, &event.UserID, &event.Reminder)
+	event, err := scanEvent(q.QueryRowContext(ctx, query, id))
 	if err == sql.ErrNoRows {
 		return nil, models.ErrEventNotFound
 	}
@@ -94,14 +533,26 @@ func (s *Storage) GetEvent(ctx context.Context, id string) (*models.Event, error
 		return nil, err
 	}
 
-	return &event, nil
+	return event, nil
 }
 
-func (s *Storage) ListEvents(ctx context.Context, from, to time.Time) ([]*models.Event, error) {
-	query := `SELECT id, title, description, start_time, end_time, user_id, reminder 
-	          FROM events WHERE start_time >= $1 AND start_time <= $2`
+// missingOrConflict определяет, почему условное изменение не затронуло ни одной строки:
+// события нет вовсе или его версия уже изменилась.
+func missingOrConflict(ctx context.Context, q querier, id string) error {
+	var exists bool
+	if err := q.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM events WHERE id=$1 AND deleted_at IS NULL)", id).Scan(&exists); err != nil {
+		return err
+	}
 
-	rows, err := s.db.QueryContext(ctx, query, from, to)
+	if exists {
+		return models.ErrVersionConflict
+	}
+
+	return models.ErrEventNotFound
+}
+
+func (s *Storage) queryEvents(ctx context.Context, query string, args ...interface{}) ([]*models.Event, error) {
+	rows, err := s.q.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -109,17 +560,65 @@ func (s *Storage) ListEvents(ctx context.Context, from, to time.Time) ([]*models
 
 	var events []*models.Event
 	for rows.Next() {
-		var event models.Event
-		if err := rows.Scan(&event.ID, &event.Title, &event.Description,
-			&event.StartTime, &event.EndTime, &event.UserID, &event.Reminder); err != nil {
+		event, err := scanEvent(rows)
+		if err != nil {
 			return nil, err
 		}
-		events = append(events, &event)
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// typeMap используется для чтения массивов PostgreSQL через database/sql.
+var typeMap = pgtype.NewMap()
+
+// textArray приводит срез строк к значению text[]: pgx передает срезы напрямую,
+// а nil записывается как пустой массив, а не NULL.
+func textArray(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanEvent читает событие из строки, выбранной по eventColumns.
+func scanEvent(row rowScanner) (*models.Event, error) {
+	var event models.Event
+	var deletedAt, deferredFrom, deferredTo sql.NullTime
+	var priority string
+	err := row.Scan(&event.ID, &event.Title, &event.Description,
+		&event.StartTime, &event.EndTime, &event.UserID, &event.Reminder,
+		typeMap.SQLScanner(&event.Tags), &event.Category, &event.Color, &priority,
+		typeMap.SQLScanner(&event.ResourceIDs), &event.Version, &deletedAt,
+		&deferredFrom, &deferredTo)
+	if err != nil {
+		return nil, err
 	}
 
-	return events, nil
+	event.Priority = models.Priority(priority)
+
+	event.DeletedAt = deletedAt.Time
+	event.ReminderDeferral = reminderDeferral(deferredFrom, deferredTo)
+	return &event, nil
+}
+
+// reminderDeferral собирает перенос напоминания из столбцов reminder_deferred_*.
+func reminderDeferral(from, to sql.NullTime) *models.ReminderDeferral {
+	if !from.Valid || !to.Valid {
+		return nil
+	}
+	return &models.ReminderDeferral{From: from.Time, To: to.Time}
 }
 
 func (s *Storage) Close() error {
 	return s.db.Close()
 }
+
+func (s *Storage) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
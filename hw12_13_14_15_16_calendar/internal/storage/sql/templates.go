@@ -0,0 +1,111 @@
+package sqlstorage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
+	"github.com/google/uuid"
+)
+
+// templateColumns — колонки event_templates; duration и notify_before хранятся в секундах
+const templateColumns = `id, name, title_pattern, duration, description, notify_before,
+	tags, category, color, priority, created_at, updated_at`
+
+func (s *Storage) CreateTemplate(ctx context.Context, template *models.EventTemplate) error {
+	query := `INSERT INTO event_templates (` + templateColumns + `)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+
+	template.ID = uuid.New().String()
+	_, err := s.q.ExecContext(ctx, query, template.ID, template.Name, template.TitlePattern,
+		int64(template.Duration/time.Second), template.Description, int64(template.NotifyBefore/time.Second),
+		textArray(template.Tags), template.Category, template.Color, string(template.Priority),
+		template.CreatedAt, template.UpdatedAt)
+	return err
+}
+
+func (s *Storage) UpdateTemplate(ctx context.Context, template *models.EventTemplate) error {
+	query := `UPDATE event_templates SET name=$1, title_pattern=$2, duration=$3, description=$4,
+	          notify_before=$5, tags=$6, category=$7, color=$8, priority=$9, updated_at=$10
+	          WHERE id=$11 RETURNING created_at`
+
+	err := s.q.QueryRowContext(ctx, query, template.Name, template.TitlePattern,
+		int64(template.Duration/time.Second), template.Description, int64(template.NotifyBefore/time.Second),
+		textArray(template.Tags), template.Category, template.Color, string(template.Priority),
+		template.UpdatedAt, template.ID).Scan(&template.CreatedAt)
+	if err == sql.ErrNoRows {
+		return models.ErrTemplateNotFound
+	}
+	return err
+}
+
+func (s *Storage) DeleteTemplate(ctx context.Context, id string) error {
+	result, err := s.q.ExecContext(ctx, "DELETE FROM event_templates WHERE id=$1", id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return models.ErrTemplateNotFound
+	}
+
+	return nil
+}
+
+func (s *Storage) GetTemplate(ctx context.Context, id string) (*models.EventTemplate, error) {
+	query := "SELECT " + templateColumns + " FROM event_templates WHERE id=$1"
+
+	template, err := scanTemplate(s.q.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, models.ErrTemplateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+func (s *Storage) ListTemplates(ctx context.Context) ([]*models.EventTemplate, error) {
+	query := "SELECT " + templateColumns + " FROM event_templates ORDER BY name"
+
+	rows, err := s.q.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []*models.EventTemplate{}
+	for rows.Next() {
+		template, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+
+	return templates, rows.Err()
+}
+
+func scanTemplate(row rowScanner) (*models.EventTemplate, error) {
+	var template models.EventTemplate
+	var duration, notifyBefore int64
+	var priority string
+	err := row.Scan(&template.ID, &template.Name, &template.TitlePattern, &duration, &template.Description,
+		&notifyBefore, typeMap.SQLScanner(&template.Tags), &template.Category, &template.Color, &priority,
+		&template.CreatedAt, &template.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	template.Duration = time.Duration(duration) * time.Second
+	template.NotifyBefore = time.Duration(notifyBefore) * time.Second
+	template.Priority = models.Priority(priority)
+	return &template, nil
+}
@@ -0,0 +1,35 @@
+package sqlstorage
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/tracing"
+)
+
+// tracedQuerier оборачивает каждый запрос в спан трассировки.
+type tracedQuerier struct {
+	q querier
+}
+
+func (t tracedQuerier) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := tracing.StartQuery(ctx, query)
+	result, err := t.q.ExecContext(ctx, query, args...)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (t tracedQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := tracing.StartQuery(ctx, query)
+	rows, err := t.q.QueryContext(ctx, query, args...)
+	tracing.End(span, err)
+	return rows, err
+}
+
+func (t tracedQuerier) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := tracing.StartQuery(ctx, query)
+	row := t.q.QueryRowContext(ctx, query, args...)
+	// sql.ErrNoRows откладывается до Scan и ошибкой запроса не считается
+	tracing.End(span, row.Err())
+	return row
+}
@@ -7,11 +7,104 @@ import (
 	"github.com/Ilya19871986/hw-test/hw12_13_14_15_16_calendar/internal/models"
 )
 
+// Storage — хранилище календаря. Методы, изменяющие события, записывают в AuditLog
+// запись NewAuditEntry в той же операции, что и само изменение: если записать ее
+// не удалось, изменение не выполняется. Исключение — DeferReminder: перенос напоминания
+// принадлежит планировщику и событие для пользователя не меняет.
 type Storage interface {
 	CreateEvent(ctx context.Context, event *models.Event) error
 	UpdateEvent(ctx context.Context, event *models.Event) error
-	DeleteEvent(ctx context.Context, id string) error
+	PatchEvent(ctx context.Context, event *models.Event, fields []models.EventField) error
+	DeleteEvent(ctx context.Context, id string, version int64) error
 	GetEvent(ctx context.Context, id string) (*models.Event, error)
-	ListEvents(ctx context.Context, from, to time.Time) ([]*models.Event, error)
+	ListEvents(ctx context.Context, from, to time.Time, filter models.EventFilter) ([]*models.Event, error)
+	// CountEvents возвращает количество активных событий пользователя.
+	CountEvents(ctx context.Context, userID string) (int, error)
+	// ListReminders возвращает активные события, у которых Event.ReminderAt попадает
+	// в интервал [from, to).
+	ListReminders(ctx context.Context, from, to time.Time) ([]*models.Event, error)
+	// DeferReminder сохраняет перенос напоминания активного события, не меняя его версию.
+	DeferReminder(ctx context.Context, id string, deferral models.ReminderDeferral) error
+	ListDeletedEvents(ctx context.Context) ([]*models.Event, error)
+	RestoreEvent(ctx context.Context, id string) (*models.Event, error)
+	PurgeDeletedEvents(ctx context.Context, deletedBefore time.Time) (int, error)
+	// ApplyBatch выполняет операции пакета атомарно относительно других изменений.
+	// При atomic=true ошибка любой операции отменяет весь пакет. Ошибка результата
+	// означает сбой хранилища; ошибки отдельных операций возвращаются в BatchResult.
+	ApplyBatch(ctx context.Context, ops []models.BatchOperation, atomic bool) ([]models.BatchResult, error)
+	AuditLog
+	AttachmentStore
+	ResourceStore
+	ScheduleStore
+	TemplateStore
+	SnapshotStore
+	// Ping проверяет доступность хранилища для проверок готовности.
+	Ping(ctx context.Context) error
 	Close() error
 }
+
+// AuditLog — журнал изменений событий, допускающий только добавление записей
+// и удаление устаревших по сроку хранения.
+type AuditLog interface {
+	AppendAudit(ctx context.Context, entry *models.AuditEntry) error
+	ListAudit(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEntry, error)
+	PurgeAudit(ctx context.Context, createdBefore time.Time) (int, error)
+}
+
+// AttachmentStore — метаданные вложений событий. Содержимое хранится отдельно
+// в blobstore.Store. Вложения удаляются вместе с окончательно удаленным событием.
+type AttachmentStore interface {
+	// AddAttachment возвращает ErrEventNotFound, если событие не существует или в корзине.
+	AddAttachment(ctx context.Context, attachment *models.Attachment) error
+	ListAttachments(ctx context.Context, eventID string) ([]*models.Attachment, error)
+	GetAttachment(ctx context.Context, eventID, id string) (*models.Attachment, error)
+	DeleteAttachment(ctx context.Context, eventID, id string) error
+}
+
+// ResourceStore — бронируемые ресурсы. Бронирование задается списком ResourceIDs события;
+// операции над событиями возвращают ErrResourceBusy, если ресурс уже занят
+// пересекающимся по времени активным событием, и ErrResourceNotFound для неизвестных ресурсов.
+type ResourceStore interface {
+	CreateResource(ctx context.Context, resource *models.Resource) error
+	UpdateResource(ctx context.Context, resource *models.Resource) error
+	// DeleteResource возвращает ErrResourceInUse, если ресурс забронирован активным событием.
+	// Восстановление из корзины события, ссылающегося на удаленный ресурс, вернет ErrResourceNotFound.
+	DeleteResource(ctx context.Context, id string) error
+	GetResource(ctx context.Context, id string) (*models.Resource, error)
+	ListResources(ctx context.Context, filter models.ResourceFilter) ([]*models.Resource, error)
+	ListBookings(ctx context.Context, resourceID string, from, to time.Time) ([]*models.Booking, error)
+}
+
+// ScheduleStore — рабочее время и периоды отсутствия пользователей.
+type ScheduleStore interface {
+	// GetWorkingHours возвращает ErrWorkingHoursNotFound, если рабочее время не задано.
+	GetWorkingHours(ctx context.Context, userID string) (*models.WorkingHours, error)
+	SetWorkingHours(ctx context.Context, hours *models.WorkingHours) error
+	AddOutOfOffice(ctx context.Context, period *models.OutOfOffice) error
+	// ListOutOfOffice возвращает периоды отсутствия пользователя, пересекающиеся с [from, to),
+	// отсортированные по времени начала.
+	ListOutOfOffice(ctx context.Context, userID string, from, to time.Time) ([]*models.OutOfOffice, error)
+	DeleteOutOfOffice(ctx context.Context, userID, id string) error
+}
+
+// TemplateStore — шаблоны событий. События, созданные из шаблона, с ним не связаны:
+// изменение или удаление шаблона их не затрагивает.
+type TemplateStore interface {
+	CreateTemplate(ctx context.Context, template *models.EventTemplate) error
+	// UpdateTemplate сохраняет CreatedAt шаблона и возвращает ErrTemplateNotFound для неизвестного ID.
+	UpdateTemplate(ctx context.Context, template *models.EventTemplate) error
+	DeleteTemplate(ctx context.Context, id string) error
+	GetTemplate(ctx context.Context, id string) (*models.EventTemplate, error)
+	// ListTemplates возвращает шаблоны, отсортированные по имени.
+	ListTemplates(ctx context.Context) ([]*models.EventTemplate, error)
+}
+
+// SnapshotStore — выгрузка и загрузка всего содержимого хранилища.
+type SnapshotStore interface {
+	// Snapshot возвращает согласованный снимок всех данных хранилища.
+	Snapshot(ctx context.Context) (*models.Snapshot, error)
+	// LoadSnapshot загружает снимок в пустое хранилище, сохраняя ID, версии и время
+	// создания записей. Конфликты времени и бронирований не проверяются.
+	// Возвращает ErrStorageNotEmpty, если в хранилище уже есть данные.
+	LoadSnapshot(ctx context.Context, snapshot *models.Snapshot) error
+}
@@ -0,0 +1,7 @@
+ALTER TABLE events ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE events ADD COLUMN category VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN color VARCHAR(7) NOT NULL DEFAULT '';
+ALTER TABLE events ADD COLUMN priority VARCHAR(16) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_events_tags ON events USING GIN (tags);
+CREATE INDEX idx_events_category ON events(category);
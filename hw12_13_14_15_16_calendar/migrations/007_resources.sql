@@ -0,0 +1,24 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+CREATE TABLE resources (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    capacity INTEGER NOT NULL DEFAULT 0,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_resources_kind ON resources(kind);
+
+ALTER TABLE events ADD COLUMN resource_ids TEXT[] NOT NULL DEFAULT '{}';
+
+-- Бронирования ресурсов активными событиями. Список ресурсов события хранится
+-- в events.resource_ids, эта таблица нужна для запрета пересекающихся бронирований.
+CREATE TABLE event_resources (
+    event_id VARCHAR(36) NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    resource_id VARCHAR(36) NOT NULL REFERENCES resources(id),
+    period TSRANGE NOT NULL,
+    PRIMARY KEY (event_id, resource_id),
+    EXCLUDE USING gist (resource_id WITH =, period WITH &&)
+);
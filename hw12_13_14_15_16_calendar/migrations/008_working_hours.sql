@@ -0,0 +1,21 @@
+CREATE TABLE working_hours (
+    user_id VARCHAR(255) PRIMARY KEY,
+    time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    -- Рабочие интервалы по дням недели: [{"weekday": 1, "start": ..., "end": ...}]
+    windows JSONB NOT NULL DEFAULT '[]',
+    defer_reminders BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE out_of_office (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    start_time TIMESTAMP NOT NULL,
+    end_time TIMESTAMP NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_out_of_office_user ON out_of_office(user_id, start_time);
+
+-- Планировщик выбирает события по времени напоминания
+CREATE INDEX idx_events_reminder ON events(reminder) WHERE deleted_at IS NULL;
@@ -0,0 +1,15 @@
+CREATE TABLE event_templates (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    title_pattern VARCHAR(255) NOT NULL,
+    -- Длительность и напоминание по умолчанию в секундах
+    duration BIGINT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    notify_before BIGINT NOT NULL DEFAULT 0,
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    category VARCHAR(100) NOT NULL DEFAULT '',
+    color VARCHAR(7) NOT NULL DEFAULT '',
+    priority VARCHAR(16) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,9 @@
+-- Перенос напоминания планировщиком: действует, пока reminder равен reminder_deferred_from
+ALTER TABLE events ADD COLUMN reminder_deferred_from TIMESTAMP;
+ALTER TABLE events ADD COLUMN reminder_deferred_to TIMESTAMP;
+
+-- Планировщик выбирает события по времени напоминания с учетом переноса
+DROP INDEX idx_events_reminder;
+CREATE INDEX idx_events_reminder_at ON events(
+    (CASE WHEN reminder_deferred_from = reminder THEN reminder_deferred_to ELSE reminder END)
+) WHERE deleted_at IS NULL;